@@ -2,9 +2,14 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"github.com/joho/godotenv"
+	"github.com/tredoc/go-crud-api/internal/handler"
+	"github.com/tredoc/go-crud-api/pkg/types"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type dbConfig struct {
@@ -24,10 +29,16 @@ type cacheConfig struct {
 }
 
 type config struct {
-	port  string
-	env   string
-	db    dbConfig
-	cache cacheConfig
+	port               string
+	env                string
+	db                 dbConfig
+	cache              cacheConfig
+	holdSweepInterval  time.Duration
+	trashRetention     time.Duration
+	trashPurgeInterval time.Duration
+	authorOnDelete     types.DeletePolicy
+	genreOnDelete      types.DeletePolicy
+	cachePolicies      handler.CachePolicies
 }
 
 func getConfig() (*config, error) {
@@ -41,6 +52,45 @@ func getConfig() (*config, error) {
 		return nil, errors.New("can't convert redis port to int")
 	}
 
+	holdSweepInterval := time.Minute
+	if interval := os.Getenv("HOLD_SWEEP_INTERVAL"); interval != "" {
+		holdSweepInterval, err = time.ParseDuration(interval)
+		if err != nil || holdSweepInterval <= 0 {
+			return nil, errors.New("can't parse hold sweep interval")
+		}
+	}
+
+	trashRetention := 30 * 24 * time.Hour
+	if retention := os.Getenv("TRASH_RETENTION"); retention != "" {
+		trashRetention, err = time.ParseDuration(retention)
+		if err != nil || trashRetention <= 0 {
+			return nil, errors.New("can't parse trash retention")
+		}
+	}
+
+	trashPurgeInterval := time.Hour
+	if interval := os.Getenv("TRASH_PURGE_INTERVAL"); interval != "" {
+		trashPurgeInterval, err = time.ParseDuration(interval)
+		if err != nil || trashPurgeInterval <= 0 {
+			return nil, errors.New("can't parse trash purge interval")
+		}
+	}
+
+	authorOnDelete, err := getDeletePolicy("AUTHOR_ON_DELETE")
+	if err != nil {
+		return nil, err
+	}
+
+	genreOnDelete, err := getDeletePolicy("GENRE_ON_DELETE")
+	if err != nil {
+		return nil, err
+	}
+
+	cachePolicies, err := getCachePolicies()
+	if err != nil {
+		return nil, err
+	}
+
 	return &config{
 		port: os.Getenv("PORT"),
 		env:  os.Getenv("ENV"),
@@ -58,5 +108,55 @@ func getConfig() (*config, error) {
 			password: os.Getenv("REDIS_PASSWORD"),
 			dbs:      redisDBS,
 		},
+		holdSweepInterval:  holdSweepInterval,
+		trashRetention:     trashRetention,
+		trashPurgeInterval: trashPurgeInterval,
+		authorOnDelete:     authorOnDelete,
+		genreOnDelete:      genreOnDelete,
+		cachePolicies:      cachePolicies,
 	}, nil
 }
+
+// getDeletePolicy reads a delete policy from the environment, cascade when it isn't set.
+func getDeletePolicy(key string) (types.DeletePolicy, error) {
+	policy := types.DeletePolicy(os.Getenv(key))
+	if policy == "" {
+		return types.CascadeOnDelete, nil
+	}
+
+	if !policy.IsValid() {
+		return "", fmt.Errorf("%s must be one of restrict, cascade", key)
+	}
+
+	return policy, nil
+}
+
+// getCachePolicies reads Cache-Control of read routes from the environment. CACHE_CONTROL is the
+// default, "private, no-cache" when it isn't set. CACHE_CONTROL_ROUTES overrides it for single
+// routes as semicolon separated path=value pairs, e.g. "/api/v1/genres=public, max-age=300".
+func getCachePolicies() (handler.CachePolicies, error) {
+	policies := handler.CachePolicies{
+		Default: "private, no-cache",
+		Routes:  make(map[string]string),
+	}
+
+	if policy := os.Getenv("CACHE_CONTROL"); policy != "" {
+		policies.Default = policy
+	}
+
+	for _, route := range strings.Split(os.Getenv("CACHE_CONTROL_ROUTES"), ";") {
+		route = strings.TrimSpace(route)
+		if route == "" {
+			continue
+		}
+
+		path, policy, ok := strings.Cut(route, "=")
+		path, policy = strings.TrimSpace(path), strings.TrimSpace(policy)
+		if !ok || !strings.HasPrefix(path, "/") || policy == "" {
+			return policies, fmt.Errorf("can't parse cache control of route %q", route)
+		}
+		policies.Routes[path] = policy
+	}
+
+	return policies, nil
+}
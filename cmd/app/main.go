@@ -1,17 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
 	_ "github.com/lib/pq"
 	"github.com/tredoc/go-crud-api/internal/cache"
 	"github.com/tredoc/go-crud-api/internal/handler"
 	"github.com/tredoc/go-crud-api/internal/repository"
 	"github.com/tredoc/go-crud-api/internal/service"
 	"github.com/tredoc/go-crud-api/pkg/log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 )
 
 // @title Swagger go-crud-api API
 // @version 4.0
 // @description personal educational project
+// @description Responses are JSON, XML or MessagePack, lists also CSV, as the Accept header asks. A successful read none of them is acceptable for gets 406 Not Acceptable, while errors and the results of changes, which are made by then, fall back to JSON.
 
 // @host localhost:3000
 // @BasePath /
@@ -38,13 +46,36 @@ func main() {
 	}
 	defer db.Close()
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var workers sync.WaitGroup
 	rch := cache.NewCache(rdb)
 	repos := repository.NewRepository(db)
-	services := service.NewService(repos, rch)
-	handlers := handler.NewHandler(services)
+	services := service.NewService(ctx, &workers, repos, rch, service.Policies{
+		AuthorOnDelete: cfg.authorOnDelete,
+		GenreOnDelete:  cfg.genreOnDelete,
+	})
+	handlers := handler.NewHandler(services, cfg.cachePolicies)
 
-	err = runServer(cfg, handlers)
-	if err != nil {
+	workers.Add(3)
+	go func() {
+		defer workers.Done()
+		runHoldSweeper(ctx, cfg, services.Hold)
+	}()
+	go func() {
+		defer workers.Done()
+		runTrashPurger(ctx, cfg, services.Trash)
+	}()
+	go func() {
+		defer workers.Done()
+		runImportReaper(ctx, services.Import)
+	}()
+
+	err = runServer(ctx, cfg, handlers)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatal(err.Error())
 	}
+
+	workers.Wait()
 }
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"github.com/redis/go-redis/v9"
 	"github.com/tredoc/go-crud-api/internal/handler"
+	"github.com/tredoc/go-crud-api/internal/service"
 	"github.com/tredoc/go-crud-api/pkg/log"
 	"net/http"
+	"time"
 )
 
 func runDB(cfg *config) (*sql.DB, error) {
@@ -42,12 +44,106 @@ func runRCache(cfg *config) (*redis.Client, error) {
 	return rdb, nil
 }
 
-func runServer(cfg *config, handlers *handler.Handler) error {
+// shutdownTimeout bounds how long the server waits for requests in flight when it's stopped.
+const shutdownTimeout = 30 * time.Second
+
+// runServer serves until the context is cancelled, then stops taking connections and waits for the
+// requests in flight.
+func runServer(ctx context.Context, cfg *config, handlers *handler.Handler) error {
 	srv := http.Server{
 		Addr:    fmt.Sprintf(":%s", cfg.port),
 		Handler: handlers.InitRoutes(),
 	}
 
 	log.Info(fmt.Sprintf("starting %s server on %s port", cfg.env, cfg.port))
-	return srv.ListenAndServe()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info("shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
+
+// runHoldSweeper periodically expires ready holds that weren't picked up in time, until the context
+// is cancelled.
+func runHoldSweeper(ctx context.Context, cfg *config, holds service.Hold) {
+	ticker := time.NewTicker(cfg.holdSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		expired, err := holds.ExpireHolds(ctx)
+		if err != nil {
+			log.Error(err.Error())
+			continue
+		}
+
+		if expired > 0 {
+			log.Info(fmt.Sprintf("expired %d uncollected holds", expired))
+		}
+	}
+}
+
+// runTrashPurger periodically removes items that stayed in the trash longer than the retention,
+// until the context is cancelled.
+func runTrashPurger(ctx context.Context, cfg *config, trash service.Trash) {
+	ticker := time.NewTicker(cfg.trashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		purged, err := trash.PurgeTrash(ctx, time.Now().Add(-cfg.trashRetention))
+		if err != nil {
+			log.Error(err.Error())
+			continue
+		}
+
+		if purged > 0 {
+			log.Info(fmt.Sprintf("purged %d items from the trash", purged))
+		}
+	}
+}
+
+// importReapInterval is how often imports that stopped saving their progress are looked for.
+const importReapInterval = time.Minute
+
+// runImportReaper fails the imports that servers went down with, right away and then periodically,
+// until the context is cancelled.
+func runImportReaper(ctx context.Context, imports service.Import) {
+	ticker := time.NewTicker(importReapInterval)
+	defer ticker.Stop()
+
+	for {
+		failed, err := imports.FailStaleImports(ctx)
+		if err != nil {
+			log.Error(err.Error())
+		} else if failed > 0 {
+			log.Info(fmt.Sprintf("failed %d interrupted imports", failed))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
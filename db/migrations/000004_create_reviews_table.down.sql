@@ -0,0 +1,4 @@
+ALTER TABLE books DROP COLUMN IF EXISTS rating_sum;
+ALTER TABLE books DROP COLUMN IF EXISTS rating_count;
+
+DROP TABLE IF EXISTS reviews;
\ No newline at end of file
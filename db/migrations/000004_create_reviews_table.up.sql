@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS reviews (
+    id bigserial PRIMARY KEY,
+    book_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    rating smallint NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    text text NOT NULL,
+    created_at timestamp DEFAULT (now()),
+    updated_at timestamp DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS review_book_user_index ON reviews ("book_id", "user_id");
+
+ALTER TABLE reviews ADD FOREIGN KEY ("book_id") REFERENCES books(id);
+ALTER TABLE reviews ADD FOREIGN KEY ("user_id") REFERENCES users(id);
+
+ALTER TABLE books
+ADD COLUMN IF NOT EXISTS rating_count integer NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS rating_sum bigint NOT NULL DEFAULT 0;
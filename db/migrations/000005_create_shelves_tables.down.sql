@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS shelf_book;
+DROP TABLE IF EXISTS shelves;
+DROP TABLE IF EXISTS reading_entries;
\ No newline at end of file
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS reading_entries (
+    user_id bigint NOT NULL,
+    book_id bigint NOT NULL,
+    status varchar(20) CHECK (status IN ('want-to-read', 'reading', 'read')) NOT NULL,
+    current_page smallint NOT NULL DEFAULT 0,
+    started_at date,
+    finished_at date,
+    updated_at timestamp DEFAULT (now())
+);
+
+CREATE TABLE IF NOT EXISTS shelves (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    name varchar(100) NOT NULL,
+    created_at timestamp DEFAULT (now())
+);
+
+CREATE TABLE IF NOT EXISTS shelf_book (
+    shelf_id bigint NOT NULL,
+    book_id bigint NOT NULL,
+    added_at timestamp DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS reading_entry_user_book_index ON reading_entries ("user_id", "book_id");
+CREATE UNIQUE INDEX IF NOT EXISTS shelf_user_name_index ON shelves ("user_id", "name");
+CREATE UNIQUE INDEX IF NOT EXISTS shelf_book_index ON shelf_book ("shelf_id", "book_id");
+
+ALTER TABLE reading_entries ADD FOREIGN KEY ("user_id") REFERENCES users(id);
+ALTER TABLE reading_entries ADD FOREIGN KEY ("book_id") REFERENCES books(id);
+ALTER TABLE shelves ADD FOREIGN KEY ("user_id") REFERENCES users(id);
+ALTER TABLE shelf_book ADD FOREIGN KEY ("shelf_id") REFERENCES shelves(id);
+ALTER TABLE shelf_book ADD FOREIGN KEY ("book_id") REFERENCES books(id);
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS copies (
+    id bigserial PRIMARY KEY,
+    book_id bigint NOT NULL,
+    barcode varchar(100) NOT NULL,
+    location varchar(100) NOT NULL DEFAULT '',
+    condition varchar(20) CHECK (condition IN ('new', 'good', 'fair', 'poor', 'damaged')) NOT NULL DEFAULT 'good',
+    created_at timestamp DEFAULT (now())
+);
+
+CREATE TABLE IF NOT EXISTS loans (
+    id bigserial PRIMARY KEY,
+    copy_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    checked_out_at timestamp NOT NULL DEFAULT (now()),
+    due_at timestamp NOT NULL,
+    returned_at timestamp,
+    renewals smallint NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS copy_barcode_index ON copies ("barcode");
+CREATE INDEX IF NOT EXISTS copy_book_index ON copies ("book_id");
+CREATE UNIQUE INDEX IF NOT EXISTS loan_active_copy_index ON loans ("copy_id") WHERE returned_at IS NULL;
+CREATE INDEX IF NOT EXISTS loan_user_index ON loans ("user_id");
+
+ALTER TABLE copies ADD FOREIGN KEY ("book_id") REFERENCES books(id);
+ALTER TABLE loans ADD FOREIGN KEY ("copy_id") REFERENCES copies(id);
+ALTER TABLE loans ADD FOREIGN KEY ("user_id") REFERENCES users(id);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS holds (
+    id bigserial PRIMARY KEY,
+    book_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    status varchar(20) CHECK (status IN ('waiting', 'ready', 'fulfilled', 'cancelled', 'expired')) NOT NULL DEFAULT 'waiting',
+    copy_id bigint,
+    created_at timestamp NOT NULL DEFAULT (now()),
+    ready_at timestamp,
+    expires_at timestamp
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS hold_open_user_book_index ON holds ("book_id", "user_id") WHERE status IN ('waiting', 'ready');
+CREATE UNIQUE INDEX IF NOT EXISTS hold_ready_copy_index ON holds ("copy_id") WHERE status = 'ready';
+CREATE INDEX IF NOT EXISTS hold_book_status_index ON holds ("book_id", "status");
+
+ALTER TABLE holds ADD FOREIGN KEY ("book_id") REFERENCES books(id);
+ALTER TABLE holds ADD FOREIGN KEY ("user_id") REFERENCES users(id);
+ALTER TABLE holds ADD FOREIGN KEY ("copy_id") REFERENCES copies(id);
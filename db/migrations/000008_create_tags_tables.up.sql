@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id bigserial PRIMARY KEY,
+    name varchar(50) UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS book_tag (
+    book_id bigint NOT NULL,
+    tag_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    created_at timestamp DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS book_tag_index ON book_tag ("book_id", "tag_id");
+CREATE INDEX IF NOT EXISTS book_tag_tag_index ON book_tag ("tag_id");
+
+ALTER TABLE book_tag ADD FOREIGN KEY ("book_id") REFERENCES books(id);
+ALTER TABLE book_tag ADD FOREIGN KEY ("tag_id") REFERENCES tags(id);
+ALTER TABLE book_tag ADD FOREIGN KEY ("user_id") REFERENCES users(id);
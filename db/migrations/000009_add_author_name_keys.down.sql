@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS author_name_key_index;
+
+ALTER TABLE authors DROP COLUMN IF EXISTS last_name_key;
+ALTER TABLE authors DROP COLUMN IF EXISTS first_name_key;
\ No newline at end of file
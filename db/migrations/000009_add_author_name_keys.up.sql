@@ -0,0 +1,7 @@
+ALTER TABLE authors
+ADD COLUMN IF NOT EXISTS first_name_key varchar(100) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS last_name_key varchar(100) NOT NULL DEFAULT '';
+
+UPDATE authors SET first_name_key = lower(first_name), last_name_key = lower(last_name);
+
+CREATE INDEX IF NOT EXISTS author_name_key_index ON authors ("last_name_key", "first_name_key");
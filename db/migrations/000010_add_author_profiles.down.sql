@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS author_aliases;
+
+DROP INDEX IF EXISTS author_wikidata_index;
+DROP INDEX IF EXISTS author_viaf_index;
+DROP INDEX IF EXISTS author_isni_index;
+
+ALTER TABLE authors DROP COLUMN IF EXISTS wikidata;
+ALTER TABLE authors DROP COLUMN IF EXISTS viaf;
+ALTER TABLE authors DROP COLUMN IF EXISTS isni;
+ALTER TABLE authors DROP COLUMN IF EXISTS biography;
+ALTER TABLE authors DROP COLUMN IF EXISTS nationality;
+ALTER TABLE authors DROP COLUMN IF EXISTS death_date;
+ALTER TABLE authors DROP COLUMN IF EXISTS birth_date;
\ No newline at end of file
@@ -0,0 +1,24 @@
+ALTER TABLE authors
+ADD COLUMN IF NOT EXISTS birth_date date,
+ADD COLUMN IF NOT EXISTS death_date date,
+ADD COLUMN IF NOT EXISTS nationality varchar(2) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS biography text NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS isni varchar(16),
+ADD COLUMN IF NOT EXISTS viaf varchar(22),
+ADD COLUMN IF NOT EXISTS wikidata varchar(20);
+
+CREATE UNIQUE INDEX IF NOT EXISTS author_isni_index ON authors ("isni");
+CREATE UNIQUE INDEX IF NOT EXISTS author_viaf_index ON authors ("viaf");
+CREATE UNIQUE INDEX IF NOT EXISTS author_wikidata_index ON authors ("wikidata");
+
+CREATE TABLE IF NOT EXISTS author_aliases (
+    id bigserial PRIMARY KEY,
+    author_id bigint NOT NULL,
+    name varchar(100) NOT NULL,
+    name_key varchar(100) NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS author_alias_name_key_index ON author_aliases ("name_key");
+CREATE INDEX IF NOT EXISTS author_alias_author_index ON author_aliases ("author_id");
+
+ALTER TABLE author_aliases ADD FOREIGN KEY ("author_id") REFERENCES authors(id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS genre_redirects;
+DROP TABLE IF EXISTS author_redirects;
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS author_redirects (
+    source_id bigint PRIMARY KEY,
+    target_id bigint NOT NULL,
+    merged_at timestamp DEFAULT (now())
+);
+
+CREATE TABLE IF NOT EXISTS genre_redirects (
+    source_id bigint PRIMARY KEY,
+    target_id bigint NOT NULL,
+    merged_at timestamp DEFAULT (now())
+);
+
+CREATE INDEX IF NOT EXISTS author_redirect_target_index ON author_redirects ("target_id");
+CREATE INDEX IF NOT EXISTS genre_redirect_target_index ON genre_redirects ("target_id");
+
+ALTER TABLE author_redirects ADD FOREIGN KEY ("target_id") REFERENCES authors(id);
+ALTER TABLE genre_redirects ADD FOREIGN KEY ("target_id") REFERENCES genres(id);
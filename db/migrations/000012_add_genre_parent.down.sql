@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS genre_parent_index;
+
+ALTER TABLE genres DROP COLUMN IF EXISTS parent_id;
\ No newline at end of file
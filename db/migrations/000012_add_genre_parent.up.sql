@@ -0,0 +1,5 @@
+ALTER TABLE genres ADD COLUMN IF NOT EXISTS parent_id bigint;
+
+CREATE INDEX IF NOT EXISTS genre_parent_index ON genres ("parent_id");
+
+ALTER TABLE genres ADD FOREIGN KEY ("parent_id") REFERENCES genres(id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS book_translations;
+DROP TABLE IF EXISTS genre_translations;
\ No newline at end of file
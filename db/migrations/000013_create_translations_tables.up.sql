@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS genre_translations (
+    genre_id bigint NOT NULL,
+    language varchar(35) NOT NULL,
+    name varchar(100) NOT NULL,
+    PRIMARY KEY (genre_id, language)
+);
+
+CREATE TABLE IF NOT EXISTS book_translations (
+    book_id bigint NOT NULL,
+    language varchar(35) NOT NULL,
+    title varchar(255) NOT NULL,
+    PRIMARY KEY (book_id, language)
+);
+
+ALTER TABLE genre_translations ADD FOREIGN KEY ("genre_id") REFERENCES genres(id);
+ALTER TABLE book_translations ADD FOREIGN KEY ("book_id") REFERENCES books(id);
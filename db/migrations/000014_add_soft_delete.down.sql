@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS author_isni_index;
+DROP INDEX IF EXISTS author_viaf_index;
+DROP INDEX IF EXISTS author_wikidata_index;
+
+CREATE UNIQUE INDEX IF NOT EXISTS author_isni_index ON authors ("isni");
+CREATE UNIQUE INDEX IF NOT EXISTS author_viaf_index ON authors ("viaf");
+CREATE UNIQUE INDEX IF NOT EXISTS author_wikidata_index ON authors ("wikidata");
+
+DROP INDEX IF EXISTS genre_deleted_at_index;
+DROP INDEX IF EXISTS author_deleted_at_index;
+DROP INDEX IF EXISTS book_deleted_at_index;
+
+ALTER TABLE genres DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE authors DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE books DROP COLUMN IF EXISTS deleted_at;
\ No newline at end of file
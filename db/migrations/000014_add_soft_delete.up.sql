@@ -0,0 +1,15 @@
+ALTER TABLE books ADD COLUMN IF NOT EXISTS deleted_at timestamp;
+ALTER TABLE authors ADD COLUMN IF NOT EXISTS deleted_at timestamp;
+ALTER TABLE genres ADD COLUMN IF NOT EXISTS deleted_at timestamp;
+
+CREATE INDEX IF NOT EXISTS book_deleted_at_index ON books ("deleted_at") WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS author_deleted_at_index ON authors ("deleted_at") WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS genre_deleted_at_index ON genres ("deleted_at") WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS author_isni_index;
+DROP INDEX IF EXISTS author_viaf_index;
+DROP INDEX IF EXISTS author_wikidata_index;
+
+CREATE UNIQUE INDEX IF NOT EXISTS author_isni_index ON authors ("isni") WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS author_viaf_index ON authors ("viaf") WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS author_wikidata_index ON authors ("wikidata") WHERE deleted_at IS NULL;
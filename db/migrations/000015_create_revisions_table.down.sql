@@ -0,0 +1 @@
+DROP TABLE IF EXISTS revisions;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS revisions (
+    id bigserial PRIMARY KEY,
+    entity_type varchar(10) NOT NULL,
+    entity_id bigint NOT NULL,
+    action varchar(10) NOT NULL,
+    snapshot jsonb NOT NULL,
+    actor_id bigint,
+    created_at timestamp DEFAULT (now())
+);
+
+CREATE INDEX IF NOT EXISTS revision_entity_index ON revisions ("entity_type", "entity_id", "id");
+
+ALTER TABLE revisions ADD FOREIGN KEY ("actor_id") REFERENCES users(id);
@@ -0,0 +1,3 @@
+ALTER TABLE genres DROP COLUMN IF EXISTS version;
+ALTER TABLE authors DROP COLUMN IF EXISTS version;
+ALTER TABLE books DROP COLUMN IF EXISTS version;
\ No newline at end of file
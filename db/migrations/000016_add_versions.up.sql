@@ -0,0 +1,3 @@
+ALTER TABLE books ADD COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 1;
+ALTER TABLE authors ADD COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 1;
+ALTER TABLE genres ADD COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 1;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id bigserial PRIMARY KEY,
+    format varchar(10) NOT NULL,
+    dry_run boolean NOT NULL DEFAULT false,
+    status varchar(10) NOT NULL,
+    total_rows integer NOT NULL DEFAULT 0,
+    processed_rows integer NOT NULL DEFAULT 0,
+    imported_rows integer NOT NULL DEFAULT 0,
+    failed_rows integer NOT NULL DEFAULT 0,
+    failure text NOT NULL DEFAULT '',
+    errors jsonb NOT NULL DEFAULT '[]',
+    actor_id bigint,
+    created_at timestamp DEFAULT (now()),
+    heartbeat_at timestamp NOT NULL DEFAULT (now()),
+    finished_at timestamp
+);
+
+ALTER TABLE import_jobs ADD FOREIGN KEY ("actor_id") REFERENCES users(id);
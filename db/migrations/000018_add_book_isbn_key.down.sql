@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS book_isbn_key_index;
+
+ALTER TABLE books DROP COLUMN IF EXISTS isbn_key;
@@ -0,0 +1,4 @@
+ALTER TABLE books
+ADD COLUMN IF NOT EXISTS isbn_key varchar(100) GENERATED ALWAYS AS (upper(regexp_replace(isbn, '[^0-9Xx]', '', 'g'))) STORED;
+
+CREATE INDEX IF NOT EXISTS book_isbn_key_index ON books ("isbn_key") WHERE deleted_at IS NULL;
@@ -17,18 +17,29 @@ const docTemplate = `{
     "paths": {
         "/api/v1/authors": {
             "get": {
-                "description": "Get a list of all authors",
+                "description": "Get a list of all authors, or the author whose full name or alias matches the name",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "authors"
                 ],
                 "summary": "Get all authors",
                 "operationId": "get-all-authors",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Full name or alias, compared ignoring case and accents",
+                        "name": "name",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -55,7 +66,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "authors"
@@ -90,7 +103,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "authors"
@@ -111,6 +126,12 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/types.Author"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the author followed by a hash of the response"
+                            }
                         }
                     }
                 }
@@ -121,18 +142,20 @@ const docTemplate = `{
                         "Bearer": []
                     }
                 ],
-                "description": "Update an author with a specific ID",
+                "description": "Replace an author with a specific ID or create the author under that ID when there is none yet",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "authors"
                 ],
-                "summary": "Update an author",
-                "operationId": "update-author",
+                "summary": "Replace an author",
+                "operationId": "replace-author",
                 "parameters": [
                     {
                         "type": "integer",
@@ -142,12 +165,18 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Author object that needs to be updated",
+                        "type": "string",
+                        "description": "ETag of the author version the replacement is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Author object that replaces the stored one",
                         "name": "author",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/types.UpdateAuthor"
+                            "$ref": "#/definitions/types.Author"
                         }
                     }
                 ],
@@ -157,6 +186,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/types.Author"
                         }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.Author"
+                        }
                     }
                 }
             },
@@ -166,12 +201,14 @@ const docTemplate = `{
                         "Bearer": []
                     }
                 ],
-                "description": "Delete an author with a specific ID",
+                "description": "Move an author with a specific ID to the trash, it can be restored until the trash is purged. Depending on the delete policy an author listed on books is either dropped from them or kept with 409",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "authors"
@@ -185,6 +222,13 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the author version the deletion is based on",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -192,94 +236,91 @@ const docTemplate = `{
                         "description": "No Content"
                     }
                 }
-            }
-        },
-        "/api/v1/books": {
-            "get": {
-                "description": "Get a list of all books",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "books"
-                ],
-                "summary": "Get all books",
-                "operationId": "get-all-books",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "array",
-                                "items": {
-                                    "$ref": "#/definitions/types.Book"
-                                }
-                            }
-                        }
-                    }
-                }
             },
-            "post": {
+            "patch": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Create a new book with the provided details",
+                "description": "Update an author with a specific ID. Besides the partial object, the body can be a JSON merge patch (application/merge-patch+json) or a JSON patch (application/json-patch+json) applied to the author",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "books"
+                    "authors"
                 ],
-                "summary": "Create a new book",
-                "operationId": "create-book",
+                "summary": "Update an author",
+                "operationId": "update-author",
                 "parameters": [
                     {
-                        "description": "Book object that needs to be added",
-                        "name": "book",
+                        "type": "integer",
+                        "description": "Author ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the author version the change is based on",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Author object that needs to be updated",
+                        "name": "author",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/types.Book"
+                            "$ref": "#/definitions/types.UpdateAuthor"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.BookWithDetails"
+                            "$ref": "#/definitions/types.Author"
                         }
                     }
                 }
             }
         },
-        "/api/v1/books/{id}": {
+        "/api/v1/authors/{id}/books": {
             "get": {
-                "description": "Get details of a book",
+                "description": "Get the bibliography of an author sorted by publish date",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "books"
+                    "authors"
                 ],
-                "summary": "Get details of a book",
-                "operationId": "get-book",
+                "summary": "Get books of an author",
+                "operationId": "get-author-books",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Preferred languages of the translated names and titles",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    },
                     {
                         "type": "integer",
-                        "description": "Book ID",
+                        "description": "Author ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -289,50 +330,54 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.BookWithDetails"
-                        },
-                        "headers": {
-                            "Token": {
-                                "type": "string",
-                                "description": "qwerty"
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/types.Book"
+                                }
                             }
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/v1/authors/{id}/merge": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Update a book with a specific ID",
+                "description": "Move books of the source author to the author with a specific ID and delete the source, the source ID keeps resolving to the target",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "books"
+                    "authors"
                 ],
-                "summary": "Update a book",
-                "operationId": "update-book",
+                "summary": "Merge a duplicate author",
+                "operationId": "merge-authors",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Book ID",
+                        "description": "Target Author ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Book object that needs to be updated",
-                        "name": "book",
+                        "description": "Source author that is merged into the target",
+                        "name": "merge",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/types.UpdateBook"
+                            "$ref": "#/definitions/types.Merge"
                         }
                     }
                 ],
@@ -340,33 +385,37 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.Book"
+                            "$ref": "#/definitions/types.Author"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/v1/authors/{id}/restore": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Delete a book with a specific ID",
+                "description": "Restore an author from the trash and list it on its books again",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "books"
+                    "trash"
                 ],
-                "summary": "Delete a book",
-                "operationId": "delete-book",
+                "summary": "Restore a deleted author",
+                "operationId": "restore-author",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Book ID",
+                        "description": "Author ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -379,20 +428,37 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/genres": {
+        "/api/v1/authors/{id}/revisions": {
             "get": {
-                "description": "Get a list of all genres",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get snapshots of an author taken after every change, the latest first",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "genres"
+                    "authors"
+                ],
+                "summary": "Get revisions of an author",
+                "operationId": "get-author-revisions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Author ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all genres",
-                "operationId": "get-all-genres",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -401,347 +467,4407 @@ const docTemplate = `{
                             "items": {
                                 "type": "array",
                                 "items": {
-                                    "$ref": "#/definitions/types.Genre"
+                                    "$ref": "#/definitions/types.Revision"
                                 }
                             }
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/api/v1/authors/{id}/revisions/diff": {
+            "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Create a new genre with the input payload",
+                "description": "Get the fields that differ between two revisions of an author",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "genres"
+                    "authors"
                 ],
-                "summary": "Create a new genre",
-                "operationId": "create-genre",
+                "summary": "Compare two revisions of an author",
+                "operationId": "diff-author-revisions",
                 "parameters": [
                     {
-                        "description": "Genre object that needs to be added",
-                        "name": "genre",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/types.Genre"
-                        }
+                        "type": "integer",
+                        "description": "Author ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision ID to compare from",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision ID to compare to",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.Genre"
+                            "$ref": "#/definitions/types.RevisionDiff"
                         }
                     }
                 }
             }
         },
-        "/api/v1/genres/{id}": {
-            "get": {
-                "description": "Get details of a genre by ID",
+        "/api/v1/authors/{id}/revisions/{revision}/revert": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Bring an author back to a revision, the result is recorded as a new revision",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "genres"
+                    "authors"
                 ],
-                "summary": "Get details of a genre",
-                "operationId": "get-genre-by-id",
+                "summary": "Revert an author to a revision",
+                "operationId": "revert-author",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Genre ID",
+                        "description": "Author ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision ID",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the author version the revert is based on",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.Genre"
+                            "$ref": "#/definitions/types.Author"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/v1/authors:batch": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Update a genre with a specific ID",
+                "description": "Apply up to 500 operations. An atomic batch applies all of them or none and is answered with the status of the failing operation, a best effort batch applies every operation that succeeds. Every operation gets a result with the status its own request would get, 424 for operations of a failed atomic batch",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "genres"
+                    "authors"
                 ],
-                "summary": "Update a genre",
-                "operationId": "update-genre",
+                "summary": "Create, update and delete authors in a batch",
+                "operationId": "batch-authors",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Genre ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Genre object that needs to be updated",
-                        "name": "genre",
+                        "description": "Mode and operations of the batch",
+                        "name": "batch",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/types.Genre"
+                            "$ref": "#/definitions/types.AuthorBatch"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of the request replay its response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.Genre"
+                            "type": "object",
+                            "properties": {
+                                "results": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/types.BatchResult"
+                                    }
+                                }
+                            }
                         }
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "Delete a genre with a specific ID",
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/api/v1/books": {
+            "get": {
+                "description": "Get a list of all books, optionally filtered by tags and genre or searched by title and author",
+                "consumes": [
+                    "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "genres"
+                    "books"
                 ],
-                "summary": "Delete a genre",
-                "operationId": "delete-genre",
+                "summary": "Get all books",
+                "operationId": "get-all-books",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Preferred languages of the translated names and titles",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated list of tags",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "any (default) or all of the tags",
+                        "name": "match",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "Genre ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "name": "genre",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include books of the subgenres of the genre",
+                        "name": "subgenres",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text in the title or in the name of an author",
+                        "name": "q",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/types.Book"
+                                }
+                            }
+                        }
                     }
                 }
-            }
-        },
-        "/api/v1/users/login": {
+            },
             "post": {
-                "description": "Log in a user with the input payload",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create a new book with the provided details",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "user"
+                    "books"
                 ],
-                "summary": "Log in a user",
-                "operationId": "login-user",
+                "summary": "Create a new book",
+                "operationId": "create-book",
                 "parameters": [
                     {
-                        "description": "User object that needs to log in",
-                        "name": "user",
+                        "description": "Book object that needs to be added",
+                        "name": "book",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/types.AuthUser"
+                            "$ref": "#/definitions/types.Book"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "{ \\\"access_token\\\": \\\"token\\\" }",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/types.BookWithDetails"
                         }
                     }
                 }
             }
         },
-        "/api/v1/users/register": {
-            "post": {
-                "description": "Register a new user with the input payload",
+        "/api/v1/books/{id}": {
+            "get": {
+                "description": "Get details of a book",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Get details of a book",
+                "operationId": "get-book",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Preferred languages of the translated names and titles",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.BookWithDetails"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the book followed by a hash of the response"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Replace all editable fields of a book with a specific ID or create the book under that ID when there is none yet",
+                "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
                 "tags": [
-                    "user"
+                    "books"
                 ],
-                "summary": "Register a new user",
-                "operationId": "register-user",
+                "summary": "Replace a book",
+                "operationId": "replace-book",
                 "parameters": [
                     {
-                        "description": "User object that needs to be registered",
-                        "name": "user",
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the book version the replacement is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Book object that replaces the stored one",
+                        "name": "book",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/types.AuthUser"
+                            "$ref": "#/definitions/types.Book"
                         }
                     }
                 ],
                 "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Book"
+                        }
+                    },
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/types.AuthUser"
+                            "$ref": "#/definitions/types.Book"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Move a book with a specific ID to the trash, it can be restored until the trash is purged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Delete a book",
+                "operationId": "delete-book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the book version the deletion is based on",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Update a book with a specific ID. Besides the partial object, the body can be a JSON merge patch (application/merge-patch+json) or a JSON patch (application/json-patch+json) applied to the editable fields of the book",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Update a book",
+                "operationId": "update-book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the book version the change is based on",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Book object that needs to be updated",
+                        "name": "book",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateBook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Book"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/books/{id}/citation": {
+            "get": {
+                "description": "Get the citation of a book for reference managers as BibTeX, RIS or CSL-JSON. The citation key is made of the last name of the first author, the year and the first word of the title, followed by the ID of the book",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/x-bibtex",
+                    "application/x-research-info-systems",
+                    "application/vnd.citationstyles.csl+json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Cite a book",
+                "operationId": "get-book-citation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "bibtex (default), ris or csl-json",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Citation",
+                        "schema": {
+                            "type": "string"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "types.AuthUser": {
+        },
+        "/api/v1/books/{id}/copies": {
+            "get": {
+                "description": "Get a list of physical copies of a book with their availability",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "copies"
+                ],
+                "summary": "Get copies of a book",
+                "operationId": "get-book-copies",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/types.Copy"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Add a physical copy with a unique barcode, shelf location and condition",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "copies"
+                ],
+                "summary": "Add a physical copy of a book",
+                "operationId": "create-copy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Copy object that needs to be added",
+                        "name": "copy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.Copy"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.Copy"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/books/{id}/holds": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get the ready and waiting holds on a book in queue order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "holds"
+                ],
+                "summary": "Get holds on a book",
+                "operationId": "get-book-holds",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/types.Hold"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Queue the current user for a book when all of its copies are on loan",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "holds"
+                ],
+                "summary": "Place a hold on a book",
+                "operationId": "place-hold",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.Hold"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/books/{id}/marc": {
+            "get": {
+                "description": "Get a book as a MARC 21 bibliographic record, in ISO 2709 or as MARCXML. The catalogue keeps no publisher, the record only holds the year of publication",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/marc",
+                    "application/marcxml+xml"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Get the MARC record of a book",
+                "operationId": "get-book-marc",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "marc (default) or marcxml",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "MARC record",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/books/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Restore a book from the trash together with its authors, genres, tags and copies",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Restore a deleted book",
+                "operationId": "restore-book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/api/v1/books/{id}/reviews": {
+            "get": {
+                "description": "Get a list of all reviews of a book, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Get reviews of a book",
+                "operationId": "get-book-reviews",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/types.Review"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Post a rating and review text for a book, one review per user and book",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Review a book",
+                "operationId": "create-review",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review object that needs to be added",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.Review"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.Review"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/books/{id}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get snapshots of a book taken after every change, the latest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Get revisions of a book",
+                "operationId": "get-book-revisions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/types.Revision"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/books/{id}/revisions/diff": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get the fields that differ between two revisions of a book",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Compare two revisions of a book",
+                "operationId": "diff-book-revisions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision ID to compare from",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision ID to compare to",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.RevisionDiff"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/books/{id}/revisions/{revision}/revert": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Bring title, publish date, ISBN, pages, authors and genres of a book back to a revision, the result is recorded as a new revision",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Revert a book to a revision",
+                "operationId": "revert-book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision ID",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the book version the revert is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Book"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/books/{id}/tags": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Apply free-form tags to a book, tags are lowercased and may contain unicode letters, digits, spaces and hyphens",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Tag a book",
+                "operationId": "add-book-tags",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tags that need to be applied",
+                        "name": "tags",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.BookTags"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Remove tags from a book, users may only remove tags they applied, admins may remove any tag",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Remove tags from a book",
+                "operationId": "remove-book-tags",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tags that need to be removed",
+                        "name": "tags",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.BookTags"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/api/v1/books/{id}/translations": {
+            "get": {
+                "description": "Get all translations of a book title",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Get translations of a book",
+                "operationId": "get-book-translations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/types.Translation"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/books/{id}/translations/{language}": {
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create or replace the title of a book in the language given by a BCP 47 tag",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Set a translation of a book",
+                "operationId": "set-book-translation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "BCP 47 language tag",
+                        "name": "language",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Translated title, the language is taken from the path",
+                        "name": "translation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.Translation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Translation"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete the translation of a book in the language given by a BCP 47 tag",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Delete a translation of a book",
+                "operationId": "delete-book-translation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "BCP 47 language tag",
+                        "name": "language",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/api/v1/books:batch": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Apply up to 500 operations. An atomic batch applies all of them or none and is answered with the status of the failing operation, a best effort batch applies every operation that succeeds. Every operation gets a result with the status its own request would get, 424 for operations of a failed atomic batch",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Create, update and delete books in a batch",
+                "operationId": "batch-books",
+                "parameters": [
+                    {
+                        "description": "Mode and operations of the batch",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.BookBatch"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of the request replay its response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "results": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/types.BatchResult"
+                                    }
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/citations": {
+            "get": {
+                "description": "Get the citations of several books at once for reference managers as BibTeX, RIS or CSL-JSON, in the order of the IDs. The citation key of a book ends with its ID, so it's the same in every export",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/x-bibtex",
+                    "application/x-research-info-systems",
+                    "application/vnd.citationstyles.csl+json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Cite books",
+                "operationId": "get-citations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma separated list of up to 100 book IDs",
+                        "name": "ids",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "bibtex (default), ris or csl-json",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Citations",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/copies/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete a copy with a specific ID, copies on an active loan can't be deleted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "copies"
+                ],
+                "summary": "Delete a copy",
+                "operationId": "delete-copy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Copy ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Update barcode, location or condition of a copy with a specific ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "copies"
+                ],
+                "summary": "Update a copy",
+                "operationId": "update-copy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Copy ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Copy object that needs to be updated",
+                        "name": "copy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateCopy"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Copy"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/export/books": {
+            "get": {
+                "description": "Stream the books matching the filters of the book list as CSV, NDJSON or a JSON array, with their authors, genres and tags by name, or as MARC 21 records in ISO 2709 or a MARCXML collection. The export isn't paged, a failure midway cuts the response short",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/marc",
+                    "application/marcxml+xml"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Export books",
+                "operationId": "export-books",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "csv, ndjson, json (default), marc or marcxml",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated list of tags",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "any (default) or all of the tags",
+                        "name": "match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Genre ID",
+                        "name": "genre",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include books of the subgenres of the genre",
+                        "name": "subgenres",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text in the title or in the name of an author",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/types.ExportedBook"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/genres": {
+            "get": {
+                "description": "Get a list of all genres",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "genres"
+                ],
+                "summary": "Get all genres",
+                "operationId": "get-all-genres",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Preferred languages of the translated names and titles",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/types.Genre"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create a new genre with the input payload",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "genres"
+                ],
+                "summary": "Create a new genre",
+                "operationId": "create-genre",
+                "parameters": [
+                    {
+                        "description": "Genre object that needs to be added",
+                        "name": "genre",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.Genre"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.Genre"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/genres/tree": {
+            "get": {
+                "description": "Get top level genres with their subgenres nested under them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "genres"
+                ],
+                "summary": "Get the genre hierarchy",
+                "operationId": "get-genre-tree",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Preferred languages of the translated names and titles",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/types.GenreNode"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/genres/{id}": {
+            "get": {
+                "description": "Get details of a genre by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "genres"
+                ],
+                "summary": "Get details of a genre",
+                "operationId": "get-genre-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Preferred languages of the translated names and titles",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Genre ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Genre"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the genre followed by a hash of the response"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Replace a genre with a specific ID or create the genre under that ID when there is none yet. A genre without parent_id becomes a top level one",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "genres"
+                ],
+                "summary": "Replace a genre",
+                "operationId": "replace-genre",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Genre ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the genre version the replacement is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Genre object that replaces the stored one",
+                        "name": "genre",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.Genre"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Genre"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.Genre"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Move a genre with a specific ID to the trash, it can be restored until the trash is purged. Depending on the delete policy a genre listed on books is either dropped from them or kept with 409",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "genres"
+                ],
+                "summary": "Delete a genre",
+                "operationId": "delete-genre",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Genre ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the genre version the deletion is based on",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Update a genre with a specific ID, parent_id 0 moves it to the top level and an omitted parent_id keeps its parent",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "genres"
+                ],
+                "summary": "Update a genre",
+                "operationId": "update-genre",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Genre ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the genre version the change is based on",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Genre object that needs to be updated",
+                        "name": "genre",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.Genre"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Genre"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/genres/{id}/merge": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Move books of the source genre to the genre with a specific ID and delete the source, the source ID keeps resolving to the target",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "genres"
+                ],
+                "summary": "Merge a duplicate genre",
+                "operationId": "merge-genres",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Target Genre ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Source genre that is merged into the target",
+                        "name": "merge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.Merge"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Genre"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/genres/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Restore a genre from the trash, list it on its books again and put its subgenres back under it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Restore a deleted genre",
+                "operationId": "restore-genre",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Genre ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/api/v1/genres/{id}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get snapshots of a genre taken after every change, the latest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "genres"
+                ],
+                "summary": "Get revisions of a genre",
+                "operationId": "get-genre-revisions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Genre ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/types.Revision"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/genres/{id}/revisions/diff": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get the fields that differ between two revisions of a genre",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "genres"
+                ],
+                "summary": "Compare two revisions of a genre",
+                "operationId": "diff-genre-revisions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Genre ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision ID to compare from",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision ID to compare to",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.RevisionDiff"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/genres/{id}/revisions/{revision}/revert": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Bring the name and the parent of a genre back to a revision, the result is recorded as a new revision",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "genres"
+                ],
+                "summary": "Revert a genre to a revision",
+                "operationId": "revert-genre",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Genre ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision ID",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the genre version the revert is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Genre"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/genres/{id}/translations": {
+            "get": {
+                "description": "Get all translations of a genre name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "genres"
+                ],
+                "summary": "Get translations of a genre",
+                "operationId": "get-genre-translations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Genre ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/types.Translation"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/genres/{id}/translations/{language}": {
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create or replace the name of a genre in the language given by a BCP 47 tag",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "genres"
+                ],
+                "summary": "Set a translation of a genre",
+                "operationId": "set-genre-translation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Genre ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "BCP 47 language tag",
+                        "name": "language",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Translated name, the language is taken from the path",
+                        "name": "translation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.Translation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Translation"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete the translation of a genre in the language given by a BCP 47 tag",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "genres"
+                ],
+                "summary": "Delete a translation of a genre",
+                "operationId": "delete-genre-translation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Genre ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "BCP 47 language tag",
+                        "name": "language",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/api/v1/genres:batch": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Apply up to 500 operations. An atomic batch applies all of them or none and is answered with the status of the failing operation, a best effort batch applies every operation that succeeds. Every operation gets a result with the status its own request would get, 424 for operations of a failed atomic batch",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "genres"
+                ],
+                "summary": "Create, update and delete genres in a batch",
+                "operationId": "batch-genres",
+                "parameters": [
+                    {
+                        "description": "Mode and operations of the batch",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.GenreBatch"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of the request replay its response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "results": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/types.BatchResult"
+                                    }
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/holds/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Cancel an open hold with a specific ID, a copy it was holding goes to the next in queue",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "holds"
+                ],
+                "summary": "Cancel a hold",
+                "operationId": "cancel-hold",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/api/v1/imports": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Upload books as CSV with a title, publish_date, isbn, pages, authors and genres header, authors and genres separated by semicolons, or as NDJSON with a book per line. Authors and genres are given by name and created when they don't exist. The rows are imported in the background, follow the job for the progress",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "imports"
+                ],
+                "summary": "Import books from a file",
+                "operationId": "import-books",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Check every row without importing anything",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of the request replay its response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/types.ImportJob"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the import job"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/imports/marc": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Upload MARC 21 bibliographic records in ISO 2709 or as MARCXML. A record creates its book or, when a book with its ISBN exists, replaces that one. Authors come from 100 and 700, genres from 655 and 650 and are created when they don't exist, pages from 300 and the year of publication from 264, 260 or 008. The records are imported right away, the result of every record is at its index",
+                "consumes": [
+                    "application/marc",
+                    "application/marcxml+xml"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "imports"
+                ],
+                "summary": "Import books from MARC records",
+                "operationId": "import-marc",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of the request replay its response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/types.BatchResult"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/imports/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get the status and progress of a book import",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "imports"
+                ],
+                "summary": "Get an import job",
+                "operationId": "get-import-job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Import job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ImportJob"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/imports/{id}/errors": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get the rows of an import that failed as CSV with a line for every field error, the row being the line of the upload",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "imports"
+                ],
+                "summary": "Download the error report of an import job",
+                "operationId": "get-import-errors",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Import job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "row,field,message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/loans": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get active, overdue or returned loans of all users",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "loans"
+                ],
+                "summary": "Get loans",
+                "operationId": "get-loans",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "active (default), overdue or returned",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/types.Loan"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Check out a copy by barcode for the current user, admins may check out for another user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "loans"
+                ],
+                "summary": "Check out a copy",
+                "operationId": "checkout",
+                "parameters": [
+                    {
+                        "description": "Copy barcode and optional borrower",
+                        "name": "checkout",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.Checkout"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.Loan"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/loans/{id}/renew": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Extend the due date of a loan with a specific ID unless it is overdue or renewed too often",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "loans"
+                ],
+                "summary": "Renew a loan",
+                "operationId": "renew-loan",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Loan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Loan"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/loans/{id}/return": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Close a loan with a specific ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "loans"
+                ],
+                "summary": "Return a copy",
+                "operationId": "return-loan",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Loan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Loan"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/holds": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get open holds of the current user with their queue positions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "holds"
+                ],
+                "summary": "Get my holds",
+                "operationId": "get-my-holds",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/types.Hold"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/loans": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get active loans of the current user ordered by due date",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "loans"
+                ],
+                "summary": "Get my loans",
+                "operationId": "get-my-loans",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/types.Loan"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/reading/{id}": {
+            "patch": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Update status, current page and start/finish dates of a book the current user reads",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "shelves"
+                ],
+                "summary": "Update reading progress",
+                "operationId": "update-reading",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reading progress that needs to be updated",
+                        "name": "reading",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateReading"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ReadingEntry"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/shelves": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get the built-in want-to-read, reading and read shelves and custom shelves of the current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "shelves"
+                ],
+                "summary": "Get my shelves",
+                "operationId": "get-shelves",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/types.Shelf"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create a custom named shelf for the current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "shelves"
+                ],
+                "summary": "Create a custom shelf",
+                "operationId": "create-shelf",
+                "parameters": [
+                    {
+                        "description": "Shelf object that needs to be added",
+                        "name": "shelf",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.Shelf"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.Shelf"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/shelves/{shelf}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get books on a built-in shelf (want-to-read, reading, read) or on a custom shelf by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "shelves"
+                ],
+                "summary": "Get books on a shelf",
+                "operationId": "get-shelf-books",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Built-in shelf name or custom shelf ID",
+                        "name": "shelf",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/types.ShelvedBook"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete a custom shelf of the current user, built-in shelves can't be deleted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "shelves"
+                ],
+                "summary": "Delete a custom shelf",
+                "operationId": "delete-shelf",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Custom shelf ID",
+                        "name": "shelf",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/api/v1/me/shelves/{shelf}/books": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Put a book on a shelf, moving it between built-in shelves updates its reading dates",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "shelves"
+                ],
+                "summary": "Put a book on a shelf",
+                "operationId": "add-book-to-shelf",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Built-in shelf name or custom shelf ID",
+                        "name": "shelf",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Book that needs to be shelved",
+                        "name": "book",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.ShelfBook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/api/v1/me/shelves/{shelf}/books/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Remove a book from a built-in or custom shelf",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "shelves"
+                ],
+                "summary": "Remove a book from a shelf",
+                "operationId": "remove-book-from-shelf",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Built-in shelf name or custom shelf ID",
+                        "name": "shelf",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/api/v1/me/stats": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get number of books and pages read per year and month by the current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "shelves"
+                ],
+                "summary": "Get yearly reading statistics",
+                "operationId": "get-reading-stats",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Year, current year by default",
+                        "name": "year",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ReadingStats"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/opds": {
+            "get": {
+                "description": "Get the navigation feed that leads to the newest books, the books by genre and by author and the search. The feeds are served as OPDS 1.2 Atom under /api/v1/opds and as OPDS 2.0 JSON under /api/v1/opds2",
+                "produces": [
+                    "application/atom+xml",
+                    "application/opds+json"
+                ],
+                "tags": [
+                    "opds"
+                ],
+                "summary": "Get the root of the OPDS catalogue",
+                "operationId": "get-opds-root",
+                "responses": {
+                    "200": {
+                        "description": "Navigation feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/opds/authors": {
+            "get": {
+                "description": "Get a navigation feed with an entry per author by last name",
+                "produces": [
+                    "application/atom+xml",
+                    "application/opds+json"
+                ],
+                "tags": [
+                    "opds"
+                ],
+                "summary": "Get the authors of the OPDS catalogue",
+                "operationId": "get-opds-authors",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page, 1 by default",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Navigation feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/opds/authors/{id}": {
+            "get": {
+                "description": "Get the books of the author by title as an acquisition feed",
+                "produces": [
+                    "application/atom+xml",
+                    "application/opds+json"
+                ],
+                "tags": [
+                    "opds"
+                ],
+                "summary": "Get the books of an author of the OPDS catalogue",
+                "operationId": "get-opds-author-books",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Author ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page, 1 by default",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Acquisition feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/opds/genres": {
+            "get": {
+                "description": "Get a navigation feed with an entry per genre",
+                "produces": [
+                    "application/atom+xml",
+                    "application/opds+json"
+                ],
+                "tags": [
+                    "opds"
+                ],
+                "summary": "Get the genres of the OPDS catalogue",
+                "operationId": "get-opds-genres",
+                "responses": {
+                    "200": {
+                        "description": "Navigation feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/opds/genres/{id}": {
+            "get": {
+                "description": "Get the books of the genre and its subgenres by title as an acquisition feed",
+                "produces": [
+                    "application/atom+xml",
+                    "application/opds+json"
+                ],
+                "tags": [
+                    "opds"
+                ],
+                "summary": "Get the books of a genre of the OPDS catalogue",
+                "operationId": "get-opds-genre-books",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Genre ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page, 1 by default",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Acquisition feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/opds/new": {
+            "get": {
+                "description": "Get the books that were added last as an acquisition feed",
+                "produces": [
+                    "application/atom+xml",
+                    "application/opds+json"
+                ],
+                "tags": [
+                    "opds"
+                ],
+                "summary": "Get the newest books of the OPDS catalogue",
+                "operationId": "get-opds-newest",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page, 1 by default",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Acquisition feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/opds/opensearch.xml": {
+            "get": {
+                "description": "Get the OpenSearch description that tells e-reader apps how to search the OPDS feeds and the book list",
+                "produces": [
+                    "application/opensearchdescription+xml"
+                ],
+                "tags": [
+                    "opds"
+                ],
+                "summary": "Get the OpenSearch description of the catalogue",
+                "operationId": "get-opensearch-description",
+                "responses": {
+                    "200": {
+                        "description": "OpenSearch description",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/opds/search": {
+            "get": {
+                "description": "Get the books with the text in their title or in the name of one of their authors by title as an acquisition feed",
+                "produces": [
+                    "application/atom+xml",
+                    "application/opds+json"
+                ],
+                "tags": [
+                    "opds"
+                ],
+                "summary": "Search the OPDS catalogue",
+                "operationId": "search-opds",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Text in the title or in the name of an author",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page, 1 by default",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Acquisition feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/reviews/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete a review with a specific ID, allowed for its author and admins",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Delete a review",
+                "operationId": "delete-review",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Update a review with a specific ID, allowed for its author and admins",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Update a review",
+                "operationId": "update-review",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review object that needs to be updated",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateReview"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Review"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tags": {
+            "get": {
+                "description": "Get the most used tags with the number of books they are applied to",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Get tag cloud",
+                "operationId": "get-tag-cloud",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of tags, 50 by default, 200 at most",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/types.TagCount"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/trash": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get deleted books, authors and genres that can still be restored, the most recently deleted first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Get the trash",
+                "operationId": "get-trash",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Trash"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/login": {
+            "post": {
+                "description": "Log in a user with the input payload",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Log in a user",
+                "operationId": "login-user",
+                "parameters": [
+                    {
+                        "description": "User object that needs to log in",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.AuthUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "{ \\\"access_token\\\": \\\"token\\\" }",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/register": {
+            "post": {
+                "description": "Register a new user with the input payload",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Register a new user",
+                "operationId": "register-user",
+                "parameters": [
+                    {
+                        "description": "User object that needs to be registered",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.AuthUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.AuthUser"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "types.AuthUser": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.Author": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "biography": {
+                    "type": "string"
+                },
+                "birth_date": {
+                    "$ref": "#/definitions/types.CustomDate"
+                },
+                "death_date": {
+                    "$ref": "#/definitions/types.CustomDate"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "isni": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "middle_name": {
+                    "type": "string"
+                },
+                "nationality": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                },
+                "viaf": {
+                    "type": "string"
+                },
+                "wikidata": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.AuthorBatch": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "$ref": "#/definitions/types.BatchMode"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.AuthorOperation"
+                    }
+                }
+            }
+        },
+        "types.AuthorOperation": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "$ref": "#/definitions/types.Author"
+                },
+                "changes": {
+                    "$ref": "#/definitions/types.UpdateAuthor"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "op": {
+                    "$ref": "#/definitions/types.BatchOp"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.BatchMode": {
+            "type": "string",
+            "enum": [
+                "atomic",
+                "best_effort"
+            ],
+            "x-enum-varnames": [
+                "AtomicBatch",
+                "BestEffortBatch"
+            ]
+        },
+        "types.BatchOp": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "BatchCreate",
+                "BatchUpdate",
+                "BatchDelete"
+            ]
+        },
+        "types.BatchResult": {
+            "type": "object",
+            "properties": {
+                "error": {},
+                "id": {
+                    "type": "integer"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.Book": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "average_rating": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "genres": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "isbn": {
+                    "type": "string"
+                },
+                "pages": {
+                    "type": "integer"
+                },
+                "publish_date": {
+                    "$ref": "#/definitions/types.CustomDate"
+                },
+                "ratings_count": {
+                    "type": "integer"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.BookBatch": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "$ref": "#/definitions/types.BatchMode"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.BookOperation"
+                    }
+                }
+            }
+        },
+        "types.BookOperation": {
+            "type": "object",
+            "properties": {
+                "book": {
+                    "$ref": "#/definitions/types.Book"
+                },
+                "changes": {
+                    "$ref": "#/definitions/types.UpdateBook"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "op": {
+                    "$ref": "#/definitions/types.BatchOp"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.BookTags": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "types.BookWithDetails": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.Author"
+                    }
+                },
+                "average_rating": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "genres": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.Genre"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "isbn": {
+                    "type": "string"
+                },
+                "pages": {
+                    "type": "integer"
+                },
+                "publish_date": {
+                    "$ref": "#/definitions/types.CustomDate"
+                },
+                "ratings_count": {
+                    "type": "integer"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.Checkout": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.Condition": {
+            "type": "string",
+            "enum": [
+                "new",
+                "good",
+                "fair",
+                "poor",
+                "damaged"
+            ],
+            "x-enum-varnames": [
+                "NewCondition",
+                "GoodCondition",
+                "FairCondition",
+                "PoorCondition",
+                "DamagedCondition"
+            ]
+        },
+        "types.Copy": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "boolean"
+                },
+                "barcode": {
+                    "type": "string"
+                },
+                "book_id": {
+                    "type": "integer"
+                },
+                "condition": {
+                    "$ref": "#/definitions/types.Condition"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "location": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.CustomDate": {
+            "type": "object",
+            "properties": {
+                "time.Time": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.ExportedBook": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "average_rating": {
+                    "type": "number"
+                },
+                "genres": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "isbn": {
+                    "type": "string"
+                },
+                "pages": {
+                    "type": "integer"
+                },
+                "publish_date": {
+                    "$ref": "#/definitions/types.CustomDate"
+                },
+                "ratings_count": {
+                    "type": "integer"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.FieldChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "from": {},
+                "to": {}
+            }
+        },
+        "types.Genre": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.GenreBatch": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "$ref": "#/definitions/types.BatchMode"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.GenreOperation"
+                    }
+                }
+            }
+        },
+        "types.GenreNode": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.GenreNode"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.GenreOperation": {
+            "type": "object",
+            "properties": {
+                "genre": {
+                    "$ref": "#/definitions/types.Genre"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "op": {
+                    "$ref": "#/definitions/types.BatchOp"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.Hold": {
             "type": "object",
             "properties": {
-                "email": {
+                "book_id": {
+                    "type": "integer"
+                },
+                "copy_id": {
+                    "type": "integer"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "password": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "ready_at": {
                     "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.HoldStatus"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "types.Author": {
+        "types.HoldStatus": {
+            "type": "string",
+            "enum": [
+                "waiting",
+                "ready",
+                "fulfilled",
+                "cancelled",
+                "expired"
+            ],
+            "x-enum-varnames": [
+                "WaitingHold",
+                "ReadyHold",
+                "FulfilledHold",
+                "CancelledHold",
+                "ExpiredHold"
+            ]
+        },
+        "types.ImportFormat": {
+            "type": "string",
+            "enum": [
+                "csv",
+                "ndjson"
+            ],
+            "x-enum-varnames": [
+                "CSVImport",
+                "NDJSONImport"
+            ]
+        },
+        "types.ImportJob": {
             "type": "object",
             "properties": {
-                "first_name": {
+                "actor_id": {
+                    "type": "integer"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "id": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "failed_rows": {
                     "type": "integer"
                 },
-                "last_name": {
+                "failure": {
                     "type": "string"
                 },
-                "middle_name": {
+                "finished_at": {
                     "type": "string"
+                },
+                "format": {
+                    "$ref": "#/definitions/types.ImportFormat"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "imported_rows": {
+                    "type": "integer"
+                },
+                "processed_rows": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.ImportStatus"
+                },
+                "total_rows": {
+                    "type": "integer"
                 }
             }
         },
-        "types.Book": {
+        "types.ImportStatus": {
+            "type": "string",
+            "enum": [
+                "running",
+                "completed",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "ImportRunning",
+                "ImportCompleted",
+                "ImportFailed"
+            ]
+        },
+        "types.Loan": {
             "type": "object",
             "properties": {
-                "authors": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
+                "barcode": {
+                    "type": "string"
                 },
-                "created_at": {
+                "book_id": {
+                    "type": "integer"
+                },
+                "checked_out_at": {
                     "type": "string"
                 },
-                "genres": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
+                "copy_id": {
+                    "type": "integer"
+                },
+                "due_at": {
+                    "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "isbn": {
+                "renewals": {
+                    "type": "integer"
+                },
+                "returned_at": {
                     "type": "string"
                 },
-                "pages": {
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.Merge": {
+            "type": "object",
+            "properties": {
+                "source_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.ReadingEntry": {
+            "type": "object",
+            "properties": {
+                "book_id": {
                     "type": "integer"
                 },
-                "publish_date": {
+                "current_page": {
+                    "type": "integer"
+                },
+                "finished_at": {
                     "$ref": "#/definitions/types.CustomDate"
                 },
-                "title": {
+                "started_at": {
+                    "$ref": "#/definitions/types.CustomDate"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.ShelfStatus"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "types.BookWithDetails": {
+        "types.ReadingStats": {
             "type": "object",
             "properties": {
-                "authors": {
+                "books_by_month": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/types.Author"
+                        "type": "integer"
                     }
                 },
+                "books_read": {
+                    "type": "integer"
+                },
+                "currently_reading": {
+                    "type": "integer"
+                },
+                "pages_read": {
+                    "type": "integer"
+                },
+                "year": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.Review": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
-                "genres": {
+                "id": {
+                    "type": "integer"
+                },
+                "rating": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.Revision": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/types.RevisionAction"
+                },
+                "actor_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "snapshot": {
+                    "type": "object"
+                }
+            }
+        },
+        "types.RevisionAction": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete",
+                "revert",
+                "merge",
+                "restore",
+                "purge"
+            ],
+            "x-enum-varnames": [
+                "RevisionCreate",
+                "RevisionUpdate",
+                "RevisionDelete",
+                "RevisionRevert",
+                "RevisionMerge",
+                "RevisionRestore",
+                "RevisionPurge"
+            ]
+        },
+        "types.RevisionDiff": {
+            "type": "object",
+            "properties": {
+                "changes": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/types.Genre"
+                        "$ref": "#/definitions/types.FieldChange"
                     }
                 },
+                "from": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.Shelf": {
+            "type": "object",
+            "properties": {
+                "books_count": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "isbn": {
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.ShelfStatus"
+                }
+            }
+        },
+        "types.ShelfBook": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.ShelfStatus": {
+            "type": "string",
+            "enum": [
+                "want-to-read",
+                "reading",
+                "read"
+            ],
+            "x-enum-varnames": [
+                "WantToReadShelf",
+                "ReadingShelf",
+                "ReadShelf"
+            ]
+        },
+        "types.ShelvedBook": {
+            "type": "object",
+            "properties": {
+                "added_at": {
                     "type": "string"
                 },
+                "book_id": {
+                    "type": "integer"
+                },
+                "current_page": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "$ref": "#/definitions/types.CustomDate"
+                },
                 "pages": {
                     "type": "integer"
                 },
-                "publish_date": {
+                "started_at": {
                     "$ref": "#/definitions/types.CustomDate"
                 },
+                "status": {
+                    "$ref": "#/definitions/types.ShelfStatus"
+                },
                 "title": {
                     "type": "string"
                 }
             }
         },
-        "types.CustomDate": {
+        "types.TagCount": {
             "type": "object",
             "properties": {
-                "time.Time": {
+                "count": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
                 }
             }
         },
-        "types.Genre": {
+        "types.Translation": {
+            "type": "object",
+            "properties": {
+                "language": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.Trash": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.TrashItem"
+                    }
+                },
+                "books": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.TrashItem"
+                    }
+                },
+                "genres": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.TrashItem"
+                    }
+                }
+            }
+        },
+        "types.TrashItem": {
             "type": "object",
             "properties": {
+                "deleted_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -753,14 +4879,41 @@ const docTemplate = `{
         "types.UpdateAuthor": {
             "type": "object",
             "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "biography": {
+                    "type": "string"
+                },
+                "birth_date": {
+                    "$ref": "#/definitions/types.CustomDate"
+                },
+                "death_date": {
+                    "$ref": "#/definitions/types.CustomDate"
+                },
                 "first_name": {
                     "type": "string"
                 },
+                "isni": {
+                    "type": "string"
+                },
                 "last_name": {
                     "type": "string"
                 },
                 "middle_name": {
                     "type": "string"
+                },
+                "nationality": {
+                    "type": "string"
+                },
+                "viaf": {
+                    "type": "string"
+                },
+                "wikidata": {
+                    "type": "string"
                 }
             }
         },
@@ -792,6 +4945,48 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "types.UpdateCopy": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string"
+                },
+                "condition": {
+                    "$ref": "#/definitions/types.Condition"
+                },
+                "location": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.UpdateReading": {
+            "type": "object",
+            "properties": {
+                "current_page": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "$ref": "#/definitions/types.CustomDate"
+                },
+                "started_at": {
+                    "$ref": "#/definitions/types.CustomDate"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.ShelfStatus"
+                }
+            }
+        },
+        "types.UpdateReview": {
+            "type": "object",
+            "properties": {
+                "rating": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -810,7 +5005,7 @@ var SwaggerInfo = &swag.Spec{
 	BasePath:         "/",
 	Schemes:          []string{},
 	Title:            "Swagger go-crud-api API",
-	Description:      "personal educational project",
+	Description:      "personal educational project\nResponses are JSON, XML or MessagePack, lists also CSV, as the Accept header asks. A successful read none of them is acceptable for gets 406 Not Acceptable, while errors and the results of changes, which are made by then, fall back to JSON.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
@@ -12,6 +12,7 @@ require (
 	github.com/swaggo/http-swagger v1.3.4
 	github.com/swaggo/swag v1.16.3
 	golang.org/x/crypto v0.21.0
+	golang.org/x/text v0.14.0
 )
 
 require (
@@ -7,7 +7,9 @@ import (
 
 type RCache interface {
 	Set(string, interface{}, time.Duration) error
+	SetNX(string, interface{}, time.Duration) (bool, error)
 	Get(string) (string, error)
+	Expire(string, time.Duration) (bool, error)
 	Invalidate(string)
 }
 
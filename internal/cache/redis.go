@@ -28,6 +28,11 @@ func (c *Redis) Set(key string, value interface{}, expiration time.Duration) err
 	return nil
 }
 
+// SetNX sets the key only when it doesn't exist yet and reports whether it did.
+func (c *Redis) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	return c.rdb.SetNX(context.Background(), key, value, expiration).Result()
+}
+
 func (c *Redis) Get(key string) (string, error) {
 	val, err := c.rdb.Get(context.Background(), key).Result()
 	if errors.Is(err, redis.Nil) {
@@ -38,6 +43,11 @@ func (c *Redis) Get(key string) (string, error) {
 	return val, nil
 }
 
+// Expire sets a new expiration of the key and reports whether the key exists.
+func (c *Redis) Expire(key string, expiration time.Duration) (bool, error) {
+	return c.rdb.Expire(context.Background(), key, expiration).Result()
+}
+
 func (c *Redis) Invalidate(key string) {
 	err := c.rdb.Del(context.Background(), key).Err()
 	if err != nil {
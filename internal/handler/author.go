@@ -28,7 +28,7 @@ func NewAuthorHandler(service service.Author) *AuthorHandler {
 // @Tags authors
 // @ID create-author
 // @Accept  json
-// @Produce  json
+// @Produce  json,xml,application/msgpack
 // @Param author body types.Author true "Author object that needs to be added"
 // @Security Bearer
 // @Success 201 {object} types.Author
@@ -51,14 +51,14 @@ func (h *AuthorHandler) CreateAuthor(w http.ResponseWriter, r *http.Request, _ h
 	newAuthor, err := h.service.CreateAuthor(r.Context(), &author)
 	if err != nil {
 		if errors.Is(err, service.ErrEntityExists) {
-			badRequestResponse(w, r, fmt.Errorf("author '%s %s %s' already exists", author.FirstName, author.MiddleName, author.LastName))
+			badRequestResponse(w, r, fmt.Errorf("author '%s %s %s', one of its aliases or external identifiers already exists", author.FirstName, author.MiddleName, author.LastName))
 			return
 		}
 		serverErrorResponse(w, r, err)
 		return
 	}
 
-	err = writeJSON(w, http.StatusCreated, envelope{"author": newAuthor}, nil)
+	err = writeResponse(w, r, http.StatusCreated, envelope{"author": newAuthor}, nil)
 	if err != nil {
 		log.Error(err.Error())
 	}
@@ -70,9 +70,10 @@ func (h *AuthorHandler) CreateAuthor(w http.ResponseWriter, r *http.Request, _ h
 // @Tags authors
 // @ID get-author-by-id
 // @Accept  json
-// @Produce  json
+// @Produce  json,xml,application/msgpack
 // @Param id path int true "Author ID"
 // @Success 200 {object} types.Author
+// @Header 200 {string} ETag "Version of the author followed by a hash of the response"
 // @Router /api/v1/authors/{id} [get]
 func (h *AuthorHandler) GetAuthorByID(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	id, err := getIdParam(ps)
@@ -91,7 +92,7 @@ func (h *AuthorHandler) GetAuthorByID(w http.ResponseWriter, r *http.Request, ps
 		return
 	}
 
-	err = writeJSON(w, http.StatusOK, envelope{"author": author}, nil)
+	err = writeResponse(w, r, http.StatusOK, envelope{"author": author}, etagHeader(author.Version))
 	if err != nil {
 		log.Error(err.Error())
 	}
@@ -99,14 +100,20 @@ func (h *AuthorHandler) GetAuthorByID(w http.ResponseWriter, r *http.Request, ps
 
 // GetAllAuthors godoc
 // @Summary Get all authors
-// @Description Get a list of all authors
+// @Description Get a list of all authors, or the author whose full name or alias matches the name
 // @Tags authors
 // @ID get-all-authors
 // @Accept  json
-// @Produce  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param name query string false "Full name or alias, compared ignoring case and accents"
 // @Success 200 {array} []types.Author
 // @Router /api/v1/authors [get]
 func (h *AuthorHandler) GetAllAuthors(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if name := r.URL.Query().Get("name"); name != "" {
+		h.findAuthorByName(w, r, name)
+		return
+	}
+
 	authors, err := h.service.GetAllAuthors(r.Context())
 	if err != nil {
 		if errors.Is(err, service.ErrNotFound) {
@@ -117,7 +124,7 @@ func (h *AuthorHandler) GetAllAuthors(w http.ResponseWriter, r *http.Request, _
 		return
 	}
 
-	err = writeJSON(w, http.StatusOK, envelope{"authors": authors}, nil)
+	err = writeResponse(w, r, http.StatusOK, envelope{"authors": authors}, nil)
 	if err != nil {
 		log.Error(err.Error())
 	}
@@ -125,16 +132,17 @@ func (h *AuthorHandler) GetAllAuthors(w http.ResponseWriter, r *http.Request, _
 
 // UpdateAuthor godoc
 // @Summary Update an author
-// @Description Update an author with a specific ID
+// @Description Update an author with a specific ID. Besides the partial object, the body can be a JSON merge patch (application/merge-patch+json) or a JSON patch (application/json-patch+json) applied to the author
 // @Tags authors
 // @ID update-author
-// @Accept  json
-// @Produce  json
+// @Accept  json,application/merge-patch+json,application/json-patch+json
+// @Produce  json,xml,application/msgpack
 // @Param id path int true "Author ID"
+// @Param If-Match header string true "ETag of the author version the change is based on"
 // @Param author body types.UpdateAuthor true "Author object that needs to be updated"
 // @Security Bearer
 // @Success 200 {object} types.Author
-// @Router /api/v1/authors/{id} [put]
+// @Router /api/v1/authors/{id} [patch]
 func (h *AuthorHandler) UpdateAuthor(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	id, err := getIdParam(ps)
 	if err != nil {
@@ -142,7 +150,88 @@ func (h *AuthorHandler) UpdateAuthor(w http.ResponseWriter, r *http.Request, ps
 		return
 	}
 
-	var author types.UpdateAuthor
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	patch, err := decodePatch(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	var updatedAuthor *types.Author
+	if patch != nil {
+		updatedAuthor, err = h.service.PatchAuthor(r.Context(), id, version, patch)
+	} else {
+		var author types.UpdateAuthor
+		err = json.NewDecoder(r.Body).Decode(&author)
+		if err != nil {
+			badRequestResponse(w, r, errors.New("can't decode request"))
+			return
+		}
+
+		v := validator.New()
+		types.ValidateUpdateAuthor(v, &author)
+		if !v.IsValid() {
+			notValidResponse(w, r, v.Errors)
+			return
+		}
+
+		updatedAuthor, err = h.service.UpdateAuthor(r.Context(), id, version, &author)
+	}
+	if err != nil {
+		var validationErr *service.ValidationError
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrVersionMismatch):
+			preconditionFailedResponse(w, r)
+		case errors.Is(err, service.ErrEntityExists):
+			conflictResponse(w, r, "alias or external identifier belongs to another author")
+		case errors.As(err, &validationErr):
+			notValidResponse(w, r, validationErr.Errors)
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"author": updatedAuthor}, etagHeader(updatedAuthor.Version))
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// ReplaceAuthor godoc
+// @Summary Replace an author
+// @Description Replace an author with a specific ID or create the author under that ID when there is none yet
+// @Tags authors
+// @ID replace-author
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Author ID"
+// @Param If-Match header string false "ETag of the author version the replacement is based on"
+// @Param author body types.Author true "Author object that replaces the stored one"
+// @Security Bearer
+// @Success 200 {object} types.Author
+// @Success 201 {object} types.Author
+// @Router /api/v1/authors/{id} [put]
+func (h *AuthorHandler) ReplaceAuthor(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	version, ok := getIfMatch(r)
+	if !ok {
+		preconditionFailedResponse(w, r)
+		return
+	}
+
+	var author types.Author
 	err = json.NewDecoder(r.Body).Decode(&author)
 	if err != nil {
 		badRequestResponse(w, r, errors.New("can't decode request"))
@@ -150,23 +239,35 @@ func (h *AuthorHandler) UpdateAuthor(w http.ResponseWriter, r *http.Request, ps
 	}
 
 	v := validator.New()
-	types.ValidateUpdateAuthor(v, &author)
+	types.ValidateAuthor(v, &author)
 	if !v.IsValid() {
 		notValidResponse(w, r, v.Errors)
 		return
 	}
 
-	updatedAuthor, err := h.service.UpdateAuthor(r.Context(), id, &author)
+	replacedAuthor, created, err := h.service.ReplaceAuthor(r.Context(), id, version, &author)
 	if err != nil {
-		if errors.Is(err, service.ErrNotFound) {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
 			notFoundResponse(w, r)
-			return
+		case errors.Is(err, service.ErrVersionMismatch):
+			preconditionFailedResponse(w, r)
+		case errors.Is(err, service.ErrIDUnavailable):
+			conflictResponse(w, r, fmt.Sprintf("author %d is in the trash or was merged into another author", id))
+		case errors.Is(err, service.ErrEntityExists):
+			conflictResponse(w, r, "alias or external identifier belongs to another author")
+		default:
+			serverErrorResponse(w, r, err)
 		}
-		serverErrorResponse(w, r, err)
 		return
 	}
 
-	err = writeJSON(w, http.StatusOK, envelope{"author": updatedAuthor}, nil)
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+
+	err = writeResponse(w, r, status, envelope{"author": replacedAuthor}, etagHeader(replacedAuthor.Version))
 	if err != nil {
 		log.Error(err.Error())
 	}
@@ -174,12 +275,13 @@ func (h *AuthorHandler) UpdateAuthor(w http.ResponseWriter, r *http.Request, ps
 
 // DeleteAuthor godoc
 // @Summary Delete an author
-// @Description Delete an author with a specific ID
+// @Description Move an author with a specific ID to the trash, it can be restored until the trash is purged. Depending on the delete policy an author listed on books is either dropped from them or kept with 409
 // @Tags authors
 // @ID delete-author
 // @Accept  json
-// @Produce  json
+// @Produce  json,xml,application/msgpack
 // @Param id path int true "Author ID"
+// @Param If-Match header string true "ETag of the author version the deletion is based on"
 // @Security Bearer
 // @Success 204 "No Content"
 // @Router /api/v1/authors/{id} [delete]
@@ -190,7 +292,48 @@ func (h *AuthorHandler) DeleteAuthor(w http.ResponseWriter, r *http.Request, ps
 		return
 	}
 
-	err = h.service.DeleteAuthor(r.Context(), id)
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	err = h.service.DeleteAuthor(r.Context(), id, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrVersionMismatch):
+			preconditionFailedResponse(w, r)
+		case errors.Is(err, service.ErrEntityInUse):
+			conflictResponse(w, r, "author is still listed on books, remove it from them first")
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetAuthorBooks godoc
+// @Summary Get books of an author
+// @Description Get the bibliography of an author sorted by publish date
+// @Tags authors
+// @ID get-author-books
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param Accept-Language header string false "Preferred languages of the translated names and titles"
+// @Param id path int true "Author ID"
+// @Success 200 {array} []types.Book
+// @Router /api/v1/authors/{id}/books [get]
+func (h *AuthorHandler) GetAuthorBooks(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	books, err := h.service.GetAuthorBooks(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, service.ErrNotFound) {
 			notFoundResponse(w, r)
@@ -200,5 +343,255 @@ func (h *AuthorHandler) DeleteAuthor(w http.ResponseWriter, r *http.Request, ps
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	err = writeResponse(w, r, http.StatusOK, envelope{"books": books}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// MergeAuthors godoc
+// @Summary Merge a duplicate author
+// @Description Move books of the source author to the author with a specific ID and delete the source, the source ID keeps resolving to the target
+// @Tags authors
+// @ID merge-authors
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Target Author ID"
+// @Param merge body types.Merge true "Source author that is merged into the target"
+// @Security Bearer
+// @Success 200 {object} types.Author
+// @Router /api/v1/authors/{id}/merge [post]
+func (h *AuthorHandler) MergeAuthors(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	var merge types.Merge
+	err = json.NewDecoder(r.Body).Decode(&merge)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("can't decode request"))
+		return
+	}
+
+	v := validator.New()
+	types.ValidateMerge(v, &merge, id)
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return
+	}
+
+	author, err := h.service.MergeAuthors(r.Context(), id, merge.SourceID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrEntityExists):
+			conflictResponse(w, r, "the source was merged by someone else, fetch it again and retry")
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"author": author}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+func (h *AuthorHandler) findAuthorByName(w http.ResponseWriter, r *http.Request, name string) {
+	authors := []*types.Author{}
+	author, err := h.service.FindAuthorByName(r.Context(), name)
+	if err != nil && !errors.Is(err, service.ErrNotFound) {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	if author != nil {
+		authors = append(authors, author)
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"authors": authors}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// BatchAuthors godoc
+// @Summary Create, update and delete authors in a batch
+// @Description Apply up to 500 operations. An atomic batch applies all of them or none and is answered with the status of the failing operation, a best effort batch applies every operation that succeeds. Every operation gets a result with the status its own request would get, 424 for operations of a failed atomic batch
+// @Tags authors
+// @ID batch-authors
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param batch body types.AuthorBatch true "Mode and operations of the batch"
+// @Param Idempotency-Key header string false "Key that makes retries of the request replay its response"
+// @Security Bearer
+// @Success 200 {object} object{results=[]types.BatchResult}
+// @Router /api/v1/authors:batch [post]
+func (h *AuthorHandler) BatchAuthors(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var batch types.AuthorBatch
+	err := json.NewDecoder(r.Body).Decode(&batch)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("can't decode request"))
+		return
+	}
+
+	v := validator.New()
+	types.ValidateBatch(v, batch.Mode, len(batch.Operations))
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return
+	}
+
+	errs, err := h.service.BatchAuthors(r.Context(), &batch)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	results := make([]*types.BatchResult, len(errs))
+	for i, err := range errs {
+		if err != nil {
+			results[i] = batchFailure(r, i, err)
+			continue
+		}
+
+		op := batch.Operations[i]
+		results[i] = batchResult(i, op.Op, op.ID, op.Version)
+	}
+
+	writeBatchResults(w, r, batch.Mode, results)
+}
+
+// GetAuthorRevisions godoc
+// @Summary Get revisions of an author
+// @Description Get snapshots of an author taken after every change, the latest first
+// @Tags authors
+// @ID get-author-revisions
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param id path int true "Author ID"
+// @Security Bearer
+// @Success 200 {array} []types.Revision
+// @Router /api/v1/authors/{id}/revisions [get]
+func (h *AuthorHandler) GetAuthorRevisions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	revisions, err := h.service.GetAuthorRevisions(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"revisions": revisions}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// DiffAuthorRevisions godoc
+// @Summary Compare two revisions of an author
+// @Description Get the fields that differ between two revisions of an author
+// @Tags authors
+// @ID diff-author-revisions
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Author ID"
+// @Param from query int true "Revision ID to compare from"
+// @Param to query int true "Revision ID to compare to"
+// @Security Bearer
+// @Success 200 {object} types.RevisionDiff
+// @Router /api/v1/authors/{id}/revisions/diff [get]
+func (h *AuthorHandler) DiffAuthorRevisions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	from, to, err := getRevisionRange(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	diff, err := h.service.DiffAuthorRevisions(r.Context(), id, from, to)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"diff": diff}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// RevertAuthor godoc
+// @Summary Revert an author to a revision
+// @Description Bring an author back to a revision, the result is recorded as a new revision
+// @Tags authors
+// @ID revert-author
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Author ID"
+// @Param revision path int true "Revision ID"
+// @Param If-Match header string false "ETag of the author version the revert is based on"
+// @Security Bearer
+// @Success 200 {object} types.Author
+// @Router /api/v1/authors/{id}/revisions/{revision}/revert [post]
+func (h *AuthorHandler) RevertAuthor(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	revisionID, err := getRevisionParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	version, ok := getIfMatch(r)
+	if !ok {
+		preconditionFailedResponse(w, r)
+		return
+	}
+
+	author, err := h.service.RevertAuthor(r.Context(), id, version, revisionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrVersionMismatch):
+			preconditionFailedResponse(w, r)
+		case errors.Is(err, service.ErrEntityExists):
+			conflictResponse(w, r, "alias or external identifier of the revision belongs to another author")
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"author": author}, etagHeader(author.Version))
+	if err != nil {
+		log.Error(err.Error())
+	}
 }
@@ -7,12 +7,15 @@ import (
 	"github.com/julienschmidt/httprouter"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"github.com/tredoc/go-crud-api/internal/service"
+	"github.com/tredoc/go-crud-api/internal/validator"
 	mockservice "github.com/tredoc/go-crud-api/mocks/service"
 	"github.com/tredoc/go-crud-api/pkg/types"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 type authorHandlerSuite struct {
@@ -32,6 +35,9 @@ func (s *authorHandlerSuite) SetupSuite() {
 	router.GET("/api/v1/authors/:id", handler.GetAuthorByID)
 	router.PATCH("/api/v1/authors/:id", handler.UpdateAuthor)
 	router.DELETE("/api/v1/authors/:id", handler.DeleteAuthor)
+	router.GET("/api/v1/authors/:id/books", handler.GetAuthorBooks)
+	router.POST("/api/v1/authors/:id/merge", handler.MergeAuthors)
+	router.POST("/api/v1/authors/:id/revisions/:revision/revert", handler.RevertAuthor)
 
 	testingServer := httptest.NewServer(router)
 
@@ -75,6 +81,156 @@ func (s *authorHandlerSuite) TestCreateAuthor_Positive() {
 	s.Equal(string(result), string(expected))
 }
 
+func (s *authorHandlerSuite) TestCreateAuthor_UnicodeName() {
+	author := types.Author{
+		FirstName:  "Gabriel",
+		MiddleName: "García",
+		LastName:   "Márquez",
+	}
+
+	newAuthor := types.Author{ID: 2, FirstName: author.FirstName, MiddleName: author.MiddleName, LastName: author.LastName}
+
+	s.usecase.On("CreateAuthor", mock.AnythingOfType("*context.cancelCtx"), &author).Return(&newAuthor, nil)
+
+	requestBody, err := json.Marshal(&author)
+	s.NoError(err, "can`t marshal struct to json")
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/authors", s.testingServer.URL), "application/json", bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusCreated, response.StatusCode)
+}
+
+func (s *authorHandlerSuite) TestCreateAuthor_NotValid() {
+	author := types.Author{
+		FirstName: "R2",
+		LastName:  "O'Brien",
+	}
+
+	requestBody, err := json.Marshal(&author)
+	s.NoError(err, "can`t marshal struct to json")
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/authors", s.testingServer.URL), "application/json", bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"error": map[string]string{"first_name": validator.OnlyLettersAndPunctuation},
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusUnprocessableEntity, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *authorHandlerSuite) TestCreateAuthor_InvalidProfile() {
+	birthDate, _ := time.Parse(time.DateOnly, "1910-06-25")
+	deathDate, _ := time.Parse(time.DateOnly, "1903-01-21")
+	author := types.Author{
+		FirstName:   "Eric",
+		LastName:    "Blair",
+		BirthDate:   &types.CustomDate{Time: birthDate},
+		DeathDate:   &types.CustomDate{Time: deathDate},
+		Nationality: "GB",
+		ISNI:        "0000 0001 2103 2684",
+	}
+
+	requestBody, err := json.Marshal(&author)
+	s.NoError(err, "can`t marshal struct to json")
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/authors", s.testingServer.URL), "application/json", bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"error": map[string]string{
+			"death_date": "can't be before birth_date",
+			"isni":       "must be a valid 16 character ISNI",
+		},
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusUnprocessableEntity, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *authorHandlerSuite) TestGetAllAuthors_ByAlias() {
+	birthDate, _ := time.Parse(time.DateOnly, "1903-06-25")
+	author := types.Author{
+		ID:          7,
+		FirstName:   "Eric",
+		MiddleName:  "Arthur",
+		LastName:    "Blair",
+		BirthDate:   &types.CustomDate{Time: birthDate},
+		Nationality: "GB",
+		Aliases:     []string{"George Orwell"},
+		ISNI:        "0000000121032683",
+		Wikidata:    "Q3335",
+	}
+
+	s.usecase.On("FindAuthorByName", mock.AnythingOfType("*context.cancelCtx"), "george orwell").Return(&author, nil)
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/authors?name=george+orwell", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"authors": []*types.Author{&author},
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *authorHandlerSuite) TestGetAuthorBooks_Positive() {
+	id := int64(7)
+	firstDate, _ := time.Parse(time.DateOnly, "1945-08-17")
+	secondDate, _ := time.Parse(time.DateOnly, "1949-06-08")
+	books := []*types.Book{
+		{ID: 3, Title: "Animal Farm", PublishDate: types.CustomDate{Time: firstDate}, ISBN: "978-0-452-28424-1", Pages: 112, Authors: []int64{id}, Genres: []int64{1}},
+		{ID: 2, Title: "Nineteen Eighty-Four", PublishDate: types.CustomDate{Time: secondDate}, ISBN: "978-0-452-28423-4", Pages: 328, Authors: []int64{id}, Genres: []int64{1}},
+	}
+
+	s.usecase.On("GetAuthorBooks", mock.AnythingOfType("*context.cancelCtx"), id).Return(books, nil)
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/authors/%d/books", s.testingServer.URL, id))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"books": books,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *authorHandlerSuite) TestGetAuthorBooks_NotFound() {
+	id := int64(404)
+	s.usecase.On("GetAuthorBooks", mock.AnythingOfType("*context.cancelCtx"), id).Return(nil, service.ErrNotFound)
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/authors/%d/books", s.testingServer.URL, id))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusNotFound, response.StatusCode)
+}
+
 func (s *authorHandlerSuite) TestGetAuthorByID_Positive() {
 	id := int64(1)
 	author := types.Author{ID: id, FirstName: "first", MiddleName: "", LastName: "last"}
@@ -127,7 +283,7 @@ func (s *authorHandlerSuite) TestUpdateAuthor_Positive() {
 	upd := types.UpdateAuthor{LastName: &lastName}
 	author := types.Author{ID: id, FirstName: "first", MiddleName: "", LastName: lastName}
 
-	s.usecase.On("UpdateAuthor", mock.AnythingOfType("*context.cancelCtx"), author.ID, &upd).Return(&author, nil)
+	s.usecase.On("UpdateAuthor", mock.AnythingOfType("*context.cancelCtx"), author.ID, int64(0), &upd).Return(&author, nil)
 
 	requestBody, err := json.Marshal(&upd)
 	s.NoError(err, "can`t marshal struct to json")
@@ -136,6 +292,7 @@ func (s *authorHandlerSuite) TestUpdateAuthor_Positive() {
 	s.NoError(err, "no error when preparing patch request")
 
 	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("If-Match", "*")
 
 	client := &http.Client{}
 	response, err := client.Do(request)
@@ -154,13 +311,38 @@ func (s *authorHandlerSuite) TestUpdateAuthor_Positive() {
 	s.Equal(string(result), string(expected))
 }
 
+func (s *authorHandlerSuite) TestUpdateAuthor_MergePatch() {
+	id := int64(5)
+	requestBody := `{"last_name": null, "nationality": "GB"}`
+	isPatch := func(patch *types.Patch) bool {
+		return string(patch.Merge) == requestBody && patch.Operations == nil
+	}
+	validationErr := &service.ValidationError{Errors: map[string]string{"last_name": validator.CantBeEmpty}}
+	s.usecase.On("PatchAuthor", mock.AnythingOfType("*context.cancelCtx"), id, int64(2), mock.MatchedBy(isPatch)).Return(nil, validationErr)
+
+	request, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("%s/api/v1/authors/%d", s.testingServer.URL, id), bytes.NewBufferString(requestBody))
+	s.NoError(err, "no error when preparing patch request")
+
+	request.Header.Set("Content-Type", "application/merge-patch+json; charset=utf-8")
+	request.Header.Set("If-Match", `"2"`)
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusUnprocessableEntity, response.StatusCode)
+}
+
 func (s *authorHandlerSuite) TestDeleteAuthor_Positive() {
 	id := int64(1)
-	s.usecase.On("DeleteAuthor", mock.AnythingOfType("*context.cancelCtx"), id).Return(nil)
+	s.usecase.On("DeleteAuthor", mock.AnythingOfType("*context.cancelCtx"), id, int64(0)).Return(nil)
 
 	request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/v1/authors/%d", s.testingServer.URL, id), nil)
 	s.NoError(err, "no error when preparing patch request")
 
+	request.Header.Set("If-Match", "*")
+
 	client := &http.Client{}
 	response, err := client.Do(request)
 	s.NoError(err, "no error when calling the endpoint")
@@ -168,6 +350,99 @@ func (s *authorHandlerSuite) TestDeleteAuthor_Positive() {
 	s.Equal(http.StatusNoContent, response.StatusCode)
 }
 
+func (s *authorHandlerSuite) TestDeleteAuthor_InUse() {
+	id := int64(3)
+	s.usecase.On("DeleteAuthor", mock.AnythingOfType("*context.cancelCtx"), id, int64(0)).Return(service.ErrEntityInUse)
+
+	request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/v1/authors/%d", s.testingServer.URL, id), nil)
+	s.NoError(err, "no error when preparing delete request")
+
+	request.Header.Set("If-Match", "*")
+
+	response, err := http.DefaultClient.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusConflict, response.StatusCode)
+}
+
+func (s *authorHandlerSuite) TestMergeAuthors_Positive() {
+	targetID, sourceID := int64(11), int64(12)
+	author := &types.Author{ID: targetID, FirstName: "John", MiddleName: "Ronald", LastName: "Tolkien", Aliases: []string{"J R R Tolkien"}}
+
+	s.usecase.On("MergeAuthors", mock.AnythingOfType("*context.cancelCtx"), targetID, sourceID).Return(author, nil)
+
+	requestBody, err := json.Marshal(&types.Merge{SourceID: sourceID})
+	s.NoError(err, "can`t marshal struct to json")
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/authors/%d/merge", s.testingServer.URL, targetID), "application/json", bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"author": author,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *authorHandlerSuite) TestMergeAuthors_IntoItself() {
+	id := int64(13)
+	requestBody, err := json.Marshal(&types.Merge{SourceID: id})
+	s.NoError(err, "can`t marshal struct to json")
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/authors/%d/merge", s.testingServer.URL, id), "application/json", bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusUnprocessableEntity, response.StatusCode)
+	s.usecase.AssertNotCalled(s.T(), "MergeAuthors", mock.Anything, id, id)
+}
+
+func (s *authorHandlerSuite) TestMergeAuthors_NotFound() {
+	targetID, sourceID := int64(14), int64(404)
+	s.usecase.On("MergeAuthors", mock.AnythingOfType("*context.cancelCtx"), targetID, sourceID).Return(nil, service.ErrNotFound)
+
+	requestBody, err := json.Marshal(&types.Merge{SourceID: sourceID})
+	s.NoError(err, "can`t marshal struct to json")
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/authors/%d/merge", s.testingServer.URL, targetID), "application/json", bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusNotFound, response.StatusCode)
+}
+
+func (s *authorHandlerSuite) TestMergeAuthors_Conflict() {
+	targetID, sourceID := int64(15), int64(16)
+	s.usecase.On("MergeAuthors", mock.AnythingOfType("*context.cancelCtx"), targetID, sourceID).Return(nil, service.ErrEntityExists).Once()
+
+	requestBody, err := json.Marshal(&types.Merge{SourceID: sourceID})
+	s.NoError(err, "can`t marshal struct to json")
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/authors/%d/merge", s.testingServer.URL, targetID), "application/json", bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusConflict, response.StatusCode)
+}
+
+func (s *authorHandlerSuite) TestRevertAuthor_Conflict() {
+	id := int64(6)
+	s.usecase.On("RevertAuthor", mock.AnythingOfType("*context.cancelCtx"), id, int64(0), int64(2)).Return(nil, service.ErrEntityExists)
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/authors/%d/revisions/2/revert", s.testingServer.URL, id), "application/json", nil)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusConflict, response.StatusCode)
+}
+
 func TestAuthorHandler(t *testing.T) {
 	suite.Run(t, new(authorHandlerSuite))
 }
@@ -1,15 +1,23 @@
 package handler
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"github.com/julienschmidt/httprouter"
 	"github.com/tredoc/go-crud-api/internal/service"
 	"github.com/tredoc/go-crud-api/internal/validator"
+	"github.com/tredoc/go-crud-api/pkg/citation"
 	"github.com/tredoc/go-crud-api/pkg/log"
+	"github.com/tredoc/go-crud-api/pkg/marc"
 	"github.com/tredoc/go-crud-api/pkg/types"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 type BookHandler struct {
@@ -28,7 +36,7 @@ func NewBookHandler(service service.Book) *BookHandler {
 // @Tags books
 // @ID create-book
 // @Accept  json
-// @Produce  json
+// @Produce  json,xml,application/msgpack
 // @Param book body types.Book true "Book object that needs to be added"
 // @Security Bearer
 // @Success 201 {object} types.BookWithDetails
@@ -50,15 +58,19 @@ func (h *BookHandler) CreateBook(w http.ResponseWriter, r *http.Request, _ httpr
 
 	newBook, err := h.service.CreateBook(r.Context(), &book)
 	if err != nil {
-		if errors.Is(err, service.ErrEntityExists) {
+		var validationErr *service.ValidationError
+		switch {
+		case errors.Is(err, service.ErrEntityExists):
 			badRequestResponse(w, r, fmt.Errorf("book with title '%s' already exists", book.Title))
-			return
+		case errors.As(err, &validationErr):
+			notValidResponse(w, r, validationErr.Errors)
+		default:
+			serverErrorResponse(w, r, err)
 		}
-		serverErrorResponse(w, r, err)
 		return
 	}
 
-	err = writeJSON(w, http.StatusCreated, envelope{"book": newBook}, nil)
+	err = writeResponse(w, r, http.StatusCreated, envelope{"book": newBook}, nil)
 	if err != nil {
 		log.Error(err.Error())
 	}
@@ -70,15 +82,17 @@ func (h *BookHandler) CreateBook(w http.ResponseWriter, r *http.Request, _ httpr
 // @Tags books
 // @ID get-book
 // @Accept  json
-// @Produce  json
+// @Produce  json,xml,application/msgpack
+// @Param Accept-Language header string false "Preferred languages of the translated names and titles"
 // @Param id path int true "Book ID"
 // @Success 200 {object} types.BookWithDetails
-// @Header 200 {string} Token "qwerty"
+// @Header 200 {string} ETag "Version of the book followed by a hash of the response"
 // @Router /api/v1/books/{id} [get]
 func (h *BookHandler) GetBookByID(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	id, err := getIdParam(ps)
 	if err != nil {
 		badRequestResponse(w, r, err)
+		return
 	}
 
 	book, err := h.service.GetBookByID(r.Context(), id)
@@ -91,7 +105,160 @@ func (h *BookHandler) GetBookByID(w http.ResponseWriter, r *http.Request, ps htt
 		return
 	}
 
-	err = writeJSON(w, http.StatusOK, envelope{"book": book}, nil)
+	err = writeResponse(w, r, http.StatusOK, envelope{"book": book}, etagHeader(book.Version))
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// GetBookMARC godoc
+// @Summary Get the MARC record of a book
+// @Description Get a book as a MARC 21 bibliographic record, in ISO 2709 or as MARCXML. The catalogue keeps no publisher, the record only holds the year of publication
+// @Tags books
+// @ID get-book-marc
+// @Accept  json
+// @Produce  application/marc,application/marcxml+xml
+// @Param id path int true "Book ID"
+// @Param format query string false "marc (default) or marcxml"
+// @Success 200 {string} string "MARC record"
+// @Router /api/v1/books/{id}/marc [get]
+func (h *BookHandler) GetBookMARC(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	format := types.MARCExport
+	if value := r.URL.Query().Get("format"); value != "" {
+		format = types.ExportFormat(value)
+	}
+
+	var encode func(*marc.Record) ([]byte, error)
+	var contentType, prefix string
+	switch format {
+	case types.MARCExport:
+		encode, contentType = marc.Marshal, marc.Type
+	case types.MARCXMLExport:
+		encode, contentType, prefix = marc.MarshalXML, marc.XMLType, xml.Header
+	default:
+		notValidResponse(w, r, map[string]string{"format": "must be one of marc, marcxml"})
+		return
+	}
+
+	book, err := h.service.GetBookByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	record, err := encode(marc.FromBook(book))
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="book-%d.%s"`, id, exportExtension(format)))
+	w.WriteHeader(http.StatusOK)
+	_, err = io.WriteString(w, prefix+string(record))
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// GetBookCitation godoc
+// @Summary Cite a book
+// @Description Get the citation of a book for reference managers as BibTeX, RIS or CSL-JSON. The citation key is made of the last name of the first author, the year and the first word of the title, followed by the ID of the book
+// @Tags books
+// @ID get-book-citation
+// @Accept  json
+// @Produce  application/x-bibtex,application/x-research-info-systems,application/vnd.citationstyles.csl+json
+// @Param id path int true "Book ID"
+// @Param format query string false "bibtex (default), ris or csl-json"
+// @Success 200 {string} string "Citation"
+// @Router /api/v1/books/{id}/citation [get]
+func (h *BookHandler) GetBookCitation(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	h.writeCitations(w, r, []int64{id})
+}
+
+// GetCitations godoc
+// @Summary Cite books
+// @Description Get the citations of several books at once for reference managers as BibTeX, RIS or CSL-JSON, in the order of the IDs. The citation key of a book ends with its ID, so it's the same in every export
+// @Tags books
+// @ID get-citations
+// @Accept  json
+// @Produce  application/x-bibtex,application/x-research-info-systems,application/vnd.citationstyles.csl+json
+// @Param ids query string true "Comma separated list of up to 100 book IDs"
+// @Param format query string false "bibtex (default), ris or csl-json"
+// @Success 200 {string} string "Citations"
+// @Router /api/v1/citations [get]
+func (h *BookHandler) GetCitations(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var ids []int64
+	seen := make(map[int64]bool)
+	for _, param := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(param), 10, 64)
+		if err != nil || id < 1 {
+			badRequestResponse(w, r, errors.New("invalid ids parameter"))
+			return
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) > citation.MaxBooks {
+		notValidResponse(w, r, map[string]string{"ids": fmt.Sprintf("can't contain more than %d books", citation.MaxBooks)})
+		return
+	}
+
+	h.writeCitations(w, r, ids)
+}
+
+// writeCitations writes the citations of the books in the format of the request.
+func (h *BookHandler) writeCitations(w http.ResponseWriter, r *http.Request, ids []int64) {
+	format := citation.BibTeX
+	if value := r.URL.Query().Get("format"); value != "" {
+		format = citation.Format(value)
+	}
+
+	contentType, ok := citation.ContentType(format)
+	if !ok {
+		notValidResponse(w, r, map[string]string{"format": "must be one of bibtex, ris, csl-json"})
+		return
+	}
+
+	books, err := h.service.GetBooksByIDs(r.Context(), ids)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	var out bytes.Buffer
+	err = citation.Write(&out, format, books)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(out.Bytes())
 	if err != nil {
 		log.Error(err.Error())
 	}
@@ -99,15 +266,34 @@ func (h *BookHandler) GetBookByID(w http.ResponseWriter, r *http.Request, ps htt
 
 // GetAllBooks godoc
 // @Summary Get all books
-// @Description Get a list of all books
+// @Description Get a list of all books, optionally filtered by tags and genre or searched by title and author
 // @Tags books
 // @ID get-all-books
 // @Accept  json
-// @Produce  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param Accept-Language header string false "Preferred languages of the translated names and titles"
+// @Param tags query string false "Comma separated list of tags"
+// @Param match query string false "any (default) or all of the tags"
+// @Param genre query int false "Genre ID"
+// @Param subgenres query bool false "Include books of the subgenres of the genre"
+// @Param q query string false "Text in the title or in the name of an author"
 // @Success 200 {array} []types.Book
 // @Router /api/v1/books [get]
 func (h *BookHandler) GetAllBooks(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-	books, err := h.service.GetAllBooks(r.Context())
+	filter, err := getBookFilter(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	types.ValidateBookFilter(v, filter)
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return
+	}
+
+	books, err := h.service.GetAllBooks(r.Context(), filter)
 	if err != nil {
 		if errors.Is(err, service.ErrNotFound) {
 			notFoundResponse(w, r)
@@ -117,57 +303,255 @@ func (h *BookHandler) GetAllBooks(w http.ResponseWriter, r *http.Request, _ http
 		return
 	}
 
-	err = writeJSON(w, http.StatusOK, envelope{"books": books}, nil)
+	err = writeResponse(w, r, http.StatusOK, envelope{"books": books}, nil)
 	if err != nil {
 		log.Error(err.Error())
 	}
 }
 
+// ExportBooks godoc
+// @Summary Export books
+// @Description Stream the books matching the filters of the book list as CSV, NDJSON or a JSON array, with their authors, genres and tags by name, or as MARC 21 records in ISO 2709 or a MARCXML collection. The export isn't paged, a failure midway cuts the response short
+// @Tags books
+// @ID export-books
+// @Accept  json
+// @Produce  json,text/csv,application/x-ndjson,application/marc,application/marcxml+xml
+// @Param format query string false "csv, ndjson, json (default), marc or marcxml"
+// @Param tags query string false "Comma separated list of tags"
+// @Param match query string false "any (default) or all of the tags"
+// @Param genre query int false "Genre ID"
+// @Param subgenres query bool false "Include books of the subgenres of the genre"
+// @Param q query string false "Text in the title or in the name of an author"
+// @Success 200 {array} []types.ExportedBook
+// @Router /api/v1/export/books [get]
+func (h *BookHandler) ExportBooks(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	format := types.JSONExport
+	if value := r.URL.Query().Get("format"); value != "" {
+		format = types.ExportFormat(value)
+	}
+
+	filter, err := getBookFilter(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	types.ValidateBookFilter(v, filter)
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return
+	}
+
+	out := bufio.NewWriter(w)
+	exporter, contentType, ok := newBookExporter(format, out)
+	if !ok {
+		notValidResponse(w, r, map[string]string{"format": "must be one of csv, ndjson, json, marc, marcxml"})
+		return
+	}
+
+	// The status goes out with the first book, so that a failing query still gets an error response.
+	started := false
+	start := func() error {
+		started = true
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="books.%s"`, exportExtension(format)))
+		w.WriteHeader(http.StatusOK)
+		return exporter.begin()
+	}
+
+	controller := http.NewResponseController(w)
+	exported := 0
+	err = h.service.ExportBooks(r.Context(), filter, func(book *types.ExportedBook) error {
+		if !started {
+			err := start()
+			if err != nil {
+				return err
+			}
+		}
+
+		err := exporter.write(book)
+		if err != nil {
+			return err
+		}
+
+		exported++
+		if exported%exportFlushInterval != 0 {
+			return nil
+		}
+
+		err = out.Flush()
+		if err != nil {
+			return err
+		}
+
+		err = controller.Flush()
+		if errors.Is(err, http.ErrNotSupported) {
+			return nil
+		}
+		return err
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = exporter.end()
+	}
+	if err == nil {
+		err = out.Flush()
+	}
+
+	if err != nil {
+		if !started {
+			serverErrorResponse(w, r, err)
+			return
+		}
+
+		// The status is out already, aborting the response lets the client see it was cut short.
+		logError(r, err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
 // UpdateBook godoc
 // @Summary Update a book
-// @Description Update a book with a specific ID
+// @Description Update a book with a specific ID. Besides the partial object, the body can be a JSON merge patch (application/merge-patch+json) or a JSON patch (application/json-patch+json) applied to the editable fields of the book
 // @Tags books
 // @ID update-book
-// @Accept  json
-// @Produce  json
+// @Accept  json,application/merge-patch+json,application/json-patch+json
+// @Produce  json,xml,application/msgpack
 // @Param id path int true "Book ID"
+// @Param If-Match header string true "ETag of the book version the change is based on"
 // @Param book body types.UpdateBook true "Book object that needs to be updated"
 // @Security Bearer
 // @Success 200 {object} types.Book
-// @Router /api/v1/books/{id} [put]
+// @Router /api/v1/books/{id} [patch]
 func (h *BookHandler) UpdateBook(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	id, err := getIdParam(ps)
 	if err != nil {
 		badRequestResponse(w, r, err)
+		return
 	}
 
-	var book types.UpdateBook
-	err = json.NewDecoder(r.Body).Decode(&book)
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	patch, err := decodePatch(r)
 	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	var updatedBook *types.Book
+	if patch != nil {
+		updatedBook, err = h.service.PatchBook(r.Context(), id, version, patch)
+	} else {
+		var book types.UpdateBook
+		err = json.NewDecoder(r.Body).Decode(&book)
 		if err != nil {
 			badRequestResponse(w, r, errors.New("can't decode request"))
 			return
 		}
+
+		v := validator.New()
+		types.ValidateUpdateBook(v, &book)
+		if !v.IsValid() {
+			notValidResponse(w, r, v.Errors)
+			return
+		}
+
+		updatedBook, err = h.service.UpdateBook(r.Context(), id, version, &book)
+	}
+	if err != nil {
+		var validationErr *service.ValidationError
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrVersionMismatch):
+			preconditionFailedResponse(w, r)
+		case errors.As(err, &validationErr):
+			notValidResponse(w, r, validationErr.Errors)
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"book": updatedBook}, etagHeader(updatedBook.Version))
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// ReplaceBook godoc
+// @Summary Replace a book
+// @Description Replace all editable fields of a book with a specific ID or create the book under that ID when there is none yet
+// @Tags books
+// @ID replace-book
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Book ID"
+// @Param If-Match header string false "ETag of the book version the replacement is based on"
+// @Param book body types.Book true "Book object that replaces the stored one"
+// @Security Bearer
+// @Success 200 {object} types.Book
+// @Success 201 {object} types.Book
+// @Router /api/v1/books/{id} [put]
+func (h *BookHandler) ReplaceBook(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	version, ok := getIfMatch(r)
+	if !ok {
+		preconditionFailedResponse(w, r)
+		return
+	}
+
+	var book types.Book
+	err = json.NewDecoder(r.Body).Decode(&book)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("can't decode request"))
+		return
 	}
 
 	v := validator.New()
-	types.ValidateUpdateBook(v, &book)
+	types.ValidateBook(v, &book)
 	if !v.IsValid() {
 		notValidResponse(w, r, v.Errors)
 		return
 	}
 
-	updatedBook, err := h.service.UpdateBook(r.Context(), id, &book)
+	replacedBook, created, err := h.service.ReplaceBook(r.Context(), id, version, &book)
 	if err != nil {
-		if errors.Is(err, service.ErrNotFound) {
+		var validationErr *service.ValidationError
+		switch {
+		case errors.Is(err, service.ErrNotFound):
 			notFoundResponse(w, r)
-			return
+		case errors.Is(err, service.ErrVersionMismatch):
+			preconditionFailedResponse(w, r)
+		case errors.Is(err, service.ErrIDUnavailable):
+			conflictResponse(w, r, fmt.Sprintf("book %d is in the trash, restore it before replacing it", id))
+		case errors.Is(err, service.ErrEntityExists):
+			conflictResponse(w, r, fmt.Sprintf("book %d was created by someone else, fetch it and retry", id))
+		case errors.As(err, &validationErr):
+			notValidResponse(w, r, validationErr.Errors)
+		default:
+			serverErrorResponse(w, r, err)
 		}
-		serverErrorResponse(w, r, err)
 		return
 	}
 
-	err = writeJSON(w, http.StatusOK, envelope{"book": updatedBook}, nil)
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+
+	err = writeResponse(w, r, status, envelope{"book": replacedBook}, etagHeader(replacedBook.Version))
 	if err != nil {
 		log.Error(err.Error())
 	}
@@ -175,12 +559,13 @@ func (h *BookHandler) UpdateBook(w http.ResponseWriter, r *http.Request, ps http
 
 // DeleteBook godoc
 // @Summary Delete a book
-// @Description Delete a book with a specific ID
+// @Description Move a book with a specific ID to the trash, it can be restored until the trash is purged
 // @Tags books
 // @ID delete-book
 // @Accept  json
-// @Produce  json
+// @Produce  json,xml,application/msgpack
 // @Param id path int true "Book ID"
+// @Param If-Match header string true "ETag of the book version the deletion is based on"
 // @Security Bearer
 // @Success 204 "No Content"
 // @Router /api/v1/books/{id} [delete]
@@ -188,9 +573,185 @@ func (h *BookHandler) DeleteBook(w http.ResponseWriter, r *http.Request, ps http
 	id, err := getIdParam(ps)
 	if err != nil {
 		badRequestResponse(w, r, err)
+		return
+	}
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
 	}
 
-	err = h.service.DeleteBook(r.Context(), id)
+	err = h.service.DeleteBook(r.Context(), id, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrVersionMismatch):
+			preconditionFailedResponse(w, r)
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BatchBooks godoc
+// @Summary Create, update and delete books in a batch
+// @Description Apply up to 500 operations. An atomic batch applies all of them or none and is answered with the status of the failing operation, a best effort batch applies every operation that succeeds. Every operation gets a result with the status its own request would get, 424 for operations of a failed atomic batch
+// @Tags books
+// @ID batch-books
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param batch body types.BookBatch true "Mode and operations of the batch"
+// @Param Idempotency-Key header string false "Key that makes retries of the request replay its response"
+// @Security Bearer
+// @Success 200 {object} object{results=[]types.BatchResult}
+// @Router /api/v1/books:batch [post]
+func (h *BookHandler) BatchBooks(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var batch types.BookBatch
+	err := json.NewDecoder(r.Body).Decode(&batch)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("can't decode request"))
+		return
+	}
+
+	v := validator.New()
+	types.ValidateBatch(v, batch.Mode, len(batch.Operations))
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return
+	}
+
+	errs, err := h.service.BatchBooks(r.Context(), &batch)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	results := make([]*types.BatchResult, len(errs))
+	for i, err := range errs {
+		if err != nil {
+			results[i] = batchFailure(r, i, err)
+			continue
+		}
+
+		op := batch.Operations[i]
+		results[i] = batchResult(i, op.Op, op.ID, op.Version)
+	}
+
+	writeBatchResults(w, r, batch.Mode, results)
+}
+
+// GetBookTranslations godoc
+// @Summary Get translations of a book
+// @Description Get all translations of a book title
+// @Tags books
+// @ID get-book-translations
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param id path int true "Book ID"
+// @Success 200 {array} []types.Translation
+// @Router /api/v1/books/{id}/translations [get]
+func (h *BookHandler) GetBookTranslations(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	translations, err := h.service.GetBookTranslations(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"translations": translations}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// SetBookTranslation godoc
+// @Summary Set a translation of a book
+// @Description Create or replace the title of a book in the language given by a BCP 47 tag
+// @Tags books
+// @ID set-book-translation
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Book ID"
+// @Param language path string true "BCP 47 language tag"
+// @Param translation body types.Translation true "Translated title, the language is taken from the path"
+// @Security Bearer
+// @Success 200 {object} types.Translation
+// @Router /api/v1/books/{id}/translations/{language} [put]
+func (h *BookHandler) SetBookTranslation(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	translation, err := decodeTranslation(r, ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	types.ValidateBookTranslation(v, translation)
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return
+	}
+
+	err = h.service.SetBookTranslation(r.Context(), id, translation)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"translation": translation}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// DeleteBookTranslation godoc
+// @Summary Delete a translation of a book
+// @Description Delete the translation of a book in the language given by a BCP 47 tag
+// @Tags books
+// @ID delete-book-translation
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Book ID"
+// @Param language path string true "BCP 47 language tag"
+// @Security Bearer
+// @Success 204 "No Content"
+// @Router /api/v1/books/{id}/translations/{language} [delete]
+func (h *BookHandler) DeleteBookTranslation(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	language, err := types.ParseLanguageTag(ps.ByName("language"))
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	err = h.service.DeleteBookTranslation(r.Context(), id, language)
 	if err != nil {
 		if errors.Is(err, service.ErrNotFound) {
 			notFoundResponse(w, r)
@@ -202,3 +763,134 @@ func (h *BookHandler) DeleteBook(w http.ResponseWriter, r *http.Request, ps http
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// GetBookRevisions godoc
+// @Summary Get revisions of a book
+// @Description Get snapshots of a book taken after every change, the latest first
+// @Tags books
+// @ID get-book-revisions
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param id path int true "Book ID"
+// @Security Bearer
+// @Success 200 {array} []types.Revision
+// @Router /api/v1/books/{id}/revisions [get]
+func (h *BookHandler) GetBookRevisions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	revisions, err := h.service.GetBookRevisions(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"revisions": revisions}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// DiffBookRevisions godoc
+// @Summary Compare two revisions of a book
+// @Description Get the fields that differ between two revisions of a book
+// @Tags books
+// @ID diff-book-revisions
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Book ID"
+// @Param from query int true "Revision ID to compare from"
+// @Param to query int true "Revision ID to compare to"
+// @Security Bearer
+// @Success 200 {object} types.RevisionDiff
+// @Router /api/v1/books/{id}/revisions/diff [get]
+func (h *BookHandler) DiffBookRevisions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	from, to, err := getRevisionRange(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	diff, err := h.service.DiffBookRevisions(r.Context(), id, from, to)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"diff": diff}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// RevertBook godoc
+// @Summary Revert a book to a revision
+// @Description Bring title, publish date, ISBN, pages, authors and genres of a book back to a revision, the result is recorded as a new revision
+// @Tags books
+// @ID revert-book
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Book ID"
+// @Param revision path int true "Revision ID"
+// @Param If-Match header string false "ETag of the book version the revert is based on"
+// @Security Bearer
+// @Success 200 {object} types.Book
+// @Router /api/v1/books/{id}/revisions/{revision}/revert [post]
+func (h *BookHandler) RevertBook(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	revisionID, err := getRevisionParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	version, ok := getIfMatch(r)
+	if !ok {
+		preconditionFailedResponse(w, r)
+		return
+	}
+
+	book, err := h.service.RevertBook(r.Context(), id, version, revisionID)
+	if err != nil {
+		var validationErr *service.ValidationError
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrVersionMismatch):
+			preconditionFailedResponse(w, r)
+		case errors.As(err, &validationErr):
+			notValidResponse(w, r, validationErr.Errors)
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"book": book}, etagHeader(book.Version))
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
@@ -3,15 +3,19 @@ package handler
 import (
 	"bytes"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"github.com/julienschmidt/httprouter"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"github.com/tredoc/go-crud-api/internal/service"
 	mockservice "github.com/tredoc/go-crud-api/mocks/service"
+	"github.com/tredoc/go-crud-api/pkg/marc"
 	"github.com/tredoc/go-crud-api/pkg/types"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 )
@@ -30,9 +34,21 @@ func (s *bookHandlerSuite) SetupSuite() {
 	router := httprouter.New()
 	router.POST("/api/v1/books", handler.CreateBook)
 	router.GET("/api/v1/books", handler.GetAllBooks)
+	router.GET("/api/v1/export/books", handler.ExportBooks)
 	router.GET("/api/v1/books/:id", handler.GetBookByID)
+	router.GET("/api/v1/books/:id/marc", handler.GetBookMARC)
+	router.GET("/api/v1/books/:id/citation", handler.GetBookCitation)
+	router.GET("/api/v1/citations", handler.GetCitations)
 	router.PATCH("/api/v1/books/:id", handler.UpdateBook)
+	router.PUT("/api/v1/books/:id", handler.ReplaceBook)
 	router.DELETE("/api/v1/books/:id", handler.DeleteBook)
+	router.GET("/api/v1/books/:id/translations", handler.GetBookTranslations)
+	router.PUT("/api/v1/books/:id/translations/:language", handler.SetBookTranslation)
+	router.DELETE("/api/v1/books/:id/translations/:language", handler.DeleteBookTranslation)
+	router.GET("/api/v1/books/:id/revisions", handler.GetBookRevisions)
+	router.GET("/api/v1/books/:id/revisions/diff", handler.DiffBookRevisions)
+	router.POST("/api/v1/books/:id/revisions/:revision/revert", handler.RevertBook)
+	router.NotFound = customMethods(map[string]httprouter.Handle{"/api/v1/books:batch": handler.BatchBooks}, notFoundResponse)
 
 	testingServer := httptest.NewServer(router)
 
@@ -149,7 +165,8 @@ func (s *bookHandlerSuite) TestGetAllBooks_Positive() {
 		},
 	}
 
-	s.usecase.On("GetAllBooks", mock.AnythingOfType("*context.cancelCtx")).Return(books, nil)
+	filter := types.BookFilter{TagMatch: types.MatchAnyTag}
+	s.usecase.On("GetAllBooks", mock.AnythingOfType("*context.cancelCtx"), &filter).Return(books, nil)
 
 	response, err := http.Get(fmt.Sprintf("%s/api/v1/books", s.testingServer.URL))
 	s.NoError(err, "no error when calling the endpoint")
@@ -167,6 +184,249 @@ func (s *bookHandlerSuite) TestGetAllBooks_Positive() {
 	s.Equal(string(result), string(expected))
 }
 
+func (s *bookHandlerSuite) TestGetAllBooks_FilterByTags() {
+	parsedTime, _ := time.Parse(time.DateOnly, "2006-01-01")
+	books := []*types.Book{
+		{
+			ID:          3,
+			Title:       "Tagged book",
+			PublishDate: types.CustomDate{Time: parsedTime},
+			CreatedAt:   time.Now(),
+			ISBN:        "22222222222222-22",
+			Pages:       299,
+			Authors:     []int64{1},
+			Genres:      []int64{1},
+			Tags:        []string{"space opera", "научная фантастика"},
+		},
+	}
+
+	filter := types.BookFilter{Tags: []string{"space opera", "научная фантастика"}, TagMatch: types.MatchAllTags}
+	s.usecase.On("GetAllBooks", mock.AnythingOfType("*context.cancelCtx"), &filter).Return(books, nil)
+
+	query := url.Values{"tags": {"Space  Opera,научная фантастика"}, "match": {"all"}}
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/books?%s", s.testingServer.URL, query.Encode()))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"books": &books,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *bookHandlerSuite) TestGetAllBooks_CSV() {
+	parsedTime, _ := time.Parse(time.DateOnly, "1969-03-01")
+	books := []*types.Book{
+		{
+			ID:            95,
+			Title:         "The Left Hand of Darkness",
+			PublishDate:   types.CustomDate{Time: parsedTime},
+			CreatedAt:     time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC),
+			ISBN:          "9780441478125",
+			Pages:         304,
+			AverageRating: 4.5,
+			RatingsCount:  2,
+			Authors:       []int64{3},
+			Genres:        []int64{4, 5},
+			Tags:          []string{"gethen", "winter, ice"},
+		},
+	}
+
+	filter := types.BookFilter{TagMatch: types.MatchAnyTag, GenreID: 11}
+	s.usecase.On("GetAllBooks", mock.AnythingOfType("*context.cancelCtx"), &filter).Return(books, nil).Once()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v1/books?genre=11", s.testingServer.URL), nil)
+	s.NoError(err, "can`t create request")
+	request.Header.Set("Accept", "application/json;q=0.5, text/csv")
+
+	response, err := http.DefaultClient.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal("text/csv; charset=utf-8", response.Header.Get("Content-Type"))
+	s.Equal("Accept", response.Header.Get("Vary"))
+	s.Equal("id,title,publish_date,created_at,isbn,pages,average_rating,ratings_count,authors,genres,tags\n"+
+		"95,The Left Hand of Darkness,1969-03-01,2024-05-01T12:00:00Z,9780441478125,304,4.5,2,3,4; 5,\"gethen; winter, ice\"\n", string(result))
+}
+
+func (s *bookHandlerSuite) TestGetBookByID_XML() {
+	parsedTime, _ := time.Parse(time.DateOnly, "1974-05-01")
+	book := types.BookWithDetails{
+		ID:          96,
+		Title:       "The Dispossessed",
+		PublishDate: types.CustomDate{Time: parsedTime},
+		ISBN:        "9780060512750",
+		Pages:       387,
+		Authors:     []*types.Author{{ID: 3, FirstName: "Ursula", MiddleName: "K.", LastName: "Le Guin"}},
+		Genres:      []*types.Genre{{ID: 4, Name: "science fiction"}},
+		Version:     2,
+	}
+	s.usecase.On("GetBookByID", mock.AnythingOfType("*context.cancelCtx"), int64(96)).Return(&book, nil).Once()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v1/books/96", s.testingServer.URL), nil)
+	s.NoError(err, "can`t create request")
+	request.Header.Set("Accept", "text/*;q=0.2, application/xml")
+
+	response, err := http.DefaultClient.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	var result struct {
+		XMLName xml.Name
+		Book    struct {
+			Title   string   `xml:"title"`
+			Pages   int      `xml:"pages"`
+			Authors []string `xml:"authors>item>last_name"`
+			Genres  []string `xml:"genres>item>name"`
+		} `xml:"book"`
+	}
+	err = xml.NewDecoder(response.Body).Decode(&result)
+	s.NoError(err, "can`t decode response")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal("application/xml; charset=utf-8", response.Header.Get("Content-Type"))
+	s.Equal(`"2"`, response.Header.Get("ETag"))
+	s.Equal("response", result.XMLName.Local)
+	s.Equal("The Dispossessed", result.Book.Title)
+	s.Equal(387, result.Book.Pages)
+	s.Equal([]string{"Le Guin"}, result.Book.Authors)
+	s.Equal([]string{"science fiction"}, result.Book.Genres)
+}
+
+func (s *bookHandlerSuite) TestGetBookByID_MessagePack() {
+	book := types.BookWithDetails{ID: 97, Title: "Go", Pages: 380}
+	s.usecase.On("GetBookByID", mock.AnythingOfType("*context.cancelCtx"), int64(97)).Return(&book, nil).Once()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v1/books/97", s.testingServer.URL), nil)
+	s.NoError(err, "can`t create request")
+	request.Header.Set("Accept", "application/x-msgpack")
+
+	response, err := http.DefaultClient.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal("application/msgpack", response.Header.Get("Content-Type"))
+	// {"book": {"id": 97, "title": "Go", ...}}
+	s.Equal([]byte{0x81, 0xa4, 'b', 'o', 'o', 'k'}, result[:6])
+	s.Equal([]byte{0xa2, 'i', 'd', 0x61, 0xa5, 't', 'i', 't', 'l', 'e', 0xa2, 'G', 'o'}, result[7:20])
+}
+
+func (s *bookHandlerSuite) TestGetBookByID_NotAcceptable() {
+	book := types.BookWithDetails{ID: 98, Title: "Go"}
+	s.usecase.On("GetBookByID", mock.AnythingOfType("*context.cancelCtx"), int64(98)).Return(&book, nil).Once()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v1/books/98", s.testingServer.URL), nil)
+	s.NoError(err, "can`t create request")
+	request.Header.Set("Accept", "text/csv, application/json;q=0")
+
+	response, err := http.DefaultClient.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	var result map[string]string
+	err = json.NewDecoder(response.Body).Decode(&result)
+	s.NoError(err, "can`t decode response")
+
+	s.Equal(http.StatusNotAcceptable, response.StatusCode)
+	s.Equal("the response can only be one of application/json, application/xml, application/msgpack", result["error"])
+}
+
+func (s *bookHandlerSuite) TestGetBookByID_NotFoundXML() {
+	s.usecase.On("GetBookByID", mock.AnythingOfType("*context.cancelCtx"), int64(99)).Return(nil, service.ErrNotFound).Once()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v1/books/99", s.testingServer.URL), nil)
+	s.NoError(err, "can`t create request")
+	request.Header.Set("Accept", "text/xml")
+
+	response, err := http.DefaultClient.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	s.Equal(http.StatusNotFound, response.StatusCode)
+	s.Equal(xml.Header+"<response><error>the requested resource could not be found</error></response>", string(result))
+}
+
+func (s *bookHandlerSuite) TestGetBookByID_InvalidID() {
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/books/abc", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	s.Equal(http.StatusBadRequest, response.StatusCode)
+	s.JSONEq(`{"error":"invalid id parameter"}`, string(result))
+	s.usecase.AssertNotCalled(s.T(), "GetBookByID", mock.Anything, int64(0))
+}
+
+func (s *bookHandlerSuite) TestGetAllBooks_InvalidMatch() {
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/books?tags=fantasy&match=some", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusUnprocessableEntity, response.StatusCode)
+}
+
+func (s *bookHandlerSuite) TestGetAllBooks_FilterByGenreWithSubgenres() {
+	parsedTime, _ := time.Parse(time.DateOnly, "1954-07-29")
+	books := []*types.Book{
+		{
+			ID:          4,
+			Title:       "The Fellowship of the Ring",
+			PublishDate: types.CustomDate{Time: parsedTime},
+			CreatedAt:   time.Now(),
+			ISBN:        "978-0-618-00222-1",
+			Pages:       423,
+			Authors:     []int64{1},
+			Genres:      []int64{3},
+			Tags:        []string{},
+		},
+	}
+
+	filter := types.BookFilter{TagMatch: types.MatchAnyTag, GenreID: 1, IncludeSubgenres: true}
+	s.usecase.On("GetAllBooks", mock.AnythingOfType("*context.cancelCtx"), &filter).Return(books, nil)
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/books?genre=1&subgenres=true", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"books": &books,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *bookHandlerSuite) TestGetAllBooks_InvalidGenre() {
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/books?genre=fantasy", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusBadRequest, response.StatusCode)
+}
+
 func (s *bookHandlerSuite) TestUpdateBook_Positive() {
 	id := int64(1)
 	newTitle := "Update Title"
@@ -187,7 +447,7 @@ func (s *bookHandlerSuite) TestUpdateBook_Positive() {
 		Genres:      []int64{1, 2},
 	}
 
-	s.usecase.On("UpdateBook", mock.AnythingOfType("*context.cancelCtx"), id, &upd).Return(&book, nil)
+	s.usecase.On("UpdateBook", mock.AnythingOfType("*context.cancelCtx"), id, int64(0), &upd).Return(&book, nil)
 
 	requestBody, err := json.Marshal(&upd)
 	s.NoError(err, "can`t marshal struct to json")
@@ -196,6 +456,7 @@ func (s *bookHandlerSuite) TestUpdateBook_Positive() {
 	s.NoError(err, "no error when preparing patch request")
 
 	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("If-Match", "*")
 
 	client := &http.Client{}
 	response, err := client.Do(request)
@@ -216,11 +477,13 @@ func (s *bookHandlerSuite) TestUpdateBook_Positive() {
 
 func (s *bookHandlerSuite) TestDeleteBook_Positive() {
 	id := int64(1)
-	s.usecase.On("DeleteBook", mock.AnythingOfType("*context.cancelCtx"), id).Return(nil)
+	s.usecase.On("DeleteBook", mock.AnythingOfType("*context.cancelCtx"), id, int64(0)).Return(nil)
 
 	request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/v1/books/%d", s.testingServer.URL, id), nil)
 	s.NoError(err, "no error when preparing patch request")
 
+	request.Header.Set("If-Match", "*")
+
 	client := &http.Client{}
 	response, err := client.Do(request)
 	s.NoError(err, "no error when calling the endpoint")
@@ -228,6 +491,731 @@ func (s *bookHandlerSuite) TestDeleteBook_Positive() {
 	s.Equal(http.StatusNoContent, response.StatusCode)
 }
 
+func (s *bookHandlerSuite) TestUpdateBook_IfMatch() {
+	id := int64(1)
+	newTitle := "Go mechanics, 2nd edition"
+	upd := types.UpdateBook{Title: &newTitle}
+
+	parsedTime, _ := time.Parse(time.DateOnly, "2006-01-01")
+	book := types.Book{
+		ID:          id,
+		Title:       newTitle,
+		PublishDate: types.CustomDate{Time: parsedTime},
+		ISBN:        "11111100-09000",
+		Pages:       499,
+		Authors:     []int64{1},
+		Genres:      []int64{1, 2},
+		Version:     4,
+	}
+
+	s.usecase.On("UpdateBook", mock.AnythingOfType("*context.cancelCtx"), id, int64(3), &upd).Return(&book, nil)
+
+	requestBody, err := json.Marshal(&upd)
+	s.NoError(err, "can`t marshal struct to json")
+
+	request, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("%s/api/v1/books/%d", s.testingServer.URL, id), bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when preparing patch request")
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("If-Match", `"3"`)
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(`"4"`, response.Header.Get("ETag"))
+}
+
+func (s *bookHandlerSuite) TestUpdateBook_VersionMismatch() {
+	id := int64(1)
+	newTitle := "Go mechanics, 2nd edition"
+	upd := types.UpdateBook{Title: &newTitle}
+
+	s.usecase.On("UpdateBook", mock.AnythingOfType("*context.cancelCtx"), id, int64(2), &upd).Return(nil, service.ErrVersionMismatch)
+
+	requestBody, err := json.Marshal(&upd)
+	s.NoError(err, "can`t marshal struct to json")
+
+	request, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("%s/api/v1/books/%d", s.testingServer.URL, id), bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when preparing patch request")
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("If-Match", `"2"`)
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"error": "the resource was changed by someone else, fetch it again and retry",
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusPreconditionFailed, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *bookHandlerSuite) TestUpdateBook_JSONPatch() {
+	id := int64(11)
+	parsedTime, _ := time.Parse(time.DateOnly, "2006-01-01")
+	book := types.Book{
+		ID:          id,
+		Title:       "Go mechanics",
+		PublishDate: types.CustomDate{Time: parsedTime},
+		ISBN:        "11111100-09000",
+		Pages:       499,
+		Authors:     []int64{1, 7},
+		Genres:      []int64{1},
+		Version:     2,
+	}
+
+	isPatch := func(patch *types.Patch) bool {
+		return patch.Merge == nil && len(patch.Operations) == 1 &&
+			patch.Operations[0].Op == "add" && patch.Operations[0].Path == "/authors/-"
+	}
+	s.usecase.On("PatchBook", mock.AnythingOfType("*context.cancelCtx"), id, int64(0), mock.MatchedBy(isPatch)).Return(&book, nil)
+
+	requestBody := `[{"op": "add", "path": "/authors/-", "value": 7}]`
+	request, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("%s/api/v1/books/%d", s.testingServer.URL, id), bytes.NewBufferString(requestBody))
+	s.NoError(err, "no error when preparing patch request")
+
+	request.Header.Set("Content-Type", "application/json-patch+json")
+	request.Header.Set("If-Match", "*")
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(`"2"`, response.Header.Get("ETag"))
+}
+
+func (s *bookHandlerSuite) TestUpdateBook_InvalidJSONPatch() {
+	id := int64(12)
+	requestBody := `[{"op": "append", "path": "/authors", "value": 7}]`
+	request, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("%s/api/v1/books/%d", s.testingServer.URL, id), bytes.NewBufferString(requestBody))
+	s.NoError(err, "no error when preparing patch request")
+
+	request.Header.Set("Content-Type", "application/json-patch+json")
+	request.Header.Set("If-Match", "*")
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusBadRequest, response.StatusCode)
+	s.usecase.AssertNotCalled(s.T(), "PatchBook", mock.Anything, id, mock.Anything, mock.Anything)
+}
+
+func (s *bookHandlerSuite) TestReplaceBook_Replaced() {
+	id := int64(13)
+	parsedTime, _ := time.Parse(time.DateOnly, "2006-01-01")
+	book := types.Book{
+		Title:       "Go mechanics",
+		PublishDate: types.CustomDate{Time: parsedTime},
+		ISBN:        "11111100-09000",
+		Pages:       520,
+		Authors:     []int64{1},
+		Genres:      []int64{1},
+	}
+
+	replaced := book
+	replaced.ID = id
+	replaced.Tags = []string{"go"}
+	replaced.Version = 6
+	s.usecase.On("ReplaceBook", mock.AnythingOfType("*context.cancelCtx"), id, int64(5), &book).Return(&replaced, false, nil)
+
+	requestBody, err := json.Marshal(&book)
+	s.NoError(err, "can`t marshal struct to json")
+
+	request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/api/v1/books/%d", s.testingServer.URL, id), bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when preparing put request")
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("If-Match", `"5"`)
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(`"6"`, response.Header.Get("ETag"))
+}
+
+func (s *bookHandlerSuite) TestReplaceBook_InTrash() {
+	id := int64(14)
+	parsedTime, _ := time.Parse(time.DateOnly, "2006-01-01")
+	book := types.Book{
+		Title:       "Go mechanics",
+		PublishDate: types.CustomDate{Time: parsedTime},
+		ISBN:        "11111100-09000",
+		Pages:       520,
+		Authors:     []int64{1},
+		Genres:      []int64{1},
+	}
+
+	s.usecase.On("ReplaceBook", mock.AnythingOfType("*context.cancelCtx"), id, int64(0), &book).Return(nil, false, service.ErrIDUnavailable)
+
+	requestBody, err := json.Marshal(&book)
+	s.NoError(err, "can`t marshal struct to json")
+
+	request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/api/v1/books/%d", s.testingServer.URL, id), bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when preparing put request")
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusConflict, response.StatusCode)
+}
+
+func (s *bookHandlerSuite) postBatch(body string) (*http.Response, []types.BatchResult) {
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/books:batch", s.testingServer.URL), "application/json", bytes.NewBufferString(body))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	var responseBody struct {
+		Results []types.BatchResult `json:"results"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&responseBody)
+	s.NoError(err, "can`t decode response")
+
+	return response, responseBody.Results
+}
+
+func (s *bookHandlerSuite) TestBatchBooks_BestEffort() {
+	isBatch := func(batch *types.BookBatch) bool {
+		return batch.Mode == types.BestEffortBatch && len(batch.Operations) == 2 && batch.Operations[1].ID == 20
+	}
+	applied := func(args mock.Arguments) {
+		created := args.Get(1).(*types.BookBatch).Operations[0]
+		created.ID, created.Version = 30, 1
+	}
+	s.usecase.On("BatchBooks", mock.AnythingOfType("*context.cancelCtx"), mock.MatchedBy(isBatch)).
+		Run(applied).Return([]error{nil, service.ErrNotFound}, nil).Once()
+
+	response, results := s.postBatch(`{"mode": "best_effort", "operations": [
+		{"op": "create", "book": {"title": "Go in batches", "publish_date": "2006-01-01", "isbn": "11111100-09001", "pages": 120, "authors": [1], "genres": [1]}},
+		{"op": "delete", "id": 20}
+	]}`)
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal([]types.BatchResult{
+		{Index: 0, Status: http.StatusCreated, ID: 30, Version: 1},
+		{Index: 1, Status: http.StatusNotFound, Error: "the requested resource could not be found"},
+	}, results)
+}
+
+func (s *bookHandlerSuite) TestBatchBooks_AtomicAborted() {
+	isBatch := func(batch *types.BookBatch) bool {
+		return batch.Mode == types.AtomicBatch && len(batch.Operations) == 2 && batch.Operations[0].ID == 21
+	}
+	s.usecase.On("BatchBooks", mock.AnythingOfType("*context.cancelCtx"), mock.MatchedBy(isBatch)).
+		Return([]error{service.ErrBatchAborted, service.ErrVersionMismatch}, nil).Once()
+
+	response, results := s.postBatch(`{"mode": "atomic", "operations": [
+		{"op": "update", "id": 21, "changes": {"pages": 200}},
+		{"op": "delete", "id": 22, "version": 3}
+	]}`)
+
+	s.Equal(http.StatusPreconditionFailed, response.StatusCode)
+	s.Len(results, 2)
+	s.Equal(http.StatusFailedDependency, results[0].Status)
+	s.Equal(http.StatusPreconditionFailed, results[1].Status)
+}
+
+func (s *bookHandlerSuite) TestBatchBooks_InvalidMode() {
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/books:batch", s.testingServer.URL), "application/json",
+		bytes.NewBufferString(`{"mode": "sometimes", "operations": [{"op": "delete", "id": 23}]}`))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusUnprocessableEntity, response.StatusCode)
+}
+
+func (s *bookHandlerSuite) exportBooks(filter *types.BookFilter, books ...*types.ExportedBook) {
+	export := func(args mock.Arguments) {
+		each := args.Get(2).(func(*types.ExportedBook) error)
+		for _, book := range books {
+			s.NoError(each(book))
+		}
+	}
+	s.usecase.On("ExportBooks", mock.AnythingOfType("*context.cancelCtx"), filter, mock.AnythingOfType("func(*types.ExportedBook) error")).
+		Run(export).Return(nil).Once()
+}
+
+func (s *bookHandlerSuite) TestExportBooks_CSV() {
+	parsedTime, _ := time.Parse(time.DateOnly, "2006-01-01")
+	s.exportBooks(&types.BookFilter{TagMatch: types.MatchAnyTag, GenreID: 7},
+		&types.ExportedBook{ID: 1, Title: "Go mechanics", PublishDate: types.CustomDate{Time: parsedTime}, ISBN: "11111100-09000", Pages: 520,
+			AverageRating: 4.5, RatingsCount: 2, Authors: []string{"Rob Pike", "Ken Thompson"}, Genres: []string{"programming"}, Tags: []string{}},
+		&types.ExportedBook{ID: 2, Title: "Go, again", PublishDate: types.CustomDate{Time: parsedTime}, ISBN: "11111100-09001", Pages: 100,
+			Authors: []string{"Rob Pike"}, Genres: []string{"programming"}, Tags: []string{"go"}},
+	)
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/export/books?format=csv&genre=7", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal("text/csv", response.Header.Get("Content-Type"))
+	s.Equal("id,title,publish_date,isbn,pages,average_rating,ratings_count,authors,genres,tags\n"+
+		"1,Go mechanics,2006-01-01,11111100-09000,520,4.5,2,Rob Pike; Ken Thompson,programming,\n"+
+		"2,\"Go, again\",2006-01-01,11111100-09001,100,0,0,Rob Pike,programming,go\n", string(body))
+}
+
+func (s *bookHandlerSuite) TestExportBooks_EmptyJSON() {
+	s.exportBooks(&types.BookFilter{TagMatch: types.MatchAnyTag, GenreID: 8})
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/export/books?genre=8", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal("application/json", response.Header.Get("Content-Type"))
+	s.Equal("[]\n", string(body))
+}
+
+func (s *bookHandlerSuite) TestExportBooks_MARCXML() {
+	parsedTime, _ := time.Parse(time.DateOnly, "2006-01-01")
+	s.exportBooks(&types.BookFilter{TagMatch: types.MatchAnyTag, GenreID: 9},
+		&types.ExportedBook{ID: 1, Title: "Go mechanics", PublishDate: types.CustomDate{Time: parsedTime}, ISBN: "11111100-09000", Pages: 520,
+			Authors: []string{"Rob Pike", "Ursula K. Le Guin"}, Genres: []string{"programming"}, Tags: []string{},
+			Credits: []*types.Author{{FirstName: "Rob", LastName: "Pike"}, {FirstName: "Ursula", MiddleName: "K.", LastName: "Le Guin"}}},
+		&types.ExportedBook{ID: 2, Title: "Go, again", PublishDate: types.CustomDate{Time: parsedTime}, ISBN: "11111100-09001", Pages: 100,
+			Authors: []string{}, Genres: []string{}, Tags: []string{}},
+	)
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/export/books?format=marcxml&genre=9", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(marc.XMLType, response.Header.Get("Content-Type"))
+	s.Equal(`attachment; filename="books.xml"`, response.Header.Get("Content-Disposition"))
+
+	reader := marc.NewXMLReader(response.Body)
+	record, err := reader.Read()
+	s.NoError(err, "can`t read the first record")
+	s.Equal("1", record.ControlField("001"))
+	s.Equal("Pike, Rob", record.Fields("100")[0].Subfield('a'))
+	s.Equal("Le Guin, Ursula K.", record.Fields("700")[0].Subfield('a'))
+	s.Equal("2006", record.Fields("264")[0].Subfield('c'))
+
+	record, err = reader.Read()
+	s.NoError(err, "can`t read the second record")
+	s.Equal(byte('0'), record.Fields("245")[0].Ind1)
+	s.Empty(record.Fields("100"))
+
+	_, err = reader.Read()
+	s.ErrorIs(err, io.EOF)
+}
+
+func (s *bookHandlerSuite) TestGetBookMARC() {
+	parsedTime, _ := time.Parse(time.DateOnly, "1985-06-01")
+	book := types.BookWithDetails{
+		ID:          91,
+		Title:       "Die Blechtrommel",
+		PublishDate: types.CustomDate{Time: parsedTime},
+		CreatedAt:   time.Date(2024, time.March, 5, 10, 0, 0, 0, time.UTC),
+		ISBN:        "978-3-423-11821-6",
+		Pages:       736,
+		Authors:     []*types.Author{{FirstName: "Günter", LastName: "Grass"}},
+		Genres:      []*types.Genre{{Name: "novel"}},
+	}
+	s.usecase.On("GetBookByID", mock.AnythingOfType("*context.cancelCtx"), int64(91)).Return(&book, nil).Once()
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/books/91/marc", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(marc.Type, response.Header.Get("Content-Type"))
+
+	record, err := marc.NewReader(response.Body).Read()
+	s.NoError(err, "can`t read the record")
+	s.Equal("240305s1985    xx |||||||||||||||||und d", record.ControlField("008"))
+	s.Equal("Grass, Günter", record.Fields("100")[0].Subfield('a'))
+	s.Equal("Die Blechtrommel", record.Fields("245")[0].Subfield('a'))
+	s.Equal("736 pages", record.Fields("300")[0].Subfield('a'))
+	s.Equal("novel", record.Fields("655")[0].Subfield('a'))
+
+	row := marc.ToImportRow(record, 1)
+	s.Nil(row.Errors)
+	s.Equal("978-3-423-11821-6", row.Book.ISBN)
+	s.Equal(uint16(736), row.Book.Pages)
+	s.Equal(1985, row.Book.PublishDate.Year())
+}
+
+func (s *bookHandlerSuite) TestGetBookCitation_BibTeX() {
+	parsedTime, _ := time.Parse(time.DateOnly, "1974-05-01")
+	book := types.BookWithDetails{
+		ID:          92,
+		Title:       "The Dispossessed & other worlds",
+		PublishDate: types.CustomDate{Time: parsedTime},
+		ISBN:        "978-0-06-051275-4",
+		Pages:       387,
+		Authors: []*types.Author{
+			{FirstName: "Ursula", MiddleName: "K.", LastName: "Le Guin"},
+			{FirstName: "Gabriel", LastName: "García Márquez"},
+		},
+	}
+	s.usecase.On("GetBooksByIDs", mock.AnythingOfType("*context.cancelCtx"), []int64{92}).Return([]*types.BookWithDetails{&book}, nil).Once()
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/books/92/citation", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal("application/x-bibtex; charset=utf-8", response.Header.Get("Content-Type"))
+	s.Equal("@book{leguin1974dispossessed-92,\n"+
+		"  author = {{Le Guin}, Ursula K. and {García Márquez}, Gabriel},\n"+
+		"  title = {The Dispossessed \\& other worlds},\n"+
+		"  year = {1974},\n"+
+		"  date = {1974-05-01},\n"+
+		"  isbn = {978-0-06-051275-4},\n"+
+		"  pagetotal = {387},\n"+
+		"}\n", string(body))
+}
+
+func (s *bookHandlerSuite) TestGetCitations_RIS() {
+	parsedTime, _ := time.Parse(time.DateOnly, "2015-01-01")
+	first := types.BookWithDetails{ID: 93, Title: "Go", PublishDate: types.CustomDate{Time: parsedTime}, ISBN: "9780134190440", Pages: 380,
+		Authors: []*types.Author{{FirstName: "Alan", MiddleName: "A. A.", LastName: "Donovan"}}, Genres: []*types.Genre{{Name: "programming"}}}
+	second := types.BookWithDetails{ID: 94, Title: "Go", PublishDate: types.CustomDate{Time: parsedTime}, ISBN: "9780134190441", Pages: 120,
+		Authors: []*types.Author{{FirstName: "Alan", LastName: "Donovan"}}}
+	s.usecase.On("GetBooksByIDs", mock.AnythingOfType("*context.cancelCtx"), []int64{94, 93}).Return([]*types.BookWithDetails{&second, &first}, nil).Once()
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/citations?ids=94,93,94&format=ris", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal("TY  - BOOK\r\nID  - donovan2015go-94\r\nAU  - Donovan, Alan\r\nTI  - Go\r\nPY  - 2015\r\nDA  - 2015/01/01\r\n"+
+		"SN  - 9780134190441\r\nSP  - 120\r\nER  - \r\n"+
+		"TY  - BOOK\r\nID  - donovan2015go-93\r\nAU  - Donovan, Alan A. A.\r\nTI  - Go\r\nPY  - 2015\r\nDA  - 2015/01/01\r\n"+
+		"SN  - 9780134190440\r\nSP  - 380\r\nKW  - programming\r\nER  - \r\n", string(body))
+}
+
+func (s *bookHandlerSuite) TestGetCitations_InvalidIDs() {
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/citations?ids=1,two&format=csl-json", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusBadRequest, response.StatusCode)
+}
+
+func (s *bookHandlerSuite) TestExportBooks_InvalidFormat() {
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/export/books?format=xml", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusUnprocessableEntity, response.StatusCode)
+}
+
+func (s *bookHandlerSuite) TestDeleteBook_WeakIfMatch() {
+	id := int64(9)
+
+	request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/v1/books/%d", s.testingServer.URL, id), nil)
+	s.NoError(err, "no error when preparing delete request")
+
+	request.Header.Set("If-Match", `W/"3"`)
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusPreconditionFailed, response.StatusCode)
+	s.usecase.AssertNotCalled(s.T(), "DeleteBook", mock.Anything, id, mock.Anything)
+}
+
+func (s *bookHandlerSuite) TestDeleteBook_MissingIfMatch() {
+	id := int64(14)
+
+	request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/v1/books/%d", s.testingServer.URL, id), nil)
+	s.NoError(err, "no error when preparing delete request")
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusPreconditionRequired, response.StatusCode)
+	s.usecase.AssertNotCalled(s.T(), "DeleteBook", mock.Anything, id, mock.Anything)
+}
+
+func (s *bookHandlerSuite) TestDeleteBook_InvalidID() {
+	request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/v1/books/abc", s.testingServer.URL), nil)
+	s.NoError(err, "no error when preparing delete request")
+
+	request.Header.Set("If-Match", "*")
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	s.Equal(http.StatusBadRequest, response.StatusCode)
+	s.JSONEq(`{"error":"invalid id parameter"}`, string(result))
+	s.usecase.AssertNotCalled(s.T(), "DeleteBook", mock.Anything, int64(0), mock.Anything)
+}
+
+func (s *bookHandlerSuite) TestGetBookTranslations_Positive() {
+	id := int64(5)
+	translations := []*types.Translation{
+		{Language: "de", Text: "Der Herr der Ringe"},
+		{Language: "pt-BR", Text: "O Senhor dos Anéis"},
+	}
+
+	s.usecase.On("GetBookTranslations", mock.AnythingOfType("*context.cancelCtx"), id).Return(translations, nil)
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/books/%d/translations", s.testingServer.URL, id))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"translations": translations,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *bookHandlerSuite) TestSetBookTranslation_Positive() {
+	id := int64(5)
+	translation := types.Translation{Language: "pt-BR", Text: "O Senhor dos Anéis"}
+
+	s.usecase.On("SetBookTranslation", mock.AnythingOfType("*context.cancelCtx"), id, &translation).Return(nil)
+
+	requestBody, err := json.Marshal(map[string]string{"text": translation.Text})
+	s.NoError(err, "can`t marshal struct to json")
+
+	request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/api/v1/books/%d/translations/pt-br", s.testingServer.URL, id), bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when preparing put request")
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"translation": translation,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *bookHandlerSuite) TestSetBookTranslation_InvalidLanguage() {
+	requestBody, err := json.Marshal(map[string]string{"text": "Title"})
+	s.NoError(err, "can`t marshal struct to json")
+
+	request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/api/v1/books/5/translations/english!", s.testingServer.URL), bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when preparing put request")
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusBadRequest, response.StatusCode)
+}
+
+func (s *bookHandlerSuite) TestDeleteBookTranslation_Positive() {
+	id := int64(5)
+	s.usecase.On("DeleteBookTranslation", mock.AnythingOfType("*context.cancelCtx"), id, "de").Return(nil)
+
+	request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/v1/books/%d/translations/de", s.testingServer.URL, id), nil)
+	s.NoError(err, "no error when preparing delete request")
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+
+	s.Equal(http.StatusNoContent, response.StatusCode)
+}
+
+func (s *bookHandlerSuite) TestCreateBook_MissingReferences() {
+	parsedTime, _ := time.Parse(time.DateOnly, "2001-05-01")
+	book := types.Book{
+		Title:       "Unknown references",
+		PublishDate: types.CustomDate{Time: parsedTime},
+		ISBN:        "11111100-09001",
+		Pages:       120,
+		Authors:     []int64{1, 404},
+		Genres:      []int64{405, 406},
+	}
+	errs := map[string]string{"authors": "don't exist: 404", "genres": "don't exist: 405, 406"}
+
+	s.usecase.On("CreateBook", mock.AnythingOfType("*context.cancelCtx"), &book).Return(nil, &service.ValidationError{Errors: errs})
+
+	requestBody, err := json.Marshal(&book)
+	s.NoError(err, "can`t marshal struct to json")
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/books", s.testingServer.URL), "application/json", bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"error": errs,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusUnprocessableEntity, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *bookHandlerSuite) TestGetBookRevisions_Positive() {
+	id := int64(7)
+	actorID := int64(1)
+	revisions := []*types.Revision{
+		{ID: 12, Action: types.RevisionUpdate, Snapshot: json.RawMessage(`{"title":"Dune Messiah","pages":256}`), ActorID: &actorID, CreatedAt: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{ID: 10, Action: types.RevisionCreate, Snapshot: json.RawMessage(`{"title":"Dune","pages":256}`), ActorID: &actorID, CreatedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	s.usecase.On("GetBookRevisions", mock.AnythingOfType("*context.cancelCtx"), id).Return(revisions, nil)
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/books/%d/revisions", s.testingServer.URL, id))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"revisions": revisions,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *bookHandlerSuite) TestGetBookRevisions_NotFound() {
+	id := int64(13)
+	s.usecase.On("GetBookRevisions", mock.AnythingOfType("*context.cancelCtx"), id).Return(nil, service.ErrNotFound)
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/books/%d/revisions", s.testingServer.URL, id))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusNotFound, response.StatusCode)
+}
+
+func (s *bookHandlerSuite) TestDiffBookRevisions_Positive() {
+	id := int64(7)
+	diff := &types.RevisionDiff{
+		From:    10,
+		To:      12,
+		Changes: []*types.FieldChange{{Field: "title", From: "Dune", To: "Dune Messiah"}},
+	}
+
+	s.usecase.On("DiffBookRevisions", mock.AnythingOfType("*context.cancelCtx"), id, int64(10), int64(12)).Return(diff, nil)
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/books/%d/revisions/diff?from=10&to=12", s.testingServer.URL, id))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"diff": diff,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *bookHandlerSuite) TestDiffBookRevisions_BadRequest() {
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/books/7/revisions/diff?from=10", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusBadRequest, response.StatusCode)
+}
+
+func (s *bookHandlerSuite) TestRevertBook_Positive() {
+	id := int64(7)
+	parsedTime, _ := time.Parse(time.DateOnly, "1965-08-01")
+	book := &types.Book{
+		ID:          id,
+		Title:       "Dune",
+		PublishDate: types.CustomDate{Time: parsedTime},
+		ISBN:        "978-0441013593",
+		Pages:       412,
+		Authors:     []int64{1},
+		Genres:      []int64{2},
+		Tags:        []string{},
+	}
+
+	s.usecase.On("RevertBook", mock.AnythingOfType("*context.cancelCtx"), id, int64(0), int64(10)).Return(book, nil)
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/books/%d/revisions/10/revert", s.testingServer.URL, id), "application/json", nil)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"book": book,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *bookHandlerSuite) TestRevertBook_NotFound() {
+	id := int64(8)
+	s.usecase.On("RevertBook", mock.AnythingOfType("*context.cancelCtx"), id, int64(0), int64(3)).Return(nil, service.ErrNotFound)
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/books/%d/revisions/3/revert", s.testingServer.URL, id), "application/json", nil)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusNotFound, response.StatusCode)
+}
+
 func TestBookHandler(t *testing.T) {
 	suite.Run(t, new(bookHandlerSuite))
 }
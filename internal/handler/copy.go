@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/tredoc/go-crud-api/internal/service"
+	"github.com/tredoc/go-crud-api/internal/validator"
+	"github.com/tredoc/go-crud-api/pkg/log"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"net/http"
+)
+
+type CopyHandler struct {
+	service service.Copy
+}
+
+func NewCopyHandler(service service.Copy) *CopyHandler {
+	return &CopyHandler{
+		service: service,
+	}
+}
+
+// CreateCopy godoc
+// @Summary Add a physical copy of a book
+// @Description Add a physical copy with a unique barcode, shelf location and condition
+// @Tags copies
+// @ID create-copy
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Book ID"
+// @Param copy body types.Copy true "Copy object that needs to be added"
+// @Security Bearer
+// @Success 201 {object} types.Copy
+// @Router /api/v1/books/{id}/copies [post]
+func (h *CopyHandler) CreateCopy(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	bookID, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	bookCopy := types.Copy{Condition: types.GoodCondition}
+	err = json.NewDecoder(r.Body).Decode(&bookCopy)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("can't decode request"))
+		return
+	}
+
+	v := validator.New()
+	types.ValidateCopy(v, &bookCopy)
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return
+	}
+
+	bookCopy.BookID = bookID
+	newCopy, err := h.service.CreateCopy(r.Context(), &bookCopy)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrEntityExists):
+			badRequestResponse(w, r, fmt.Errorf("copy with barcode '%s' already exists", bookCopy.Barcode))
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusCreated, envelope{"copy": newCopy}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// GetBookCopies godoc
+// @Summary Get copies of a book
+// @Description Get a list of physical copies of a book with their availability
+// @Tags copies
+// @ID get-book-copies
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param id path int true "Book ID"
+// @Success 200 {array} []types.Copy
+// @Router /api/v1/books/{id}/copies [get]
+func (h *CopyHandler) GetBookCopies(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	bookID, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	copies, err := h.service.GetCopiesByBookID(r.Context(), bookID)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"copies": copies}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// UpdateCopy godoc
+// @Summary Update a copy
+// @Description Update barcode, location or condition of a copy with a specific ID
+// @Tags copies
+// @ID update-copy
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Copy ID"
+// @Param copy body types.UpdateCopy true "Copy object that needs to be updated"
+// @Security Bearer
+// @Success 200 {object} types.Copy
+// @Router /api/v1/copies/{id} [patch]
+func (h *CopyHandler) UpdateCopy(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	var bookCopy types.UpdateCopy
+	err = json.NewDecoder(r.Body).Decode(&bookCopy)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("can't decode request"))
+		return
+	}
+
+	v := validator.New()
+	types.ValidateUpdateCopy(v, &bookCopy)
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return
+	}
+
+	updatedCopy, err := h.service.UpdateCopy(r.Context(), id, &bookCopy)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrEntityExists):
+			badRequestResponse(w, r, fmt.Errorf("copy with barcode '%s' already exists", *bookCopy.Barcode))
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"copy": updatedCopy}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// DeleteCopy godoc
+// @Summary Delete a copy
+// @Description Delete a copy with a specific ID, copies on an active loan can't be deleted
+// @Tags copies
+// @ID delete-copy
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Copy ID"
+// @Security Bearer
+// @Success 204 "No Content"
+// @Router /api/v1/copies/{id} [delete]
+func (h *CopyHandler) DeleteCopy(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	err = h.service.DeleteCopy(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrCopyUnavailable):
+			conflictResponse(w, r, "copy is on an active loan")
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
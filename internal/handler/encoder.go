@@ -0,0 +1,696 @@
+package handler
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/tredoc/go-crud-api/pkg/msgpack"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"math"
+	"mime"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// responseEncoder writes response envelopes in one media type. Encoders walk the envelope the way
+// encoding/json does, so field names, omitted fields and the format of dates are the same in every
+// type.
+type responseEncoder struct {
+	contentType string
+	// mediaTypes are the types of the Accept header the encoder answers, the first is its own.
+	mediaTypes []string
+	// listsOnly encoders can only write envelopes that hold a single list.
+	listsOnly bool
+	encode    func(data envelope) ([]byte, error)
+}
+
+// responseEncoders are the encoders of responses in the order of preference, JSON is the default.
+var responseEncoders = []*responseEncoder{
+	{
+		contentType: "application/json",
+		mediaTypes:  []string{"application/json"},
+		encode:      encodeJSON,
+	},
+	{
+		contentType: "application/xml; charset=utf-8",
+		mediaTypes:  []string{"application/xml", "text/xml"},
+		encode:      encodeXML,
+	},
+	{
+		contentType: types.CSVType + "; charset=utf-8",
+		mediaTypes:  []string{types.CSVType},
+		listsOnly:   true,
+		encode:      encodeCSV,
+	},
+	{
+		contentType: msgpack.Type,
+		mediaTypes:  []string{msgpack.Type, "application/x-msgpack", "application/vnd.msgpack"},
+		encode:      encodeMessagePack,
+	},
+}
+
+// mediaRange is a range of the Accept header with its weight.
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept reads the ranges of an Accept header. Ranges that can't be parsed are skipped.
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(value, 64)
+			if err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, q: q})
+	}
+
+	return ranges
+}
+
+// quality returns the weight the ranges give the encoder. The most specific range that matches one
+// of its media types decides, as RFC 9110 has it, so "text/*;q=0, text/csv" accepts CSV. Wildcards
+// only match the own type of the encoder, not its aliases.
+func (e *responseEncoder) quality(ranges []mediaRange) float64 {
+	quality := 0.0
+	for i, mediaType := range e.mediaTypes {
+		kind, _, _ := strings.Cut(mediaType, "/")
+		specificity := -1
+		q := 0.0
+		for _, r := range ranges {
+			var s int
+			switch {
+			case r.mediaType == mediaType:
+				s = 2
+			case i == 0 && r.mediaType == kind+"/*":
+				s = 1
+			case i == 0 && r.mediaType == "*/*":
+				s = 0
+			default:
+				continue
+			}
+			if s > specificity {
+				specificity, q = s, r.q
+			}
+		}
+		quality = max(quality, q)
+	}
+
+	return quality
+}
+
+// negotiateEncoder picks the encoder of the response from the Accept header, the one with the
+// highest weight and of those the one registered first. A header without a range that can be
+// parsed is treated like a missing one and gets JSON.
+func negotiateEncoder(accept string, list bool) (*responseEncoder, bool) {
+	ranges := parseAccept(accept)
+	if len(ranges) == 0 {
+		return responseEncoders[0], true
+	}
+
+	var best *responseEncoder
+	bestQuality := 0.0
+	for _, encoder := range responseEncoders {
+		if encoder.listsOnly && !list {
+			continue
+		}
+		if q := encoder.quality(ranges); q > bestQuality {
+			best, bestQuality = encoder, q
+		}
+	}
+
+	return best, best != nil
+}
+
+// acceptableTypes returns the media types a response can be sent as.
+func acceptableTypes(list bool) []string {
+	var mediaTypes []string
+	for _, encoder := range responseEncoders {
+		if !encoder.listsOnly || list {
+			mediaTypes = append(mediaTypes, encoder.mediaTypes[0])
+		}
+	}
+
+	return mediaTypes
+}
+
+// isListEnvelope tells if the envelope holds nothing but a list, the only kind of response CSV can
+// hold.
+func isListEnvelope(data envelope) bool {
+	if len(data) != 1 {
+		return false
+	}
+
+	for _, value := range data {
+		kind := reflect.ValueOf(value).Kind()
+		return kind == reflect.Slice || kind == reflect.Array
+	}
+
+	return false
+}
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	jsonNumberType    = reflect.TypeFor[json.Number]()
+)
+
+// resolveValue follows pointers and interfaces and runs the MarshalJSON and MarshalText methods
+// encoding/json would run, the value they return is decoded like the JSON of a request. Null is the
+// invalid value.
+func resolveValue(v reflect.Value) (reflect.Value, error) {
+	for v.IsValid() {
+		switch v.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+			if v.IsNil() {
+				return reflect.Value{}, nil
+			}
+		}
+
+		if marshaler, ok := methodReceiver(v, jsonMarshalerType); ok {
+			js, err := marshaler.Interface().(json.Marshaler).MarshalJSON()
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			decoder := json.NewDecoder(bytes.NewReader(js))
+			decoder.UseNumber()
+			var value any
+			err = decoder.Decode(&value)
+			return reflect.ValueOf(value), err
+		}
+
+		if marshaler, ok := methodReceiver(v, textMarshalerType); ok {
+			text, err := marshaler.Interface().(encoding.TextMarshaler).MarshalText()
+			return reflect.ValueOf(string(text)), err
+		}
+
+		if v.Kind() != reflect.Pointer && v.Kind() != reflect.Interface {
+			return v, nil
+		}
+		v = v.Elem()
+	}
+
+	return v, nil
+}
+
+// methodReceiver returns the value or, when only its pointer has them, the address of an
+// addressable value that has the methods of the interface.
+func methodReceiver(v reflect.Value, methods reflect.Type) (reflect.Value, bool) {
+	if v.Type().Implements(methods) {
+		return v, true
+	}
+	if v.Kind() != reflect.Pointer && v.CanAddr() && v.Addr().Type().Implements(methods) {
+		return v.Addr(), true
+	}
+
+	return reflect.Value{}, false
+}
+
+// marshalJSON returns the JSON of the value the way encoding/json writes it as a member, CSV cells
+// hold nested objects as JSON.
+func marshalJSON(v reflect.Value) ([]byte, error) {
+	if v.CanAddr() {
+		return json.Marshal(v.Addr().Interface())
+	}
+
+	return json.Marshal(v.Interface())
+}
+
+func isObject(v reflect.Value) bool {
+	return v.IsValid() && (v.Kind() == reflect.Struct || v.Kind() == reflect.Map)
+}
+
+// isList tells if the value is an array or a slice other than []byte, which encoding/json writes
+// as a base64 string.
+func isList(v reflect.Value) bool {
+	return v.IsValid() && (v.Kind() == reflect.Array || v.Kind() == reflect.Slice) && !isBytes(v)
+}
+
+func isBytes(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8
+}
+
+// valueMember is a member of an object of a response.
+type valueMember struct {
+	name  string
+	value reflect.Value
+}
+
+// objectMembers returns the members of a resolved struct or map in the order encoding/json writes
+// them, struct fields in the order of their declaration and map entries by key.
+func objectMembers(v reflect.Value) []valueMember {
+	var members []valueMember
+	if v.Kind() == reflect.Map {
+		iter := v.MapRange()
+		for iter.Next() {
+			members = append(members, valueMember{name: mapKeyName(iter.Key()), value: iter.Value()})
+		}
+		sort.Slice(members, func(i, j int) bool {
+			return members[i].name < members[j].name
+		})
+		return members
+	}
+
+	for _, field := range structFields(v.Type()) {
+		value, ok := fieldByIndex(v, field.index)
+		if !ok || field.omitEmpty && isEmptyValue(value) {
+			continue
+		}
+		members = append(members, valueMember{name: field.name, value: value})
+	}
+
+	return members
+}
+
+func mapKeyName(key reflect.Value) string {
+	switch key.Kind() {
+	case reflect.String:
+		return key.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(key.Uint(), 10)
+	}
+
+	return fmt.Sprint(key.Interface())
+}
+
+// structField is a field of a struct as encoding/json writes it.
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+var structFieldCache sync.Map
+
+// structFields returns the fields encoding/json writes of the struct type: the exported ones, named
+// by their json tags and without the "-" ones, and the fields of embedded structs that no shallower
+// field has the name of.
+func structFields(t reflect.Type) []structField {
+	if fields, ok := structFieldCache.Load(t); ok {
+		return fields.([]structField)
+	}
+
+	var fields []structField
+	seen := make(map[string]bool)
+	level := []structField{{}}
+	levelTypes := []reflect.Type{t}
+	for len(level) > 0 {
+		var next []structField
+		var nextTypes []reflect.Type
+		for k, embedded := range level {
+			st := levelTypes[k]
+			for i := 0; i < st.NumField(); i++ {
+				f := st.Field(i)
+				tag := f.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+
+				name, options, _ := strings.Cut(tag, ",")
+				index := append(slices.Clone(embedded.index), i)
+				ft := f.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+					next = append(next, structField{index: index})
+					nextTypes = append(nextTypes, ft)
+					continue
+				}
+
+				if !f.IsExported() {
+					continue
+				}
+				if name == "" {
+					name = f.Name
+				}
+				if seen[name] {
+					continue
+				}
+				seen[name] = true
+				fields = append(fields, structField{name: name, index: index, omitEmpty: strings.Contains(","+options+",", ",omitempty,")})
+			}
+		}
+		level, levelTypes = next, nextTypes
+	}
+
+	slices.SortFunc(fields, func(a, b structField) int {
+		return slices.Compare(a.index, b.index)
+	})
+	structFieldCache.Store(t, fields)
+	return fields
+}
+
+// fieldByIndex returns the field of the struct, it's missing when an embedded pointer on its way
+// is nil.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, fieldIndex := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(fieldIndex)
+	}
+
+	return v, true
+}
+
+// isEmptyValue tells if omitempty leaves the value out.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+
+	return false
+}
+
+// scalarText returns the text of a resolved string, number or boolean as encoding/json writes it,
+// without quotes, and an empty string for null.
+func scalarText(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		format := byte('f')
+		if abs := math.Abs(v.Float()); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+			format = 'e'
+		}
+		return strconv.FormatFloat(v.Float(), format, -1, v.Type().Bits())
+	case reflect.Slice:
+		if isBytes(v) {
+			return base64.StdEncoding.EncodeToString(v.Bytes())
+		}
+	}
+
+	return ""
+}
+
+func encodeJSON(data envelope) ([]byte, error) {
+	return json.Marshal(data)
+}
+
+var xmlNameRX = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// encodeXML writes the envelope as a response element. Members of objects become elements of the
+// same name, members whose names can't be element names, like the keys of some validation errors,
+// become entry elements with a key attribute. Elements of arrays become item elements.
+func encodeXML(data envelope) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	err := writeXMLValue(encoder, xml.StartElement{Name: xml.Name{Local: "response"}}, reflect.ValueOf(data))
+	if err != nil {
+		return nil, err
+	}
+	err = encoder.Flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeXMLValue(encoder *xml.Encoder, start xml.StartElement, value reflect.Value) error {
+	value, err := resolveValue(value)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case isObject(value):
+		for _, member := range objectMembers(value) {
+			element := xml.StartElement{Name: xml.Name{Local: member.name}}
+			if !xmlNameRX.MatchString(member.name) || strings.HasPrefix(strings.ToLower(member.name), "xml") {
+				element = xml.StartElement{
+					Name: xml.Name{Local: "entry"},
+					Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: member.name}},
+				}
+			}
+			err = writeXMLValue(encoder, element, member.value)
+			if err != nil {
+				return err
+			}
+		}
+	case isList(value):
+		for i := 0; i < value.Len(); i++ {
+			err = writeXMLValue(encoder, xml.StartElement{Name: xml.Name{Local: "item"}}, value.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		if text := scalarText(value); text != "" {
+			err = encoder.EncodeToken(xml.CharData(text))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return encoder.EncodeToken(start.End())
+}
+
+// encodeCSV writes the list of the envelope with a header and a line per element. Columns are the
+// members of the elements in the order they first show up, a list of plain values has a single
+// column named after the list. Lists of plain values in a cell are separated by semicolons like
+// the import expects them, nested objects are written as JSON.
+func encodeCSV(data envelope) ([]byte, error) {
+	if len(data) != 1 {
+		return nil, errors.New("csv response has to hold a single list")
+	}
+
+	var name string
+	var list reflect.Value
+	for key, value := range data {
+		name = key
+		var err error
+		list, err = resolveValue(reflect.ValueOf(value))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if list.IsValid() && !isList(list) {
+		return nil, errors.New("csv response has to hold a single list")
+	}
+
+	var items []reflect.Value
+	plain := false
+	for i := 0; list.IsValid() && i < list.Len(); i++ {
+		item, err := resolveValue(list.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		plain = plain || !isObject(item)
+	}
+
+	rows := make([][]valueMember, len(items))
+	var columns []string
+	seen := make(map[string]bool)
+	for i, item := range items {
+		if !isObject(item) {
+			rows[i] = []valueMember{{name: name, value: item}}
+			continue
+		}
+
+		rows[i] = objectMembers(item)
+		for _, member := range rows[i] {
+			if !seen[member.name] {
+				seen[member.name] = true
+				columns = append(columns, member.name)
+			}
+		}
+	}
+	if plain {
+		columns = []string{name}
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	err := writer.Write(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, members := range rows {
+		record := make([]string, len(columns))
+		for _, member := range members {
+			for i, column := range columns {
+				if column == member.name {
+					record[i], err = csvCell(member.value)
+					if err != nil {
+						return nil, err
+					}
+					break
+				}
+			}
+		}
+		err = writer.Write(record)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+func csvCell(value reflect.Value) (string, error) {
+	resolved, err := resolveValue(value)
+	if err != nil {
+		return "", err
+	}
+
+	if isList(resolved) {
+		values := make([]string, resolved.Len())
+		for i := range values {
+			item, err := resolveValue(resolved.Index(i))
+			if err != nil {
+				return "", err
+			}
+			if isObject(item) || isList(item) {
+				js, err := marshalJSON(value)
+				return string(js), err
+			}
+			values[i] = scalarText(item)
+		}
+		return strings.Join(values, "; "), nil
+	}
+
+	if isObject(resolved) {
+		js, err := marshalJSON(value)
+		return string(js), err
+	}
+
+	return scalarText(resolved), nil
+}
+
+// encodeMessagePack writes the envelope as MessagePack maps, arrays and values. Integers keep
+// their type, floats are written as 64 bit floats and json.Number values by what they hold.
+func encodeMessagePack(data envelope) ([]byte, error) {
+	var encoder msgpack.Encoder
+	err := writeMessagePackValue(&encoder, reflect.ValueOf(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return encoder.Bytes(), nil
+}
+
+func writeMessagePackValue(encoder *msgpack.Encoder, value reflect.Value) error {
+	value, err := resolveValue(value)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !value.IsValid():
+		encoder.EncodeNil()
+		return nil
+	case isObject(value):
+		members := objectMembers(value)
+		encoder.EncodeMapLen(len(members))
+		for _, member := range members {
+			encoder.EncodeString(member.name)
+			err = writeMessagePackValue(encoder, member.value)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case isList(value):
+		encoder.EncodeArrayLen(value.Len())
+		for i := 0; i < value.Len(); i++ {
+			err = writeMessagePackValue(encoder, value.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case value.Type() == jsonNumberType:
+		number := json.Number(value.String())
+		if i, err := number.Int64(); err == nil {
+			encoder.EncodeInt(i)
+			return nil
+		}
+		f, err := number.Float64()
+		encoder.EncodeFloat(f)
+		return err
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		encoder.EncodeString(value.String())
+	case reflect.Bool:
+		encoder.EncodeBool(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		encoder.EncodeInt(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		encoder.EncodeUint(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		encoder.EncodeFloat(value.Float())
+	case reflect.Slice:
+		// isList leaves out []byte, encoding/json writes it as base64.
+		encoder.EncodeString(scalarText(value))
+	default:
+		return fmt.Errorf("can't encode %s as msgpack", value.Type())
+	}
+
+	return nil
+}
+
+// isSafeMethod tells if the request changes nothing, so refusing its response loses nothing.
+func isSafeMethod(r *http.Request) bool {
+	return r.Method == http.MethodGet || r.Method == http.MethodHead
+}
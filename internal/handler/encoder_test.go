@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"encoding/xml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tredoc/go-crud-api/pkg/msgpack"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseAccept(t *testing.T) {
+	ranges := parseAccept("text/csv;q=0.5, application/json , */*;q=0, text/xml;q=2, ;;, application/msgpack;q=abc")
+
+	assert.Equal(t, []mediaRange{
+		{mediaType: "text/csv", q: 0.5},
+		{mediaType: "application/json", q: 1},
+		{mediaType: "*/*", q: 0},
+	}, ranges)
+}
+
+func TestNegotiateEncoder(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		list        bool
+		contentType string
+	}{
+		{name: "no header", accept: "", contentType: "application/json"},
+		{name: "unparsable header", accept: ";;", contentType: "application/json"},
+		{name: "any type", accept: "*/*", contentType: "application/json"},
+		{name: "exact type", accept: "application/xml", contentType: "application/xml; charset=utf-8"},
+		{name: "alias", accept: "application/x-msgpack", contentType: msgpack.Type},
+		{name: "highest weight", accept: "application/json;q=0.4, application/msgpack;q=0.9", contentType: msgpack.Type},
+		{name: "registered first of the same weight", accept: "application/msgpack, application/xml", contentType: "application/xml; charset=utf-8"},
+		{name: "type wildcard", accept: "text/*", list: true, contentType: "text/csv; charset=utf-8"},
+		{name: "type wildcard without aliases", accept: "application/*;q=0.5, application/json;q=0", contentType: "application/xml; charset=utf-8"},
+		{name: "specific range beats the wildcard", accept: "text/*;q=0, text/csv", list: true, contentType: "text/csv; charset=utf-8"},
+		{name: "csv for a list", accept: "text/csv, application/json;q=0.5", list: true, contentType: "text/csv; charset=utf-8"},
+		{name: "csv only for lists", accept: "text/csv, application/json;q=0.5", contentType: "application/json"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encoder, ok := negotiateEncoder(test.accept, test.list)
+			require.True(t, ok)
+			assert.Equal(t, test.contentType, encoder.contentType)
+		})
+	}
+}
+
+func TestNegotiateEncoder_NotAcceptable(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		list   bool
+	}{
+		{name: "refused with q=0", accept: "application/json;q=0"},
+		{name: "all refused", accept: "*/*;q=0"},
+		{name: "unknown type", accept: "image/png"},
+		{name: "csv for a single entity", accept: "text/csv"},
+		{name: "wildcard of an alias", accept: "application/x-*", list: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encoder, ok := negotiateEncoder(test.accept, test.list)
+			assert.False(t, ok)
+			assert.Nil(t, encoder)
+		})
+	}
+}
+
+func TestWriteResponse_NothingAcceptable(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		status      int
+		data        envelope
+		wantStatus  int
+		contentType string
+	}{
+		{name: "successful read", method: http.MethodGet, status: http.StatusOK, data: envelope{"genre": &types.Genre{ID: 5}},
+			wantStatus: http.StatusNotAcceptable, contentType: "application/json"},
+		{name: "failed read", method: http.MethodGet, status: http.StatusNotFound, data: envelope{"error": "not found"},
+			wantStatus: http.StatusNotFound, contentType: "application/json"},
+		{name: "successful change", method: http.MethodPost, status: http.StatusCreated, data: envelope{"genre": &types.Genre{ID: 5}},
+			wantStatus: http.StatusCreated, contentType: "application/json"},
+		{name: "failed change", method: http.MethodPut, status: http.StatusConflict, data: envelope{"error": "conflict"},
+			wantStatus: http.StatusConflict, contentType: "application/json"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(test.method, "/api/v1/genres/5", nil)
+			r.Header.Set("Accept", "image/png")
+			w := httptest.NewRecorder()
+
+			err := writeResponse(w, r, test.status, test.data, nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.wantStatus, w.Code)
+			assert.Equal(t, test.contentType, w.Header().Get("Content-Type"))
+			if test.wantStatus == test.status {
+				body, err := encodeJSON(test.data)
+				require.NoError(t, err)
+				assert.Equal(t, body, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+type encodedEdition struct {
+	Number int    `json:"number"`
+	Note   string `json:"note,omitempty"`
+}
+
+type encodedBook struct {
+	encodedEdition
+	ID          int64             `json:"id"`
+	Title       string            `json:"title"`
+	Subtitle    string            `json:"subtitle,omitempty"`
+	PublishDate types.CustomDate  `json:"publish_date"`
+	Rating      float64           `json:"rating"`
+	Pages       uint16            `json:"pages"`
+	Hidden      string            `json:"-"`
+	Tags        []string          `json:"tags"`
+	Extra       map[string]string `json:"extra,omitempty"`
+	Series      *encodedEdition   `json:"series"`
+	internal    string
+}
+
+func sampleEncodedBook(t *testing.T) *encodedBook {
+	published, err := time.Parse(time.DateOnly, "1974-05-01")
+	require.NoError(t, err)
+	return &encodedBook{
+		encodedEdition: encodedEdition{Number: 2},
+		ID:             7,
+		Title:          "The Dispossessed",
+		PublishDate:    types.CustomDate{Time: published},
+		Rating:         4,
+		Pages:          387,
+		Hidden:         "secret",
+		Tags:           []string{"utopia", "classic"},
+		internal:       "internal",
+	}
+}
+
+func TestEncodeXML(t *testing.T) {
+	body, err := encodeXML(envelope{"book": sampleEncodedBook(t), "errors": map[string]string{"pages[0]": "invalid"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, xml.Header+"<response>"+
+		"<book><number>2</number><id>7</id><title>The Dispossessed</title><publish_date>1974-05-01</publish_date>"+
+		"<rating>4</rating><pages>387</pages><tags><item>utopia</item><item>classic</item></tags><series></series></book>"+
+		`<errors><entry key="pages[0]">invalid</entry></errors>`+
+		"</response>", string(body))
+}
+
+func TestEncodeCSV(t *testing.T) {
+	t.Run("objects", func(t *testing.T) {
+		other := sampleEncodedBook(t)
+		other.ID, other.Subtitle, other.Tags = 8, "A novel", nil
+		other.Series = &encodedEdition{Number: 1, Note: "Hainish"}
+
+		body, err := encodeCSV(envelope{"books": []*encodedBook{sampleEncodedBook(t), other}})
+		require.NoError(t, err)
+
+		assert.Equal(t, "number,id,title,publish_date,rating,pages,tags,series,subtitle\n"+
+			"2,7,The Dispossessed,1974-05-01,4,387,utopia; classic,,\n"+
+			`2,8,The Dispossessed,1974-05-01,4,387,,"{""number"":1,""note"":""Hainish""}",A novel`+"\n", string(body))
+	})
+
+	t.Run("plain values", func(t *testing.T) {
+		body, err := encodeCSV(envelope{"tags": []string{"utopia", "a, b"}})
+		require.NoError(t, err)
+		assert.Equal(t, "tags\nutopia\n\"a, b\"\n", string(body))
+	})
+
+	t.Run("not a list", func(t *testing.T) {
+		_, err := encodeCSV(envelope{"book": sampleEncodedBook(t)})
+		assert.Error(t, err)
+	})
+}
+
+func TestEncodeMessagePack(t *testing.T) {
+	body, err := encodeMessagePack(envelope{"genre": &types.Genre{ID: 5, Name: "sf"}, "count": uint64(300)})
+	require.NoError(t, err)
+
+	var want msgpack.Encoder
+	want.EncodeMapLen(2)
+	want.EncodeString("count")
+	want.EncodeInt(300)
+	want.EncodeString("genre")
+	want.EncodeMapLen(2)
+	want.EncodeString("id")
+	want.EncodeInt(5)
+	want.EncodeString("name")
+	want.EncodeString("sf")
+	assert.Equal(t, want.Bytes(), body)
+
+	t.Run("floats stay floats", func(t *testing.T) {
+		body, err := encodeMessagePack(envelope{"rating": 4.0})
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0x81, 0xa6, 'r', 'a', 't', 'i', 'n', 'g', 0xcb, 0x40, 0x10, 0, 0, 0, 0, 0, 0}, body)
+	})
+
+	t.Run("dates like json", func(t *testing.T) {
+		body, err := encodeMessagePack(envelope{"book": sampleEncodedBook(t)})
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "\xacpublish_date\xaa1974-05-01")
+	})
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"github.com/tredoc/go-crud-api/pkg/marc"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportFlushInterval is the number of books written between flushes of an export response.
+const exportFlushInterval = 100
+
+// bookExporter writes the books of an export in one format as they come from the database.
+type bookExporter interface {
+	begin() error
+	write(*types.ExportedBook) error
+	end() error
+}
+
+// newBookExporter returns the exporter of the format together with its content type.
+func newBookExporter(format types.ExportFormat, w io.Writer) (bookExporter, string, bool) {
+	switch format {
+	case types.CSVExport:
+		return &csvBookExporter{w: csv.NewWriter(w)}, types.CSVType, true
+	case types.NDJSONExport:
+		return &ndjsonBookExporter{encoder: json.NewEncoder(w)}, types.NDJSONType, true
+	case types.JSONExport:
+		return &jsonBookExporter{w: w}, "application/json", true
+	case types.MARCExport:
+		return &marcBookExporter{w: w}, marc.Type, true
+	case types.MARCXMLExport:
+		return &marcXMLBookExporter{w: w}, marc.XMLType, true
+	}
+
+	return nil, "", false
+}
+
+// exportExtension returns the file extension of the format.
+func exportExtension(format types.ExportFormat) string {
+	switch format {
+	case types.MARCExport:
+		return "mrc"
+	case types.MARCXMLExport:
+		return "xml"
+	}
+
+	return string(format)
+}
+
+// csvBookExporter writes a header and a line per book, authors, genres and tags are separated by
+// semicolons like the import expects them.
+type csvBookExporter struct {
+	w *csv.Writer
+}
+
+func (e *csvBookExporter) begin() error {
+	return e.w.Write([]string{"id", "title", "publish_date", "isbn", "pages", "average_rating", "ratings_count", "authors", "genres", "tags"})
+}
+
+func (e *csvBookExporter) write(book *types.ExportedBook) error {
+	err := e.w.Write([]string{
+		strconv.FormatInt(book.ID, 10),
+		book.Title,
+		book.PublishDate.Format(time.DateOnly),
+		book.ISBN,
+		strconv.FormatUint(uint64(book.Pages), 10),
+		strconv.FormatFloat(book.AverageRating, 'f', -1, 64),
+		strconv.FormatInt(book.RatingsCount, 10),
+		strings.Join(book.Authors, "; "),
+		strings.Join(book.Genres, "; "),
+		strings.Join(book.Tags, "; "),
+	})
+	if err != nil {
+		return err
+	}
+
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvBookExporter) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// ndjsonBookExporter writes a JSON object per line.
+type ndjsonBookExporter struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonBookExporter) begin() error {
+	return nil
+}
+
+func (e *ndjsonBookExporter) write(book *types.ExportedBook) error {
+	return e.encoder.Encode(book)
+}
+
+func (e *ndjsonBookExporter) end() error {
+	return nil
+}
+
+// jsonBookExporter writes a JSON array one element at a time.
+type jsonBookExporter struct {
+	w       io.Writer
+	written bool
+}
+
+func (e *jsonBookExporter) begin() error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonBookExporter) write(book *types.ExportedBook) error {
+	js, err := json.Marshal(book)
+	if err != nil {
+		return err
+	}
+
+	if e.written {
+		_, err = io.WriteString(e.w, ",")
+		if err != nil {
+			return err
+		}
+	}
+	e.written = true
+
+	_, err = e.w.Write(js)
+	return err
+}
+
+func (e *jsonBookExporter) end() error {
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+// marcBookExporter writes a MARC 21 record per book in ISO 2709.
+type marcBookExporter struct {
+	w io.Writer
+}
+
+func (e *marcBookExporter) begin() error {
+	return nil
+}
+
+func (e *marcBookExporter) write(book *types.ExportedBook) error {
+	record, err := marc.Marshal(marc.FromBook(exportedBookDetails(book)))
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(record)
+	return err
+}
+
+func (e *marcBookExporter) end() error {
+	return nil
+}
+
+// marcXMLBookExporter writes a MARCXML collection one record at a time.
+type marcXMLBookExporter struct {
+	w io.Writer
+}
+
+func (e *marcXMLBookExporter) begin() error {
+	_, err := io.WriteString(e.w, xml.Header+`<collection xmlns="`+marc.Namespace+`">`)
+	return err
+}
+
+func (e *marcXMLBookExporter) write(book *types.ExportedBook) error {
+	record, err := marc.MarshalXML(marc.FromBook(exportedBookDetails(book)))
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(record)
+	return err
+}
+
+func (e *marcXMLBookExporter) end() error {
+	_, err := io.WriteString(e.w, "</collection>\n")
+	return err
+}
+
+// exportedBookDetails returns the exported book the way MARC records are made from.
+func exportedBookDetails(book *types.ExportedBook) *types.BookWithDetails {
+	genres := make([]*types.Genre, len(book.Genres))
+	for i, name := range book.Genres {
+		genres[i] = &types.Genre{Name: name}
+	}
+
+	return &types.BookWithDetails{
+		ID:          book.ID,
+		Title:       book.Title,
+		PublishDate: book.PublishDate,
+		CreatedAt:   book.CreatedAt,
+		ISBN:        book.ISBN,
+		Pages:       book.Pages,
+		Authors:     book.Credits,
+		Genres:      genres,
+		Tags:        book.Tags,
+	}
+}
@@ -0,0 +1,388 @@
+package handler
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/tredoc/go-crud-api/pkg/marc"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	atomNavigationType  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	atomAcquisitionType = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	opds2Type           = "application/opds+json"
+	openSearchType      = "application/opensearchdescription+xml"
+
+	opdsSortNewRel = "http://opds-spec.org/sort/new"
+)
+
+// opdsFeed is a catalogue feed before it's written as OPDS 1.2 or 2.0. A navigation feed lists
+// other feeds, an acquisition feed lists books. Paths are relative to the root of the OPDS version.
+type opdsFeed struct {
+	title       string
+	path        string
+	query       url.Values
+	acquisition bool
+	navigation  []*opdsNavigation
+	books       []*types.ExportedBook
+	// page counts from 1 in a paged feed of total entries, 0 in a feed that isn't paged.
+	page  int
+	total int
+}
+
+type opdsNavigation struct {
+	title       string
+	summary     string
+	path        string
+	rel         string
+	acquisition bool
+}
+
+// opdsLinks holds the absolute URLs the feeds of one OPDS version are linked with.
+type opdsLinks struct {
+	origin string
+	root   string
+}
+
+func newOPDSLinks(r *http.Request, root string) *opdsLinks {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	return &opdsLinks{origin: scheme + "://" + r.Host, root: root}
+}
+
+func (l *opdsLinks) feed(path string, query url.Values) string {
+	if len(query) == 0 {
+		return l.origin + l.root + path
+	}
+
+	return l.origin + l.root + path + "?" + query.Encode()
+}
+
+func (l *opdsLinks) book(id int64) string {
+	return fmt.Sprintf("%s/api/v1/books/%d", l.origin, id)
+}
+
+// pages returns the links of a paged feed by relation, the feed itself included.
+func (l *opdsLinks) pages(feed *opdsFeed) map[string]string {
+	link := func(page int) string {
+		query := url.Values{}
+		for key, values := range feed.query {
+			query[key] = values
+		}
+		query.Set("page", strconv.Itoa(page))
+		return l.feed(feed.path, query)
+	}
+
+	if feed.page == 0 {
+		return map[string]string{"self": l.feed(feed.path, feed.query)}
+	}
+
+	last := (feed.total + opdsPageSize - 1) / opdsPageSize
+	if last < 1 {
+		last = 1
+	}
+
+	links := map[string]string{"self": link(feed.page), "first": link(1), "last": link(last)}
+	if feed.page > 1 {
+		links["previous"] = link(min(feed.page-1, last))
+	}
+	if feed.page < last {
+		links["next"] = link(feed.page + 1)
+	}
+
+	return links
+}
+
+// pageRels is the order the pagination links are written in.
+var pageRels = []string{"self", "first", "previous", "next", "last"}
+
+// updated returns when the books of the feed were last added to, or now for a feed without books.
+func (f *opdsFeed) updated() time.Time {
+	var updated time.Time
+	for _, book := range f.books {
+		if book.CreatedAt.After(updated) {
+			updated = book.CreatedAt
+		}
+	}
+
+	if updated.IsZero() {
+		return time.Now().UTC()
+	}
+
+	return updated.UTC()
+}
+
+type atomFeed struct {
+	XMLName         xml.Name     `xml:"feed"`
+	Xmlns           string       `xml:"xmlns,attr"`
+	XmlnsDC         string       `xml:"xmlns:dc,attr"`
+	XmlnsOpenSearch string       `xml:"xmlns:opensearch,attr"`
+	ID              string       `xml:"id"`
+	Title           string       `xml:"title"`
+	Updated         string       `xml:"updated"`
+	Author          atomPerson   `xml:"author"`
+	TotalResults    *int         `xml:"opensearch:totalResults,omitempty"`
+	ItemsPerPage    *int         `xml:"opensearch:itemsPerPage,omitempty"`
+	StartIndex      *int         `xml:"opensearch:startIndex,omitempty"`
+	Links           []*atomLink  `xml:"link"`
+	Entries         []*atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel   string `xml:"rel,attr"`
+	Href  string `xml:"href,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Title string `xml:"title,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string          `xml:"title"`
+	ID         string          `xml:"id"`
+	Updated    string          `xml:"updated"`
+	Authors    []*atomPerson   `xml:"author"`
+	Identifier string          `xml:"dc:identifier,omitempty"`
+	Issued     string          `xml:"dc:issued,omitempty"`
+	Categories []*atomCategory `xml:"category"`
+	Content    *atomContent    `xml:"content"`
+	Links      []*atomLink     `xml:"link"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// writeAtomFeed writes the feed as an OPDS 1.2 Atom feed.
+func writeAtomFeed(w http.ResponseWriter, links *opdsLinks, feed *opdsFeed) error {
+	feedType := atomNavigationType
+	if feed.acquisition {
+		feedType = atomAcquisitionType
+	}
+
+	updated := feed.updated().Format(time.RFC3339)
+	out := atomFeed{
+		Xmlns:           "http://www.w3.org/2005/Atom",
+		XmlnsDC:         "http://purl.org/dc/terms/",
+		XmlnsOpenSearch: "http://a9.com/-/spec/opensearch/1.1/",
+		ID:              links.feed(feed.path, feed.query),
+		Title:           feed.title,
+		Updated:         updated,
+		Author:          atomPerson{Name: "Library catalogue"},
+		Links: []*atomLink{
+			{Rel: "start", Href: links.feed("", nil), Type: atomNavigationType},
+			{Rel: "search", Href: links.origin + opdsRoot + "/opensearch.xml", Type: openSearchType},
+		},
+	}
+
+	if feed.page > 0 {
+		startIndex := (feed.page-1)*opdsPageSize + 1
+		itemsPerPage := opdsPageSize
+		out.TotalResults, out.ItemsPerPage, out.StartIndex = &feed.total, &itemsPerPage, &startIndex
+	}
+
+	pages := links.pages(feed)
+	for _, rel := range pageRels {
+		if href, ok := pages[rel]; ok {
+			out.Links = append(out.Links, &atomLink{Rel: rel, Href: href, Type: feedType})
+		}
+	}
+
+	for _, nav := range feed.navigation {
+		entryType := atomNavigationType
+		if nav.acquisition {
+			entryType = atomAcquisitionType
+		}
+		href := links.feed(nav.path, nil)
+		out.Entries = append(out.Entries, &atomEntry{
+			Title:   nav.title,
+			ID:      href,
+			Updated: updated,
+			Content: &atomContent{Type: "text", Text: nav.summary},
+			Links:   []*atomLink{{Rel: nav.rel, Href: href, Type: entryType}},
+		})
+	}
+
+	for _, book := range feed.books {
+		entry := atomEntry{
+			Title:   book.Title,
+			ID:      links.book(book.ID),
+			Updated: book.CreatedAt.UTC().Format(time.RFC3339),
+			Issued:  book.PublishDate.Format(time.DateOnly),
+			Links: []*atomLink{
+				{Rel: "alternate", Href: links.book(book.ID), Type: "application/json"},
+				{Rel: "alternate", Href: links.book(book.ID) + "/marc?format=marcxml", Type: marc.XMLType},
+			},
+		}
+		if book.ISBN != "" {
+			entry.Identifier = "urn:isbn:" + book.ISBN
+		}
+		for _, name := range book.Authors {
+			entry.Authors = append(entry.Authors, &atomPerson{Name: name})
+		}
+		for _, genre := range book.Genres {
+			entry.Categories = append(entry.Categories, &atomCategory{Term: genre, Label: genre})
+		}
+		out.Entries = append(out.Entries, &entry)
+	}
+
+	body, err := xml.Marshal(out)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", feedType)
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(append([]byte(xml.Header), body...))
+	return err
+}
+
+type opds2Feed struct {
+	Metadata     opds2Metadata       `json:"metadata"`
+	Links        []*opds2Link        `json:"links"`
+	Navigation   []*opds2Link        `json:"navigation,omitempty"`
+	Publications []*opds2Publication `json:"publications,omitempty"`
+}
+
+type opds2Metadata struct {
+	Title         string `json:"title"`
+	NumberOfItems *int   `json:"numberOfItems,omitempty"`
+	ItemsPerPage  int    `json:"itemsPerPage,omitempty"`
+	CurrentPage   int    `json:"currentPage,omitempty"`
+}
+
+type opds2Link struct {
+	Rel       string `json:"rel,omitempty"`
+	Href      string `json:"href"`
+	Type      string `json:"type,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Templated bool   `json:"templated,omitempty"`
+}
+
+type opds2Publication struct {
+	Metadata opds2PublicationMetadata `json:"metadata"`
+	Links    []*opds2Link             `json:"links"`
+}
+
+type opds2PublicationMetadata struct {
+	Type          string          `json:"@type"`
+	Identifier    string          `json:"identifier,omitempty"`
+	Title         string          `json:"title"`
+	Author        []*opds2Subject `json:"author,omitempty"`
+	Published     string          `json:"published"`
+	Modified      string          `json:"modified"`
+	Subject       []*opds2Subject `json:"subject,omitempty"`
+	NumberOfPages uint16          `json:"numberOfPages,omitempty"`
+}
+
+type opds2Subject struct {
+	Name string `json:"name"`
+}
+
+// writeOPDS2Feed writes the feed as an OPDS 2.0 feed.
+func writeOPDS2Feed(w http.ResponseWriter, links *opdsLinks, feed *opdsFeed) error {
+	out := opds2Feed{
+		Metadata: opds2Metadata{Title: feed.title},
+		Links: []*opds2Link{
+			{Rel: "start", Href: links.feed("", nil), Type: opds2Type},
+			{Rel: "search", Href: links.feed("/search", nil) + "{?q}", Type: opds2Type, Templated: true},
+		},
+	}
+
+	if feed.page > 0 {
+		out.Metadata.NumberOfItems = &feed.total
+		out.Metadata.ItemsPerPage = opdsPageSize
+		out.Metadata.CurrentPage = feed.page
+	}
+
+	pages := links.pages(feed)
+	for _, rel := range pageRels {
+		if href, ok := pages[rel]; ok {
+			out.Links = append(out.Links, &opds2Link{Rel: rel, Href: href, Type: opds2Type})
+		}
+	}
+
+	for _, nav := range feed.navigation {
+		out.Navigation = append(out.Navigation, &opds2Link{Rel: nav.rel, Href: links.feed(nav.path, nil), Type: opds2Type, Title: nav.title})
+	}
+
+	if feed.acquisition {
+		out.Publications = []*opds2Publication{}
+	}
+	for _, book := range feed.books {
+		publication := opds2Publication{
+			Metadata: opds2PublicationMetadata{
+				Type:          "http://schema.org/Book",
+				Title:         book.Title,
+				Published:     book.PublishDate.Format(time.DateOnly),
+				Modified:      book.CreatedAt.UTC().Format(time.RFC3339),
+				NumberOfPages: book.Pages,
+			},
+			Links: []*opds2Link{
+				{Rel: "self", Href: links.book(book.ID), Type: "application/json"},
+				{Rel: "alternate", Href: links.book(book.ID) + "/marc?format=marcxml", Type: marc.XMLType},
+			},
+		}
+		if book.ISBN != "" {
+			publication.Metadata.Identifier = "urn:isbn:" + book.ISBN
+		}
+		for _, name := range book.Authors {
+			publication.Metadata.Author = append(publication.Metadata.Author, &opds2Subject{Name: name})
+		}
+		for _, genre := range book.Genres {
+			publication.Metadata.Subject = append(publication.Metadata.Subject, &opds2Subject{Name: genre})
+		}
+		out.Publications = append(out.Publications, &publication)
+	}
+
+	body, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", opds2Type)
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(append(body, '\n'))
+	return err
+}
+
+type openSearchDescription struct {
+	XMLName       xml.Name         `xml:"OpenSearchDescription"`
+	Xmlns         string           `xml:"xmlns,attr"`
+	ShortName     string           `xml:"ShortName"`
+	Description   string           `xml:"Description"`
+	InputEncoding string           `xml:"InputEncoding"`
+	URLs          []*openSearchURL `xml:"Url"`
+}
+
+type openSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Template string `xml:"template,attr"`
+}
+
+// openSearchTemplate returns the URL template of the search at the path with {searchTerms} in
+// place of q.
+func openSearchTemplate(origin string, path string, paged bool) string {
+	template := origin + path + "?q={searchTerms}"
+	if paged {
+		template += "&page={startPage?}"
+	}
+
+	return template
+}
@@ -28,7 +28,7 @@ func NewGenreHandler(service service.Genre) *GenreHandler {
 // @Tags genres
 // @ID create-genre
 // @Accept  json
-// @Produce  json
+// @Produce  json,xml,application/msgpack
 // @Param genre body types.Genre true "Genre object that needs to be added"
 // @Security Bearer
 // @Success 201 {object} types.Genre
@@ -50,15 +50,19 @@ func (h *GenreHandler) CreateGenre(w http.ResponseWriter, r *http.Request, _ htt
 
 	newGenre, err := h.service.CreateGenre(r.Context(), &genre)
 	if err != nil {
-		if errors.Is(err, service.ErrEntityExists) {
+		var validationErr *service.ValidationError
+		switch {
+		case errors.Is(err, service.ErrEntityExists):
 			badRequestResponse(w, r, fmt.Errorf("genre '%s' already exists", genre.Name))
-			return
+		case errors.As(err, &validationErr):
+			notValidResponse(w, r, validationErr.Errors)
+		default:
+			serverErrorResponse(w, r, err)
 		}
-		serverErrorResponse(w, r, err)
 		return
 	}
 
-	err = writeJSON(w, http.StatusCreated, envelope{"genre": newGenre}, nil)
+	err = writeResponse(w, r, http.StatusCreated, envelope{"genre": newGenre}, nil)
 	if err != nil {
 		log.Error(err.Error())
 	}
@@ -70,14 +74,17 @@ func (h *GenreHandler) CreateGenre(w http.ResponseWriter, r *http.Request, _ htt
 // @Tags genres
 // @ID get-genre-by-id
 // @Accept  json
-// @Produce  json
+// @Produce  json,xml,application/msgpack
+// @Param Accept-Language header string false "Preferred languages of the translated names and titles"
 // @Param id path int true "Genre ID"
 // @Success 200 {object} types.Genre
+// @Header 200 {string} ETag "Version of the genre followed by a hash of the response"
 // @Router /api/v1/genres/{id} [get]
 func (h *GenreHandler) GetGenreByID(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	id, err := getIdParam(ps)
 	if err != nil {
 		badRequestResponse(w, r, err)
+		return
 	}
 
 	genre, err := h.service.GetGenreByID(r.Context(), id)
@@ -90,7 +97,7 @@ func (h *GenreHandler) GetGenreByID(w http.ResponseWriter, r *http.Request, ps h
 		return
 	}
 
-	err = writeJSON(w, http.StatusOK, envelope{"genre": genre}, nil)
+	err = writeResponse(w, r, http.StatusOK, envelope{"genre": genre}, etagHeader(genre.Version))
 	if err != nil {
 		log.Error(err.Error())
 	}
@@ -102,7 +109,8 @@ func (h *GenreHandler) GetGenreByID(w http.ResponseWriter, r *http.Request, ps h
 // @Tags genres
 // @ID get-all-genres
 // @Accept  json
-// @Produce  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param Accept-Language header string false "Preferred languages of the translated names and titles"
 // @Success 200 {array} []types.Genre
 // @Router /api/v1/genres [get]
 func (h *GenreHandler) GetAllGenres(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -116,7 +124,30 @@ func (h *GenreHandler) GetAllGenres(w http.ResponseWriter, r *http.Request, _ ht
 		return
 	}
 
-	err = writeJSON(w, http.StatusOK, envelope{"genres": genres}, nil)
+	err = writeResponse(w, r, http.StatusOK, envelope{"genres": genres}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// GetGenreTree godoc
+// @Summary Get the genre hierarchy
+// @Description Get top level genres with their subgenres nested under them
+// @Tags genres
+// @ID get-genre-tree
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param Accept-Language header string false "Preferred languages of the translated names and titles"
+// @Success 200 {array} []types.GenreNode
+// @Router /api/v1/genres/tree [get]
+func (h *GenreHandler) GetGenreTree(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	tree, err := h.service.GetGenreTree(r.Context())
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"genres": tree}, nil)
 	if err != nil {
 		log.Error(err.Error())
 	}
@@ -124,20 +155,27 @@ func (h *GenreHandler) GetAllGenres(w http.ResponseWriter, r *http.Request, _ ht
 
 // UpdateGenre godoc
 // @Summary Update a genre
-// @Description Update a genre with a specific ID
+// @Description Update a genre with a specific ID, parent_id 0 moves it to the top level and an omitted parent_id keeps its parent
 // @Tags genres
 // @ID update-genre
 // @Accept  json
-// @Produce  json
+// @Produce  json,xml,application/msgpack
 // @Param id path int true "Genre ID"
+// @Param If-Match header string true "ETag of the genre version the change is based on"
 // @Param genre body types.Genre true "Genre object that needs to be updated"
 // @Security Bearer
 // @Success 200 {object} types.Genre
-// @Router /api/v1/genres/{id} [put]
+// @Router /api/v1/genres/{id} [patch]
 func (h *GenreHandler) UpdateGenre(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	id, err := getIdParam(ps)
 	if err != nil {
 		badRequestResponse(w, r, err)
+		return
+	}
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
 	}
 
 	var genre types.Genre
@@ -154,18 +192,102 @@ func (h *GenreHandler) UpdateGenre(w http.ResponseWriter, r *http.Request, ps ht
 		return
 	}
 
-	err = h.service.UpdateGenre(r.Context(), id, &genre)
+	err = h.service.UpdateGenre(r.Context(), id, version, &genre)
 	if err != nil {
-		if errors.Is(err, service.ErrNotFound) {
+		var validationErr *service.ValidationError
+		switch {
+		case errors.Is(err, service.ErrNotFound):
 			notFoundResponse(w, r)
-			return
+		case errors.Is(err, service.ErrVersionMismatch):
+			preconditionFailedResponse(w, r)
+		case errors.Is(err, service.ErrEntityExists):
+			conflictResponse(w, r, fmt.Sprintf("genre '%s' already exists", genre.Name))
+		case errors.Is(err, service.ErrGenreCycle):
+			notValidResponse(w, r, map[string]string{"parent_id": "can't be the genre itself or one of its subgenres"})
+		case errors.As(err, &validationErr):
+			notValidResponse(w, r, validationErr.Errors)
+		default:
+			serverErrorResponse(w, r, err)
 		}
-		serverErrorResponse(w, r, err)
 		return
 	}
 
 	genre.ID = id
-	err = writeJSON(w, http.StatusOK, envelope{"genre": genre}, nil)
+	err = writeResponse(w, r, http.StatusOK, envelope{"genre": genre}, etagHeader(genre.Version))
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// ReplaceGenre godoc
+// @Summary Replace a genre
+// @Description Replace a genre with a specific ID or create the genre under that ID when there is none yet. A genre without parent_id becomes a top level one
+// @Tags genres
+// @ID replace-genre
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Genre ID"
+// @Param If-Match header string false "ETag of the genre version the replacement is based on"
+// @Param genre body types.Genre true "Genre object that replaces the stored one"
+// @Security Bearer
+// @Success 200 {object} types.Genre
+// @Success 201 {object} types.Genre
+// @Router /api/v1/genres/{id} [put]
+func (h *GenreHandler) ReplaceGenre(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	version, ok := getIfMatch(r)
+	if !ok {
+		preconditionFailedResponse(w, r)
+		return
+	}
+
+	var genre types.Genre
+	err = json.NewDecoder(r.Body).Decode(&genre)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("can't decode request"))
+		return
+	}
+
+	v := validator.New()
+	types.ValidateGenre(v, &genre)
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return
+	}
+
+	created, err := h.service.ReplaceGenre(r.Context(), id, version, &genre)
+	if err != nil {
+		var validationErr *service.ValidationError
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrVersionMismatch):
+			preconditionFailedResponse(w, r)
+		case errors.Is(err, service.ErrIDUnavailable):
+			conflictResponse(w, r, fmt.Sprintf("genre %d is in the trash or was merged into another genre", id))
+		case errors.Is(err, service.ErrEntityExists):
+			conflictResponse(w, r, fmt.Sprintf("genre '%s' already exists", genre.Name))
+		case errors.Is(err, service.ErrGenreCycle):
+			notValidResponse(w, r, map[string]string{"parent_id": "can't be the genre itself or one of its subgenres"})
+		case errors.As(err, &validationErr):
+			notValidResponse(w, r, validationErr.Errors)
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+
+	err = writeResponse(w, r, status, envelope{"genre": genre}, etagHeader(genre.Version))
 	if err != nil {
 		log.Error(err.Error())
 	}
@@ -173,12 +295,13 @@ func (h *GenreHandler) UpdateGenre(w http.ResponseWriter, r *http.Request, ps ht
 
 // DeleteGenre godoc
 // @Summary Delete a genre
-// @Description Delete a genre with a specific ID
+// @Description Move a genre with a specific ID to the trash, it can be restored until the trash is purged. Depending on the delete policy a genre listed on books is either dropped from them or kept with 409
 // @Tags genres
 // @ID delete-genre
 // @Accept  json
-// @Produce  json
+// @Produce  json,xml,application/msgpack
 // @Param id path int true "Genre ID"
+// @Param If-Match header string true "ETag of the genre version the deletion is based on"
 // @Security Bearer
 // @Success 204 "No Content"
 // @Router /api/v1/genres/{id} [delete]
@@ -189,7 +312,236 @@ func (h *GenreHandler) DeleteGenre(w http.ResponseWriter, r *http.Request, ps ht
 		return
 	}
 
-	err = h.service.DeleteGenre(r.Context(), id)
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	err = h.service.DeleteGenre(r.Context(), id, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrVersionMismatch):
+			preconditionFailedResponse(w, r)
+		case errors.Is(err, service.ErrEntityInUse):
+			conflictResponse(w, r, "genre is still listed on books, remove it from them first")
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BatchGenres godoc
+// @Summary Create, update and delete genres in a batch
+// @Description Apply up to 500 operations. An atomic batch applies all of them or none and is answered with the status of the failing operation, a best effort batch applies every operation that succeeds. Every operation gets a result with the status its own request would get, 424 for operations of a failed atomic batch
+// @Tags genres
+// @ID batch-genres
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param batch body types.GenreBatch true "Mode and operations of the batch"
+// @Param Idempotency-Key header string false "Key that makes retries of the request replay its response"
+// @Security Bearer
+// @Success 200 {object} object{results=[]types.BatchResult}
+// @Router /api/v1/genres:batch [post]
+func (h *GenreHandler) BatchGenres(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var batch types.GenreBatch
+	err := json.NewDecoder(r.Body).Decode(&batch)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("can't decode request"))
+		return
+	}
+
+	v := validator.New()
+	types.ValidateBatch(v, batch.Mode, len(batch.Operations))
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return
+	}
+
+	errs, err := h.service.BatchGenres(r.Context(), &batch)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	results := make([]*types.BatchResult, len(errs))
+	for i, err := range errs {
+		if err != nil {
+			results[i] = batchFailure(r, i, err)
+			continue
+		}
+
+		op := batch.Operations[i]
+		results[i] = batchResult(i, op.Op, op.ID, op.Version)
+	}
+
+	writeBatchResults(w, r, batch.Mode, results)
+}
+
+// MergeGenres godoc
+// @Summary Merge a duplicate genre
+// @Description Move books of the source genre to the genre with a specific ID and delete the source, the source ID keeps resolving to the target
+// @Tags genres
+// @ID merge-genres
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Target Genre ID"
+// @Param merge body types.Merge true "Source genre that is merged into the target"
+// @Security Bearer
+// @Success 200 {object} types.Genre
+// @Router /api/v1/genres/{id}/merge [post]
+func (h *GenreHandler) MergeGenres(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	var merge types.Merge
+	err = json.NewDecoder(r.Body).Decode(&merge)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("can't decode request"))
+		return
+	}
+
+	v := validator.New()
+	types.ValidateMerge(v, &merge, id)
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return
+	}
+
+	genre, err := h.service.MergeGenres(r.Context(), id, merge.SourceID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrEntityExists):
+			conflictResponse(w, r, "the source was merged by someone else, fetch it again and retry")
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"genre": genre}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// GetGenreTranslations godoc
+// @Summary Get translations of a genre
+// @Description Get all translations of a genre name
+// @Tags genres
+// @ID get-genre-translations
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param id path int true "Genre ID"
+// @Success 200 {array} []types.Translation
+// @Router /api/v1/genres/{id}/translations [get]
+func (h *GenreHandler) GetGenreTranslations(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	translations, err := h.service.GetGenreTranslations(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"translations": translations}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// SetGenreTranslation godoc
+// @Summary Set a translation of a genre
+// @Description Create or replace the name of a genre in the language given by a BCP 47 tag
+// @Tags genres
+// @ID set-genre-translation
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Genre ID"
+// @Param language path string true "BCP 47 language tag"
+// @Param translation body types.Translation true "Translated name, the language is taken from the path"
+// @Security Bearer
+// @Success 200 {object} types.Translation
+// @Router /api/v1/genres/{id}/translations/{language} [put]
+func (h *GenreHandler) SetGenreTranslation(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	translation, err := decodeTranslation(r, ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	types.ValidateGenreTranslation(v, translation)
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return
+	}
+
+	err = h.service.SetGenreTranslation(r.Context(), id, translation)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"translation": translation}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// DeleteGenreTranslation godoc
+// @Summary Delete a translation of a genre
+// @Description Delete the translation of a genre in the language given by a BCP 47 tag
+// @Tags genres
+// @ID delete-genre-translation
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Genre ID"
+// @Param language path string true "BCP 47 language tag"
+// @Security Bearer
+// @Success 204 "No Content"
+// @Router /api/v1/genres/{id}/translations/{language} [delete]
+func (h *GenreHandler) DeleteGenreTranslation(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	language, err := types.ParseLanguageTag(ps.ByName("language"))
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	err = h.service.DeleteGenreTranslation(r.Context(), id, language)
 	if err != nil {
 		if errors.Is(err, service.ErrNotFound) {
 			notFoundResponse(w, r)
@@ -201,3 +553,138 @@ func (h *GenreHandler) DeleteGenre(w http.ResponseWriter, r *http.Request, ps ht
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// GetGenreRevisions godoc
+// @Summary Get revisions of a genre
+// @Description Get snapshots of a genre taken after every change, the latest first
+// @Tags genres
+// @ID get-genre-revisions
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param id path int true "Genre ID"
+// @Security Bearer
+// @Success 200 {array} []types.Revision
+// @Router /api/v1/genres/{id}/revisions [get]
+func (h *GenreHandler) GetGenreRevisions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	revisions, err := h.service.GetGenreRevisions(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"revisions": revisions}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// DiffGenreRevisions godoc
+// @Summary Compare two revisions of a genre
+// @Description Get the fields that differ between two revisions of a genre
+// @Tags genres
+// @ID diff-genre-revisions
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Genre ID"
+// @Param from query int true "Revision ID to compare from"
+// @Param to query int true "Revision ID to compare to"
+// @Security Bearer
+// @Success 200 {object} types.RevisionDiff
+// @Router /api/v1/genres/{id}/revisions/diff [get]
+func (h *GenreHandler) DiffGenreRevisions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	from, to, err := getRevisionRange(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	diff, err := h.service.DiffGenreRevisions(r.Context(), id, from, to)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"diff": diff}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// RevertGenre godoc
+// @Summary Revert a genre to a revision
+// @Description Bring the name and the parent of a genre back to a revision, the result is recorded as a new revision
+// @Tags genres
+// @ID revert-genre
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Genre ID"
+// @Param revision path int true "Revision ID"
+// @Param If-Match header string false "ETag of the genre version the revert is based on"
+// @Security Bearer
+// @Success 200 {object} types.Genre
+// @Router /api/v1/genres/{id}/revisions/{revision}/revert [post]
+func (h *GenreHandler) RevertGenre(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	revisionID, err := getRevisionParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	version, ok := getIfMatch(r)
+	if !ok {
+		preconditionFailedResponse(w, r)
+		return
+	}
+
+	genre, err := h.service.RevertGenre(r.Context(), id, version, revisionID)
+	if err != nil {
+		var validationErr *service.ValidationError
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrVersionMismatch):
+			preconditionFailedResponse(w, r)
+		case errors.Is(err, service.ErrEntityExists):
+			conflictResponse(w, r, "another genre already has the name of the revision")
+		case errors.Is(err, service.ErrGenreCycle):
+			notValidResponse(w, r, map[string]string{"parent_id": "can't be the genre itself or one of its subgenres"})
+		case errors.As(err, &validationErr):
+			notValidResponse(w, r, validationErr.Errors)
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"genre": genre}, etagHeader(genre.Version))
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
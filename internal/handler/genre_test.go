@@ -2,16 +2,19 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/julienschmidt/httprouter"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"github.com/tredoc/go-crud-api/internal/service"
 	mockservice "github.com/tredoc/go-crud-api/mocks/service"
 	"github.com/tredoc/go-crud-api/pkg/types"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 )
 
@@ -29,9 +32,13 @@ func (s *genreHandlerSuite) SetupSuite() {
 	router := httprouter.New()
 	router.POST("/api/v1/genres", handler.CreateGenre)
 	router.GET("/api/v1/genres", handler.GetAllGenres)
-	router.GET("/api/v1/genres/:id", handler.GetGenreByID)
+	router.GET("/api/v1/genres/:id", staticSegment("id", "tree", handler.GetGenreTree, handler.GetGenreByID))
 	router.PATCH("/api/v1/genres/:id", handler.UpdateGenre)
+	router.PUT("/api/v1/genres/:id", handler.ReplaceGenre)
 	router.DELETE("/api/v1/genres/:id", handler.DeleteGenre)
+	router.POST("/api/v1/genres/:id/merge", handler.MergeGenres)
+	router.POST("/api/v1/genres/:id/revisions/:revision/revert", handler.RevertGenre)
+	router.GET("/api/v1/localized/genres/:id", new(Middleware).localeMW(handler.GetGenreByID))
 
 	testingServer := httptest.NewServer(router)
 
@@ -73,6 +80,34 @@ func (s *genreHandlerSuite) TestCreateGenre_Positive() {
 	s.Equal(string(result), string(expected))
 }
 
+func (s *genreHandlerSuite) TestCreateGenre_UnicodeName() {
+	genre := types.Genre{
+		Name: "Science Fiction",
+	}
+
+	newGenre := types.Genre{ID: 2, Name: "science fiction"}
+
+	s.usecase.On("CreateGenre", mock.AnythingOfType("*context.cancelCtx"), &genre).Return(&newGenre, nil)
+
+	requestBody, err := json.Marshal(&genre)
+	s.NoError(err, "can`t marshal struct to json")
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/genres", s.testingServer.URL), "application/json", bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"genre": newGenre,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusCreated, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
 func (s *genreHandlerSuite) TestGetGenreByID_Positive() {
 	id := int64(1)
 	genre := types.Genre{ID: id, Name: "any"}
@@ -95,6 +130,33 @@ func (s *genreHandlerSuite) TestGetGenreByID_Positive() {
 	s.Equal(string(result), string(expected))
 }
 
+func (s *genreHandlerSuite) TestGetGenreByID_ETag() {
+	id := int64(2)
+	genre := types.Genre{ID: id, Name: "programming", Version: 7}
+
+	s.usecase.On("GetGenreByID", mock.AnythingOfType("*context.cancelCtx"), genre.ID).Return(&genre, nil)
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/genres/%d", s.testingServer.URL, id))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(`"7"`, response.Header.Get("ETag"))
+}
+
+func (s *genreHandlerSuite) TestGetGenreByID_InvalidID() {
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/genres/abc", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	s.Equal(http.StatusBadRequest, response.StatusCode)
+	s.JSONEq(`{"error":"invalid id parameter"}`, string(result))
+	s.usecase.AssertNotCalled(s.T(), "GetGenreByID", mock.Anything, int64(0))
+}
+
 func (s *genreHandlerSuite) TestGetAllGenres_Positive() {
 	genres := []*types.Genre{{ID: 1, Name: "medicine"}, {ID: 2, Name: "programming"}}
 
@@ -120,7 +182,7 @@ func (s *genreHandlerSuite) TestUpdateGenre_Positive() {
 	id := int64(1)
 	genre := types.Genre{ID: id, Name: "updated"}
 
-	s.usecase.On("UpdateGenre", mock.AnythingOfType("*context.cancelCtx"), id, &genre).Return(nil)
+	s.usecase.On("UpdateGenre", mock.AnythingOfType("*context.cancelCtx"), id, int64(0), &genre).Return(nil)
 
 	requestBody, err := json.Marshal(&genre)
 	s.NoError(err, "can`t marshal struct to json")
@@ -129,6 +191,7 @@ func (s *genreHandlerSuite) TestUpdateGenre_Positive() {
 	s.NoError(err, "no error when preparing patch request")
 
 	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("If-Match", "*")
 
 	client := &http.Client{}
 	response, err := client.Do(request)
@@ -147,13 +210,122 @@ func (s *genreHandlerSuite) TestUpdateGenre_Positive() {
 	s.Equal(string(result), string(expected))
 }
 
+func (s *genreHandlerSuite) TestGetGenreTree_Positive() {
+	tree := []*types.GenreNode{
+		{ID: 1, Name: "fiction", Children: []*types.GenreNode{
+			{ID: 2, Name: "fantasy", Children: []*types.GenreNode{
+				{ID: 3, Name: "epic fantasy", Children: []*types.GenreNode{}},
+			}},
+		}},
+	}
+
+	s.usecase.On("GetGenreTree", mock.AnythingOfType("*context.cancelCtx")).Return(tree, nil)
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/genres/tree", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"genres": tree,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *genreHandlerSuite) TestUpdateGenre_Cycle() {
+	id, parentID := int64(2), int64(3)
+	genre := types.Genre{Name: "fantasy", ParentID: &parentID}
+
+	s.usecase.On("UpdateGenre", mock.AnythingOfType("*context.cancelCtx"), id, int64(0), &genre).Return(service.ErrGenreCycle)
+
+	requestBody, err := json.Marshal(&genre)
+	s.NoError(err, "can`t marshal struct to json")
+
+	request, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("%s/api/v1/genres/%d", s.testingServer.URL, id), bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when preparing patch request")
+
+	request.Header.Set("If-Match", "*")
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusUnprocessableEntity, response.StatusCode)
+}
+
+func (s *genreHandlerSuite) TestReplaceGenre_Created() {
+	id := int64(40)
+	genre := types.Genre{Name: "solarpunk"}
+
+	created := func(args mock.Arguments) {
+		replaced := args.Get(3).(*types.Genre)
+		replaced.ID = id
+		replaced.Version = 1
+	}
+	s.usecase.On("ReplaceGenre", mock.AnythingOfType("*context.cancelCtx"), id, int64(0), &genre).Run(created).Return(true, nil)
+
+	requestBody, err := json.Marshal(&genre)
+	s.NoError(err, "can`t marshal struct to json")
+
+	request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/api/v1/genres/%d", s.testingServer.URL, id), bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when preparing put request")
+
+	request.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"genre": types.Genre{ID: id, Name: "solarpunk", Version: 1},
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusCreated, response.StatusCode)
+	s.Equal(`"1"`, response.Header.Get("ETag"))
+	s.Equal(string(expected), string(result))
+}
+
+func (s *genreHandlerSuite) TestReplaceGenre_NameTaken() {
+	id := int64(41)
+	genre := types.Genre{Name: "cyberpunk"}
+	s.usecase.On("ReplaceGenre", mock.AnythingOfType("*context.cancelCtx"), id, int64(0), &genre).Return(false, service.ErrEntityExists).Once()
+
+	requestBody, err := json.Marshal(&genre)
+	s.NoError(err, "can`t marshal struct to json")
+
+	request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/api/v1/genres/%d", s.testingServer.URL, id), bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when preparing put request")
+
+	request.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusConflict, response.StatusCode)
+}
+
 func (s *genreHandlerSuite) TestDeleteGenre_Positive() {
 	id := int64(1)
-	s.usecase.On("DeleteGenre", mock.AnythingOfType("*context.cancelCtx"), id).Return(nil)
+	s.usecase.On("DeleteGenre", mock.AnythingOfType("*context.cancelCtx"), id, int64(0)).Return(nil)
 
 	request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/v1/genres/%d", s.testingServer.URL, id), nil)
 	s.NoError(err, "no error when preparing patch request")
 
+	request.Header.Set("If-Match", "*")
+
 	client := &http.Client{}
 	response, err := client.Do(request)
 	s.NoError(err, "no error when calling the endpoint")
@@ -161,6 +333,73 @@ func (s *genreHandlerSuite) TestDeleteGenre_Positive() {
 	s.Equal(http.StatusNoContent, response.StatusCode)
 }
 
+func (s *genreHandlerSuite) TestMergeGenres_Positive() {
+	targetID, sourceID := int64(5), int64(6)
+	genre := &types.Genre{ID: targetID, Name: "science fiction"}
+
+	s.usecase.On("MergeGenres", mock.AnythingOfType("*context.cancelCtx"), targetID, sourceID).Return(genre, nil)
+
+	requestBody, err := json.Marshal(&types.Merge{SourceID: sourceID})
+	s.NoError(err, "can`t marshal struct to json")
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/genres/%d/merge", s.testingServer.URL, targetID), "application/json", bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"genre": genre,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *genreHandlerSuite) TestGetGenreByID_AcceptLanguage() {
+	id := int64(8)
+	genre := &types.Genre{ID: id, Name: "fantasia"}
+	chain := []string{"pt-BR", "pt", "en"}
+
+	s.usecase.On("GetGenreByID", mock.MatchedBy(func(ctx context.Context) bool {
+		return reflect.DeepEqual(types.LanguagesFromContext(ctx), chain)
+	}), id).Return(genre, nil)
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v1/localized/genres/%d", s.testingServer.URL, id), nil)
+	s.NoError(err, "no error when preparing get request")
+	request.Header.Set("Accept-Language", "pt-BR, en;q=0.5")
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"genre": genre,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal("Accept-Language", response.Header.Get("Vary"))
+	s.Equal(string(result), string(expected))
+}
+
+func (s *genreHandlerSuite) TestRevertGenre_Cycle() {
+	id := int64(4)
+	s.usecase.On("RevertGenre", mock.AnythingOfType("*context.cancelCtx"), id, int64(0), int64(9)).Return(nil, service.ErrGenreCycle)
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/genres/%d/revisions/9/revert", s.testingServer.URL, id), "application/json", nil)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusUnprocessableEntity, response.StatusCode)
+}
+
 func TestGenreHandler(t *testing.T) {
 	suite.Run(t, new(genreHandlerSuite))
 }
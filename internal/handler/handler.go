@@ -12,9 +12,21 @@ import (
 type Book interface {
 	CreateBook(http.ResponseWriter, *http.Request, httprouter.Params)
 	GetBookByID(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetBookMARC(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetBookCitation(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetCitations(http.ResponseWriter, *http.Request, httprouter.Params)
 	GetAllBooks(http.ResponseWriter, *http.Request, httprouter.Params)
+	ExportBooks(http.ResponseWriter, *http.Request, httprouter.Params)
 	UpdateBook(http.ResponseWriter, *http.Request, httprouter.Params)
+	ReplaceBook(http.ResponseWriter, *http.Request, httprouter.Params)
 	DeleteBook(http.ResponseWriter, *http.Request, httprouter.Params)
+	BatchBooks(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetBookRevisions(http.ResponseWriter, *http.Request, httprouter.Params)
+	DiffBookRevisions(http.ResponseWriter, *http.Request, httprouter.Params)
+	RevertBook(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetBookTranslations(http.ResponseWriter, *http.Request, httprouter.Params)
+	SetBookTranslation(http.ResponseWriter, *http.Request, httprouter.Params)
+	DeleteBookTranslation(http.ResponseWriter, *http.Request, httprouter.Params)
 }
 
 type Genre interface {
@@ -22,7 +34,17 @@ type Genre interface {
 	GetGenreByID(http.ResponseWriter, *http.Request, httprouter.Params)
 	GetAllGenres(http.ResponseWriter, *http.Request, httprouter.Params)
 	UpdateGenre(http.ResponseWriter, *http.Request, httprouter.Params)
+	ReplaceGenre(http.ResponseWriter, *http.Request, httprouter.Params)
 	DeleteGenre(http.ResponseWriter, *http.Request, httprouter.Params)
+	BatchGenres(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetGenreRevisions(http.ResponseWriter, *http.Request, httprouter.Params)
+	DiffGenreRevisions(http.ResponseWriter, *http.Request, httprouter.Params)
+	RevertGenre(http.ResponseWriter, *http.Request, httprouter.Params)
+	MergeGenres(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetGenreTree(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetGenreTranslations(http.ResponseWriter, *http.Request, httprouter.Params)
+	SetGenreTranslation(http.ResponseWriter, *http.Request, httprouter.Params)
+	DeleteGenreTranslation(http.ResponseWriter, *http.Request, httprouter.Params)
 }
 
 type Author interface {
@@ -30,7 +52,14 @@ type Author interface {
 	GetAuthorByID(http.ResponseWriter, *http.Request, httprouter.Params)
 	GetAllAuthors(http.ResponseWriter, *http.Request, httprouter.Params)
 	UpdateAuthor(http.ResponseWriter, *http.Request, httprouter.Params)
+	ReplaceAuthor(http.ResponseWriter, *http.Request, httprouter.Params)
 	DeleteAuthor(http.ResponseWriter, *http.Request, httprouter.Params)
+	BatchAuthors(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetAuthorRevisions(http.ResponseWriter, *http.Request, httprouter.Params)
+	DiffAuthorRevisions(http.ResponseWriter, *http.Request, httprouter.Params)
+	RevertAuthor(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetAuthorBooks(http.ResponseWriter, *http.Request, httprouter.Params)
+	MergeAuthors(http.ResponseWriter, *http.Request, httprouter.Params)
 }
 
 type User interface {
@@ -38,9 +67,99 @@ type User interface {
 	LoginUser(http.ResponseWriter, *http.Request, httprouter.Params)
 }
 
+type Review interface {
+	CreateReview(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetBookReviews(http.ResponseWriter, *http.Request, httprouter.Params)
+	UpdateReview(http.ResponseWriter, *http.Request, httprouter.Params)
+	DeleteReview(http.ResponseWriter, *http.Request, httprouter.Params)
+}
+
+type Shelf interface {
+	GetShelves(http.ResponseWriter, *http.Request, httprouter.Params)
+	CreateShelf(http.ResponseWriter, *http.Request, httprouter.Params)
+	DeleteShelf(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetShelfBooks(http.ResponseWriter, *http.Request, httprouter.Params)
+	AddBookToShelf(http.ResponseWriter, *http.Request, httprouter.Params)
+	RemoveBookFromShelf(http.ResponseWriter, *http.Request, httprouter.Params)
+	UpdateReading(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetReadingStats(http.ResponseWriter, *http.Request, httprouter.Params)
+}
+
+type Copy interface {
+	CreateCopy(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetBookCopies(http.ResponseWriter, *http.Request, httprouter.Params)
+	UpdateCopy(http.ResponseWriter, *http.Request, httprouter.Params)
+	DeleteCopy(http.ResponseWriter, *http.Request, httprouter.Params)
+}
+
+type Loan interface {
+	Checkout(http.ResponseWriter, *http.Request, httprouter.Params)
+	ReturnLoan(http.ResponseWriter, *http.Request, httprouter.Params)
+	RenewLoan(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetMyLoans(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetLoans(http.ResponseWriter, *http.Request, httprouter.Params)
+}
+
+type Hold interface {
+	PlaceHold(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetBookHolds(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetMyHolds(http.ResponseWriter, *http.Request, httprouter.Params)
+	CancelHold(http.ResponseWriter, *http.Request, httprouter.Params)
+}
+
+type Tag interface {
+	AddBookTags(http.ResponseWriter, *http.Request, httprouter.Params)
+	RemoveBookTags(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetTagCloud(http.ResponseWriter, *http.Request, httprouter.Params)
+}
+
+type Trash interface {
+	GetTrash(http.ResponseWriter, *http.Request, httprouter.Params)
+	RestoreBook(http.ResponseWriter, *http.Request, httprouter.Params)
+	RestoreAuthor(http.ResponseWriter, *http.Request, httprouter.Params)
+	RestoreGenre(http.ResponseWriter, *http.Request, httprouter.Params)
+}
+
+type Import interface {
+	ImportBooks(http.ResponseWriter, *http.Request, httprouter.Params)
+	ImportMARC(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetImportJob(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetImportErrors(http.ResponseWriter, *http.Request, httprouter.Params)
+}
+
+type OPDS interface {
+	GetOPDSRoot(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetOPDSNewest(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetOPDSGenres(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetOPDSGenreBooks(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetOPDSAuthors(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetOPDSAuthorBooks(http.ResponseWriter, *http.Request, httprouter.Params)
+	SearchOPDS(http.ResponseWriter, *http.Request, httprouter.Params)
+	GetOpenSearchDescription(http.ResponseWriter, *http.Request, httprouter.Params)
+}
+
 type Middlewares interface {
 	authMW(httprouter.Handle) httprouter.Handle
 	adminOnlyMW(httprouter.Handle) httprouter.Handle
+	authenticatedOnlyMW(httprouter.Handle) httprouter.Handle
+	localeMW(httprouter.Handle) httprouter.Handle
+	conditionalMW(string, httprouter.Handle) httprouter.Handle
+	idempotencyMW(httprouter.Handle) httprouter.Handle
+}
+
+// CachePolicies hold the Cache-Control values of read routes keyed by the route path, e.g.
+// /api/v1/books/:id. Routes without a value of their own get the default one.
+type CachePolicies struct {
+	Default string
+	Routes  map[string]string
+}
+
+func (p CachePolicies) For(path string) string {
+	if policy, ok := p.Routes[path]; ok {
+		return policy
+	}
+
+	return p.Default
 }
 
 type Handler struct {
@@ -48,23 +167,47 @@ type Handler struct {
 	genre  Genre
 	author Author
 	user   User
+	review Review
+	shelf  Shelf
+	copy   Copy
+	loan   Loan
+	hold   Hold
+	tag    Tag
+	trash  Trash
+	imp    Import
+	opds   OPDS
 	mw     Middlewares
+	cache  CachePolicies
 }
 
-func NewHandler(services *service.Service) *Handler {
+func NewHandler(services *service.Service, cachePolicies CachePolicies) *Handler {
 	return &Handler{
 		book:   NewBookHandler(services.Book),
 		genre:  NewGenreHandler(services.Genre),
 		author: NewAuthorHandler(services.Author),
 		user:   NewUserHandler(services.User),
-		mw:     NewMiddleware(services.User),
+		review: NewReviewHandler(services.Review),
+		shelf:  NewShelfHandler(services.Shelf),
+		copy:   NewCopyHandler(services.Copy),
+		loan:   NewLoanHandler(services.Loan),
+		hold:   NewHoldHandler(services.Hold),
+		tag:    NewTagHandler(services.Tag),
+		trash:  NewTrashHandler(services.Trash),
+		imp:    NewImportHandler(services.Import),
+		opds:   NewOPDSHandler(services.Book, services.Genre, services.Author),
+		mw:     NewMiddleware(services.User, services.Idempotency),
+		cache:  cachePolicies,
 	}
 }
 
 func (h *Handler) InitRoutes() *httprouter.Router {
 	router := httprouter.New()
 
-	router.NotFound = http.HandlerFunc(notFoundResponse)
+	router.NotFound = customMethods(map[string]httprouter.Handle{
+		"/api/v1/books:batch":   h.mw.authMW(h.mw.idempotencyMW(h.mw.adminOnlyMW(h.book.BatchBooks))),
+		"/api/v1/genres:batch":  h.mw.authMW(h.mw.idempotencyMW(h.mw.adminOnlyMW(h.genre.BatchGenres))),
+		"/api/v1/authors:batch": h.mw.authMW(h.mw.idempotencyMW(h.mw.adminOnlyMW(h.author.BatchAuthors))),
+	}, notFoundResponse)
 	router.MethodNotAllowed = http.HandlerFunc(notAllowedResponse)
 
 	if os.Getenv("ENV") == "dev" {
@@ -73,26 +216,121 @@ func (h *Handler) InitRoutes() *httprouter.Router {
 		})
 	}
 
-	router.POST("/api/v1/books", h.mw.authMW(h.mw.adminOnlyMW(h.book.CreateBook)))
-	router.GET("/api/v1/books", h.mw.authMW(h.book.GetAllBooks))
-	router.GET("/api/v1/books/:id", h.mw.authMW(h.book.GetBookByID))
+	h.post(router, "/api/v1/books", h.mw.adminOnlyMW(h.book.CreateBook))
+	h.get(router, "/api/v1/books", h.mw.authMW(h.mw.localeMW(h.book.GetAllBooks)))
+	h.get(router, "/api/v1/books/:id", h.mw.authMW(h.mw.localeMW(h.book.GetBookByID)))
+	h.get(router, "/api/v1/books/:id/marc", h.mw.authMW(h.book.GetBookMARC))
+	h.get(router, "/api/v1/books/:id/citation", h.mw.authMW(h.book.GetBookCitation))
+	h.get(router, "/api/v1/citations", h.mw.authMW(h.book.GetCitations))
+	// The export streams its response, the conditional middleware of read routes would buffer it.
+	router.GET("/api/v1/export/books", h.mw.authMW(h.book.ExportBooks))
 	router.PATCH("/api/v1/books/:id", h.mw.authMW(h.mw.adminOnlyMW(h.book.UpdateBook)))
+	router.PUT("/api/v1/books/:id", h.mw.authMW(h.mw.adminOnlyMW(h.book.ReplaceBook)))
 	router.DELETE("/api/v1/books/:id", h.mw.authMW(h.mw.adminOnlyMW(h.book.DeleteBook)))
+	h.get(router, "/api/v1/books/:id/revisions", h.mw.authMW(h.mw.adminOnlyMW(h.book.GetBookRevisions)))
+	h.get(router, "/api/v1/books/:id/revisions/diff", h.mw.authMW(h.mw.adminOnlyMW(h.book.DiffBookRevisions)))
+	h.post(router, "/api/v1/books/:id/revisions/:revision/revert", h.mw.adminOnlyMW(h.book.RevertBook))
+	h.get(router, "/api/v1/books/:id/translations", h.mw.authMW(h.book.GetBookTranslations))
+	router.PUT("/api/v1/books/:id/translations/:language", h.mw.authMW(h.mw.adminOnlyMW(h.book.SetBookTranslation)))
+	router.DELETE("/api/v1/books/:id/translations/:language", h.mw.authMW(h.mw.adminOnlyMW(h.book.DeleteBookTranslation)))
 
-	router.POST("/api/v1/genres", h.mw.authMW(h.mw.adminOnlyMW(h.genre.CreateGenre)))
-	router.GET("/api/v1/genres", h.mw.authMW(h.genre.GetAllGenres))
-	router.GET("/api/v1/genres/:id", h.mw.authMW(h.genre.GetGenreByID))
+	h.post(router, "/api/v1/genres", h.mw.adminOnlyMW(h.genre.CreateGenre))
+	h.get(router, "/api/v1/genres", h.mw.authMW(h.mw.localeMW(h.genre.GetAllGenres)))
+	h.get(router, "/api/v1/genres/:id", h.mw.authMW(h.mw.localeMW(staticSegment("id", "tree", h.genre.GetGenreTree, h.genre.GetGenreByID))))
 	router.PATCH("/api/v1/genres/:id", h.mw.authMW(h.mw.adminOnlyMW(h.genre.UpdateGenre)))
+	router.PUT("/api/v1/genres/:id", h.mw.authMW(h.mw.adminOnlyMW(h.genre.ReplaceGenre)))
 	router.DELETE("/api/v1/genres/:id", h.mw.authMW(h.mw.adminOnlyMW(h.genre.DeleteGenre)))
+	h.get(router, "/api/v1/genres/:id/revisions", h.mw.authMW(h.mw.adminOnlyMW(h.genre.GetGenreRevisions)))
+	h.get(router, "/api/v1/genres/:id/revisions/diff", h.mw.authMW(h.mw.adminOnlyMW(h.genre.DiffGenreRevisions)))
+	h.post(router, "/api/v1/genres/:id/revisions/:revision/revert", h.mw.adminOnlyMW(h.genre.RevertGenre))
+	h.post(router, "/api/v1/genres/:id/merge", h.mw.adminOnlyMW(h.genre.MergeGenres))
+	h.get(router, "/api/v1/genres/:id/translations", h.mw.authMW(h.genre.GetGenreTranslations))
+	router.PUT("/api/v1/genres/:id/translations/:language", h.mw.authMW(h.mw.adminOnlyMW(h.genre.SetGenreTranslation)))
+	router.DELETE("/api/v1/genres/:id/translations/:language", h.mw.authMW(h.mw.adminOnlyMW(h.genre.DeleteGenreTranslation)))
 
-	router.POST("/api/v1/authors", h.mw.authMW(h.mw.adminOnlyMW(h.author.CreateAuthor)))
-	router.GET("/api/v1/authors", h.mw.authMW(h.author.GetAllAuthors))
-	router.GET("/api/v1/authors/:id", h.mw.authMW(h.author.GetAuthorByID))
+	h.post(router, "/api/v1/authors", h.mw.adminOnlyMW(h.author.CreateAuthor))
+	h.get(router, "/api/v1/authors", h.mw.authMW(h.author.GetAllAuthors))
+	h.get(router, "/api/v1/authors/:id", h.mw.authMW(h.author.GetAuthorByID))
 	router.PATCH("/api/v1/authors/:id", h.mw.authMW(h.mw.adminOnlyMW(h.author.UpdateAuthor)))
+	router.PUT("/api/v1/authors/:id", h.mw.authMW(h.mw.adminOnlyMW(h.author.ReplaceAuthor)))
 	router.DELETE("/api/v1/authors/:id", h.mw.authMW(h.mw.adminOnlyMW(h.author.DeleteAuthor)))
+	h.get(router, "/api/v1/authors/:id/revisions", h.mw.authMW(h.mw.adminOnlyMW(h.author.GetAuthorRevisions)))
+	h.get(router, "/api/v1/authors/:id/revisions/diff", h.mw.authMW(h.mw.adminOnlyMW(h.author.DiffAuthorRevisions)))
+	h.post(router, "/api/v1/authors/:id/revisions/:revision/revert", h.mw.adminOnlyMW(h.author.RevertAuthor))
+	h.get(router, "/api/v1/authors/:id/books", h.mw.authMW(h.mw.localeMW(h.author.GetAuthorBooks)))
+	h.post(router, "/api/v1/authors/:id/merge", h.mw.adminOnlyMW(h.author.MergeAuthors))
+
+	h.post(router, "/api/v1/books/:id/reviews", h.mw.authenticatedOnlyMW(h.review.CreateReview))
+	h.get(router, "/api/v1/books/:id/reviews", h.mw.authMW(h.review.GetBookReviews))
+	router.PATCH("/api/v1/reviews/:id", h.mw.authMW(h.mw.authenticatedOnlyMW(h.review.UpdateReview)))
+	router.DELETE("/api/v1/reviews/:id", h.mw.authMW(h.mw.authenticatedOnlyMW(h.review.DeleteReview)))
+
+	h.get(router, "/api/v1/me/shelves", h.mw.authMW(h.mw.authenticatedOnlyMW(h.shelf.GetShelves)))
+	h.post(router, "/api/v1/me/shelves", h.mw.authenticatedOnlyMW(h.shelf.CreateShelf))
+	h.get(router, "/api/v1/me/shelves/:shelf", h.mw.authMW(h.mw.authenticatedOnlyMW(h.shelf.GetShelfBooks)))
+	router.DELETE("/api/v1/me/shelves/:shelf", h.mw.authMW(h.mw.authenticatedOnlyMW(h.shelf.DeleteShelf)))
+	h.post(router, "/api/v1/me/shelves/:shelf/books", h.mw.authenticatedOnlyMW(h.shelf.AddBookToShelf))
+	router.DELETE("/api/v1/me/shelves/:shelf/books/:id", h.mw.authMW(h.mw.authenticatedOnlyMW(h.shelf.RemoveBookFromShelf)))
+	router.PATCH("/api/v1/me/reading/:id", h.mw.authMW(h.mw.authenticatedOnlyMW(h.shelf.UpdateReading)))
+	h.get(router, "/api/v1/me/stats", h.mw.authMW(h.mw.authenticatedOnlyMW(h.shelf.GetReadingStats)))
+
+	h.post(router, "/api/v1/books/:id/copies", h.mw.adminOnlyMW(h.copy.CreateCopy))
+	h.get(router, "/api/v1/books/:id/copies", h.mw.authMW(h.copy.GetBookCopies))
+	router.PATCH("/api/v1/copies/:id", h.mw.authMW(h.mw.adminOnlyMW(h.copy.UpdateCopy)))
+	router.DELETE("/api/v1/copies/:id", h.mw.authMW(h.mw.adminOnlyMW(h.copy.DeleteCopy)))
+
+	h.post(router, "/api/v1/loans", h.mw.authenticatedOnlyMW(h.loan.Checkout))
+	h.get(router, "/api/v1/loans", h.mw.authMW(h.mw.adminOnlyMW(h.loan.GetLoans)))
+	h.post(router, "/api/v1/loans/:id/return", h.mw.authenticatedOnlyMW(h.loan.ReturnLoan))
+	h.post(router, "/api/v1/loans/:id/renew", h.mw.authenticatedOnlyMW(h.loan.RenewLoan))
+	h.get(router, "/api/v1/me/loans", h.mw.authMW(h.mw.authenticatedOnlyMW(h.loan.GetMyLoans)))
+
+	h.post(router, "/api/v1/books/:id/holds", h.mw.authenticatedOnlyMW(h.hold.PlaceHold))
+	h.get(router, "/api/v1/books/:id/holds", h.mw.authMW(h.mw.adminOnlyMW(h.hold.GetBookHolds)))
+	h.get(router, "/api/v1/me/holds", h.mw.authMW(h.mw.authenticatedOnlyMW(h.hold.GetMyHolds)))
+	router.DELETE("/api/v1/holds/:id", h.mw.authMW(h.mw.authenticatedOnlyMW(h.hold.CancelHold)))
 
+	h.post(router, "/api/v1/books/:id/tags", h.mw.authenticatedOnlyMW(h.tag.AddBookTags))
+	router.DELETE("/api/v1/books/:id/tags", h.mw.authMW(h.mw.authenticatedOnlyMW(h.tag.RemoveBookTags)))
+	h.get(router, "/api/v1/tags", h.mw.authMW(h.tag.GetTagCloud))
+
+	h.get(router, "/api/v1/trash", h.mw.authMW(h.mw.adminOnlyMW(h.trash.GetTrash)))
+	h.post(router, "/api/v1/books/:id/restore", h.mw.adminOnlyMW(h.trash.RestoreBook))
+	h.post(router, "/api/v1/authors/:id/restore", h.mw.adminOnlyMW(h.trash.RestoreAuthor))
+	h.post(router, "/api/v1/genres/:id/restore", h.mw.adminOnlyMW(h.trash.RestoreGenre))
+
+	h.post(router, "/api/v1/imports", h.mw.adminOnlyMW(h.imp.ImportBooks))
+	h.post(router, "/api/v1/imports/marc", h.mw.adminOnlyMW(h.imp.ImportMARC))
+	h.get(router, "/api/v1/imports/:id", h.mw.authMW(h.mw.adminOnlyMW(h.imp.GetImportJob)))
+	h.get(router, "/api/v1/imports/:id/errors", h.mw.authMW(h.mw.adminOnlyMW(h.imp.GetImportErrors)))
+
+	// Both OPDS versions serve the same feeds, the handlers write the version of the route.
+	for _, root := range []string{opdsRoot, opds2Root} {
+		h.get(router, root, h.mw.authMW(h.opds.GetOPDSRoot))
+		h.get(router, root+"/new", h.mw.authMW(h.opds.GetOPDSNewest))
+		h.get(router, root+"/genres", h.mw.authMW(h.opds.GetOPDSGenres))
+		h.get(router, root+"/genres/:id", h.mw.authMW(h.opds.GetOPDSGenreBooks))
+		h.get(router, root+"/authors", h.mw.authMW(h.opds.GetOPDSAuthors))
+		h.get(router, root+"/authors/:id", h.mw.authMW(h.opds.GetOPDSAuthorBooks))
+		h.get(router, root+"/search", h.mw.authMW(h.opds.SearchOPDS))
+	}
+	h.get(router, opdsRoot+"/opensearch.xml", h.mw.authMW(h.opds.GetOpenSearchDescription))
+
+	// Registrations and logins are anonymous, so their Idempotency-Keys can't be kept apart, and a
+	// replayed login would hand out the token again.
 	router.POST("/auth/register", h.user.RegisterUser)
 	router.POST("/auth/login", h.user.LoginUser)
 
 	return router
 }
+
+// get registers a read route. Its responses carry the Cache-Control policy of the route and answer
+// conditional requests.
+func (h *Handler) get(router *httprouter.Router, path string, handle httprouter.Handle) {
+	router.GET(path, h.mw.conditionalMW(h.cache.For(path), handle))
+}
+
+// post registers an authenticated POST route that honours the Idempotency-Key header.
+func (h *Handler) post(router *httprouter.Router, path string, handle httprouter.Handle) {
+	router.POST(path, h.mw.authMW(h.mw.idempotencyMW(handle)))
+}
@@ -2,20 +2,39 @@ package handler
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/julienschmidt/httprouter"
+	"github.com/tredoc/go-crud-api/internal/service"
 	"github.com/tredoc/go-crud-api/pkg/log"
 	"github.com/tredoc/go-crud-api/pkg/types"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type envelope map[string]any
 
-func writeJSON(w http.ResponseWriter, status int, data envelope, headers http.Header) error {
-	js, err := json.Marshal(data)
+// writeResponse writes the envelope in the media type the Accept header asks for. A successful GET
+// or HEAD nothing acceptable can hold is answered with 406, while errors of any method and the
+// results of changes, which are made by then, fall back to JSON rather than being lost.
+func writeResponse(w http.ResponseWriter, r *http.Request, status int, data envelope, headers http.Header) error {
+	list := isListEnvelope(data)
+	encoder, ok := negotiateEncoder(r.Header.Get("Accept"), list)
+	if !ok {
+		if status < http.StatusMultipleChoices && isSafeMethod(r) {
+			notAcceptableResponse(w, r, acceptableTypes(list)...)
+			return nil
+		}
+		encoder = responseEncoders[0]
+	}
+
+	body, err := encoder.encode(data)
 	if err != nil {
 		return err
 	}
@@ -23,9 +42,10 @@ func writeJSON(w http.ResponseWriter, status int, data envelope, headers http.He
 	for key, value := range headers {
 		w.Header()[key] = value
 	}
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Add("Vary", "Accept")
+	w.Header().Set("Content-Type", encoder.contentType)
 	w.WriteHeader(status)
-	_, err = w.Write(js)
+	_, err = w.Write(body)
 	if err != nil {
 		return err
 	}
@@ -42,13 +62,319 @@ func getIdParam(ps httprouter.Params) (int64, error) {
 	return id, nil
 }
 
+func getRevisionParam(ps httprouter.Params) (int64, error) {
+	id, err := strconv.ParseInt(ps.ByName("revision"), 10, 64)
+	if err != nil || id < 1 {
+		return 0, errors.New("invalid revision parameter")
+	}
+
+	return id, nil
+}
+
+// getRevisionRange reads the IDs of the compared revisions from the from and to query parameters.
+func getRevisionRange(r *http.Request) (int64, int64, error) {
+	query := r.URL.Query()
+	from, err := strconv.ParseInt(query.Get("from"), 10, 64)
+	if err != nil || from < 1 {
+		return 0, 0, errors.New("invalid from parameter")
+	}
+
+	to, err := strconv.ParseInt(query.Get("to"), 10, 64)
+	if err != nil || to < 1 {
+		return 0, 0, errors.New("invalid to parameter")
+	}
+
+	return from, to, nil
+}
+
+// getIfMatch reads the version the client expects from the If-Match header, zero when the header
+// is missing or "*". Only the ETags this API hands out are accepted: "<version>" of an update and
+// "<version>-<hash>" of a read. Anything else, e.g. a weak ETag, a list of ETags or the bare hash
+// of a read without a version, matches no version and isn't ok.
+func getIfMatch(r *http.Request) (int64, bool) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, true
+	}
+
+	tag, ok := strings.CutPrefix(value, `"`)
+	if !ok {
+		return 0, false
+	}
+
+	tag, ok = strings.CutSuffix(tag, `"`)
+	if !ok {
+		return 0, false
+	}
+
+	tag, hash, hashed := strings.Cut(tag, "-")
+	if hashed && !isContentHash(hash) || !hashed && len(tag) == contentHashLen {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version < 1 || strconv.FormatInt(version, 10) != tag {
+		return 0, false
+	}
+
+	return version, true
+}
+
+// isContentHash reports whether s is a hash contentETag puts into an ETag.
+func isContentHash(s string) bool {
+	if len(s) != contentHashLen {
+		return false
+	}
+
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// requireIfMatch reads the If-Match header like getIfMatch for changes that have to name the
+// version they are based on. A request without the header gets a 428, one whose header matches no
+// version a 412. "*" still matches any version for clients that mean to overwrite.
+func requireIfMatch(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	if strings.TrimSpace(r.Header.Get("If-Match")) == "" {
+		preconditionRequiredResponse(w, r)
+		return 0, false
+	}
+
+	version, ok := getIfMatch(r)
+	if !ok {
+		preconditionFailedResponse(w, r)
+		return 0, false
+	}
+
+	return version, true
+}
+
+// decodePatch reads the body as a JSON merge patch or a JSON patch depending on the Content-Type of
+// the request. Any other body isn't a patch and is left for the caller, which gets a nil patch.
+func decodePatch(r *http.Request) (*types.Patch, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, nil
+	}
+
+	switch mediaType {
+	case types.MergePatchType:
+		return types.DecodeMergePatch(r.Body)
+	case types.JSONPatchType:
+		return types.DecodeJSONPatch(r.Body)
+	}
+
+	return nil, nil
+}
+
+// etagHeader carries the version of the returned entity as its ETag.
+func etagHeader(version int64) http.Header {
+	return http.Header{"Etag": []string{fmt.Sprintf(`"%d"`, version)}}
+}
+
+// contentHashLen is the length of the hex encoded hash in the ETag of a read.
+const contentHashLen = 16
+
+// contentETag derives the ETag of a read from its body. A version ETag set by the handler stays in
+// front of the hash, so the ETag still works with If-Match and changes that don't bump the version,
+// like ratings or translations, show up as well.
+func contentETag(versionETag string, body []byte) string {
+	sum := sha256.Sum256(body)
+	hash := hex.EncodeToString(sum[:contentHashLen/2])
+	if version := strings.Trim(versionETag, `"`); version != "" {
+		return fmt.Sprintf(`"%s-%s"`, version, hash)
+	}
+
+	return fmt.Sprintf(`"%s"`, hash)
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when there is no If-None-Match, against
+// the validators of a read.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, tag := range strings.Split(ifNoneMatch, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// idempotencyScope keeps the Idempotency-Keys of different users apart.
+func idempotencyScope(user *types.User) string {
+	return "user:" + strconv.FormatInt(user.ID, 10)
+}
+
+// requestFingerprint identifies a request by its method, target and body.
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func getShelfParam(ps httprouter.Params) (types.ShelfRef, error) {
+	shelf := ps.ByName("shelf")
+	if status := types.ShelfStatus(shelf); status.IsValid() {
+		return types.ShelfRef{Status: status}, nil
+	}
+
+	id, err := strconv.ParseInt(shelf, 10, 64)
+	if err != nil || id < 1 {
+		return types.ShelfRef{}, errors.New("invalid shelf parameter")
+	}
+
+	return types.ShelfRef{ID: id}, nil
+}
+
+func getBookFilter(r *http.Request) (*types.BookFilter, error) {
+	query := r.URL.Query()
+	filter := types.BookFilter{TagMatch: types.MatchAnyTag}
+
+	if match := query.Get("match"); match != "" {
+		filter.TagMatch = types.TagMatch(match)
+	}
+
+	if tags := query.Get("tags"); tags != "" {
+		filter.Tags = types.NormalizeTags(strings.Split(tags, ","))
+	}
+
+	if genre := query.Get("genre"); genre != "" {
+		genreID, err := strconv.ParseInt(genre, 10, 64)
+		if err != nil {
+			return nil, errors.New("invalid genre parameter")
+		}
+		filter.GenreID = genreID
+	}
+
+	if subgenres := query.Get("subgenres"); subgenres != "" {
+		includeSubgenres, err := strconv.ParseBool(subgenres)
+		if err != nil {
+			return nil, errors.New("invalid subgenres parameter")
+		}
+		filter.IncludeSubgenres = includeSubgenres
+	}
+
+	filter.Query = strings.TrimSpace(query.Get("q"))
+
+	return &filter, nil
+}
+
+// staticSegment serves the static handler when the named parameter equals value and next
+// otherwise. httprouter can't register a static segment next to a parameter, e.g. /genres/tree
+// beside /genres/:id.
+func staticSegment(name string, value string, static httprouter.Handle, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if ps.ByName(name) == value {
+			static(w, r, ps)
+			return
+		}
+
+		next(w, r, ps)
+	}
+}
+
+// customMethods serves POST requests to the paths of custom methods, e.g. /books:batch, which
+// httprouter can't register beside /books. Requests to other paths get the fallback handler.
+func customMethods(routes map[string]httprouter.Handle, fallback http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handle, ok := routes[r.URL.Path]
+		if !ok {
+			fallback(w, r)
+			return
+		}
+
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			notAllowedResponse(w, r)
+			return
+		}
+
+		handle(w, r, nil)
+	}
+}
+
+// batchResult reports a successful batch operation.
+func batchResult(index int, op types.BatchOp, id int64, version int64) *types.BatchResult {
+	switch op {
+	case types.BatchCreate:
+		return &types.BatchResult{Index: index, Status: http.StatusCreated, ID: id, Version: version}
+	case types.BatchDelete:
+		return &types.BatchResult{Index: index, Status: http.StatusNoContent, ID: id}
+	}
+
+	return &types.BatchResult{Index: index, Status: http.StatusOK, ID: id, Version: version}
+}
+
+// batchFailure reports a failed batch operation with the status and message its own request would
+// get.
+func batchFailure(r *http.Request, index int, err error) *types.BatchResult {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return &types.BatchResult{Index: index, Status: http.StatusUnprocessableEntity, Error: validationErr.Errors}
+	case errors.Is(err, service.ErrNotFound):
+		return &types.BatchResult{Index: index, Status: http.StatusNotFound, Error: "the requested resource could not be found"}
+	case errors.Is(err, service.ErrVersionMismatch):
+		return &types.BatchResult{Index: index, Status: http.StatusPreconditionFailed, Error: "the resource was changed by someone else, fetch it again and retry"}
+	case errors.Is(err, service.ErrEntityExists):
+		return &types.BatchResult{Index: index, Status: http.StatusConflict, Error: "name, alias or external identifier belongs to another entity"}
+	case errors.Is(err, service.ErrEntityInUse):
+		return &types.BatchResult{Index: index, Status: http.StatusConflict, Error: "still listed on books, remove it from them first"}
+	case errors.Is(err, service.ErrGenreCycle):
+		return &types.BatchResult{Index: index, Status: http.StatusUnprocessableEntity, Error: map[string]string{"parent_id": "can't be the genre itself or one of its subgenres"}}
+	case errors.Is(err, service.ErrBatchAborted):
+		return &types.BatchResult{Index: index, Status: http.StatusFailedDependency, Error: "not applied, another operation of the atomic batch failed"}
+	}
+
+	logError(r, err)
+	return &types.BatchResult{Index: index, Status: http.StatusInternalServerError, Error: "the server encountered a problem and could not process the operation"}
+}
+
+// writeBatchResults answers with the result of every operation. An atomic batch that failed is
+// answered with the status of the operation that failed it, other batches with 200.
+func writeBatchResults(w http.ResponseWriter, r *http.Request, mode types.BatchMode, results []*types.BatchResult) {
+	status := http.StatusOK
+	if mode == types.AtomicBatch {
+		for _, result := range results {
+			if result.Status >= http.StatusBadRequest && result.Status != http.StatusFailedDependency {
+				status = result.Status
+				break
+			}
+		}
+	}
+
+	err := writeResponse(w, r, status, envelope{"results": results}, nil)
+	if err != nil {
+		logError(r, err)
+	}
+}
+
 func logError(r *http.Request, err error) {
 	log.Error(err.Error())
 }
 
 func errorResponse(w http.ResponseWriter, r *http.Request, status int, message any) {
 	env := envelope{"error": message}
-	err := writeJSON(w, status, env, nil)
+	err := writeResponse(w, r, status, env, nil)
 	if err != nil {
 		logError(r, err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -76,6 +402,30 @@ func notAllowedResponse(w http.ResponseWriter, r *http.Request) {
 	errorResponse(w, r, http.StatusMethodNotAllowed, message)
 }
 
+func conflictResponse(w http.ResponseWriter, r *http.Request, message string) {
+	errorResponse(w, r, http.StatusConflict, message)
+}
+
+func preconditionFailedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the resource was changed by someone else, fetch it again and retry"
+	errorResponse(w, r, http.StatusPreconditionFailed, message)
+}
+
+func preconditionRequiredResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the request has to name the version it is based on in an If-Match header"
+	errorResponse(w, r, http.StatusPreconditionRequired, message)
+}
+
+func unsupportedMediaTypeResponse(w http.ResponseWriter, r *http.Request, supported ...string) {
+	message := fmt.Sprintf("the request body has to be one of %s", strings.Join(supported, ", "))
+	errorResponse(w, r, http.StatusUnsupportedMediaType, message)
+}
+
+func notAcceptableResponse(w http.ResponseWriter, r *http.Request, acceptable ...string) {
+	message := fmt.Sprintf("the response can only be one of %s", strings.Join(acceptable, ", "))
+	errorResponse(w, r, http.StatusNotAcceptable, message)
+}
+
 func notValidResponse(w http.ResponseWriter, r *http.Request, errors map[string]string) {
 	errorResponse(w, r, http.StatusUnprocessableEntity, errors)
 }
@@ -96,7 +446,47 @@ func insufficientPermissionsResponse(w http.ResponseWriter, r *http.Request) {
 	errorResponse(w, r, http.StatusUnauthorized, message)
 }
 
+func authenticationRequiredResponse(w http.ResponseWriter, r *http.Request) {
+	message := "you must be authenticated to access this resource"
+	errorResponse(w, r, http.StatusUnauthorized, message)
+}
+
 func contextSetUser(r *http.Request, user *types.User) *http.Request {
 	ctx := context.WithValue(r.Context(), types.UserContextKey, user)
 	return r.WithContext(ctx)
 }
+
+func contextSetLanguages(r *http.Request, chain []string) *http.Request {
+	ctx := context.WithValue(r.Context(), types.LanguagesContextKey, chain)
+	return r.WithContext(ctx)
+}
+
+func contextSetLastModified(r *http.Request, lastModified *time.Time) *http.Request {
+	ctx := context.WithValue(r.Context(), types.LastModifiedContextKey, lastModified)
+	return r.WithContext(ctx)
+}
+
+func contextGetUser(r *http.Request) *types.User {
+	user, ok := r.Context().Value(types.UserContextKey).(*types.User)
+	if !ok {
+		return types.AnonymousUser
+	}
+
+	return user
+}
+
+// decodeTranslation reads the translated text from the body and the language tag from the path.
+func decodeTranslation(r *http.Request, ps httprouter.Params) (*types.Translation, error) {
+	var translation types.Translation
+	err := json.NewDecoder(r.Body).Decode(&translation)
+	if err != nil {
+		return nil, errors.New("can't decode request")
+	}
+
+	translation.Language, err = types.ParseLanguageTag(ps.ByName("language"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &translation, nil
+}
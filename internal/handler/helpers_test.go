@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func withUser(user *types.User, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		next(w, contextSetUser(r, user), ps)
+	}
+}
+
+func TestGetIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		version int64
+		ok      bool
+	}{
+		{name: "missing", header: "", ok: true},
+		{name: "any version", header: "*", ok: true},
+		{name: "etag of an update", header: `"12"`, version: 12, ok: true},
+		{name: "etag of a read", header: contentETag(`"12"`, []byte(`{"id":1}`)), version: 12, ok: true},
+		{name: "weak etag", header: `W/"12"`},
+		{name: "weak etag of a read", header: "W/" + contentETag(`"12"`, []byte(`{"id":1}`))},
+		{name: "all-digit hash without a version", header: `"1234567890123456"`},
+		{name: "hash of another length", header: `"12-abc"`},
+		{name: "uppercase hash", header: `"12-0123456789ABCDEF"`},
+		{name: "list of etags", header: `"12", "13"`},
+		{name: "signed version", header: `"+12"`},
+		{name: "leading zero", header: `"012"`},
+		{name: "zero version", header: `"0"`},
+		{name: "unquoted", header: "12"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodDelete, "/api/v1/books/1", nil)
+			if test.header != "" {
+				r.Header.Set("If-Match", test.header)
+			}
+
+			version, ok := getIfMatch(r)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.version, version)
+		})
+	}
+}
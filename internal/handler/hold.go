@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/tredoc/go-crud-api/internal/service"
+	"github.com/tredoc/go-crud-api/pkg/log"
+	"net/http"
+)
+
+type HoldHandler struct {
+	service service.Hold
+}
+
+func NewHoldHandler(service service.Hold) *HoldHandler {
+	return &HoldHandler{
+		service: service,
+	}
+}
+
+// PlaceHold godoc
+// @Summary Place a hold on a book
+// @Description Queue the current user for a book when all of its copies are on loan
+// @Tags holds
+// @ID place-hold
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Book ID"
+// @Security Bearer
+// @Success 201 {object} types.Hold
+// @Router /api/v1/books/{id}/holds [post]
+func (h *HoldHandler) PlaceHold(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	bookID, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	hold, err := h.service.PlaceHold(r.Context(), bookID, contextGetUser(r).ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrEntityExists):
+			badRequestResponse(w, r, errors.New("book is already on hold"))
+		case errors.Is(err, service.ErrCopyAvailable):
+			conflictResponse(w, r, "a copy is available for checkout")
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusCreated, envelope{"hold": hold}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// GetBookHolds godoc
+// @Summary Get holds on a book
+// @Description Get the ready and waiting holds on a book in queue order
+// @Tags holds
+// @ID get-book-holds
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param id path int true "Book ID"
+// @Security Bearer
+// @Success 200 {array} []types.Hold
+// @Router /api/v1/books/{id}/holds [get]
+func (h *HoldHandler) GetBookHolds(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	bookID, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	holds, err := h.service.GetBookHolds(r.Context(), bookID)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"holds": holds}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// GetMyHolds godoc
+// @Summary Get my holds
+// @Description Get open holds of the current user with their queue positions
+// @Tags holds
+// @ID get-my-holds
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Security Bearer
+// @Success 200 {array} []types.Hold
+// @Router /api/v1/me/holds [get]
+func (h *HoldHandler) GetMyHolds(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	holds, err := h.service.GetUserHolds(r.Context(), contextGetUser(r).ID)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"holds": holds}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// CancelHold godoc
+// @Summary Cancel a hold
+// @Description Cancel an open hold with a specific ID, a copy it was holding goes to the next in queue
+// @Tags holds
+// @ID cancel-hold
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Hold ID"
+// @Security Bearer
+// @Success 204 "No Content"
+// @Router /api/v1/holds/{id} [delete]
+func (h *HoldHandler) CancelHold(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	err = h.service.CancelHold(r.Context(), id, contextGetUser(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrNotPermitted):
+			insufficientPermissionsResponse(w, r)
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
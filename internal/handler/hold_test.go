@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/tredoc/go-crud-api/internal/service"
+	mockservice "github.com/tredoc/go-crud-api/mocks/service"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type holdHandlerSuite struct {
+	suite.Suite
+	usecase       *mockservice.Hold
+	handler       *HoldHandler
+	user          *types.User
+	testingServer *httptest.Server
+}
+
+func (s *holdHandlerSuite) SetupSuite() {
+	usecase := new(mockservice.Hold)
+	handler := NewHoldHandler(usecase)
+	user := &types.User{ID: 5, Email: "patron@example.com", Role: types.UserRole}
+
+	router := httprouter.New()
+	router.POST("/api/v1/books/:id/holds", withUser(user, handler.PlaceHold))
+	router.GET("/api/v1/books/:id/holds", handler.GetBookHolds)
+	router.GET("/api/v1/me/holds", withUser(user, handler.GetMyHolds))
+	router.DELETE("/api/v1/holds/:id", withUser(user, handler.CancelHold))
+
+	testingServer := httptest.NewServer(router)
+
+	s.testingServer = testingServer
+	s.usecase = usecase
+	s.handler = handler
+	s.user = user
+}
+
+func (s *holdHandlerSuite) TearDownSuite() {
+	s.usecase.AssertExpectations(s.T())
+	defer s.testingServer.Close()
+}
+
+func (s *holdHandlerSuite) TestPlaceHold_Positive() {
+	bookID := int64(1)
+	hold := types.Hold{ID: 1, BookID: bookID, UserID: s.user.ID, Status: types.WaitingHold, Position: 2, CreatedAt: time.Now()}
+
+	s.usecase.On("PlaceHold", mock.AnythingOfType("*context.valueCtx"), bookID, s.user.ID).Return(&hold, nil)
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/books/%d/holds", s.testingServer.URL, bookID), "application/json", nil)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"hold": &hold,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusCreated, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *holdHandlerSuite) TestPlaceHold_CopyAvailable() {
+	bookID := int64(2)
+	s.usecase.On("PlaceHold", mock.AnythingOfType("*context.valueCtx"), bookID, s.user.ID).Return(nil, service.ErrCopyAvailable)
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/books/%d/holds", s.testingServer.URL, bookID), "application/json", nil)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusConflict, response.StatusCode)
+}
+
+func (s *holdHandlerSuite) TestGetBookHolds_Positive() {
+	bookID := int64(3)
+	copyID := int64(7)
+	readyAt := time.Now()
+	expiresAt := readyAt.Add(types.HoldPickupWindow)
+	holds := []*types.Hold{
+		{ID: 4, BookID: bookID, UserID: 8, Status: types.ReadyHold, CopyID: &copyID, CreatedAt: time.Now(), ReadyAt: &readyAt, ExpiresAt: &expiresAt},
+		{ID: 6, BookID: bookID, UserID: 9, Status: types.WaitingHold, Position: 1, CreatedAt: time.Now()},
+	}
+
+	s.usecase.On("GetBookHolds", mock.AnythingOfType("*context.cancelCtx"), bookID).Return(holds, nil)
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/books/%d/holds", s.testingServer.URL, bookID))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"holds": holds,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *holdHandlerSuite) TestCancelHold_NotPermitted() {
+	id := int64(4)
+	s.usecase.On("CancelHold", mock.AnythingOfType("*context.valueCtx"), id, s.user).Return(service.ErrNotPermitted)
+
+	request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/v1/holds/%d", s.testingServer.URL, id), nil)
+	s.NoError(err, "can`t create request")
+
+	response, err := http.DefaultClient.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusUnauthorized, response.StatusCode)
+}
+
+func TestHoldHandler(t *testing.T) {
+	suite.Run(t, new(holdHandlerSuite))
+}
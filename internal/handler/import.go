@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/tredoc/go-crud-api/internal/service"
+	"github.com/tredoc/go-crud-api/pkg/log"
+	"github.com/tredoc/go-crud-api/pkg/marc"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+const (
+	// maxImportSize is the largest upload accepted, in bytes.
+	maxImportSize = 10 << 20
+	// maxMARCRecords is the most records a MARC upload can hold, they are imported while the
+	// request waits.
+	maxMARCRecords = 500
+)
+
+type ImportHandler struct {
+	service service.Import
+}
+
+func NewImportHandler(service service.Import) *ImportHandler {
+	return &ImportHandler{
+		service: service,
+	}
+}
+
+// ImportBooks godoc
+// @Summary Import books from a file
+// @Description Upload books as CSV with a title, publish_date, isbn, pages, authors and genres header, authors and genres separated by semicolons, or as NDJSON with a book per line. Authors and genres are given by name and created when they don't exist. The rows are imported in the background, follow the job for the progress
+// @Tags imports
+// @ID import-books
+// @Accept  text/csv,application/x-ndjson
+// @Produce  json,xml,application/msgpack
+// @Param dry_run query bool false "Check every row without importing anything"
+// @Param Idempotency-Key header string false "Key that makes retries of the request replay its response"
+// @Security Bearer
+// @Success 202 {object} types.ImportJob
+// @Header 202 {string} Location "URL of the import job"
+// @Router /api/v1/imports [post]
+func (h *ImportHandler) ImportBooks(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	job := types.ImportJob{}
+	switch mediaType {
+	case types.CSVType:
+		job.Format = types.CSVImport
+	case types.NDJSONType:
+		job.Format = types.NDJSONImport
+	default:
+		unsupportedMediaTypeResponse(w, r, types.CSVType, types.NDJSONType)
+		return
+	}
+
+	if dryRun := r.URL.Query().Get("dry_run"); dryRun != "" {
+		var err error
+		job.DryRun, err = strconv.ParseBool(dryRun)
+		if err != nil {
+			badRequestResponse(w, r, errors.New("dry_run must be true or false"))
+			return
+		}
+	}
+
+	rows, err := types.ParseImport(http.MaxBytesReader(w, r.Body, maxImportSize), job.Format)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			errorResponse(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("upload can't be larger than %d bytes", maxImportSize))
+			return
+		}
+		notValidResponse(w, r, map[string]string{"file": err.Error()})
+		return
+	}
+
+	err = h.service.StartImport(r.Context(), &job, rows)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	headers := http.Header{"Location": {fmt.Sprintf("/api/v1/imports/%d", job.ID)}}
+	err = writeResponse(w, r, http.StatusAccepted, envelope{"job": job}, headers)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// ImportMARC godoc
+// @Summary Import books from MARC records
+// @Description Upload MARC 21 bibliographic records in ISO 2709 or as MARCXML. A record creates its book or, when a book with its ISBN exists, replaces that one. Authors come from 100 and 700, genres from 655 and 650 and are created when they don't exist, pages from 300 and the year of publication from 264, 260 or 008. The records are imported right away, the result of every record is at its index
+// @Tags imports
+// @ID import-marc
+// @Accept  application/marc,application/marcxml+xml
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param Idempotency-Key header string false "Key that makes retries of the request replay its response"
+// @Security Bearer
+// @Success 200 {array} types.BatchResult
+// @Router /api/v1/imports/marc [post]
+func (h *ImportHandler) ImportMARC(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+	var read func() (*marc.Record, error)
+	switch mediaType {
+	case marc.Type:
+		read = marc.NewReader(body).Read
+	case marc.XMLType:
+		read = marc.NewXMLReader(body).Read
+	default:
+		unsupportedMediaTypeResponse(w, r, marc.Type, marc.XMLType)
+		return
+	}
+
+	var rows []*types.ImportRow
+	for {
+		record, err := read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				errorResponse(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("upload can't be larger than %d bytes", maxImportSize))
+				return
+			}
+			notValidResponse(w, r, map[string]string{"file": fmt.Sprintf("record %d: %s", len(rows)+1, err.Error())})
+			return
+		}
+
+		if len(rows) == maxMARCRecords {
+			notValidResponse(w, r, map[string]string{"file": fmt.Sprintf("can't hold more than %d records", maxMARCRecords)})
+			return
+		}
+		rows = append(rows, marc.ToImportRow(record, len(rows)+1))
+	}
+
+	if len(rows) == 0 {
+		notValidResponse(w, r, map[string]string{"file": "holds no records"})
+		return
+	}
+
+	outcomes := h.service.UpsertBooks(r.Context(), rows)
+	results := make([]*types.BatchResult, len(outcomes))
+	for i, outcome := range outcomes {
+		switch {
+		case outcome.Errors != nil:
+			results[i] = &types.BatchResult{Index: i, Status: http.StatusUnprocessableEntity, Error: outcome.Errors}
+		case outcome.Created:
+			results[i] = &types.BatchResult{Index: i, Status: http.StatusCreated, ID: outcome.ID, Version: outcome.Version}
+		default:
+			results[i] = &types.BatchResult{Index: i, Status: http.StatusOK, ID: outcome.ID, Version: outcome.Version}
+		}
+	}
+
+	writeBatchResults(w, r, types.BestEffortBatch, results)
+}
+
+// GetImportJob godoc
+// @Summary Get an import job
+// @Description Get the status and progress of a book import
+// @Tags imports
+// @ID get-import-job
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Import job ID"
+// @Security Bearer
+// @Success 200 {object} types.ImportJob
+// @Router /api/v1/imports/{id} [get]
+func (h *ImportHandler) GetImportJob(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	job, ok := h.getImportJob(w, r, ps)
+	if !ok {
+		return
+	}
+
+	err := writeResponse(w, r, http.StatusOK, envelope{"job": job}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// GetImportErrors godoc
+// @Summary Download the error report of an import job
+// @Description Get the rows of an import that failed as CSV with a line for every field error, the row being the line of the upload
+// @Tags imports
+// @ID get-import-errors
+// @Accept  json
+// @Produce  text/csv
+// @Param id path int true "Import job ID"
+// @Security Bearer
+// @Success 200 {string} string "row,field,message"
+// @Router /api/v1/imports/{id}/errors [get]
+func (h *ImportHandler) GetImportErrors(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	job, ok := h.getImportJob(w, r, ps)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", types.CSVType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="import-%d-errors.csv"`, job.ID))
+	w.WriteHeader(http.StatusOK)
+
+	report := csv.NewWriter(w)
+	records := [][]string{{"row", "field", "message"}}
+	for _, rowErr := range job.Errors {
+		fields := make([]string, 0, len(rowErr.Errors))
+		for field := range rowErr.Errors {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		for _, field := range fields {
+			records = append(records, []string{strconv.Itoa(rowErr.Row), field, rowErr.Errors[field]})
+		}
+	}
+
+	err := report.WriteAll(records)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+func (h *ImportHandler) getImportJob(w http.ResponseWriter, r *http.Request, ps httprouter.Params) (*types.ImportJob, bool) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return nil, false
+	}
+
+	job, err := h.service.GetImportJob(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return nil, false
+		}
+		serverErrorResponse(w, r, err)
+		return nil, false
+	}
+
+	return job, true
+}
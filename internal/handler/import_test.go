@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/tredoc/go-crud-api/internal/service"
+	mockservice "github.com/tredoc/go-crud-api/mocks/service"
+	"github.com/tredoc/go-crud-api/pkg/marc"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type importHandlerSuite struct {
+	suite.Suite
+	usecase       *mockservice.Import
+	handler       *ImportHandler
+	testingServer *httptest.Server
+}
+
+func (s *importHandlerSuite) SetupSuite() {
+	usecase := new(mockservice.Import)
+	handler := NewImportHandler(usecase)
+
+	router := httprouter.New()
+	router.POST("/api/v1/imports", handler.ImportBooks)
+	router.POST("/api/v1/imports/marc", handler.ImportMARC)
+	router.GET("/api/v1/imports/:id", handler.GetImportJob)
+	router.GET("/api/v1/imports/:id/errors", handler.GetImportErrors)
+
+	testingServer := httptest.NewServer(router)
+
+	s.testingServer = testingServer
+	s.usecase = usecase
+	s.handler = handler
+}
+
+func (s *importHandlerSuite) TearDownSuite() {
+	s.usecase.AssertExpectations(s.T())
+	defer s.testingServer.Close()
+}
+
+func (s *importHandlerSuite) TestImportBooks_CSV() {
+	isJob := func(job *types.ImportJob) bool {
+		return job.Format == types.CSVImport && job.DryRun
+	}
+	isRows := func(rows []*types.ImportRow) bool {
+		return len(rows) == 2 &&
+			rows[0].Line == 2 && rows[0].Book.Title == "Go mechanics" && rows[0].Book.Pages == 520 &&
+			len(rows[0].Authors) == 2 && rows[0].Authors[1] == "Le Guin, Ursula K." &&
+			rows[1].Errors["pages"] != ""
+	}
+	started := func(args mock.Arguments) {
+		args.Get(1).(*types.ImportJob).ID = 3
+	}
+	s.usecase.On("StartImport", mock.AnythingOfType("*context.cancelCtx"), mock.MatchedBy(isJob), mock.MatchedBy(isRows)).
+		Run(started).Return(nil).Once()
+
+	body := "title,publish_date,isbn,pages,authors,genres\n" +
+		"Go mechanics,2006-01-01,11111100-09000,520,\"Rob Pike; Le Guin, Ursula K.\",programming\n" +
+		"Go internals,2007-01-01,11111100-09002,many,Rob Pike,programming\n"
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/imports?dry_run=true", s.testingServer.URL), "text/csv; charset=utf-8", strings.NewReader(body))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	var responseBody struct {
+		Job types.ImportJob `json:"job"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&responseBody)
+	s.NoError(err, "can`t decode response")
+
+	s.Equal(http.StatusAccepted, response.StatusCode)
+	s.Equal("/api/v1/imports/3", response.Header.Get("Location"))
+	s.Equal(int64(3), responseBody.Job.ID)
+}
+
+func (s *importHandlerSuite) TestImportBooks_MissingColumn() {
+	body := "title,isbn\nGo mechanics,11111100-09000\n"
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/imports", s.testingServer.URL), "text/csv", strings.NewReader(body))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusUnprocessableEntity, response.StatusCode)
+}
+
+func (s *importHandlerSuite) TestImportBooks_UnreadableRow() {
+	isJob := func(job *types.ImportJob) bool {
+		return job.Format == types.CSVImport && !job.DryRun
+	}
+	isRows := func(rows []*types.ImportRow) bool {
+		return len(rows) == 2 &&
+			rows[0].Line == 2 && rows[0].Errors["row"] != "" &&
+			rows[1].Line == 3 && rows[1].Book.Title == "Go internals" && len(rows[1].Errors) == 0
+	}
+	s.usecase.On("StartImport", mock.AnythingOfType("*context.cancelCtx"), mock.MatchedBy(isJob), mock.MatchedBy(isRows)).
+		Return(nil).Once()
+
+	body := "title,publish_date,isbn,pages,authors,genres\n" +
+		"Go \"mechanics\" revisited,2006-01-01,11111100-09004,520,Rob Pike,programming\n" +
+		"Go internals,2007-01-01,11111100-09006,300,Rob Pike,programming\n"
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/imports", s.testingServer.URL), "text/csv", strings.NewReader(body))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusAccepted, response.StatusCode)
+}
+
+func (s *importHandlerSuite) TestImportBooks_HeaderTooLarge() {
+	body := strings.Repeat("t", maxImportSize+1)
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/imports", s.testingServer.URL), "text/csv", strings.NewReader(body))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusRequestEntityTooLarge, response.StatusCode)
+}
+
+func (s *importHandlerSuite) TestImportBooks_UnsupportedType() {
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/imports", s.testingServer.URL), "application/json", strings.NewReader(`[]`))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusUnsupportedMediaType, response.StatusCode)
+}
+
+func (s *importHandlerSuite) TestImportMARC_XML() {
+	isRows := func(rows []*types.ImportRow) bool {
+		return len(rows) == 2 &&
+			rows[0].Line == 1 && rows[0].Book.Title == "Go: the language" && rows[0].Book.ISBN == "9780134190440" &&
+			rows[0].Book.Pages == 380 && rows[0].Book.PublishDate.Year() == 2015 &&
+			len(rows[0].Authors) == 2 && rows[0].Authors[0] == "Donovan, Alan A. A." && rows[0].Authors[1] == "Kernighan, Brian W." &&
+			len(rows[0].Genres) == 1 && rows[0].Genres[0] == "Go (Computer program language)" &&
+			rows[1].Errors["publish_date"] != ""
+	}
+	outcomes := []*types.ImportOutcome{
+		{ID: 12, Version: 3},
+		{Errors: map[string]string{"publish_date": "no year of publication in 008, 260 or 264"}},
+	}
+	s.usecase.On("UpsertBooks", mock.AnythingOfType("*context.cancelCtx"), mock.MatchedBy(isRows)).Return(outcomes).Once()
+
+	body := `<?xml version="1.0" encoding="UTF-8"?>
+<collection xmlns="http://www.loc.gov/MARC21/slim">
+  <record>
+    <leader>01142cam  2200301 i 4500</leader>
+    <controlfield tag="001">ocn910845123</controlfield>
+    <datafield tag="020" ind1=" " ind2=" "><subfield code="a">9780134190440 (pbk.)</subfield></datafield>
+    <datafield tag="100" ind1="1" ind2=" "><subfield code="a">Donovan, Alan A. A.,</subfield><subfield code="e">author.</subfield></datafield>
+    <datafield tag="245" ind1="1" ind2="4"><subfield code="a">Go :</subfield><subfield code="b">the language /</subfield></datafield>
+    <datafield tag="264" ind1=" " ind2="1"><subfield code="b">Addison-Wesley,</subfield><subfield code="c">[2015]</subfield></datafield>
+    <datafield tag="300" ind1=" " ind2=" "><subfield code="a">xvii, 380 pages ;</subfield></datafield>
+    <datafield tag="650" ind1=" " ind2="0"><subfield code="a">Go (Computer program language)</subfield></datafield>
+    <datafield tag="700" ind1="1" ind2=" "><subfield code="a">Kernighan, Brian W.,</subfield><subfield code="e">author.</subfield></datafield>
+  </record>
+  <record>
+    <leader>00000nam a2200000   4500</leader>
+    <datafield tag="245" ind1="0" ind2="0"><subfield code="a">Undated</subfield></datafield>
+  </record>
+</collection>`
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/imports/marc", s.testingServer.URL), marc.XMLType, strings.NewReader(body))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	var responseBody struct {
+		Results []types.BatchResult `json:"results"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&responseBody)
+	s.NoError(err, "can`t decode response")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Len(responseBody.Results, 2)
+	s.Equal(http.StatusOK, responseBody.Results[0].Status)
+	s.Equal(int64(12), responseBody.Results[0].ID)
+	s.Equal(http.StatusUnprocessableEntity, responseBody.Results[1].Status)
+}
+
+func (s *importHandlerSuite) TestImportMARC_Malformed() {
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/imports/marc", s.testingServer.URL), marc.Type, strings.NewReader("00026nam a2200025   4500\x1d"))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusUnprocessableEntity, response.StatusCode)
+}
+
+func (s *importHandlerSuite) TestGetImportErrors() {
+	job := types.ImportJob{
+		ID:     4,
+		Status: types.ImportCompleted,
+		Errors: []*types.ImportRowError{
+			{Row: 3, Errors: map[string]string{"title": "can't be empty", "isbn": "can't be empty"}},
+			{Row: 7, Errors: map[string]string{"authors": `"R2" isn't a valid author name`}},
+		},
+	}
+	s.usecase.On("GetImportJob", mock.AnythingOfType("*context.cancelCtx"), int64(4)).Return(&job, nil).Once()
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/imports/4/errors", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal("text/csv", response.Header.Get("Content-Type"))
+	s.Equal("row,field,message\n3,isbn,can't be empty\n3,title,can't be empty\n7,authors,\"\"\"R2\"\" isn't a valid author name\"\n", string(body))
+}
+
+func (s *importHandlerSuite) TestGetImportJob_NotFound() {
+	s.usecase.On("GetImportJob", mock.AnythingOfType("*context.cancelCtx"), int64(5)).Return(nil, service.ErrNotFound).Once()
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/imports/5", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusNotFound, response.StatusCode)
+}
+
+func TestImportHandler(t *testing.T) {
+	suite.Run(t, new(importHandlerSuite))
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/tredoc/go-crud-api/internal/service"
+	"github.com/tredoc/go-crud-api/internal/validator"
+	"github.com/tredoc/go-crud-api/pkg/log"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"net/http"
+)
+
+type LoanHandler struct {
+	service service.Loan
+}
+
+func NewLoanHandler(service service.Loan) *LoanHandler {
+	return &LoanHandler{
+		service: service,
+	}
+}
+
+// Checkout godoc
+// @Summary Check out a copy
+// @Description Check out a copy by barcode for the current user, admins may check out for another user
+// @Tags loans
+// @ID checkout
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param checkout body types.Checkout true "Copy barcode and optional borrower"
+// @Security Bearer
+// @Success 201 {object} types.Loan
+// @Router /api/v1/loans [post]
+func (h *LoanHandler) Checkout(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var checkout types.Checkout
+	err := json.NewDecoder(r.Body).Decode(&checkout)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("can't decode request"))
+		return
+	}
+
+	v := validator.New()
+	types.ValidateCheckout(v, &checkout)
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return
+	}
+
+	loan, err := h.service.Checkout(r.Context(), contextGetUser(r), &checkout)
+	if err != nil {
+		h.loanErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusCreated, envelope{"loan": loan}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// ReturnLoan godoc
+// @Summary Return a copy
+// @Description Close a loan with a specific ID
+// @Tags loans
+// @ID return-loan
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Loan ID"
+// @Security Bearer
+// @Success 200 {object} types.Loan
+// @Router /api/v1/loans/{id}/return [post]
+func (h *LoanHandler) ReturnLoan(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	loan, err := h.service.ReturnLoan(r.Context(), id, contextGetUser(r))
+	if err != nil {
+		h.loanErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"loan": loan}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// RenewLoan godoc
+// @Summary Renew a loan
+// @Description Extend the due date of a loan with a specific ID unless it is overdue or renewed too often
+// @Tags loans
+// @ID renew-loan
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Loan ID"
+// @Security Bearer
+// @Success 200 {object} types.Loan
+// @Router /api/v1/loans/{id}/renew [post]
+func (h *LoanHandler) RenewLoan(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	loan, err := h.service.RenewLoan(r.Context(), id, contextGetUser(r))
+	if err != nil {
+		h.loanErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"loan": loan}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// GetMyLoans godoc
+// @Summary Get my loans
+// @Description Get active loans of the current user ordered by due date
+// @Tags loans
+// @ID get-my-loans
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Security Bearer
+// @Success 200 {array} []types.Loan
+// @Router /api/v1/me/loans [get]
+func (h *LoanHandler) GetMyLoans(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	loans, err := h.service.GetUserLoans(r.Context(), contextGetUser(r).ID)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"loans": loans}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// GetLoans godoc
+// @Summary Get loans
+// @Description Get active, overdue or returned loans of all users
+// @Tags loans
+// @ID get-loans
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param status query string false "active (default), overdue or returned"
+// @Security Bearer
+// @Success 200 {array} []types.Loan
+// @Router /api/v1/loans [get]
+func (h *LoanHandler) GetLoans(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	status := types.ActiveLoan
+	if param := r.URL.Query().Get("status"); param != "" {
+		status = types.LoanStatus(param)
+		if !status.IsValid() {
+			badRequestResponse(w, r, errors.New("invalid status parameter"))
+			return
+		}
+	}
+
+	loans, err := h.service.GetLoans(r.Context(), status)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"loans": loans}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+func (h *LoanHandler) loanErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		notFoundResponse(w, r)
+	case errors.Is(err, service.ErrNotPermitted):
+		insufficientPermissionsResponse(w, r)
+	case errors.Is(err, service.ErrCopyUnavailable):
+		conflictResponse(w, r, "copy is already on loan")
+	case errors.Is(err, service.ErrLoanLimitReached):
+		conflictResponse(w, r, "loan limit reached")
+	case errors.Is(err, service.ErrLoanClosed):
+		conflictResponse(w, r, "loan is already returned")
+	case errors.Is(err, service.ErrRenewalNotAllowed):
+		conflictResponse(w, r, "loan can't be renewed")
+	default:
+		serverErrorResponse(w, r, err)
+	}
+}
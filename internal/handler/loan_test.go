@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/tredoc/go-crud-api/internal/service"
+	mockservice "github.com/tredoc/go-crud-api/mocks/service"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type loanHandlerSuite struct {
+	suite.Suite
+	usecase       *mockservice.Loan
+	handler       *LoanHandler
+	user          *types.User
+	testingServer *httptest.Server
+}
+
+func (s *loanHandlerSuite) SetupSuite() {
+	usecase := new(mockservice.Loan)
+	handler := NewLoanHandler(usecase)
+	user := &types.User{ID: 5, Email: "patron@example.com", Role: types.UserRole}
+
+	router := httprouter.New()
+	router.POST("/api/v1/loans", withUser(user, handler.Checkout))
+	router.GET("/api/v1/loans", handler.GetLoans)
+	router.POST("/api/v1/loans/:id/return", withUser(user, handler.ReturnLoan))
+	router.POST("/api/v1/loans/:id/renew", withUser(user, handler.RenewLoan))
+	router.GET("/api/v1/me/loans", withUser(user, handler.GetMyLoans))
+
+	testingServer := httptest.NewServer(router)
+
+	s.testingServer = testingServer
+	s.usecase = usecase
+	s.handler = handler
+	s.user = user
+}
+
+func (s *loanHandlerSuite) TearDownSuite() {
+	s.usecase.AssertExpectations(s.T())
+	defer s.testingServer.Close()
+}
+
+func (s *loanHandlerSuite) TestCheckout_Positive() {
+	checkout := types.Checkout{Barcode: "LIB-0001"}
+	loan := types.Loan{ID: 1, CopyID: 1, BookID: 1, UserID: s.user.ID, Barcode: "LIB-0001", CheckedOutAt: time.Now(), DueAt: time.Now().Add(types.LoanPeriod)}
+
+	s.usecase.On("Checkout", mock.AnythingOfType("*context.valueCtx"), s.user, &checkout).Return(&loan, nil)
+
+	requestBody, err := json.Marshal(&checkout)
+	s.NoError(err, "can`t marshal struct to json")
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/loans", s.testingServer.URL), "application/json", bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"loan": &loan,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusCreated, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *loanHandlerSuite) TestCheckout_CopyUnavailable() {
+	checkout := types.Checkout{Barcode: "LIB-0002"}
+
+	s.usecase.On("Checkout", mock.AnythingOfType("*context.valueCtx"), s.user, &checkout).Return(nil, service.ErrCopyUnavailable)
+
+	requestBody, err := json.Marshal(&checkout)
+	s.NoError(err, "can`t marshal struct to json")
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/loans", s.testingServer.URL), "application/json", bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusConflict, response.StatusCode)
+}
+
+func (s *loanHandlerSuite) TestRenewLoan_NotAllowed() {
+	id := int64(3)
+	s.usecase.On("RenewLoan", mock.AnythingOfType("*context.valueCtx"), id, s.user).Return(nil, service.ErrRenewalNotAllowed)
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/loans/%d/renew", s.testingServer.URL, id), "application/json", nil)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusConflict, response.StatusCode)
+}
+
+func (s *loanHandlerSuite) TestGetLoans_Overdue() {
+	loans := []*types.Loan{
+		{ID: 2, CopyID: 4, BookID: 1, UserID: 9, Barcode: "LIB-0004", CheckedOutAt: time.Now().Add(-30 * 24 * time.Hour), DueAt: time.Now().Add(-16 * 24 * time.Hour)},
+	}
+
+	s.usecase.On("GetLoans", mock.AnythingOfType("*context.cancelCtx"), types.OverdueLoan).Return(loans, nil)
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/loans?status=overdue", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"loans": loans,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *loanHandlerSuite) TestGetLoans_InvalidStatus() {
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/loans?status=lost", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusBadRequest, response.StatusCode)
+}
+
+func TestLoanHandler(t *testing.T) {
+	suite.Run(t, new(loanHandlerSuite))
+}
@@ -1,11 +1,16 @@
 package handler
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"github.com/julienschmidt/httprouter"
 	"github.com/pascaldekloe/jwt"
 	"github.com/tredoc/go-crud-api/internal/service"
+	"github.com/tredoc/go-crud-api/pkg/log"
 	"github.com/tredoc/go-crud-api/pkg/types"
+	"io"
 	"net/http"
 	"os"
 	"strconv"
@@ -13,12 +18,21 @@ import (
 	"time"
 )
 
+const (
+	maxIdempotencyKeyLength = 255
+
+	// maxIdempotentRequestSize is the largest body of a request with an Idempotency-Key, the one of
+	// the largest upload.
+	maxIdempotentRequestSize = maxImportSize
+)
+
 type Middleware struct {
-	service service.User
+	service     service.User
+	idempotency service.Idempotency
 }
 
-func NewMiddleware(service service.User) *Middleware {
-	return &Middleware{service: service}
+func NewMiddleware(service service.User, idempotency service.Idempotency) *Middleware {
+	return &Middleware{service: service, idempotency: idempotency}
 }
 
 func (m *Middleware) authMW(next httprouter.Handle) httprouter.Handle {
@@ -97,3 +111,195 @@ func (m *Middleware) adminOnlyMW(next httprouter.Handle) httprouter.Handle {
 		next(w, r, ps)
 	}
 }
+
+func (m *Middleware) authenticatedOnlyMW(next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		user := contextGetUser(r)
+		if user.IsAnonymous() {
+			authenticationRequiredResponse(w, r)
+			return
+		}
+		next(w, r, ps)
+	}
+}
+
+// localeMW stores the languages accepted by the client, most preferred first, in the request
+// context so that names and titles can be served translated.
+func (m *Middleware) localeMW(next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		w.Header().Add("Vary", "Accept-Language")
+		if acceptLanguage := r.Header.Get("Accept-Language"); acceptLanguage != "" {
+			r = contextSetLanguages(r, types.LanguageChain(acceptLanguage))
+		}
+		next(w, r, ps)
+	}
+}
+
+// conditionalMW gives successful reads an ETag derived from the body, Last-Modified reported by the
+// services and the Cache-Control policy of the route, and answers matching If-None-Match or
+// If-Modified-Since headers with 304 Not Modified.
+func (m *Middleware) conditionalMW(cacheControl string, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		var lastModified time.Time
+		buffered := &bufferedResponse{ResponseWriter: w, status: http.StatusOK}
+		next(buffered, contextSetLastModified(r, &lastModified), ps)
+
+		if buffered.status != http.StatusOK {
+			buffered.flush()
+			return
+		}
+
+		header := w.Header()
+		etag := contentETag(header.Get("ETag"), buffered.body.Bytes())
+		header.Set("ETag", etag)
+		if !lastModified.IsZero() {
+			header.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+		}
+		if cacheControl != "" {
+			header.Set("Cache-Control", cacheControl)
+		}
+
+		if notModified(r, etag, lastModified) {
+			header.Del("Content-Type")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		buffered.flush()
+	}
+}
+
+// idempotencyMW replays the stored response when a request is repeated with the same
+// Idempotency-Key header instead of handling it again. Keys are scoped to the authenticated user,
+// so the middleware goes after authMW and ignores the key of anonymous requests. Reusing a key for
+// another request is refused with 422, repeating a request that is still being handled with 409.
+// Server errors aren't stored, so such a request can be retried with its key. A replay is sent as
+// it was stored, in the media type negotiated for the first request.
+func (m *Middleware) idempotencyMW(next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		key := r.Header.Get("Idempotency-Key")
+		user := contextGetUser(r)
+		if key == "" || user.IsAnonymous() {
+			next(w, r, ps)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			badRequestResponse(w, r, fmt.Errorf("Idempotency-Key can't be longer than %d characters", maxIdempotencyKeyLength))
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentRequestSize))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				errorResponse(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("request can't be larger than %d bytes", maxIdempotentRequestSize))
+				return
+			}
+			badRequestResponse(w, r, errors.New("can't read request"))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		key = idempotencyScope(user) + ":" + key
+		fingerprint := requestFingerprint(r, body)
+		stored, err := m.idempotency.Begin(r.Context(), key, fingerprint)
+		switch {
+		case errors.Is(err, service.ErrIdempotencyKeyReused):
+			notValidResponse(w, r, map[string]string{"Idempotency-Key": "was already used for another request"})
+			return
+		case errors.Is(err, service.ErrRequestInProgress):
+			conflictResponse(w, r, "a request with this Idempotency-Key is still being handled")
+			return
+		case err != nil:
+			log.Error("idempotency error on begin: " + err.Error())
+			next(w, r, ps)
+			return
+		case stored != nil:
+			for name, values := range stored.Header {
+				w.Header()[name] = values
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.Status)
+			_, err = w.Write(stored.Body)
+			if err != nil {
+				log.Error(err.Error())
+			}
+			return
+		}
+
+		buffered := &bufferedResponse{ResponseWriter: w, status: http.StatusOK}
+		release := m.holdClaim(r.Context(), key)
+		next(buffered, r, ps)
+		release()
+
+		if buffered.status >= http.StatusInternalServerError {
+			m.idempotency.Abandon(r.Context(), key)
+		} else {
+			err = m.idempotency.Finish(r.Context(), key, &types.IdempotentResponse{
+				Fingerprint: fingerprint,
+				Status:      buffered.status,
+				Header:      w.Header().Clone(),
+				Body:        buffered.body.Bytes(),
+			})
+			if err != nil {
+				log.Error("idempotency error on finish: " + err.Error())
+			}
+		}
+
+		buffered.flush()
+	}
+}
+
+// holdClaim extends the claim of the key until the returned function is called, so that a retry
+// isn't handled anew while a slow request is still running.
+func (m *Middleware) holdClaim(ctx context.Context, key string) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(service.IdempotencyClaimRenewal)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				err := m.idempotency.Extend(ctx, key)
+				if err != nil {
+					log.Error("idempotency error on extend: " + err.Error())
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// bufferedResponse holds back the status and the body of a response until its validators are
+// known. Headers go straight to the underlying writer.
+type bufferedResponse struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	b.status = status
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) flush() {
+	b.ResponseWriter.WriteHeader(b.status)
+	_, err := b.ResponseWriter.Write(b.body.Bytes())
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
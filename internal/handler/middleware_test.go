@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/tredoc/go-crud-api/internal/service"
+	mockservice "github.com/tredoc/go-crud-api/mocks/service"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type conditionalMiddlewareSuite struct {
+	suite.Suite
+	usecase       *mockservice.Genre
+	testingServer *httptest.Server
+	modifiedAt    time.Time
+}
+
+func (s *conditionalMiddlewareSuite) SetupSuite() {
+	usecase := new(mockservice.Genre)
+	handler := NewGenreHandler(usecase)
+	mw := NewMiddleware(nil, nil)
+
+	router := httprouter.New()
+	router.GET("/api/v1/genres/:id", mw.conditionalMW("public, max-age=300", handler.GetGenreByID))
+
+	s.testingServer = httptest.NewServer(router)
+	s.usecase = usecase
+	s.modifiedAt = time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	reportModified := func(args mock.Arguments) {
+		types.SetLastModified(args.Get(0).(context.Context), s.modifiedAt)
+	}
+	usecase.On("GetGenreByID", mock.AnythingOfType("*context.valueCtx"), int64(1)).
+		Run(reportModified).Return(&types.Genre{ID: 1, Name: "fantasy", Version: 3}, nil)
+	usecase.On("GetGenreByID", mock.AnythingOfType("*context.valueCtx"), int64(2)).
+		Return(nil, service.ErrNotFound)
+}
+
+func (s *conditionalMiddlewareSuite) TearDownSuite() {
+	s.usecase.AssertExpectations(s.T())
+	defer s.testingServer.Close()
+}
+
+func (s *conditionalMiddlewareSuite) get(id int64, headers map[string]string) *http.Response {
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v1/genres/%d", s.testingServer.URL, id), nil)
+	s.NoError(err, "no error when preparing get request")
+
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	return response
+}
+
+func (s *conditionalMiddlewareSuite) TestValidators() {
+	response := s.get(1, nil)
+	defer response.Body.Close()
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.True(strings.HasPrefix(response.Header.Get("ETag"), `"3-`))
+	s.Equal("Wed, 01 May 2024 12:30:00 GMT", response.Header.Get("Last-Modified"))
+	s.Equal("public, max-age=300", response.Header.Get("Cache-Control"))
+}
+
+func (s *conditionalMiddlewareSuite) TestIfNoneMatch() {
+	first := s.get(1, nil)
+	first.Body.Close()
+	etag := first.Header.Get("ETag")
+
+	response := s.get(1, map[string]string{"If-None-Match": `"stale", ` + etag})
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	s.Equal(http.StatusNotModified, response.StatusCode)
+	s.Equal(etag, response.Header.Get("ETag"))
+	s.Empty(body)
+
+	response = s.get(1, map[string]string{"If-None-Match": `"stale"`})
+	defer response.Body.Close()
+
+	s.Equal(http.StatusOK, response.StatusCode)
+}
+
+func (s *conditionalMiddlewareSuite) TestIfModifiedSince() {
+	response := s.get(1, map[string]string{"If-Modified-Since": "Wed, 01 May 2024 12:30:00 GMT"})
+	defer response.Body.Close()
+
+	s.Equal(http.StatusNotModified, response.StatusCode)
+
+	response = s.get(1, map[string]string{"If-Modified-Since": "Wed, 01 May 2024 12:29:59 GMT"})
+	defer response.Body.Close()
+
+	s.Equal(http.StatusOK, response.StatusCode)
+}
+
+func (s *conditionalMiddlewareSuite) TestErrorsPassThrough() {
+	response := s.get(2, map[string]string{"If-None-Match": "*"})
+	defer response.Body.Close()
+
+	s.Equal(http.StatusNotFound, response.StatusCode)
+	s.Empty(response.Header.Get("ETag"))
+	s.Empty(response.Header.Get("Cache-Control"))
+}
+
+func TestConditionalMiddleware(t *testing.T) {
+	suite.Run(t, new(conditionalMiddlewareSuite))
+}
+
+type idempotencyMiddlewareSuite struct {
+	suite.Suite
+	usecase       *mockservice.Genre
+	idempotency   *mockservice.Idempotency
+	testingServer *httptest.Server
+}
+
+func (s *idempotencyMiddlewareSuite) SetupSuite() {
+	usecase := new(mockservice.Genre)
+	idempotency := new(mockservice.Idempotency)
+	handler := NewGenreHandler(usecase)
+	mw := NewMiddleware(nil, idempotency)
+
+	authenticated := func(next httprouter.Handle) httprouter.Handle {
+		return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+			next(w, contextSetUser(r, &types.User{ID: 7, Role: types.AdminRole}), ps)
+		}
+	}
+
+	router := httprouter.New()
+	router.POST("/api/v1/genres", authenticated(mw.idempotencyMW(handler.CreateGenre)))
+	router.POST("/api/v1/anonymous/genres", mw.idempotencyMW(handler.CreateGenre))
+
+	s.testingServer = httptest.NewServer(router)
+	s.usecase = usecase
+	s.idempotency = idempotency
+}
+
+func (s *idempotencyMiddlewareSuite) TearDownSuite() {
+	s.usecase.AssertExpectations(s.T())
+	s.idempotency.AssertExpectations(s.T())
+	defer s.testingServer.Close()
+}
+
+func (s *idempotencyMiddlewareSuite) post(key string, body string) *http.Response {
+	return s.postTo("/api/v1/genres", key, body)
+}
+
+func (s *idempotencyMiddlewareSuite) postTo(path string, key string, body string) *http.Response {
+	request, err := http.NewRequest(http.MethodPost, s.testingServer.URL+path, strings.NewReader(body))
+	s.NoError(err, "no error when preparing post request")
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Idempotency-Key", key)
+
+	response, err := http.DefaultClient.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	return response
+}
+
+func (s *idempotencyMiddlewareSuite) TestFirstRequest() {
+	genre := types.Genre{Name: "cyberpunk"}
+	s.usecase.On("CreateGenre", mock.AnythingOfType("*context.valueCtx"), &genre).
+		Return(&types.Genre{ID: 5, Name: "cyberpunk"}, nil).Once()
+
+	isKey := func(key string) bool {
+		return key == "user:7:first"
+	}
+	isStored := func(stored *types.IdempotentResponse) bool {
+		return stored.Status == http.StatusCreated && stored.Fingerprint != "" &&
+			string(stored.Body) == `{"genre":{"id":5,"name":"cyberpunk"}}` &&
+			stored.Header.Get("Content-Type") == "application/json"
+	}
+	s.idempotency.On("Begin", mock.AnythingOfType("*context.valueCtx"), mock.MatchedBy(isKey), mock.AnythingOfType("string")).Return(nil, nil).Once()
+	s.idempotency.On("Finish", mock.AnythingOfType("*context.valueCtx"), mock.MatchedBy(isKey), mock.MatchedBy(isStored)).Return(nil).Once()
+
+	response := s.post("first", `{"name": "cyberpunk"}`)
+	defer response.Body.Close()
+
+	s.Equal(http.StatusCreated, response.StatusCode)
+	s.Empty(response.Header.Get("Idempotent-Replayed"))
+}
+
+func (s *idempotencyMiddlewareSuite) TestReplay() {
+	stored := &types.IdempotentResponse{
+		Fingerprint: "fingerprint",
+		Status:      http.StatusCreated,
+		Header:      http.Header{"Content-Type": {"application/json"}},
+		Body:        []byte(`{"genre":{"id":6,"name":"steampunk"}}`),
+	}
+	isKey := func(key string) bool {
+		return strings.HasSuffix(key, ":repeated")
+	}
+	s.idempotency.On("Begin", mock.AnythingOfType("*context.valueCtx"), mock.MatchedBy(isKey), mock.AnythingOfType("string")).Return(stored, nil).Once()
+
+	response := s.post("repeated", `{"name": "steampunk"}`)
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	s.Equal(http.StatusCreated, response.StatusCode)
+	s.Equal("true", response.Header.Get("Idempotent-Replayed"))
+	s.Equal(string(stored.Body), string(body))
+	s.usecase.AssertNotCalled(s.T(), "CreateGenre", mock.Anything, &types.Genre{Name: "steampunk"})
+}
+
+func (s *idempotencyMiddlewareSuite) TestKeyReused() {
+	isKey := func(key string) bool {
+		return strings.HasSuffix(key, ":reused")
+	}
+	s.idempotency.On("Begin", mock.AnythingOfType("*context.valueCtx"), mock.MatchedBy(isKey), mock.AnythingOfType("string")).
+		Return(nil, service.ErrIdempotencyKeyReused).Once()
+
+	response := s.post("reused", `{"name": "solarpunk"}`)
+	defer response.Body.Close()
+
+	s.Equal(http.StatusUnprocessableEntity, response.StatusCode)
+}
+
+func (s *idempotencyMiddlewareSuite) TestInProgress() {
+	isKey := func(key string) bool {
+		return strings.HasSuffix(key, ":running")
+	}
+	s.idempotency.On("Begin", mock.AnythingOfType("*context.valueCtx"), mock.MatchedBy(isKey), mock.AnythingOfType("string")).
+		Return(nil, service.ErrRequestInProgress).Once()
+
+	response := s.post("running", `{"name": "dieselpunk"}`)
+	defer response.Body.Close()
+
+	s.Equal(http.StatusConflict, response.StatusCode)
+}
+
+func (s *idempotencyMiddlewareSuite) TestAnonymous() {
+	genre := types.Genre{Name: "biopunk"}
+	s.usecase.On("CreateGenre", mock.AnythingOfType("*context.cancelCtx"), &genre).
+		Return(&types.Genre{ID: 8, Name: "biopunk"}, nil).Once()
+
+	response := s.postTo("/api/v1/anonymous/genres", "anonymous", `{"name": "biopunk"}`)
+	defer response.Body.Close()
+
+	s.Equal(http.StatusCreated, response.StatusCode)
+	s.idempotency.AssertNotCalled(s.T(), "Begin", mock.Anything, mock.MatchedBy(func(key string) bool {
+		return strings.HasSuffix(key, ":anonymous")
+	}), mock.Anything)
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	suite.Run(t, new(idempotencyMiddlewareSuite))
+}
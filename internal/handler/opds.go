@@ -0,0 +1,337 @@
+package handler
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/tredoc/go-crud-api/internal/service"
+	"github.com/tredoc/go-crud-api/internal/validator"
+	"github.com/tredoc/go-crud-api/pkg/log"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// opdsRoot serves the OPDS 1.2 feeds, opds2Root the same feeds as OPDS 2.0.
+	opdsRoot  = "/api/v1/opds"
+	opds2Root = "/api/v1/opds2"
+
+	opdsPageSize = 50
+)
+
+// OPDSHandler serves the catalogue to e-reader apps. The catalogue has no cover images, so the
+// entries carry no image links.
+type OPDSHandler struct {
+	books   service.Book
+	genres  service.Genre
+	authors service.Author
+}
+
+func NewOPDSHandler(books service.Book, genres service.Genre, authors service.Author) *OPDSHandler {
+	return &OPDSHandler{
+		books:   books,
+		genres:  genres,
+		authors: authors,
+	}
+}
+
+// GetOPDSRoot godoc
+// @Summary Get the root of the OPDS catalogue
+// @Description Get the navigation feed that leads to the newest books, the books by genre and by author and the search. The feeds are served as OPDS 1.2 Atom under /api/v1/opds and as OPDS 2.0 JSON under /api/v1/opds2
+// @Tags opds
+// @ID get-opds-root
+// @Produce  application/atom+xml,application/opds+json
+// @Success 200 {string} string "Navigation feed"
+// @Router /api/v1/opds [get]
+func (h *OPDSHandler) GetOPDSRoot(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	feed := opdsFeed{
+		title: "Library catalogue",
+		navigation: []*opdsNavigation{
+			{title: "Newest additions", summary: "The books added last", path: "/new", rel: opdsSortNewRel, acquisition: true},
+			{title: "By genre", summary: "Browse the books by genre", path: "/genres", rel: "subsection"},
+			{title: "By author", summary: "Browse the books by author", path: "/authors", rel: "subsection"},
+		},
+	}
+
+	writeOPDSFeed(w, r, &feed)
+}
+
+// GetOPDSNewest godoc
+// @Summary Get the newest books of the OPDS catalogue
+// @Description Get the books that were added last as an acquisition feed
+// @Tags opds
+// @ID get-opds-newest
+// @Produce  application/atom+xml,application/opds+json
+// @Param page query int false "Page, 1 by default"
+// @Success 200 {string} string "Acquisition feed"
+// @Router /api/v1/opds/new [get]
+func (h *OPDSHandler) GetOPDSNewest(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	feed := opdsFeed{title: "Newest additions", path: "/new"}
+	h.writeBookFeed(w, r, &feed, &types.BookFilter{}, types.OrderByNewest)
+}
+
+// GetOPDSGenres godoc
+// @Summary Get the genres of the OPDS catalogue
+// @Description Get a navigation feed with an entry per genre
+// @Tags opds
+// @ID get-opds-genres
+// @Produce  application/atom+xml,application/opds+json
+// @Success 200 {string} string "Navigation feed"
+// @Router /api/v1/opds/genres [get]
+func (h *OPDSHandler) GetOPDSGenres(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	genres, err := h.genres.GetAllGenres(r.Context())
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	sort.SliceStable(genres, func(i, j int) bool {
+		return strings.ToLower(genres[i].Name) < strings.ToLower(genres[j].Name)
+	})
+
+	feed := opdsFeed{title: "Genres", path: "/genres"}
+	for _, genre := range genres {
+		feed.navigation = append(feed.navigation, &opdsNavigation{
+			title:       genre.Name,
+			summary:     fmt.Sprintf("Books of %s and its subgenres", genre.Name),
+			path:        fmt.Sprintf("/genres/%d", genre.ID),
+			rel:         "subsection",
+			acquisition: true,
+		})
+	}
+
+	writeOPDSFeed(w, r, &feed)
+}
+
+// GetOPDSGenreBooks godoc
+// @Summary Get the books of a genre of the OPDS catalogue
+// @Description Get the books of the genre and its subgenres by title as an acquisition feed
+// @Tags opds
+// @ID get-opds-genre-books
+// @Produce  application/atom+xml,application/opds+json
+// @Param id path int true "Genre ID"
+// @Param page query int false "Page, 1 by default"
+// @Success 200 {string} string "Acquisition feed"
+// @Router /api/v1/opds/genres/{id} [get]
+func (h *OPDSHandler) GetOPDSGenreBooks(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	genre, err := h.genres.GetGenreByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	feed := opdsFeed{title: genre.Name, path: fmt.Sprintf("/genres/%d", id)}
+	filter := types.BookFilter{GenreID: id, IncludeSubgenres: true}
+	h.writeBookFeed(w, r, &feed, &filter, types.OrderByTitle)
+}
+
+// GetOPDSAuthors godoc
+// @Summary Get the authors of the OPDS catalogue
+// @Description Get a navigation feed with an entry per author by last name
+// @Tags opds
+// @ID get-opds-authors
+// @Produce  application/atom+xml,application/opds+json
+// @Param page query int false "Page, 1 by default"
+// @Success 200 {string} string "Navigation feed"
+// @Router /api/v1/opds/authors [get]
+func (h *OPDSHandler) GetOPDSAuthors(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	page, ok := getOPDSPage(w, r)
+	if !ok {
+		return
+	}
+
+	authors, err := h.authors.GetAuthorPage(r.Context(), page, opdsPageSize)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	if isPastLastOPDSPage(page, authors.Total) {
+		notFoundResponse(w, r)
+		return
+	}
+
+	feed := opdsFeed{title: "Authors", path: "/authors", page: page, total: authors.Total}
+	for _, author := range authors.Authors {
+		name := author.FullName()
+		feed.navigation = append(feed.navigation, &opdsNavigation{
+			title:       name,
+			summary:     fmt.Sprintf("Books by %s", name),
+			path:        fmt.Sprintf("/authors/%d", author.ID),
+			rel:         "subsection",
+			acquisition: true,
+		})
+	}
+
+	writeOPDSFeed(w, r, &feed)
+}
+
+// GetOPDSAuthorBooks godoc
+// @Summary Get the books of an author of the OPDS catalogue
+// @Description Get the books of the author by title as an acquisition feed
+// @Tags opds
+// @ID get-opds-author-books
+// @Produce  application/atom+xml,application/opds+json
+// @Param id path int true "Author ID"
+// @Param page query int false "Page, 1 by default"
+// @Success 200 {string} string "Acquisition feed"
+// @Router /api/v1/opds/authors/{id} [get]
+func (h *OPDSHandler) GetOPDSAuthorBooks(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	author, err := h.authors.GetAuthorByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	feed := opdsFeed{title: author.FullName(), path: fmt.Sprintf("/authors/%d", id)}
+	h.writeBookFeed(w, r, &feed, &types.BookFilter{AuthorID: id}, types.OrderByTitle)
+}
+
+// SearchOPDS godoc
+// @Summary Search the OPDS catalogue
+// @Description Get the books with the text in their title or in the name of one of their authors by title as an acquisition feed
+// @Tags opds
+// @ID search-opds
+// @Produce  application/atom+xml,application/opds+json
+// @Param q query string true "Text in the title or in the name of an author"
+// @Param page query int false "Page, 1 by default"
+// @Success 200 {string} string "Acquisition feed"
+// @Router /api/v1/opds/search [get]
+func (h *OPDSHandler) SearchOPDS(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	filter := types.BookFilter{TagMatch: types.MatchAnyTag, Query: strings.TrimSpace(r.URL.Query().Get("q"))}
+	v := validator.New()
+	v.Check(filter.Query != "", "q", validator.CantBeEmpty)
+	types.ValidateBookFilter(v, &filter)
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return
+	}
+
+	feed := opdsFeed{
+		title: fmt.Sprintf("Search for %q", filter.Query),
+		path:  "/search",
+		query: url.Values{"q": {filter.Query}},
+	}
+	h.writeBookFeed(w, r, &feed, &filter, types.OrderByTitle)
+}
+
+// GetOpenSearchDescription godoc
+// @Summary Get the OpenSearch description of the catalogue
+// @Description Get the OpenSearch description that tells e-reader apps how to search the OPDS feeds and the book list
+// @Tags opds
+// @ID get-opensearch-description
+// @Produce  application/opensearchdescription+xml
+// @Success 200 {string} string "OpenSearch description"
+// @Router /api/v1/opds/opensearch.xml [get]
+func (h *OPDSHandler) GetOpenSearchDescription(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	origin := newOPDSLinks(r, opdsRoot).origin
+	description := openSearchDescription{
+		Xmlns:         "http://a9.com/-/spec/opensearch/1.1/",
+		ShortName:     "Library",
+		Description:   "Search the books of the library by title and author",
+		InputEncoding: "UTF-8",
+		URLs: []*openSearchURL{
+			{Type: atomAcquisitionType, Template: openSearchTemplate(origin, opdsRoot+"/search", true)},
+			{Type: opds2Type, Template: openSearchTemplate(origin, opds2Root+"/search", true)},
+			{Type: "application/json", Template: openSearchTemplate(origin, "/api/v1/books", false)},
+		},
+	}
+
+	body, err := xml.Marshal(description)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", openSearchType)
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(append([]byte(xml.Header), body...))
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// writeBookFeed fills the feed with the page of the books the request asks for and writes it.
+func (h *OPDSHandler) writeBookFeed(w http.ResponseWriter, r *http.Request, feed *opdsFeed, filter *types.BookFilter, order types.BookOrder) {
+	page, ok := getOPDSPage(w, r)
+	if !ok {
+		return
+	}
+
+	books, err := h.books.GetBookPage(r.Context(), filter, order, page, opdsPageSize)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	if isPastLastOPDSPage(page, books.Total) {
+		notFoundResponse(w, r)
+		return
+	}
+
+	feed.acquisition = true
+	feed.books = books.Books
+	feed.page = page
+	feed.total = books.Total
+	writeOPDSFeed(w, r, feed)
+}
+
+// writeOPDSFeed writes the feed in the OPDS version of the route the request came in on.
+func writeOPDSFeed(w http.ResponseWriter, r *http.Request, feed *opdsFeed) {
+	var err error
+	if strings.HasPrefix(r.URL.Path, opds2Root) {
+		err = writeOPDS2Feed(w, newOPDSLinks(r, opds2Root), feed)
+	} else {
+		err = writeAtomFeed(w, newOPDSLinks(r, opdsRoot), feed)
+	}
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+func getOPDSPage(w http.ResponseWriter, r *http.Request) (int, bool) {
+	param := r.URL.Query().Get("page")
+	if param == "" {
+		return 1, true
+	}
+
+	page, err := strconv.Atoi(param)
+	if err != nil || page < 1 || page > math.MaxInt/opdsPageSize {
+		badRequestResponse(w, r, errors.New("invalid page parameter"))
+		return 0, false
+	}
+
+	return page, true
+}
+
+// isPastLastOPDSPage reports whether the page comes after the last one of total entries. The first
+// page of an empty feed exists.
+func isPastLastOPDSPage(page int, total int) bool {
+	return page > 1 && (page-1)*opdsPageSize >= total
+}
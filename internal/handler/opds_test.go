@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	mockservice "github.com/tredoc/go-crud-api/mocks/service"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type opdsHandlerSuite struct {
+	suite.Suite
+	books         *mockservice.Book
+	genres        *mockservice.Genre
+	authors       *mockservice.Author
+	handler       *OPDSHandler
+	testingServer *httptest.Server
+}
+
+func (s *opdsHandlerSuite) SetupSuite() {
+	books := new(mockservice.Book)
+	genres := new(mockservice.Genre)
+	authors := new(mockservice.Author)
+	handler := NewOPDSHandler(books, genres, authors)
+
+	router := httprouter.New()
+	for _, root := range []string{opdsRoot, opds2Root} {
+		router.GET(root, handler.GetOPDSRoot)
+		router.GET(root+"/new", handler.GetOPDSNewest)
+		router.GET(root+"/genres", handler.GetOPDSGenres)
+		router.GET(root+"/genres/:id", handler.GetOPDSGenreBooks)
+		router.GET(root+"/authors", handler.GetOPDSAuthors)
+		router.GET(root+"/authors/:id", handler.GetOPDSAuthorBooks)
+		router.GET(root+"/search", handler.SearchOPDS)
+	}
+	router.GET(opdsRoot+"/opensearch.xml", handler.GetOpenSearchDescription)
+
+	testingServer := httptest.NewServer(router)
+
+	s.testingServer = testingServer
+	s.books = books
+	s.genres = genres
+	s.authors = authors
+	s.handler = handler
+}
+
+func (s *opdsHandlerSuite) TearDownSuite() {
+	s.books.AssertExpectations(s.T())
+	s.genres.AssertExpectations(s.T())
+	s.authors.AssertExpectations(s.T())
+	defer s.testingServer.Close()
+}
+
+func (s *opdsHandlerSuite) TestGetOPDSNewest_Atom() {
+	parsedTime, _ := time.Parse(time.DateOnly, "2006-01-01")
+	page := types.BookPage{
+		Books: []*types.ExportedBook{{ID: 1, Title: "Go mechanics", PublishDate: types.CustomDate{Time: parsedTime}, ISBN: "9780134190440",
+			Pages: 520, CreatedAt: time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC), Authors: []string{"Rob Pike"}, Genres: []string{"programming"}}},
+		Total: 51,
+	}
+	s.books.On("GetBookPage", mock.AnythingOfType("*context.cancelCtx"), &types.BookFilter{}, types.OrderByNewest, 2, opdsPageSize).
+		Return(&page, nil).Once()
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/opds/new?page=2", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	var feed struct {
+		TotalResults int `xml:"totalResults"`
+		Links        []struct {
+			Rel  string `xml:"rel,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+		Entries []struct {
+			Title      string `xml:"title"`
+			Identifier string `xml:"identifier"`
+			Author     string `xml:"author>name"`
+		} `xml:"entry"`
+	}
+	err = xml.NewDecoder(response.Body).Decode(&feed)
+	s.NoError(err, "can`t decode response")
+
+	rels := make(map[string]string)
+	for _, link := range feed.Links {
+		rels[link.Rel] = link.Href
+	}
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(atomAcquisitionType, response.Header.Get("Content-Type"))
+	s.Equal(51, feed.TotalResults)
+	s.Equal(s.testingServer.URL+"/api/v1/opds/new?page=1", rels["previous"])
+	s.Equal(s.testingServer.URL+"/api/v1/opds/new?page=2", rels["last"])
+	s.NotContains(rels, "next")
+	s.Len(feed.Entries, 1)
+	s.Equal("urn:isbn:9780134190440", feed.Entries[0].Identifier)
+	s.Equal("Rob Pike", feed.Entries[0].Author)
+}
+
+func (s *opdsHandlerSuite) TestSearchOPDS_OPDS2() {
+	filter := types.BookFilter{TagMatch: types.MatchAnyTag, Query: "le guin"}
+	page := types.BookPage{
+		Books: []*types.ExportedBook{{ID: 2, Title: "The Dispossessed", Pages: 387, Authors: []string{"Ursula K. Le Guin"}, Genres: []string{}}},
+		Total: 120,
+	}
+	s.books.On("GetBookPage", mock.AnythingOfType("*context.cancelCtx"), &filter, types.OrderByTitle, 1, opdsPageSize).
+		Return(&page, nil).Once()
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/opds2/search?q=le+guin", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	var feed opds2Feed
+	err = json.NewDecoder(response.Body).Decode(&feed)
+	s.NoError(err, "can`t decode response")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(opds2Type, response.Header.Get("Content-Type"))
+	s.Equal(120, *feed.Metadata.NumberOfItems)
+	s.Contains(feed.Links, &opds2Link{Rel: "next", Href: s.testingServer.URL + "/api/v1/opds2/search?page=2&q=le+guin", Type: opds2Type})
+	s.Contains(feed.Links, &opds2Link{Rel: "search", Href: s.testingServer.URL + "/api/v1/opds2/search{?q}", Type: opds2Type, Templated: true})
+	s.Len(feed.Publications, 1)
+	s.Equal("The Dispossessed", feed.Publications[0].Metadata.Title)
+	s.Equal(uint16(387), feed.Publications[0].Metadata.NumberOfPages)
+}
+
+func (s *opdsHandlerSuite) TestSearchOPDS_EmptyQuery() {
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/opds/search?q=+", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusUnprocessableEntity, response.StatusCode)
+}
+
+func (s *opdsHandlerSuite) TestGetOPDSAuthors() {
+	page := types.AuthorPage{
+		Authors: []*types.Author{
+			{ID: 5, FirstName: "Alan", LastName: "Donovan"},
+			{ID: 3, FirstName: "Ursula", MiddleName: "K.", LastName: "Le Guin"},
+			{ID: 4, FirstName: "Rob", LastName: "Pike"},
+		},
+		Total: 3,
+	}
+	s.authors.On("GetAuthorPage", mock.AnythingOfType("*context.cancelCtx"), 1, opdsPageSize).Return(&page, nil).Once()
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/opds2/authors", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	var feed opds2Feed
+	err = json.NewDecoder(response.Body).Decode(&feed)
+	s.NoError(err, "can`t decode response")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Len(feed.Navigation, 3)
+	s.Equal("Alan Donovan", feed.Navigation[0].Title)
+	s.Equal(s.testingServer.URL+"/api/v1/opds2/authors/3", feed.Navigation[1].Href)
+	s.Nil(feed.Publications)
+}
+
+func (s *opdsHandlerSuite) TestGetOPDSAuthors_PastLastPage() {
+	page := types.AuthorPage{Authors: []*types.Author{}, Total: 50}
+	s.authors.On("GetAuthorPage", mock.AnythingOfType("*context.cancelCtx"), 2, opdsPageSize).Return(&page, nil).Once()
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/opds2/authors?page=2", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusNotFound, response.StatusCode)
+}
+
+func (s *opdsHandlerSuite) TestGetOPDSNewest_PageTooLarge() {
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/opds/new?page=%d", s.testingServer.URL, math.MaxInt/opdsPageSize+1))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusBadRequest, response.StatusCode)
+}
+
+func (s *opdsHandlerSuite) TestGetOpenSearchDescription() {
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/opds/opensearch.xml", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(openSearchType, response.Header.Get("Content-Type"))
+	s.True(strings.Contains(string(body), `template="`+s.testingServer.URL+`/api/v1/opds/search?q={searchTerms}&amp;page={startPage?}"`))
+	s.True(strings.Contains(string(body), `template="`+s.testingServer.URL+`/api/v1/books?q={searchTerms}"`))
+}
+
+func TestOPDSHandler(t *testing.T) {
+	suite.Run(t, new(opdsHandlerSuite))
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/tredoc/go-crud-api/internal/service"
+	"github.com/tredoc/go-crud-api/internal/validator"
+	"github.com/tredoc/go-crud-api/pkg/log"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"net/http"
+)
+
+type ReviewHandler struct {
+	service service.Review
+}
+
+func NewReviewHandler(service service.Review) *ReviewHandler {
+	return &ReviewHandler{
+		service: service,
+	}
+}
+
+// CreateReview godoc
+// @Summary Review a book
+// @Description Post a rating and review text for a book, one review per user and book
+// @Tags reviews
+// @ID create-review
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Book ID"
+// @Param review body types.Review true "Review object that needs to be added"
+// @Security Bearer
+// @Success 201 {object} types.Review
+// @Router /api/v1/books/{id}/reviews [post]
+func (h *ReviewHandler) CreateReview(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	bookID, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	var review types.Review
+	err = json.NewDecoder(r.Body).Decode(&review)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("can't decode request"))
+		return
+	}
+
+	v := validator.New()
+	types.ValidateReview(v, &review)
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return
+	}
+
+	review.BookID = bookID
+	review.UserID = contextGetUser(r).ID
+
+	newReview, err := h.service.CreateReview(r.Context(), &review)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrEntityExists):
+			conflictResponse(w, r, "you have already reviewed this book")
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusCreated, envelope{"review": newReview}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// GetBookReviews godoc
+// @Summary Get reviews of a book
+// @Description Get a list of all reviews of a book, newest first
+// @Tags reviews
+// @ID get-book-reviews
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param id path int true "Book ID"
+// @Success 200 {array} []types.Review
+// @Router /api/v1/books/{id}/reviews [get]
+func (h *ReviewHandler) GetBookReviews(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	bookID, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	reviews, err := h.service.GetReviewsByBookID(r.Context(), bookID)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"reviews": reviews}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// UpdateReview godoc
+// @Summary Update a review
+// @Description Update a review with a specific ID, allowed for its author and admins
+// @Tags reviews
+// @ID update-review
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Review ID"
+// @Param review body types.UpdateReview true "Review object that needs to be updated"
+// @Security Bearer
+// @Success 200 {object} types.Review
+// @Router /api/v1/reviews/{id} [patch]
+func (h *ReviewHandler) UpdateReview(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	var review types.UpdateReview
+	err = json.NewDecoder(r.Body).Decode(&review)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("can't decode request"))
+		return
+	}
+
+	v := validator.New()
+	types.ValidateUpdateReview(v, &review)
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return
+	}
+
+	updatedReview, err := h.service.UpdateReview(r.Context(), id, contextGetUser(r), &review)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrNotPermitted):
+			insufficientPermissionsResponse(w, r)
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"review": updatedReview}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// DeleteReview godoc
+// @Summary Delete a review
+// @Description Delete a review with a specific ID, allowed for its author and admins
+// @Tags reviews
+// @ID delete-review
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Review ID"
+// @Security Bearer
+// @Success 204 "No Content"
+// @Router /api/v1/reviews/{id} [delete]
+func (h *ReviewHandler) DeleteReview(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	err = h.service.DeleteReview(r.Context(), id, contextGetUser(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrNotPermitted):
+			insufficientPermissionsResponse(w, r)
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/tredoc/go-crud-api/internal/service"
+	mockservice "github.com/tredoc/go-crud-api/mocks/service"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type reviewHandlerSuite struct {
+	suite.Suite
+	usecase       *mockservice.Review
+	handler       *ReviewHandler
+	user          *types.User
+	testingServer *httptest.Server
+}
+
+func (s *reviewHandlerSuite) SetupSuite() {
+	usecase := new(mockservice.Review)
+	handler := NewReviewHandler(usecase)
+	user := &types.User{ID: 7, Email: "reader@example.com", Role: types.UserRole}
+
+	router := httprouter.New()
+	router.POST("/api/v1/books/:id/reviews", withUser(user, handler.CreateReview))
+	router.GET("/api/v1/books/:id/reviews", handler.GetBookReviews)
+	router.PATCH("/api/v1/reviews/:id", withUser(user, handler.UpdateReview))
+	router.DELETE("/api/v1/reviews/:id", withUser(user, handler.DeleteReview))
+
+	testingServer := httptest.NewServer(router)
+
+	s.testingServer = testingServer
+	s.usecase = usecase
+	s.handler = handler
+	s.user = user
+}
+
+func (s *reviewHandlerSuite) TearDownSuite() {
+	s.usecase.AssertExpectations(s.T())
+	defer s.testingServer.Close()
+}
+
+func (s *reviewHandlerSuite) TestCreateReview_Positive() {
+	review := types.Review{Rating: 5, Text: "Great read"}
+	expectedReview := types.Review{BookID: 1, UserID: s.user.ID, Rating: 5, Text: "Great read"}
+	newReview := types.Review{ID: 1, BookID: 1, UserID: s.user.ID, Rating: 5, Text: "Great read", CreatedAt: time.Now()}
+
+	s.usecase.On("CreateReview", mock.AnythingOfType("*context.valueCtx"), &expectedReview).Return(&newReview, nil)
+
+	requestBody, err := json.Marshal(&review)
+	s.NoError(err, "can`t marshal struct to json")
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/books/%d/reviews", s.testingServer.URL, 1), "application/json", bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"review": &newReview,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusCreated, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *reviewHandlerSuite) TestCreateReview_AlreadyReviewed() {
+	review := types.Review{Rating: 4, Text: "Read it twice"}
+	expectedReview := types.Review{BookID: 2, UserID: s.user.ID, Rating: 4, Text: "Read it twice"}
+
+	s.usecase.On("CreateReview", mock.AnythingOfType("*context.valueCtx"), &expectedReview).Return(nil, service.ErrEntityExists).Once()
+
+	requestBody, err := json.Marshal(&review)
+	s.NoError(err, "can`t marshal struct to json")
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/books/%d/reviews", s.testingServer.URL, 2), "application/json", bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusConflict, response.StatusCode)
+}
+
+func (s *reviewHandlerSuite) TestCreateReview_InvalidRating() {
+	review := types.Review{Rating: 6, Text: "Too good"}
+
+	requestBody, err := json.Marshal(&review)
+	s.NoError(err, "can`t marshal struct to json")
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/books/%d/reviews", s.testingServer.URL, 1), "application/json", bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusUnprocessableEntity, response.StatusCode)
+}
+
+func (s *reviewHandlerSuite) TestGetBookReviews_Positive() {
+	bookID := int64(2)
+	reviews := []*types.Review{
+		{ID: 1, BookID: bookID, UserID: 1, Rating: 4, Text: "Good", CreatedAt: time.Now()},
+		{ID: 2, BookID: bookID, UserID: 2, Rating: 2, Text: "Meh", CreatedAt: time.Now()},
+	}
+
+	s.usecase.On("GetReviewsByBookID", mock.AnythingOfType("*context.cancelCtx"), bookID).Return(reviews, nil)
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/books/%d/reviews", s.testingServer.URL, bookID))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"reviews": reviews,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *reviewHandlerSuite) TestUpdateReview_NotOwner() {
+	id := int64(3)
+	text := "Changed my mind"
+	upd := types.UpdateReview{Text: &text}
+
+	s.usecase.On("UpdateReview", mock.AnythingOfType("*context.valueCtx"), id, s.user, &upd).Return(nil, service.ErrNotPermitted)
+
+	requestBody, err := json.Marshal(&upd)
+	s.NoError(err, "can`t marshal struct to json")
+
+	request, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("%s/api/v1/reviews/%d", s.testingServer.URL, id), bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when preparing patch request")
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusUnauthorized, response.StatusCode)
+}
+
+func (s *reviewHandlerSuite) TestDeleteReview_Positive() {
+	id := int64(1)
+	s.usecase.On("DeleteReview", mock.AnythingOfType("*context.valueCtx"), id, s.user).Return(nil)
+
+	request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/v1/reviews/%d", s.testingServer.URL, id), nil)
+	s.NoError(err, "no error when preparing delete request")
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+
+	s.Equal(http.StatusNoContent, response.StatusCode)
+}
+
+func TestReviewHandler(t *testing.T) {
+	suite.Run(t, new(reviewHandlerSuite))
+}
@@ -0,0 +1,334 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/tredoc/go-crud-api/internal/service"
+	"github.com/tredoc/go-crud-api/internal/validator"
+	"github.com/tredoc/go-crud-api/pkg/log"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type ShelfHandler struct {
+	service service.Shelf
+}
+
+func NewShelfHandler(service service.Shelf) *ShelfHandler {
+	return &ShelfHandler{
+		service: service,
+	}
+}
+
+// GetShelves godoc
+// @Summary Get my shelves
+// @Description Get the built-in want-to-read, reading and read shelves and custom shelves of the current user
+// @Tags shelves
+// @ID get-shelves
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Security Bearer
+// @Success 200 {array} []types.Shelf
+// @Router /api/v1/me/shelves [get]
+func (h *ShelfHandler) GetShelves(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	shelves, err := h.service.GetShelves(r.Context(), contextGetUser(r).ID)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"shelves": shelves}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// CreateShelf godoc
+// @Summary Create a custom shelf
+// @Description Create a custom named shelf for the current user
+// @Tags shelves
+// @ID create-shelf
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param shelf body types.Shelf true "Shelf object that needs to be added"
+// @Security Bearer
+// @Success 201 {object} types.Shelf
+// @Router /api/v1/me/shelves [post]
+func (h *ShelfHandler) CreateShelf(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var shelf types.Shelf
+	err := json.NewDecoder(r.Body).Decode(&shelf)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("can't decode request"))
+		return
+	}
+
+	v := validator.New()
+	types.ValidateShelf(v, &shelf)
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return
+	}
+
+	newShelf, err := h.service.CreateShelf(r.Context(), contextGetUser(r).ID, &shelf)
+	if err != nil {
+		if errors.Is(err, service.ErrEntityExists) {
+			badRequestResponse(w, r, fmt.Errorf("shelf '%s' already exists", shelf.Name))
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusCreated, envelope{"shelf": newShelf}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// DeleteShelf godoc
+// @Summary Delete a custom shelf
+// @Description Delete a custom shelf of the current user, built-in shelves can't be deleted
+// @Tags shelves
+// @ID delete-shelf
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param shelf path string true "Custom shelf ID"
+// @Security Bearer
+// @Success 204 "No Content"
+// @Router /api/v1/me/shelves/{shelf} [delete]
+func (h *ShelfHandler) DeleteShelf(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	shelf, err := getShelfParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	if shelf.IsBuiltIn() {
+		badRequestResponse(w, r, errors.New("built-in shelves can't be deleted"))
+		return
+	}
+
+	err = h.service.DeleteShelf(r.Context(), contextGetUser(r).ID, shelf.ID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetShelfBooks godoc
+// @Summary Get books on a shelf
+// @Description Get books on a built-in shelf (want-to-read, reading, read) or on a custom shelf by ID
+// @Tags shelves
+// @ID get-shelf-books
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param shelf path string true "Built-in shelf name or custom shelf ID"
+// @Security Bearer
+// @Success 200 {array} []types.ShelvedBook
+// @Router /api/v1/me/shelves/{shelf} [get]
+func (h *ShelfHandler) GetShelfBooks(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	shelf, err := getShelfParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	books, err := h.service.GetShelfBooks(r.Context(), contextGetUser(r).ID, shelf)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"books": books}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// AddBookToShelf godoc
+// @Summary Put a book on a shelf
+// @Description Put a book on a shelf, moving it between built-in shelves updates its reading dates
+// @Tags shelves
+// @ID add-book-to-shelf
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param shelf path string true "Built-in shelf name or custom shelf ID"
+// @Param book body types.ShelfBook true "Book that needs to be shelved"
+// @Security Bearer
+// @Success 204 "No Content"
+// @Router /api/v1/me/shelves/{shelf}/books [post]
+func (h *ShelfHandler) AddBookToShelf(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	shelf, err := getShelfParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	var book types.ShelfBook
+	err = json.NewDecoder(r.Body).Decode(&book)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("can't decode request"))
+		return
+	}
+
+	v := validator.New()
+	types.ValidateShelfBook(v, &book)
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return
+	}
+
+	err = h.service.AddBookToShelf(r.Context(), contextGetUser(r).ID, shelf, book.BookID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrEntityExists):
+			badRequestResponse(w, r, errors.New("book is already on this shelf"))
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveBookFromShelf godoc
+// @Summary Remove a book from a shelf
+// @Description Remove a book from a built-in or custom shelf
+// @Tags shelves
+// @ID remove-book-from-shelf
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param shelf path string true "Built-in shelf name or custom shelf ID"
+// @Param id path int true "Book ID"
+// @Security Bearer
+// @Success 204 "No Content"
+// @Router /api/v1/me/shelves/{shelf}/books/{id} [delete]
+func (h *ShelfHandler) RemoveBookFromShelf(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	shelf, err := getShelfParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	bookID, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	err = h.service.RemoveBookFromShelf(r.Context(), contextGetUser(r).ID, shelf, bookID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UpdateReading godoc
+// @Summary Update reading progress
+// @Description Update status, current page and start/finish dates of a book the current user reads
+// @Tags shelves
+// @ID update-reading
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Book ID"
+// @Param reading body types.UpdateReading true "Reading progress that needs to be updated"
+// @Security Bearer
+// @Success 200 {object} types.ReadingEntry
+// @Router /api/v1/me/reading/{id} [patch]
+func (h *ShelfHandler) UpdateReading(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	bookID, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	var reading types.UpdateReading
+	err = json.NewDecoder(r.Body).Decode(&reading)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("can't decode request"))
+		return
+	}
+
+	v := validator.New()
+	types.ValidateUpdateReading(v, &reading)
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return
+	}
+
+	entry, err := h.service.UpdateReading(r.Context(), contextGetUser(r).ID, bookID, &reading)
+	if err != nil {
+		var validationErr *service.ValidationError
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.As(err, &validationErr):
+			notValidResponse(w, r, validationErr.Errors)
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"reading": entry}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// GetReadingStats godoc
+// @Summary Get yearly reading statistics
+// @Description Get number of books and pages read per year and month by the current user
+// @Tags shelves
+// @ID get-reading-stats
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param year query int false "Year, current year by default"
+// @Security Bearer
+// @Success 200 {object} types.ReadingStats
+// @Router /api/v1/me/stats [get]
+func (h *ShelfHandler) GetReadingStats(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	year := time.Now().Year()
+	if param := r.URL.Query().Get("year"); param != "" {
+		parsedYear, err := strconv.Atoi(param)
+		if err != nil || parsedYear < 1 || parsedYear > 9999 {
+			badRequestResponse(w, r, errors.New("invalid year parameter"))
+			return
+		}
+		year = parsedYear
+	}
+
+	stats, err := h.service.GetReadingStats(r.Context(), contextGetUser(r).ID, year)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"stats": stats}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/tredoc/go-crud-api/internal/service"
+	mockservice "github.com/tredoc/go-crud-api/mocks/service"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type shelfHandlerSuite struct {
+	suite.Suite
+	usecase       *mockservice.Shelf
+	handler       *ShelfHandler
+	user          *types.User
+	testingServer *httptest.Server
+}
+
+func (s *shelfHandlerSuite) SetupSuite() {
+	usecase := new(mockservice.Shelf)
+	handler := NewShelfHandler(usecase)
+	user := &types.User{ID: 3, Email: "reader@example.com", Role: types.UserRole}
+
+	router := httprouter.New()
+	router.GET("/api/v1/me/shelves", withUser(user, handler.GetShelves))
+	router.POST("/api/v1/me/shelves", withUser(user, handler.CreateShelf))
+	router.GET("/api/v1/me/shelves/:shelf", withUser(user, handler.GetShelfBooks))
+	router.DELETE("/api/v1/me/shelves/:shelf", withUser(user, handler.DeleteShelf))
+	router.POST("/api/v1/me/shelves/:shelf/books", withUser(user, handler.AddBookToShelf))
+	router.DELETE("/api/v1/me/shelves/:shelf/books/:id", withUser(user, handler.RemoveBookFromShelf))
+	router.PATCH("/api/v1/me/reading/:id", withUser(user, handler.UpdateReading))
+	router.GET("/api/v1/me/stats", withUser(user, handler.GetReadingStats))
+
+	testingServer := httptest.NewServer(router)
+
+	s.testingServer = testingServer
+	s.usecase = usecase
+	s.handler = handler
+	s.user = user
+}
+
+func (s *shelfHandlerSuite) TearDownSuite() {
+	s.usecase.AssertExpectations(s.T())
+	defer s.testingServer.Close()
+}
+
+func (s *shelfHandlerSuite) TestGetShelves_Positive() {
+	shelves := []*types.Shelf{
+		{Name: "want-to-read", Status: types.WantToReadShelf, BooksCount: 2},
+		{Name: "reading", Status: types.ReadingShelf, BooksCount: 1},
+		{Name: "read", Status: types.ReadShelf, BooksCount: 0},
+		{ID: 1, Name: "favourites", BooksCount: 5},
+	}
+
+	s.usecase.On("GetShelves", mock.AnythingOfType("*context.valueCtx"), s.user.ID).Return(shelves, nil)
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/me/shelves", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"shelves": shelves,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *shelfHandlerSuite) TestCreateShelf_ReservedName() {
+	requestBody, err := json.Marshal(&types.Shelf{Name: "reading"})
+	s.NoError(err, "can`t marshal struct to json")
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/me/shelves", s.testingServer.URL), "application/json", bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusUnprocessableEntity, response.StatusCode)
+}
+
+func (s *shelfHandlerSuite) TestAddBookToShelf_BuiltIn() {
+	shelf := types.ShelfRef{Status: types.ReadingShelf}
+	s.usecase.On("AddBookToShelf", mock.AnythingOfType("*context.valueCtx"), s.user.ID, shelf, int64(4)).Return(nil)
+
+	requestBody, err := json.Marshal(&types.ShelfBook{BookID: 4})
+	s.NoError(err, "can`t marshal struct to json")
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/me/shelves/reading/books", s.testingServer.URL), "application/json", bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusNoContent, response.StatusCode)
+}
+
+func (s *shelfHandlerSuite) TestDeleteShelf_BuiltIn() {
+	request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/v1/me/shelves/read", s.testingServer.URL), nil)
+	s.NoError(err, "no error when preparing delete request")
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusBadRequest, response.StatusCode)
+}
+
+func (s *shelfHandlerSuite) TestUpdateReading_PageOutOfRange() {
+	bookID := int64(9)
+	page := uint16(1200)
+	upd := types.UpdateReading{CurrentPage: &page}
+	validationErr := &service.ValidationError{Errors: map[string]string{"current_page": "can't be bigger than book pages"}}
+
+	s.usecase.On("UpdateReading", mock.AnythingOfType("*context.valueCtx"), s.user.ID, bookID, &upd).Return(nil, validationErr)
+
+	requestBody, err := json.Marshal(&upd)
+	s.NoError(err, "can`t marshal struct to json")
+
+	request, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("%s/api/v1/me/reading/%d", s.testingServer.URL, bookID), bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when preparing patch request")
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"error": validationErr.Errors,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusUnprocessableEntity, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *shelfHandlerSuite) TestGetReadingStats_Positive() {
+	stats := types.ReadingStats{Year: 2023, BooksRead: 3, PagesRead: 900}
+	stats.BooksByMonth[0] = 3
+
+	s.usecase.On("GetReadingStats", mock.AnythingOfType("*context.valueCtx"), s.user.ID, 2023).Return(&stats, nil)
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/me/stats?year=2023", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"stats": &stats,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func TestShelfHandler(t *testing.T) {
+	suite.Run(t, new(shelfHandlerSuite))
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/tredoc/go-crud-api/internal/service"
+	"github.com/tredoc/go-crud-api/internal/validator"
+	"github.com/tredoc/go-crud-api/pkg/log"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"net/http"
+	"strconv"
+)
+
+const defaultTagCloudLimit = 50
+
+type TagHandler struct {
+	service service.Tag
+}
+
+func NewTagHandler(service service.Tag) *TagHandler {
+	return &TagHandler{
+		service: service,
+	}
+}
+
+// AddBookTags godoc
+// @Summary Tag a book
+// @Description Apply free-form tags to a book, tags are lowercased and may contain unicode letters, digits, spaces and hyphens
+// @Tags tags
+// @ID add-book-tags
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param id path int true "Book ID"
+// @Param tags body types.BookTags true "Tags that need to be applied"
+// @Security Bearer
+// @Success 200 {array} string
+// @Router /api/v1/books/{id}/tags [post]
+func (h *TagHandler) AddBookTags(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	bookID, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	bookTags, ok := decodeBookTags(w, r)
+	if !ok {
+		return
+	}
+
+	tags, err := h.service.AddBookTags(r.Context(), bookID, contextGetUser(r).ID, bookTags.Tags)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"tags": tags}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// RemoveBookTags godoc
+// @Summary Remove tags from a book
+// @Description Remove tags from a book, users may only remove tags they applied, admins may remove any tag
+// @Tags tags
+// @ID remove-book-tags
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Book ID"
+// @Param tags body types.BookTags true "Tags that need to be removed"
+// @Security Bearer
+// @Success 204 "No Content"
+// @Router /api/v1/books/{id}/tags [delete]
+func (h *TagHandler) RemoveBookTags(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	bookID, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	bookTags, ok := decodeBookTags(w, r)
+	if !ok {
+		return
+	}
+
+	err = h.service.RemoveBookTags(r.Context(), bookID, contextGetUser(r), bookTags.Tags)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetTagCloud godoc
+// @Summary Get tag cloud
+// @Description Get the most used tags with the number of books they are applied to
+// @Tags tags
+// @ID get-tag-cloud
+// @Accept  json
+// @Produce  json,xml,text/csv,application/msgpack
+// @Param limit query int false "Number of tags, 50 by default, 200 at most"
+// @Success 200 {array} []types.TagCount
+// @Router /api/v1/tags [get]
+func (h *TagHandler) GetTagCloud(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	limit := defaultTagCloudLimit
+	if param := r.URL.Query().Get("limit"); param != "" {
+		parsedLimit, err := strconv.Atoi(param)
+		if err != nil || parsedLimit < 1 || parsedLimit > 200 {
+			badRequestResponse(w, r, errors.New("invalid limit parameter"))
+			return
+		}
+		limit = parsedLimit
+	}
+
+	tags, err := h.service.GetTagCloud(r.Context(), limit)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"tags": tags}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+func decodeBookTags(w http.ResponseWriter, r *http.Request) (*types.BookTags, bool) {
+	var bookTags types.BookTags
+	err := json.NewDecoder(r.Body).Decode(&bookTags)
+	if err != nil {
+		badRequestResponse(w, r, errors.New("can't decode request"))
+		return nil, false
+	}
+
+	bookTags.Tags = types.NormalizeTags(bookTags.Tags)
+
+	v := validator.New()
+	types.ValidateBookTags(v, &bookTags)
+	if !v.IsValid() {
+		notValidResponse(w, r, v.Errors)
+		return nil, false
+	}
+
+	return &bookTags, true
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/tredoc/go-crud-api/internal/service"
+	mockservice "github.com/tredoc/go-crud-api/mocks/service"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type tagHandlerSuite struct {
+	suite.Suite
+	usecase       *mockservice.Tag
+	handler       *TagHandler
+	user          *types.User
+	testingServer *httptest.Server
+}
+
+func (s *tagHandlerSuite) SetupSuite() {
+	usecase := new(mockservice.Tag)
+	handler := NewTagHandler(usecase)
+	user := &types.User{ID: 5, Email: "patron@example.com", Role: types.UserRole}
+
+	router := httprouter.New()
+	router.POST("/api/v1/books/:id/tags", withUser(user, handler.AddBookTags))
+	router.DELETE("/api/v1/books/:id/tags", withUser(user, handler.RemoveBookTags))
+	router.GET("/api/v1/tags", handler.GetTagCloud)
+
+	testingServer := httptest.NewServer(router)
+
+	s.testingServer = testingServer
+	s.usecase = usecase
+	s.handler = handler
+	s.user = user
+}
+
+func (s *tagHandlerSuite) TearDownSuite() {
+	s.usecase.AssertExpectations(s.T())
+	defer s.testingServer.Close()
+}
+
+func (s *tagHandlerSuite) TestAddBookTags_Positive() {
+	bookID := int64(1)
+	bookTags := types.BookTags{Tags: []string{" Science-Fiction ", "Космос", "science-fiction"}}
+	tags := []string{"classic", "science-fiction", "космос"}
+
+	s.usecase.On("AddBookTags", mock.AnythingOfType("*context.valueCtx"), bookID, s.user.ID, []string{"science-fiction", "космос"}).Return(tags, nil)
+
+	requestBody, err := json.Marshal(&bookTags)
+	s.NoError(err, "can`t marshal struct to json")
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/books/%d/tags", s.testingServer.URL, bookID), "application/json", bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"tags": tags,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *tagHandlerSuite) TestAddBookTags_NotValid() {
+	bookTags := types.BookTags{Tags: []string{"sci_fi!"}}
+
+	requestBody, err := json.Marshal(&bookTags)
+	s.NoError(err, "can`t marshal struct to json")
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/books/1/tags", s.testingServer.URL), "application/json", bytes.NewBuffer(requestBody))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusUnprocessableEntity, response.StatusCode)
+}
+
+func (s *tagHandlerSuite) TestRemoveBookTags_NotFound() {
+	bookID := int64(2)
+	bookTags := types.BookTags{Tags: []string{"classic"}}
+
+	s.usecase.On("RemoveBookTags", mock.AnythingOfType("*context.valueCtx"), bookID, s.user, bookTags.Tags).Return(service.ErrNotFound)
+
+	requestBody, err := json.Marshal(&bookTags)
+	s.NoError(err, "can`t marshal struct to json")
+
+	request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/v1/books/%d/tags", s.testingServer.URL, bookID), bytes.NewBuffer(requestBody))
+	s.NoError(err, "can`t create request")
+
+	response, err := http.DefaultClient.Do(request)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusNotFound, response.StatusCode)
+}
+
+func (s *tagHandlerSuite) TestGetTagCloud_Positive() {
+	tags := []*types.TagCount{
+		{Name: "classic", Count: 12},
+		{Name: "space opera", Count: 4},
+	}
+
+	s.usecase.On("GetTagCloud", mock.AnythingOfType("*context.cancelCtx"), 10).Return(tags, nil)
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/tags?limit=10", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"tags": tags,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func TestTagHandler(t *testing.T) {
+	suite.Run(t, new(tagHandlerSuite))
+}
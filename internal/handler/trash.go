@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/tredoc/go-crud-api/internal/service"
+	"github.com/tredoc/go-crud-api/pkg/log"
+	"net/http"
+)
+
+type TrashHandler struct {
+	service service.Trash
+}
+
+func NewTrashHandler(service service.Trash) *TrashHandler {
+	return &TrashHandler{
+		service: service,
+	}
+}
+
+// GetTrash godoc
+// @Summary Get the trash
+// @Description Get deleted books, authors and genres that can still be restored, the most recently deleted first
+// @Tags trash
+// @ID get-trash
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Security Bearer
+// @Success 200 {object} types.Trash
+// @Router /api/v1/trash [get]
+func (h *TrashHandler) GetTrash(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	trash, err := h.service.GetTrash(r.Context())
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = writeResponse(w, r, http.StatusOK, envelope{"trash": trash}, nil)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// RestoreBook godoc
+// @Summary Restore a deleted book
+// @Description Restore a book from the trash together with its authors, genres, tags and copies
+// @Tags trash
+// @ID restore-book
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Book ID"
+// @Security Bearer
+// @Success 204 "No Content"
+// @Router /api/v1/books/{id}/restore [post]
+func (h *TrashHandler) RestoreBook(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	h.restore(w, r, ps, h.service.RestoreBook, "")
+}
+
+// RestoreAuthor godoc
+// @Summary Restore a deleted author
+// @Description Restore an author from the trash and list it on its books again
+// @Tags trash
+// @ID restore-author
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Author ID"
+// @Security Bearer
+// @Success 204 "No Content"
+// @Router /api/v1/authors/{id}/restore [post]
+func (h *TrashHandler) RestoreAuthor(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	h.restore(w, r, ps, h.service.RestoreAuthor, "an author with the same name was added after the author was deleted")
+}
+
+// RestoreGenre godoc
+// @Summary Restore a deleted genre
+// @Description Restore a genre from the trash, list it on its books again and put its subgenres back under it
+// @Tags trash
+// @ID restore-genre
+// @Accept  json
+// @Produce  json,xml,application/msgpack
+// @Param id path int true "Genre ID"
+// @Security Bearer
+// @Success 204 "No Content"
+// @Router /api/v1/genres/{id}/restore [post]
+func (h *TrashHandler) RestoreGenre(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	h.restore(w, r, ps, h.service.RestoreGenre, "a genre with the same name was added after the genre was deleted")
+}
+
+func (h *TrashHandler) restore(w http.ResponseWriter, r *http.Request, ps httprouter.Params, restore func(context.Context, int64) error, conflict string) {
+	id, err := getIdParam(ps)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	err = restore(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, service.ErrEntityExists):
+			conflictResponse(w, r, conflict)
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/tredoc/go-crud-api/internal/service"
+	mockservice "github.com/tredoc/go-crud-api/mocks/service"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type trashHandlerSuite struct {
+	suite.Suite
+	usecase       *mockservice.Trash
+	handler       *TrashHandler
+	testingServer *httptest.Server
+}
+
+func (s *trashHandlerSuite) SetupSuite() {
+	usecase := new(mockservice.Trash)
+	handler := NewTrashHandler(usecase)
+
+	router := httprouter.New()
+	router.GET("/api/v1/trash", handler.GetTrash)
+	router.POST("/api/v1/books/:id/restore", handler.RestoreBook)
+	router.POST("/api/v1/authors/:id/restore", handler.RestoreAuthor)
+	router.POST("/api/v1/genres/:id/restore", handler.RestoreGenre)
+
+	testingServer := httptest.NewServer(router)
+
+	s.testingServer = testingServer
+	s.usecase = usecase
+	s.handler = handler
+}
+
+func (s *trashHandlerSuite) TearDownSuite() {
+	s.usecase.AssertExpectations(s.T())
+	defer s.testingServer.Close()
+}
+
+func (s *trashHandlerSuite) TestGetTrash_Positive() {
+	deletedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	trash := &types.Trash{
+		Books:   []*types.TrashItem{{ID: 1, Name: "Dune", DeletedAt: deletedAt}},
+		Authors: []*types.TrashItem{},
+		Genres:  []*types.TrashItem{{ID: 3, Name: "space opera", DeletedAt: deletedAt}},
+	}
+
+	s.usecase.On("GetTrash", mock.AnythingOfType("*context.cancelCtx")).Return(trash, nil)
+
+	response, err := http.Get(fmt.Sprintf("%s/api/v1/trash", s.testingServer.URL))
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	s.NoError(err, "can`t get string from response")
+
+	expected, err := json.Marshal(map[string]any{
+		"trash": trash,
+	})
+	s.NoError(err, "can`t convert expected map to json")
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal(string(result), string(expected))
+}
+
+func (s *trashHandlerSuite) TestRestoreBook_Positive() {
+	id := int64(1)
+
+	s.usecase.On("RestoreBook", mock.AnythingOfType("*context.cancelCtx"), id).Return(nil)
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/books/%d/restore", s.testingServer.URL, id), "application/json", nil)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusNoContent, response.StatusCode)
+}
+
+func (s *trashHandlerSuite) TestRestoreBook_NotFound() {
+	id := int64(2)
+
+	s.usecase.On("RestoreBook", mock.AnythingOfType("*context.cancelCtx"), id).Return(service.ErrNotFound)
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/books/%d/restore", s.testingServer.URL, id), "application/json", nil)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusNotFound, response.StatusCode)
+}
+
+func (s *trashHandlerSuite) TestRestoreAuthor_Conflict() {
+	id := int64(4)
+
+	s.usecase.On("RestoreAuthor", mock.AnythingOfType("*context.cancelCtx"), id).Return(service.ErrEntityExists)
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/authors/%d/restore", s.testingServer.URL, id), "application/json", nil)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusConflict, response.StatusCode)
+}
+
+func (s *trashHandlerSuite) TestRestoreGenre_Positive() {
+	id := int64(3)
+
+	s.usecase.On("RestoreGenre", mock.AnythingOfType("*context.cancelCtx"), id).Return(nil)
+
+	response, err := http.Post(fmt.Sprintf("%s/api/v1/genres/%d/restore", s.testingServer.URL, id), "application/json", nil)
+	s.NoError(err, "no error when calling the endpoint")
+	defer response.Body.Close()
+
+	s.Equal(http.StatusNoContent, response.StatusCode)
+}
+
+func TestTrashHandler(t *testing.T) {
+	suite.Run(t, new(trashHandlerSuite))
+}
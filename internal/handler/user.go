@@ -28,7 +28,7 @@ func NewUserHandler(service service.User) *UserHandler {
 // @TAGS user
 // @ID register-user
 // @Accept  json
-// @Produce  json
+// @Produce  json,xml,application/msgpack
 // @Param user body types.AuthUser true "User object that needs to be registered"
 // @Success 201 {object} types.AuthUser
 // @Router /api/v1/users/register [post]
@@ -57,7 +57,7 @@ func (h *UserHandler) RegisterUser(w http.ResponseWriter, r *http.Request, _ htt
 		return
 	}
 
-	err = writeJSON(w, http.StatusCreated, envelope{"user": newUser}, nil)
+	err = writeResponse(w, r, http.StatusCreated, envelope{"user": newUser}, nil)
 	if err != nil {
 		log.Error(err.Error())
 	}
@@ -69,7 +69,7 @@ func (h *UserHandler) RegisterUser(w http.ResponseWriter, r *http.Request, _ htt
 // @TAGS user
 // @ID login-user
 // @Accept  json
-// @Produce  json
+// @Produce  json,xml,application/msgpack
 // @Param user body types.AuthUser true "User object that needs to log in"
 // @Success 200 {object} map[string]string "{ \"access_token\": \"token\" }"
 // @Router /api/v1/users/login [post]
@@ -98,7 +98,7 @@ func (h *UserHandler) LoginUser(w http.ResponseWriter, r *http.Request, _ httpro
 		return
 	}
 
-	err = writeJSON(w, http.StatusOK, envelope{"access_token": token}, nil)
+	err = writeResponse(w, r, http.StatusOK, envelope{"access_token": token}, nil)
 	if err != nil {
 		log.Error(err.Error())
 	}
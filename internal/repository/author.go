@@ -5,11 +5,14 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"github.com/tredoc/go-crud-api/pkg/log"
+	"github.com/lib/pq"
 	"github.com/tredoc/go-crud-api/pkg/types"
-	"strings"
 )
 
+const authorColumns = `a.id, a.first_name, COALESCE(a.middle_name, ''), a.last_name, a.birth_date, a.death_date, a.nationality, a.biography,
+	COALESCE(a.isni, ''), COALESCE(a.viaf, ''), COALESCE(a.wikidata, ''), a.version,
+	ARRAY(SELECT al.name FROM author_aliases AS al WHERE al.author_id = a.id ORDER BY al.name)`
+
 type AuthorRepository struct {
 	db *sql.DB
 }
@@ -20,23 +23,26 @@ func NewAuthorRepository(db *sql.DB) *AuthorRepository {
 	}
 }
 
+// CreateAuthor stores a new author unless an author with the same first and last name exists,
+// names are compared ignoring case and accents.
 func (r *AuthorRepository) CreateAuthor(ctx context.Context, author *types.Author) (int64, error) {
-	stmt := `SELECT id FROM authors WHERE first_name = $1 AND last_name = $2`
-	row := r.db.QueryRowContext(ctx, stmt, author.FirstName, author.LastName)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
 
-	var foundAuthorID int64
-	err := row.Scan(&foundAuthorID)
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+	id, err := createAuthor(ctx, tx, author)
+	if err != nil {
 		return 0, err
 	}
 
-	if foundAuthorID != 0 {
-		return 0, ErrEntityExists
+	err = recordAuthorRevision(ctx, tx, id, types.RevisionCreate)
+	if err != nil {
+		return 0, err
 	}
 
-	var id int64
-	stmt = `INSERT INTO authors (first_name, middle_name, last_name) VALUES ($1, $2, $3) RETURNING id`
-	err = r.db.QueryRowContext(ctx, stmt, author.FirstName, author.MiddleName, author.LastName).Scan(&id)
+	err = tx.Commit()
 	if err != nil {
 		return 0, err
 	}
@@ -44,12 +50,39 @@ func (r *AuthorRepository) CreateAuthor(ctx context.Context, author *types.Autho
 	return id, nil
 }
 
+func createAuthor(ctx context.Context, tx *sql.Tx, author *types.Author) (int64, error) {
+	firstNameKey, lastNameKey := types.FoldName(author.FirstName), types.FoldName(author.LastName)
+	err := checkAuthorName(ctx, tx, 0, firstNameKey, lastNameKey)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	stmt := `
+		INSERT INTO authors (first_name, middle_name, last_name, first_name_key, last_name_key,
+		birth_date, death_date, nationality, biography, isni, viaf, wikidata)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, version`
+	err = tx.QueryRowContext(ctx, stmt, author.FirstName, stringToNullString(author.MiddleName), author.LastName, firstNameKey, lastNameKey,
+		dateToNullString(author.BirthDate), dateToNullString(author.DeathDate), author.Nationality, author.Biography,
+		stringToNullString(author.ISNI), stringToNullString(author.VIAF), stringToNullString(author.Wikidata)).Scan(&id, &author.Version)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, ErrEntityExists
+		}
+		return 0, err
+	}
+
+	return id, saveAuthorAliases(ctx, tx, id, author.Aliases)
+}
+
+// GetAuthorByID returns the author, IDs of authors merged into another one resolve to the target.
 func (r *AuthorRepository) GetAuthorByID(ctx context.Context, id int64) (*types.Author, error) {
-	stmt := `SELECT id, first_name, middle_name, last_name FROM authors WHERE id = $1`
+	stmt := fmt.Sprintf(`
+		SELECT %s FROM authors AS a
+		WHERE a.id = COALESCE((SELECT target_id FROM author_redirects WHERE source_id = $1), $1) AND a.deleted_at IS NULL`, authorColumns)
 	row := r.db.QueryRowContext(ctx, stmt, id)
 
-	var author types.Author
-	err := row.Scan(&author.ID, &author.FirstName, &author.MiddleName, &author.LastName)
+	author, err := scanAuthor(row)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -57,133 +90,500 @@ func (r *AuthorRepository) GetAuthorByID(ctx context.Context, id int64) (*types.
 		return nil, err
 	}
 
-	return &author, nil
+	return author, nil
 }
 
+// GetAuthorsByIDs returns the authors with the IDs, IDs of authors merged into another one resolve
+// to the target.
 func (r *AuthorRepository) GetAuthorsByIDs(ctx context.Context, ids []int64) ([]*types.Author, error) {
-	placeholders := make([]string, len(ids))
-	for idx := range ids {
-		placeholders[idx] = fmt.Sprintf("$%d", idx+1)
+	stmt := fmt.Sprintf(`
+		SELECT %s FROM authors AS a
+		WHERE a.id IN (SELECT COALESCE((SELECT target_id FROM author_redirects WHERE source_id = r.id), r.id) FROM unnest($1::bigint[]) AS r(id))
+		AND a.deleted_at IS NULL`, authorColumns)
+	return r.queryAuthors(ctx, stmt, pq.Array(ids))
+}
+
+// GetAuthorByName looks the author up by first and last name or by an alias made of them,
+// ignoring case and accents.
+func (r *AuthorRepository) GetAuthorByName(ctx context.Context, firstName string, lastName string) (*types.Author, error) {
+	stmt := fmt.Sprintf(`
+		SELECT %s FROM authors AS a
+		WHERE a.deleted_at IS NULL AND ((a.first_name_key = $1 AND a.last_name_key = $2)
+		OR a.id = (SELECT author_id FROM author_aliases WHERE name_key = $3))`, authorColumns)
+	row := r.db.QueryRowContext(ctx, stmt, types.FoldName(firstName), types.FoldName(lastName), types.FoldName(firstName+" "+lastName))
+
+	author, err := scanAuthor(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
 	}
 
-	placeholder := strings.Join(placeholders, ",")
-	stmt := fmt.Sprintf("SELECT id, first_name, middle_name, last_name FROM authors WHERE id IN (%s)", placeholder)
-	args := make([]interface{}, len(ids))
-	for i, id := range ids {
-		args[i] = id
+	return author, nil
+}
+
+// FindAuthorByName resolves a full name or an alias, e.g. a pen name, to the canonical author.
+func (r *AuthorRepository) FindAuthorByName(ctx context.Context, name string) (*types.Author, error) {
+	stmt := fmt.Sprintf(`
+		SELECT %s FROM authors AS a
+		WHERE a.deleted_at IS NULL AND (a.first_name_key || ' ' || a.last_name_key = $1
+		OR a.id = (SELECT author_id FROM author_aliases WHERE name_key = $1))
+		LIMIT 1`, authorColumns)
+	row := r.db.QueryRowContext(ctx, stmt, types.FoldName(name))
+
+	author, err := scanAuthor(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
 	}
-	rows, err := r.db.QueryContext(ctx, stmt, args...)
+
+	return author, nil
+}
+
+func (r *AuthorRepository) GetAllAuthors(ctx context.Context) ([]*types.Author, error) {
+	stmt := fmt.Sprintf(`SELECT %s FROM authors AS a WHERE a.deleted_at IS NULL`, authorColumns)
+	return r.queryAuthors(ctx, stmt)
+}
+
+// GetAuthorPage returns the authors by last and first name from offset on, at most limit of them,
+// and how many there are in all.
+func (r *AuthorRepository) GetAuthorPage(ctx context.Context, limit int, offset int) (*types.AuthorPage, error) {
+	var page types.AuthorPage
+	err := r.db.QueryRowContext(ctx, `SELECT count(*) FROM authors WHERE deleted_at IS NULL`).Scan(&page.Total)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var authors []*types.Author
-	for rows.Next() {
-		var author types.Author
-		err := rows.Scan(&author.ID, &author.FirstName, &author.MiddleName, &author.LastName)
+	stmt := fmt.Sprintf(`
+		SELECT %s FROM authors AS a WHERE a.deleted_at IS NULL
+		ORDER BY a.last_name_key, a.first_name_key, a.id LIMIT $1 OFFSET $2`, authorColumns)
+	page.Authors, err = r.queryAuthors(ctx, stmt, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+// UpdateAuthor overwrites the author when it's still at author.Version, a zero version matches
+// any, and sets author.Version to the bumped one. An author changed meanwhile fails with
+// ErrVersionMismatch. The change is recorded as a revision with the given action.
+func (r *AuthorRepository) UpdateAuthor(ctx context.Context, id int64, author *types.Author, action types.RevisionAction) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	version, err := updateAuthor(ctx, tx, id, author)
+	if err != nil {
+		return err
+	}
+
+	err = recordAuthorRevision(ctx, tx, id, action)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	author.Version = version
+	return nil
+}
+
+// UpsertAuthor replaces the author like UpdateAuthor does or, when there is none with the ID,
+// creates the author under it. It reports whether the author was created.
+func (r *AuthorRepository) UpsertAuthor(ctx context.Context, id int64, author *types.Author) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	exists, err := lockForUpsert(ctx, tx, "authors", "author_redirects", id, author.Version)
+	if err != nil {
+		return false, err
+	}
+
+	var version int64
+	if exists {
+		version, err = updateAuthor(ctx, tx, id, author)
 		if err != nil {
-			return nil, err
+			return false, err
+		}
+	} else {
+		firstNameKey, lastNameKey := types.FoldName(author.FirstName), types.FoldName(author.LastName)
+		err = checkAuthorName(ctx, tx, id, firstNameKey, lastNameKey)
+		if err != nil {
+			return false, err
 		}
 
-		authors = append(authors, &author)
+		stmt := `
+			INSERT INTO authors (id, first_name, middle_name, last_name, first_name_key, last_name_key,
+			birth_date, death_date, nationality, biography, isni, viaf, wikidata)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING version`
+		err = tx.QueryRowContext(ctx, stmt, id, author.FirstName, stringToNullString(author.MiddleName), author.LastName, firstNameKey, lastNameKey,
+			dateToNullString(author.BirthDate), dateToNullString(author.DeathDate), author.Nationality, author.Biography,
+			stringToNullString(author.ISNI), stringToNullString(author.VIAF), stringToNullString(author.Wikidata)).Scan(&version)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return false, ErrEntityExists
+			}
+			return false, err
+		}
+
+		err = syncSequence(ctx, tx, "authors", id)
+		if err != nil {
+			return false, err
+		}
+
+		err = saveAuthorAliases(ctx, tx, id, author.Aliases)
+		if err != nil {
+			return false, err
+		}
 	}
 
-	return authors, nil
+	action := types.RevisionUpdate
+	if !exists {
+		action = types.RevisionCreate
+	}
+
+	err = recordAuthorRevision(ctx, tx, id, action)
+	if err != nil {
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+
+	author.Version = version
+	return !exists, nil
 }
 
-func (r *AuthorRepository) GetAuthorByName(ctx context.Context, firstName string, lastName string) (*types.Author, error) {
-	stmt := `SELECT id, first_name, middle_name, last_name FROM authors WHERE first_name = $1 AND last_name = $2`
-	row := r.db.QueryRowContext(ctx, stmt, firstName, lastName)
+func updateAuthor(ctx context.Context, tx *sql.Tx, id int64, author *types.Author) (int64, error) {
+	firstNameKey, lastNameKey := types.FoldName(author.FirstName), types.FoldName(author.LastName)
+	err := checkAuthorName(ctx, tx, id, firstNameKey, lastNameKey)
+	if err != nil {
+		return 0, err
+	}
 
-	var author types.Author
-	err := row.Scan(&author.ID, &author.FirstName, &author.MiddleName, &author.LastName)
+	stmt := `
+		UPDATE authors SET first_name = $1, middle_name = $2, last_name = $3, first_name_key = $4, last_name_key = $5,
+		birth_date = $6, death_date = $7, nationality = $8, biography = $9, isni = $10, viaf = $11, wikidata = $12,
+		version = version + 1
+		WHERE id = $13 AND deleted_at IS NULL AND ($14 = 0 OR version = $14)
+		RETURNING version`
+	var version int64
+	err = tx.QueryRowContext(ctx, stmt, author.FirstName, stringToNullString(author.MiddleName), author.LastName, firstNameKey, lastNameKey,
+		dateToNullString(author.BirthDate), dateToNullString(author.DeathDate), author.Nationality, author.Biography,
+		stringToNullString(author.ISNI), stringToNullString(author.VIAF), stringToNullString(author.Wikidata), id, author.Version).Scan(&version)
 	if err != nil {
-		if errors.Is(row.Err(), sql.ErrNoRows) {
-			return nil, ErrNotFound
+		if isUniqueViolation(err) {
+			return 0, ErrEntityExists
 		}
-		return nil, err
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, versionConflict(ctx, tx, "authors", id)
+		}
+		return 0, err
 	}
 
-	return &author, nil
+	stmt = `DELETE FROM author_aliases WHERE author_id = $1`
+	_, err = tx.ExecContext(ctx, stmt, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return version, saveAuthorAliases(ctx, tx, id, author.Aliases)
 }
 
-func (r *AuthorRepository) GetAllAuthors(ctx context.Context) ([]*types.Author, error) {
-	stmt := `SELECT id, first_name, middle_name, last_name FROM authors`
-	rows, err := r.db.QueryContext(ctx, stmt)
+// DeleteAuthor moves the author to the trash and returns IDs of the books that no longer list
+// the author. Relations are kept for a restore, PurgeTrash removes the author for good. With the
+// restrict policy an author listed on books fails with ErrEntityInUse. A non-zero version has to
+// match the one of the author.
+func (r *AuthorRepository) DeleteAuthor(ctx context.Context, id int64, version int64, policy types.DeletePolicy) ([]int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	var authors []*types.Author
-	for rows.Next() {
-		var author types.Author
-		err := rows.Scan(&author.ID, &author.FirstName, &author.MiddleName, &author.LastName)
-		if err != nil {
-			return nil, err
-		}
-		authors = append(authors, &author)
+	bookIDs, err := deleteAuthor(ctx, tx, id, version, policy)
+	if err != nil {
+		return nil, err
 	}
-	return authors, nil
+
+	err = recordAuthorRevision(ctx, tx, id, types.RevisionDelete)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return bookIDs, nil
 }
 
-func (r *AuthorRepository) UpdateAuthor(ctx context.Context, id int64, author *types.Author) error {
-	stmt := `UPDATE authors SET first_name = $1, middle_name = $2, last_name = $3 WHERE id = $4`
-	res, err := r.db.ExecContext(ctx, stmt, author.FirstName, author.MiddleName, author.LastName, id)
+func deleteAuthor(ctx context.Context, tx *sql.Tx, id int64, version int64, policy types.DeletePolicy) ([]int64, error) {
+	stmt := `UPDATE authors SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2)`
+	res, err := tx.ExecContext(ctx, stmt, id, version)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	rowsAffected, err := res.RowsAffected()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if rowsAffected == 0 {
-		return ErrNotFound
+		return nil, versionConflict(ctx, tx, "authors", id)
 	}
 
-	return nil
+	bookIDs, err := queryInt64s(ctx, tx, `
+		SELECT ba.book_id FROM book_author AS ba JOIN books AS b ON b.id = ba.book_id
+		WHERE ba.author_id = $1 AND b.deleted_at IS NULL`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy == types.RestrictOnDelete && len(bookIDs) > 0 {
+		return nil, ErrEntityInUse
+	}
+
+	return bookIDs, nil
+}
+
+// ApplyAuthorBatch applies the operations in a single transaction as runBatch describes. Authors
+// to update come with all their fields and the version they are expected at, deletions follow the
+// policy.
+func (r *AuthorRepository) ApplyAuthorBatch(ctx context.Context, operations []*types.AuthorOperation, atomic bool, policy types.DeletePolicy) ([]error, error) {
+	return runBatch(ctx, r.db, len(operations), atomic, func(tx *sql.Tx, i int) error {
+		op := operations[i]
+		switch op.Op {
+		case types.BatchCreate:
+			id, err := createAuthor(ctx, tx, op.Author)
+			if err != nil {
+				return err
+			}
+
+			op.Author.ID = id
+			op.ID, op.Version = id, op.Author.Version
+		case types.BatchUpdate:
+			version, err := updateAuthor(ctx, tx, op.ID, op.Author)
+			if err != nil {
+				return err
+			}
+
+			op.Author.Version = version
+			op.Version = version
+		case types.BatchDelete:
+			bookIDs, err := deleteAuthor(ctx, tx, op.ID, op.Version, policy)
+			if err != nil {
+				return err
+			}
+
+			op.BookIDs = bookIDs
+		}
+
+		return recordAuthorRevision(ctx, tx, op.ID, batchRevisionAction(op.Op))
+	})
 }
 
-func (r *AuthorRepository) DeleteAuthor(ctx context.Context, id int64) error {
+// MergeAuthors moves books and aliases of the source author to the target, keeps the source
+// name as an alias of the target and leaves a redirect from the source ID. The target gets a new
+// version. Both authors get a merge revision, the source one as it was before the merge. It
+// returns IDs of the books whose authors changed.
+func (r *AuthorRepository) MergeAuthors(ctx context.Context, sourceID int64, targetID int64) ([]int64, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer tx.Rollback()
 
-	stmt := `DELETE FROM book_author WHERE author_id = $1`
-	res, err := tx.ExecContext(ctx, stmt, id)
+	// Both authors are locked in the order of their IDs, so merges running the other way round wait
+	// for each other instead of deadlocking.
+	stmt := `SELECT id FROM authors WHERE id IN ($1, $2) AND deleted_at IS NULL ORDER BY id FOR UPDATE`
+	lockedIDs, err := queryInt64s(ctx, tx, stmt, sourceID, targetID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	rowsAffected, err := res.RowsAffected()
+	if len(lockedIDs) != 2 {
+		return nil, ErrNotFound
+	}
+
+	err = recordAuthorRevision(ctx, tx, sourceID, types.RevisionMerge)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if rowsAffected == 0 {
-		log.Info("no rows affected on book_author relation delete")
+	stmt = `UPDATE authors SET version = version + 1 WHERE id = $1`
+	_, err = tx.ExecContext(ctx, stmt, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt = `SELECT first_name, last_name FROM authors WHERE id = $1`
+	var source types.Author
+	err = tx.QueryRowContext(ctx, stmt, sourceID).Scan(&source.FirstName, &source.LastName)
+	if err != nil {
+		return nil, err
+	}
+
+	bookIDs, err := queryInt64s(ctx, tx, `SELECT book_id FROM book_author WHERE author_id = $1`, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt = `DELETE FROM book_author WHERE author_id = $1 AND book_id IN (SELECT book_id FROM book_author WHERE author_id = $2)`
+	_, err = tx.ExecContext(ctx, stmt, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt = `UPDATE book_author SET author_id = $1 WHERE author_id = $2`
+	_, err = tx.ExecContext(ctx, stmt, targetID, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt = `UPDATE author_aliases SET author_id = $1 WHERE author_id = $2`
+	_, err = tx.ExecContext(ctx, stmt, targetID, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceName := source.FullName()
+	stmt = `INSERT INTO author_aliases(author_id, name, name_key) VALUES($1, $2, $3) ON CONFLICT (name_key) DO NOTHING`
+	_, err = tx.ExecContext(ctx, stmt, targetID, sourceName, types.FoldName(sourceName))
+	if err != nil {
+		return nil, err
+	}
+
+	stmt = `UPDATE author_redirects SET target_id = $1 WHERE target_id = $2`
+	_, err = tx.ExecContext(ctx, stmt, targetID, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt = `INSERT INTO author_redirects(source_id, target_id) VALUES($1, $2)`
+	_, err = tx.ExecContext(ctx, stmt, sourceID, targetID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrEntityExists
+		}
+		return nil, err
 	}
 
 	stmt = `DELETE FROM authors WHERE id = $1`
-	res, err = tx.ExecContext(ctx, stmt, id)
+	_, err = tx.ExecContext(ctx, stmt, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordAuthorRevision(ctx, tx, targetID, types.RevisionMerge)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return bookIDs, nil
+}
+
+func (r *AuthorRepository) queryAuthors(ctx context.Context, stmt string, args ...any) ([]*types.Author, error) {
+	rows, err := r.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var authors []*types.Author
+	for rows.Next() {
+		author, err := scanAuthor(rows)
+		if err != nil {
+			return nil, err
+		}
+		authors = append(authors, author)
+	}
+
+	return authors, nil
+}
+
+// saveAuthorAliases stores aliases of the author, an alias already taken by another author
+// makes the lookup ambiguous and is rejected.
+// checkAuthorName fails with ErrEntityExists when an author other than the one with the ID has
+// the folded first and last name. The name stays locked until the transaction ends, so that
+// concurrent writes of the same name can't both pass the check.
+func checkAuthorName(ctx context.Context, tx *sql.Tx, id int64, firstNameKey string, lastNameKey string) error {
+	err := lockAuthorName(ctx, tx, firstNameKey, lastNameKey)
 	if err != nil {
 		return err
 	}
 
-	rowsAffected, err = res.RowsAffected()
+	stmt := `SELECT EXISTS (SELECT 1 FROM authors WHERE first_name_key = $1 AND last_name_key = $2 AND deleted_at IS NULL AND id <> $3)`
+	var exists bool
+	err = tx.QueryRowContext(ctx, stmt, firstNameKey, lastNameKey, id).Scan(&exists)
 	if err != nil {
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return ErrNotFound
+	if exists {
+		return ErrEntityExists
 	}
 
-	err = tx.Commit()
-	return err
+	return nil
+}
+
+// lockAuthorName locks the folded first and last name of an author until the transaction ends.
+func lockAuthorName(ctx context.Context, tx *sql.Tx, firstNameKey string, lastNameKey string) error {
+	return lockKey(ctx, tx, "authors:"+firstNameKey+" "+lastNameKey)
+}
+
+func saveAuthorAliases(ctx context.Context, tx *sql.Tx, authorID int64, aliases []string) error {
+	stmt := `INSERT INTO author_aliases(author_id, name, name_key) VALUES($1, $2, $3) ON CONFLICT (name_key) DO NOTHING`
+	for _, alias := range aliases {
+		res, err := tx.ExecContext(ctx, stmt, authorID, alias, types.FoldName(alias))
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return ErrEntityExists
+		}
+	}
+
+	return nil
+}
+
+func scanAuthor(row rowScanner) (*types.Author, error) {
+	var author types.Author
+	var birthDate, deathDate sql.NullTime
+	err := row.Scan(&author.ID, &author.FirstName, &author.MiddleName, &author.LastName, &birthDate, &deathDate,
+		&author.Nationality, &author.Biography, &author.ISNI, &author.VIAF, &author.Wikidata, &author.Version, pq.Array(&author.Aliases))
+	if err != nil {
+		return nil, err
+	}
+
+	author.BirthDate = nullTimeToDate(birthDate)
+	author.DeathDate = nullTimeToDate(deathDate)
+	return &author, nil
 }
@@ -3,11 +3,21 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/lib/pq"
 	"github.com/tredoc/go-crud-api/pkg/types"
+	"strings"
 	"time"
 )
 
+// exportBatchSize is the number of books fetched from the export cursor at a time.
+const exportBatchSize = 500
+
+// likeEscaper escapes the wildcards of a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 type BookRepository struct {
 	db *sql.DB
 }
@@ -28,8 +38,31 @@ func (r *BookRepository) CreateBook(ctx context.Context, book *types.Book) (int6
 	}
 	defer tx.Rollback()
 
-	stmt := `INSERT INTO books(title, publish_date, isbn, pages) VALUES($1, $2, $3, $4) RETURNING id, created_at`
-	err = tx.QueryRowContext(ctx, stmt, book.Title, book.PublishDate.Format(time.DateOnly), book.ISBN, book.Pages).Scan(&bookID, &createdAt)
+	bookID, createdAt, err = createBook(ctx, tx, book)
+	if err != nil {
+		return bookID, createdAt, err
+	}
+
+	err = recordBookRevision(ctx, tx, bookID, types.RevisionCreate)
+	if err != nil {
+		return bookID, createdAt, err
+	}
+
+	err = tx.Commit()
+	return bookID, createdAt, err
+}
+
+func createBook(ctx context.Context, tx *sql.Tx, book *types.Book) (int64, time.Time, error) {
+	var bookID int64
+	var createdAt time.Time
+
+	err := lockBookReferences(ctx, tx, book)
+	if err != nil {
+		return bookID, createdAt, err
+	}
+
+	stmt := `INSERT INTO books(title, publish_date, isbn, pages) VALUES($1, $2, $3, $4) RETURNING id, created_at, version`
+	err = tx.QueryRowContext(ctx, stmt, book.Title, book.PublishDate.Format(time.DateOnly), book.ISBN, book.Pages).Scan(&bookID, &createdAt, &book.Version)
 	if err != nil {
 		return bookID, createdAt, err
 	}
@@ -55,28 +88,30 @@ func (r *BookRepository) CreateBook(ctx context.Context, book *types.Book) (int6
 	for _, genreID := range book.Genres {
 		res, err := tx.ExecContext(ctx, stmt, bookID, genreID)
 		if err != nil {
-			return genreID, createdAt, err
+			return bookID, createdAt, err
 		}
 
 		rowsAffected, err := res.RowsAffected()
 		if err != nil {
-			return genreID, createdAt, err
+			return bookID, createdAt, err
 		}
 
-		if rowsAffected < 0 {
-			return genreID, createdAt, errors.New("no rows affected on book_author relation create")
+		if rowsAffected == 0 {
+			return bookID, createdAt, errors.New("no rows affected on book_genre relation create")
 		}
 	}
 
-	err = tx.Commit()
-	return bookID, createdAt, err
+	return bookID, createdAt, nil
 }
 
 func (r *BookRepository) GetBookByID(ctx context.Context, id int64) (*types.Book, error) {
 	var customDate time.Time
 	var book types.Book
-	stmt := `SELECT title, publish_date, created_at, isbn, pages FROM books WHERE id=$1`
-	err := r.db.QueryRowContext(ctx, stmt, id).Scan(&book.Title, &customDate, &book.CreatedAt, &book.ISBN, &book.Pages)
+	stmt := `
+		SELECT title, publish_date, created_at, isbn, pages, 
+		COALESCE(rating_sum::float / NULLIF(rating_count, 0), 0), rating_count, version 
+		FROM books WHERE id=$1 AND deleted_at IS NULL`
+	err := r.db.QueryRowContext(ctx, stmt, id).Scan(&book.Title, &customDate, &book.CreatedAt, &book.ISBN, &book.Pages, &book.AverageRating, &book.RatingsCount, &book.Version)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -85,56 +120,66 @@ func (r *BookRepository) GetBookByID(ctx context.Context, id int64) (*types.Book
 		return nil, err
 	}
 
-	stmt = `SELECT author_id FROM book_author WHERE book_id = $1`
-	rows, err := r.db.QueryContext(ctx, stmt, id)
+	stmt = `
+		SELECT ba.author_id FROM book_author AS ba JOIN authors AS a ON a.id = ba.author_id
+		WHERE ba.book_id = $1 AND a.deleted_at IS NULL`
+	authors, err := queryColumn[int64](ctx, r.db, stmt, id)
 	if err != nil {
 		return nil, err
 	}
 
-	var authors []int64
-	for rows.Next() {
-		var authorID int64
-		err := rows.Scan(&authorID)
-		if err != nil {
-			return nil, err
-		}
-		authors = append(authors, authorID)
+	stmt = `
+		SELECT bg.genre_id FROM book_genre AS bg JOIN genres AS g ON g.id = bg.genre_id
+		WHERE bg.book_id = $1 AND g.deleted_at IS NULL`
+	genres, err := queryColumn[int64](ctx, r.db, stmt, id)
+	if err != nil {
+		return nil, err
 	}
 
-	stmt = `SELECT genre_id FROM book_genre WHERE book_id=$1`
-	rows, err = r.db.QueryContext(ctx, stmt, id)
+	stmt = `SELECT t.name FROM book_tag AS bt JOIN tags AS t ON t.id = bt.tag_id WHERE bt.book_id = $1 ORDER BY t.name`
+	tags, err := queryColumn[string](ctx, r.db, stmt, id)
 	if err != nil {
 		return nil, err
 	}
-
-	var genres []int64
-	for rows.Next() {
-		var genreID int64
-		err := rows.Scan(&genreID)
-		if err != nil {
-			return nil, err
-		}
-		genres = append(genres, genreID)
+	if tags == nil {
+		tags = []string{}
 	}
 
 	book.PublishDate = types.CustomDate{Time: customDate}
 	book.Authors = authors
 	book.Genres = genres
+	book.Tags = tags
 
 	return &book, nil
 }
 
-func (r *BookRepository) GetAllBooks(ctx context.Context) ([]*types.Book, error) {
+func (r *BookRepository) GetAllBooks(ctx context.Context, filter *types.BookFilter) ([]*types.Book, error) {
+	where, args := bookFilterWhere(filter)
+	return r.queryBooks(ctx, where, args...)
+}
+
+// GetBooksByIDs returns the books with the IDs that aren't in the trash, in no particular order.
+func (r *BookRepository) GetBooksByIDs(ctx context.Context, ids []int64) ([]*types.Book, error) {
+	return r.queryBooks(ctx, `WHERE b.id = ANY($1) AND b.deleted_at IS NULL`, pq.Array(ids))
+}
+
+// queryBooks returns the books, aliased b, that the WHERE clause selects.
+func (r *BookRepository) queryBooks(ctx context.Context, where string, args ...any) ([]*types.Book, error) {
 	var books []*types.Book
-	stmt := `
+	stmt := fmt.Sprintf(`
 		SELECT b.id, b.title, b.publish_date, b.created_at, b.isbn, b.pages, 
-		array_agg(DISTINCT ba.author_id) as authors, array_agg(DISTINCT bg.genre_id) as genres 
+		COALESCE(b.rating_sum::float / NULLIF(b.rating_count, 0), 0), b.rating_count, b.version, 
+		array_agg(DISTINCT ba.author_id) as authors, array_agg(DISTINCT bg.genre_id) as genres, 
+		array_remove(array_agg(DISTINCT t.name), NULL) as tags 
 		FROM books AS b 
-		LEFT JOIN book_author AS ba on b.id = ba.book_id 
-		LEFT JOIN book_genre AS bg on b.id = bg.book_id
-		GROUP BY b.id`
-
-	rows, err := r.db.QueryContext(ctx, stmt)
+		LEFT JOIN book_author AS ba on b.id = ba.book_id AND ba.author_id IN (SELECT id FROM authors WHERE deleted_at IS NULL)
+		LEFT JOIN book_genre AS bg on b.id = bg.book_id AND bg.genre_id IN (SELECT id FROM genres WHERE deleted_at IS NULL)
+		LEFT JOIN book_tag AS bt on b.id = bt.book_id
+		LEFT JOIN tags AS t on t.id = bt.tag_id
+		%s
+		GROUP BY b.id`, where)
+
+	rows, err := r.db.QueryContext(ctx, stmt, args...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -149,7 +194,8 @@ func (r *BookRepository) GetAllBooks(ctx context.Context) ([]*types.Book, error)
 		var book types.Book
 		var authorsStr string
 		var genresStr string
-		err := rows.Scan(&book.ID, &book.Title, &customDate, &book.CreatedAt, &book.ISBN, &book.Pages, &authorsStr, &genresStr)
+		tags := []string{}
+		err := rows.Scan(&book.ID, &book.Title, &customDate, &book.CreatedAt, &book.ISBN, &book.Pages, &book.AverageRating, &book.RatingsCount, &book.Version, &authorsStr, &genresStr, pq.Array(&tags))
 		if err != nil {
 			return nil, err
 		}
@@ -167,26 +213,320 @@ func (r *BookRepository) GetAllBooks(ctx context.Context) ([]*types.Book, error)
 		book.PublishDate = types.CustomDate{Time: customDate}
 		book.Authors = authors
 		book.Genres = genres
+		book.Tags = tags
 		books = append(books, &book)
 	}
 
 	return books, nil
 }
 
-func (r *BookRepository) UpdateBook(ctx context.Context, id int64, book *types.Book) error {
+// bookFilterWhere builds the WHERE clause of the books, aliased b, that match the filter and
+// aren't in the trash.
+func bookFilterWhere(filter *types.BookFilter) (string, []any) {
+	conditions := []string{`b.deleted_at IS NULL`}
+	var args []any
+	if len(filter.Tags) > 0 {
+		args = append(args, pq.Array(filter.Tags))
+		having := ""
+		if filter.TagMatch == types.MatchAllTags {
+			having = fmt.Sprintf(`GROUP BY ft.book_id HAVING count(DISTINCT t.name) = cardinality($%d::text[])`, len(args))
+		}
+
+		conditions = append(conditions, fmt.Sprintf(`b.id IN (
+			SELECT ft.book_id FROM book_tag AS ft JOIN tags AS t ON t.id = ft.tag_id
+			WHERE t.name = ANY($%d::text[]) %s
+		)`, len(args), having))
+	}
+
+	// The IDs of merged authors and genres are resolved through their redirects, like reads by ID.
+	if filter.AuthorID != 0 {
+		args = append(args, filter.AuthorID)
+		conditions = append(conditions, fmt.Sprintf(`b.id IN (
+			SELECT book_id FROM book_author
+			WHERE author_id = COALESCE((SELECT target_id FROM author_redirects WHERE source_id = $%[1]d), $%[1]d)
+		)`, len(args)))
+	}
+
+	if filter.GenreID != 0 {
+		args = append(args, filter.GenreID)
+		genres := fmt.Sprintf("COALESCE((SELECT target_id FROM genre_redirects WHERE source_id = $%[1]d), $%[1]d)", len(args))
+		if filter.IncludeSubgenres {
+			genres = fmt.Sprintf(genreSubtreeStmt, genres)
+		}
+		conditions = append(conditions, fmt.Sprintf(`b.id IN (SELECT book_id FROM book_genre WHERE genre_id IN (%s) AND genre_id IN (SELECT id FROM genres WHERE deleted_at IS NULL))`, genres))
+	}
+
+	if filter.Query != "" {
+		args = append(args, "%"+likeEscaper.Replace(filter.Query)+"%")
+		conditions = append(conditions, fmt.Sprintf(`(b.title ILIKE $%d OR b.id IN (
+			SELECT sa.book_id FROM book_author AS sa JOIN authors AS a ON a.id = sa.author_id
+			WHERE a.deleted_at IS NULL AND concat_ws(' ', a.first_name, NULLIF(a.middle_name, ''), a.last_name) ILIKE $%d
+		))`, len(args), len(args)))
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// exportedBookColumns selects the books, aliased b, with their authors, genres and tags by name
+// for scanExportedBook.
+const exportedBookColumns = `
+	b.id, b.title, b.publish_date, b.created_at, b.isbn, b.pages,
+	COALESCE(b.rating_sum::float / NULLIF(b.rating_count, 0), 0), b.rating_count,
+	(
+		SELECT COALESCE(json_agg(json_build_object(
+			'first_name', a.first_name, 'middle_name', COALESCE(a.middle_name, ''), 'last_name', a.last_name
+		) ORDER BY a.last_name, a.first_name), '[]')
+		FROM book_author AS ba JOIN authors AS a ON a.id = ba.author_id
+		WHERE ba.book_id = b.id AND a.deleted_at IS NULL
+	),
+	ARRAY(
+		SELECT g.name FROM book_genre AS bg JOIN genres AS g ON g.id = bg.genre_id
+		WHERE bg.book_id = b.id AND g.deleted_at IS NULL ORDER BY g.name
+	),
+	ARRAY(
+		SELECT t.name FROM book_tag AS bt JOIN tags AS t ON t.id = bt.tag_id
+		WHERE bt.book_id = b.id ORDER BY t.name
+	)`
+
+// ExportBooks hands every book matching the filter to each, in the order of their IDs. The books
+// are fetched from a cursor a batch at a time, so the memory used doesn't grow with the catalogue.
+// An error of each stops the export and is returned.
+func (r *BookRepository) ExportBooks(ctx context.Context, filter *types.BookFilter, each func(*types.ExportedBook) error) error {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	where, args := bookFilterWhere(filter)
+	stmt := fmt.Sprintf(`
+		DECLARE export_books NO SCROLL CURSOR FOR
+		SELECT %s
+		FROM books AS b
+		%s
+		ORDER BY b.id`, exportedBookColumns, where)
+	_, err = tx.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return err
+	}
+
+	fetch := fmt.Sprintf(`FETCH %d FROM export_books`, exportBatchSize)
+	for {
+		fetched, err := fetchExportedBooks(ctx, tx, fetch, each)
+		if err != nil {
+			return err
+		}
+
+		if fetched < exportBatchSize {
+			return nil
+		}
+	}
+}
+
+func fetchExportedBooks(ctx context.Context, tx *sql.Tx, fetch string, each func(*types.ExportedBook) error) (int, error) {
+	rows, err := tx.QueryContext(ctx, fetch)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	fetched := 0
+	for rows.Next() {
+		book, err := scanExportedBook(rows)
+		if err != nil {
+			return 0, err
+		}
+
+		err = each(book)
+		if err != nil {
+			return 0, err
+		}
+		fetched++
+	}
+
+	return fetched, rows.Err()
+}
+
+// GetBookPage returns the books matching the filter from offset on, at most limit of them, and how
+// many match in all. The newest order is by the time the books were added.
+func (r *BookRepository) GetBookPage(ctx context.Context, filter *types.BookFilter, order types.BookOrder, limit int, offset int) (*types.BookPage, error) {
+	where, args := bookFilterWhere(filter)
+	page := types.BookPage{Books: []*types.ExportedBook{}}
+	err := r.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT count(*) FROM books AS b %s`, where), args...).Scan(&page.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	orderBy := `lower(b.title), b.id`
+	if order == types.OrderByNewest {
+		orderBy = `b.created_at DESC, b.id DESC`
+	}
+
+	args = append(args, limit, offset)
+	stmt := fmt.Sprintf(`
+		SELECT %s
+		FROM books AS b
+		%s
+		ORDER BY %s LIMIT $%d OFFSET $%d`, exportedBookColumns, where, orderBy, len(args)-1, len(args))
+	rows, err := r.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		book, err := scanExportedBook(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Books = append(page.Books, book)
+	}
+
+	return &page, rows.Err()
+}
+
+func scanExportedBook(rows *sql.Rows) (*types.ExportedBook, error) {
+	book := types.ExportedBook{Genres: []string{}, Tags: []string{}}
+	var publishDate time.Time
+	var credits []byte
+	err := rows.Scan(&book.ID, &book.Title, &publishDate, &book.CreatedAt, &book.ISBN, &book.Pages, &book.AverageRating,
+		&book.RatingsCount, &credits, pq.Array(&book.Genres), pq.Array(&book.Tags))
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(credits, &book.Credits)
+	if err != nil {
+		return nil, err
+	}
+
+	book.PublishDate = types.CustomDate{Time: publishDate}
+	book.Authors = make([]string, len(book.Credits))
+	for i, author := range book.Credits {
+		book.Authors[i] = author.FullName()
+	}
+
+	return &book, nil
+}
+
+// UpdateBook overwrites the book when it's still at book.Version, a zero version matches any, and
+// sets book.Version to the bumped one. A book changed meanwhile fails with ErrVersionMismatch.
+// The change is recorded as a revision with the given action.
+func (r *BookRepository) UpdateBook(ctx context.Context, id int64, book *types.Book, action types.RevisionAction) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	stmt := `UPDATE books SET title = $1, publish_date = $2, isbn = $3, pages = $4 WHERE id = $5`
-	_, err = tx.ExecContext(ctx, stmt, book.Title, book.PublishDate.Format(time.DateOnly), book.ISBN, book.Pages, id)
+	version, _, err := updateBook(ctx, tx, id, book)
+	if err != nil {
+		return err
+	}
+
+	err = recordBookRevision(ctx, tx, id, action)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	book.Version = version
+	return nil
+}
+
+// UpsertBook replaces the book like UpdateBook does or, when there is none with the ID, creates
+// the book under it. It reports whether the book was created and sets its version and creation
+// time.
+func (r *BookRepository) UpsertBook(ctx context.Context, id int64, book *types.Book) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	exists, err := lockForUpsert(ctx, tx, "books", "", id, book.Version)
+	if err != nil {
+		return false, err
+	}
+
+	var version int64
+	var createdAt time.Time
+	if exists {
+		version, createdAt, err = updateBook(ctx, tx, id, book)
+		if err != nil {
+			return false, err
+		}
+	} else {
+		stmt := `INSERT INTO books(id, title, publish_date, isbn, pages) VALUES($1, $2, $3, $4, $5) RETURNING version, created_at`
+		err = tx.QueryRowContext(ctx, stmt, id, book.Title, book.PublishDate.Format(time.DateOnly), book.ISBN, book.Pages).Scan(&version, &createdAt)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return false, ErrEntityExists
+			}
+			return false, err
+		}
+
+		err = syncSequence(ctx, tx, "books", id)
+		if err != nil {
+			return false, err
+		}
+
+		err = replaceBookRelations(ctx, tx, id, book)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	action := types.RevisionUpdate
+	if !exists {
+		action = types.RevisionCreate
+	}
+
+	err = recordBookRevision(ctx, tx, id, action)
+	if err != nil {
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+
+	book.Version = version
+	book.CreatedAt = createdAt
+	return !exists, nil
+}
+
+func updateBook(ctx context.Context, tx *sql.Tx, id int64, book *types.Book) (int64, time.Time, error) {
+	stmt := `
+		UPDATE books SET title = $1, publish_date = $2, isbn = $3, pages = $4, version = version + 1
+		WHERE id = $5 AND deleted_at IS NULL AND ($6 = 0 OR version = $6)
+		RETURNING version, created_at`
+	var version int64
+	var createdAt time.Time
+	err := tx.QueryRowContext(ctx, stmt, book.Title, book.PublishDate.Format(time.DateOnly), book.ISBN, book.Pages, id, book.Version).Scan(&version, &createdAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, createdAt, versionConflict(ctx, tx, "books", id)
+		}
+		return 0, createdAt, err
+	}
+
+	return version, createdAt, replaceBookRelations(ctx, tx, id, book)
+}
+
+// replaceBookRelations rewrites the authors and genres of the book.
+func replaceBookRelations(ctx context.Context, tx *sql.Tx, id int64, book *types.Book) error {
+	err := lockBookReferences(ctx, tx, book)
 	if err != nil {
 		return err
 	}
 
-	stmt = `DELETE FROM book_author WHERE book_id = $1`
+	stmt := `DELETE FROM book_author WHERE book_id = $1`
 	_, err = tx.ExecContext(ctx, stmt, id)
 	if err != nil {
 		return err
@@ -214,34 +554,163 @@ func (r *BookRepository) UpdateBook(ctx context.Context, id int64, book *types.B
 		}
 	}
 
-	return tx.Commit()
+	return nil
 }
 
-func (r *BookRepository) DeleteBook(ctx context.Context, id int64) error {
+// lockBookReferences resolves IDs of merged authors and genres of the book to the ones they were
+// merged into and locks the authors and genres FOR SHARE, so that they can't be deleted or merged
+// before the transaction ends. IDs that don't belong to an author or genre outside of the trash
+// fail with a ReferenceError.
+func lockBookReferences(ctx context.Context, tx *sql.Tx, book *types.Book) error {
+	authors, missingAuthors, err := lockReferences(ctx, tx, "authors", "author_redirects", book.Authors)
+	if err != nil {
+		return err
+	}
+
+	genres, missingGenres, err := lockReferences(ctx, tx, "genres", "genre_redirects", book.Genres)
+	if err != nil {
+		return err
+	}
+
+	if len(missingAuthors) > 0 || len(missingGenres) > 0 {
+		return &ReferenceError{Authors: missingAuthors, Genres: missingGenres}
+	}
+
+	book.Authors, book.Genres = authors, genres
+	return nil
+}
+
+// lockReferences locks the live rows of the table the IDs resolve to through the redirects. It
+// returns the resolved IDs in the original order without duplicates and the IDs without a row.
+func lockReferences(ctx context.Context, tx *sql.Tx, table string, redirects string, ids []int64) ([]int64, []int64, error) {
+	if len(ids) == 0 {
+		return ids, nil, nil
+	}
+
+	stmt := fmt.Sprintf(`
+		SELECT COALESCE((SELECT target_id FROM %s WHERE source_id = r.id), r.id)
+		FROM unnest($1::bigint[]) WITH ORDINALITY AS r(id, n) ORDER BY r.n`, redirects)
+	resolved, err := queryInt64s(ctx, tx, stmt, pq.Array(ids))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stmt = fmt.Sprintf(`SELECT id FROM %s WHERE id = ANY($1) AND deleted_at IS NULL ORDER BY id FOR SHARE`, table)
+	locked, err := queryInt64s(ctx, tx, stmt, pq.Array(resolved))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	found := make(map[int64]bool, len(locked))
+	for _, id := range locked {
+		found[id] = true
+	}
+
+	var missing []int64
+	unique := make([]int64, 0, len(resolved))
+	seen := make(map[int64]bool, len(resolved))
+	for i, id := range resolved {
+		switch {
+		case !found[id]:
+			missing = append(missing, ids[i])
+		case !seen[id]:
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	return unique, missing, nil
+}
+
+// DeleteBook moves the book to the trash when it's still at the given version, zero matches any.
+// It keeps its relations so that a restore brings the book back as it was, PurgeTrash removes it
+// for good.
+func (r *BookRepository) DeleteBook(ctx context.Context, id int64, version int64) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	stmt := `DELETE FROM book_genre WHERE book_id = $1`
-	_, err = tx.ExecContext(ctx, stmt, id)
+	err = deleteBook(ctx, tx, id, version)
 	if err != nil {
 		return err
 	}
 
-	stmt = `DELETE FROM book_author WHERE book_id = $1`
-	_, err = tx.ExecContext(ctx, stmt, id)
+	err = recordBookRevision(ctx, tx, id, types.RevisionDelete)
 	if err != nil {
 		return err
 	}
 
-	stmt = `DELETE FROM books WHERE id = $1`
-	_, err = tx.ExecContext(ctx, stmt, id)
+	return tx.Commit()
+}
+
+func deleteBook(ctx context.Context, q execQuerier, id int64, version int64) error {
+	stmt := `UPDATE books SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2)`
+	res, err := q.ExecContext(ctx, stmt, id, version)
 	if err != nil {
 		return err
 	}
 
-	err = tx.Commit()
-	return err
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return versionConflict(ctx, q, "books", id)
+	}
+
+	return nil
+}
+
+// ApplyBookBatch applies the operations in a single transaction as runBatch describes. Books to
+// update come with all their fields and the version they are expected at, the outcome of each
+// operation shows in its ID and Version.
+func (r *BookRepository) ApplyBookBatch(ctx context.Context, operations []*types.BookOperation, atomic bool) ([]error, error) {
+	return runBatch(ctx, r.db, len(operations), atomic, func(tx *sql.Tx, i int) error {
+		op := operations[i]
+		switch op.Op {
+		case types.BatchCreate:
+			id, createdAt, err := createBook(ctx, tx, op.Book)
+			if err != nil {
+				return err
+			}
+
+			op.Book.ID = id
+			op.Book.CreatedAt = createdAt
+			op.ID, op.Version = id, op.Book.Version
+		case types.BatchUpdate:
+			version, _, err := updateBook(ctx, tx, op.ID, op.Book)
+			if err != nil {
+				return err
+			}
+
+			op.Book.Version = version
+			op.Version = version
+		case types.BatchDelete:
+			err := deleteBook(ctx, tx, op.ID, op.Version)
+			if err != nil {
+				return err
+			}
+		}
+
+		return recordBookRevision(ctx, tx, op.ID, batchRevisionAction(op.Op))
+	})
+}
+
+func (r *BookRepository) GetBookTranslations(ctx context.Context, id int64) ([]*types.Translation, error) {
+	return getTranslations(ctx, r.db, bookTranslations, id)
+}
+
+func (r *BookRepository) GetBookTranslationsByIDs(ctx context.Context, ids []int64, languages []string) (map[int64]map[string]string, error) {
+	return getTranslationsByIDs(ctx, r.db, bookTranslations, ids, languages)
+}
+
+func (r *BookRepository) SetBookTranslation(ctx context.Context, id int64, translation *types.Translation) error {
+	return setTranslation(ctx, r.db, bookTranslations, id, translation)
+}
+
+func (r *BookRepository) DeleteBookTranslation(ctx context.Context, id int64, language string) error {
+	return deleteTranslation(ctx, r.db, bookTranslations, id, language)
 }
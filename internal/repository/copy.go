@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"time"
+)
+
+type CopyRepository struct {
+	db *sql.DB
+}
+
+func NewCopyRepository(db *sql.DB) *CopyRepository {
+	return &CopyRepository{
+		db: db,
+	}
+}
+
+func (r *CopyRepository) CreateCopy(ctx context.Context, bookCopy *types.Copy) (int64, time.Time, error) {
+	stmt := `SELECT id FROM books WHERE id = $1 AND deleted_at IS NULL`
+	var bookID int64
+	err := r.db.QueryRowContext(ctx, stmt, bookCopy.BookID).Scan(&bookID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, time.Time{}, ErrNotFound
+		}
+		return 0, time.Time{}, err
+	}
+
+	stmt = `SELECT id FROM copies WHERE barcode = $1`
+	var foundCopyID int64
+	err = r.db.QueryRowContext(ctx, stmt, bookCopy.Barcode).Scan(&foundCopyID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, time.Time{}, err
+	}
+
+	if foundCopyID != 0 {
+		return 0, time.Time{}, ErrEntityExists
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	var createdAt time.Time
+	stmt = `INSERT INTO copies(book_id, barcode, location, condition) VALUES($1, $2, $3, $4) RETURNING id, created_at`
+	err = tx.QueryRowContext(ctx, stmt, bookCopy.BookID, bookCopy.Barcode, bookCopy.Location, bookCopy.Condition).Scan(&id, &createdAt)
+	if err != nil {
+		return 0, createdAt, err
+	}
+
+	err = assignCopyToNextHold(ctx, tx, id)
+	if err != nil {
+		return 0, createdAt, err
+	}
+
+	err = tx.Commit()
+	return id, createdAt, err
+}
+
+func (r *CopyRepository) GetCopyByID(ctx context.Context, id int64) (*types.Copy, error) {
+	stmt := `
+		SELECT c.id, c.book_id, c.barcode, c.location, c.condition, c.created_at,
+		NOT EXISTS (SELECT 1 FROM loans AS l WHERE l.copy_id = c.id AND l.returned_at IS NULL)
+		FROM copies AS c WHERE c.id = $1`
+
+	var bookCopy types.Copy
+	err := r.db.QueryRowContext(ctx, stmt, id).Scan(&bookCopy.ID, &bookCopy.BookID, &bookCopy.Barcode, &bookCopy.Location, &bookCopy.Condition, &bookCopy.CreatedAt, &bookCopy.Available)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &bookCopy, nil
+}
+
+func (r *CopyRepository) GetCopiesByBookID(ctx context.Context, bookID int64) ([]*types.Copy, error) {
+	stmt := `
+		SELECT c.id, c.book_id, c.barcode, c.location, c.condition, c.created_at,
+		NOT EXISTS (SELECT 1 FROM loans AS l WHERE l.copy_id = c.id AND l.returned_at IS NULL)
+		FROM copies AS c JOIN books AS b ON b.id = c.book_id
+		WHERE c.book_id = $1 AND b.deleted_at IS NULL
+		ORDER BY c.id`
+	rows, err := r.db.QueryContext(ctx, stmt, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var copies []*types.Copy
+	for rows.Next() {
+		var bookCopy types.Copy
+		err := rows.Scan(&bookCopy.ID, &bookCopy.BookID, &bookCopy.Barcode, &bookCopy.Location, &bookCopy.Condition, &bookCopy.CreatedAt, &bookCopy.Available)
+		if err != nil {
+			return nil, err
+		}
+		copies = append(copies, &bookCopy)
+	}
+
+	return copies, nil
+}
+
+func (r *CopyRepository) UpdateCopy(ctx context.Context, id int64, bookCopy *types.Copy) error {
+	stmt := `SELECT id FROM copies WHERE barcode = $1 AND id <> $2`
+	var foundCopyID int64
+	err := r.db.QueryRowContext(ctx, stmt, bookCopy.Barcode, id).Scan(&foundCopyID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if foundCopyID != 0 {
+		return ErrEntityExists
+	}
+
+	stmt = `UPDATE copies SET barcode = $1, location = $2, condition = $3 WHERE id = $4`
+	res, err := r.db.ExecContext(ctx, stmt, bookCopy.Barcode, bookCopy.Location, bookCopy.Condition, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *CopyRepository) DeleteCopy(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt := `SELECT id FROM copies WHERE id = $1 FOR UPDATE`
+	var copyID int64
+	err = tx.QueryRowContext(ctx, stmt, id).Scan(&copyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	stmt = `SELECT count(*) FROM loans WHERE copy_id = $1 AND returned_at IS NULL`
+	var activeLoans int
+	err = tx.QueryRowContext(ctx, stmt, id).Scan(&activeLoans)
+	if err != nil {
+		return err
+	}
+
+	if activeLoans > 0 {
+		return ErrCopyUnavailable
+	}
+
+	stmt = `UPDATE holds SET status = 'waiting', ready_at = NULL, expires_at = NULL WHERE copy_id = $1 AND status = 'ready'`
+	_, err = tx.ExecContext(ctx, stmt, id)
+	if err != nil {
+		return err
+	}
+
+	stmt = `UPDATE holds SET copy_id = NULL WHERE copy_id = $1`
+	_, err = tx.ExecContext(ctx, stmt, id)
+	if err != nil {
+		return err
+	}
+
+	stmt = `DELETE FROM loans WHERE copy_id = $1`
+	_, err = tx.ExecContext(ctx, stmt, id)
+	if err != nil {
+		return err
+	}
+
+	stmt = `DELETE FROM copies WHERE id = $1`
+	_, err = tx.ExecContext(ctx, stmt, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -1,8 +1,29 @@
 package repository
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
-	ErrNotFound     = errors.New("not found")
-	ErrEntityExists = errors.New("entity exists")
+	ErrNotFound         = errors.New("not found")
+	ErrEntityExists     = errors.New("entity exists")
+	ErrCopyUnavailable  = errors.New("copy unavailable")
+	ErrLoanLimitReached = errors.New("loan limit reached")
+	ErrLoanClosed       = errors.New("loan closed")
+	ErrCopyAvailable    = errors.New("copy available")
+	ErrGenreCycle       = errors.New("genre cycle")
+	ErrEntityInUse      = errors.New("entity in use")
+	ErrVersionMismatch  = errors.New("version mismatch")
+	ErrIDUnavailable    = errors.New("id unavailable")
 )
+
+// ReferenceError lists the IDs of authors and genres a book refers to that don't exist.
+type ReferenceError struct {
+	Authors []int64
+	Genres  []int64
+}
+
+func (e *ReferenceError) Error() string {
+	return fmt.Sprintf("missing authors %v and genres %v", e.Authors, e.Genres)
+}
@@ -5,11 +5,22 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"github.com/tredoc/go-crud-api/pkg/log"
+	"github.com/lib/pq"
 	"github.com/tredoc/go-crud-api/pkg/types"
-	"strings"
 )
 
+const genreColumns = `id, name, parent_id, version`
+
+// genreSubtreeStmt selects the ID of the genre given by the format argument together with the IDs
+// of all its subgenres.
+const genreSubtreeStmt = `
+	WITH RECURSIVE subtree AS (
+		SELECT id FROM genres WHERE id = %[1]s
+		UNION
+		SELECT g.id FROM genres AS g JOIN subtree AS s ON g.parent_id = s.id
+	)
+	SELECT id FROM subtree`
+
 type GenreRepository struct {
 	db *sql.DB
 }
@@ -21,16 +32,41 @@ func NewGenreRepository(db *sql.DB) *GenreRepository {
 }
 
 func (r *GenreRepository) CreateGenre(ctx context.Context, genre *types.Genre) (int64, error) {
-	stmt := `INSERT INTO genres (name) VALUES ($1) RETURNING id`
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	id, err := createGenre(ctx, tx, genre)
+	if err != nil {
+		return 0, err
+	}
+
+	err = recordGenreRevision(ctx, tx, id, types.RevisionCreate)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func createGenre(ctx context.Context, q rowQuerier, genre *types.Genre) (int64, error) {
+	stmt := `INSERT INTO genres (name, parent_id) VALUES ($1, $2) RETURNING id, version`
 	var id int64
-	err := r.db.QueryRowContext(ctx, stmt, genre.Name).Scan(&id)
+	err := q.QueryRowContext(ctx, stmt, genre.Name, int64PtrToNullInt64(genre.ParentID)).Scan(&id, &genre.Version)
 	return id, err
 }
 
+// GetGenreByID returns the genre, IDs of genres merged into another one resolve to the target.
 func (r *GenreRepository) GetGenreByID(ctx context.Context, id int64) (*types.Genre, error) {
-	stmt := `SELECT id, name FROM genres WHERE id = $1`
-	var genre types.Genre
-	err := r.db.QueryRowContext(ctx, stmt, id).Scan(&genre.ID, &genre.Name)
+	stmt := fmt.Sprintf(`SELECT %s FROM genres WHERE id = COALESCE((SELECT target_id FROM genre_redirects WHERE source_id = $1), $1) AND deleted_at IS NULL`, genreColumns)
+	genre, err := scanGenre(r.db.QueryRowContext(ctx, stmt, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -38,118 +74,428 @@ func (r *GenreRepository) GetGenreByID(ctx context.Context, id int64) (*types.Ge
 		return nil, err
 	}
 
-	return &genre, nil
+	return genre, nil
 }
 
+// GetGenresByIDs returns the genres with the IDs, IDs of genres merged into another one resolve to
+// the target.
 func (r *GenreRepository) GetGenresByIDs(ctx context.Context, ids []int64) ([]*types.Genre, error) {
-	placeholders := make([]string, len(ids))
-	for idx := range ids {
-		placeholders[idx] = fmt.Sprintf("$%d", idx+1)
+	stmt := fmt.Sprintf(`
+		SELECT %s FROM genres
+		WHERE id IN (SELECT COALESCE((SELECT target_id FROM genre_redirects WHERE source_id = r.id), r.id) FROM unnest($1::bigint[]) AS r(id))
+		AND deleted_at IS NULL`, genreColumns)
+	return r.queryGenres(ctx, stmt, pq.Array(ids))
+}
+
+func (r *GenreRepository) GetAllGenres(ctx context.Context) ([]*types.Genre, error) {
+	stmt := fmt.Sprintf(`SELECT %s FROM genres WHERE deleted_at IS NULL`, genreColumns)
+	return r.queryGenres(ctx, stmt)
+}
+
+// GetGenreTree returns genres reachable from the top level ones, each parent precedes its
+// subgenres. Subgenres of a genre in the trash are listed on the top level.
+func (r *GenreRepository) GetGenreTree(ctx context.Context) ([]*types.Genre, error) {
+	stmt := fmt.Sprintf(`
+		WITH RECURSIVE tree AS (
+			SELECT g.id, g.name, g.parent_id, g.version, ARRAY[g.name::text] AS path FROM genres AS g
+			LEFT JOIN genres AS p ON p.id = g.parent_id
+			WHERE g.deleted_at IS NULL AND (g.parent_id IS NULL OR p.deleted_at IS NOT NULL)
+			UNION ALL
+			SELECT g.id, g.name, g.parent_id, g.version, t.path || g.name::text FROM genres AS g JOIN tree AS t ON g.parent_id = t.id
+			WHERE g.deleted_at IS NULL
+		)
+		SELECT %[1]s FROM tree ORDER BY path`, genreColumns)
+	return r.queryGenres(ctx, stmt)
+}
+
+// UpdateGenre renames the genre and moves it under its parent. Moving a genre under itself or
+// one of its subgenres fails with ErrGenreCycle. A non-zero genre.Version has to match the one of
+// the genre, which is then set to the bumped one. The change is recorded as a revision with the
+// given action.
+func (r *GenreRepository) UpdateGenre(ctx context.Context, id int64, genre *types.Genre, action types.RevisionAction) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback()
 
-	placeholder := strings.Join(placeholders, ",")
-	stmt := fmt.Sprintf(`SELECT id, name FROM genres WHERE id IN (%s)`, placeholder)
-	args := make([]interface{}, len(ids))
-	for i, id := range ids {
-		args[i] = id
+	err = lockGenres(ctx, tx)
+	if err != nil {
+		return err
 	}
-	rows, err := r.db.QueryContext(ctx, stmt, args...)
+
+	version, err := updateGenre(ctx, tx, id, genre)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
 
-	var genres []*types.Genre
-	for rows.Next() {
-		var genre types.Genre
-		err := rows.Scan(&genre.ID, &genre.Name)
+	err = recordGenreRevision(ctx, tx, id, action)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	genre.Version = version
+	return nil
+}
+
+// UpsertGenre replaces the genre like UpdateGenre does or, when there is none with the ID, creates
+// the genre under it. It reports whether the genre was created.
+func (r *GenreRepository) UpsertGenre(ctx context.Context, id int64, genre *types.Genre) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	err = lockGenres(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+
+	exists, err := lockForUpsert(ctx, tx, "genres", "genre_redirects", id, genre.Version)
+	if err != nil {
+		return false, err
+	}
+
+	var version int64
+	if exists {
+		version, err = updateGenre(ctx, tx, id, genre)
 		if err != nil {
-			return nil, err
+			return false, err
+		}
+	} else {
+		stmt := `INSERT INTO genres (id, name, parent_id) VALUES ($1, $2, $3) RETURNING version`
+		err = tx.QueryRowContext(ctx, stmt, id, genre.Name, int64PtrToNullInt64(genre.ParentID)).Scan(&version)
+		if err != nil {
+			return false, err
 		}
 
-		genres = append(genres, &genre)
+		err = syncSequence(ctx, tx, "genres", id)
+		if err != nil {
+			return false, err
+		}
 	}
 
-	return genres, nil
-}
+	action := types.RevisionUpdate
+	if !exists {
+		action = types.RevisionCreate
+	}
 
-func (r *GenreRepository) GetAllGenres(ctx context.Context) ([]*types.Genre, error) {
-	stmt := `SELECT id, name FROM genres`
-	rows, err := r.db.QueryContext(ctx, stmt)
+	err = recordGenreRevision(ctx, tx, id, action)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
-	defer rows.Close()
 
-	var genres []*types.Genre
-	for rows.Next() {
-		var genre types.Genre
-		err := rows.Scan(&genre.ID, &genre.Name)
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+
+	genre.Version = version
+	return !exists, nil
+}
+
+// updateGenre overwrites the genre, the caller holds the lock of lockGenres so that the parent
+// can't turn into a subgenre meanwhile.
+func updateGenre(ctx context.Context, tx *sql.Tx, id int64, genre *types.Genre) (int64, error) {
+	if genre.ParentID != nil {
+		stmt := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM (%s) AS subtree WHERE id = $2)`, fmt.Sprintf(genreSubtreeStmt, "$1"))
+		var isDescendant bool
+		err := tx.QueryRowContext(ctx, stmt, id, *genre.ParentID).Scan(&isDescendant)
 		if err != nil {
-			return nil, err
+			return 0, err
+		}
+
+		if isDescendant {
+			return 0, ErrGenreCycle
 		}
-		genres = append(genres, &genre)
 	}
 
-	return genres, nil
+	stmt := `
+		UPDATE genres SET name = $1, parent_id = $2, version = version + 1
+		WHERE id = $3 AND deleted_at IS NULL AND ($4 = 0 OR version = $4)
+		RETURNING version`
+	var version int64
+	err := tx.QueryRowContext(ctx, stmt, genre.Name, int64PtrToNullInt64(genre.ParentID), id, genre.Version).Scan(&version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, versionConflict(ctx, tx, "genres", id)
+		}
+		return 0, err
+	}
+
+	return version, nil
 }
 
-func (r *GenreRepository) UpdateGenre(ctx context.Context, id int64, genre *types.Genre) error {
-	stmt := `UPDATE genres SET name = $1 WHERE id = $2`
-	res, err := r.db.ExecContext(ctx, stmt, genre.Name, id)
+// DeleteGenre moves the genre to the trash and returns IDs of the books that no longer list the
+// genre. Its subgenres show on the top level until it is restored or purged. With the restrict
+// policy a genre listed on books fails with ErrEntityInUse. A non-zero version has to match the
+// one of the genre.
+func (r *GenreRepository) DeleteGenre(ctx context.Context, id int64, version int64, policy types.DeletePolicy) ([]int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	bookIDs, err := deleteGenre(ctx, tx, id, version, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordGenreRevision(ctx, tx, id, types.RevisionDelete)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return bookIDs, nil
+}
+
+func deleteGenre(ctx context.Context, tx *sql.Tx, id int64, version int64, policy types.DeletePolicy) ([]int64, error) {
+	stmt := `UPDATE genres SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2)`
+	res, err := tx.ExecContext(ctx, stmt, id, version)
+	if err != nil {
+		return nil, err
 	}
 
 	rowsAffected, err := res.RowsAffected()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if rowsAffected == 0 {
-		return ErrNotFound
+		return nil, versionConflict(ctx, tx, "genres", id)
 	}
 
-	return nil
+	bookIDs, err := queryInt64s(ctx, tx, `
+		SELECT bg.book_id FROM book_genre AS bg JOIN books AS b ON b.id = bg.book_id
+		WHERE bg.genre_id = $1 AND b.deleted_at IS NULL`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy == types.RestrictOnDelete && len(bookIDs) > 0 {
+		return nil, ErrEntityInUse
+	}
+
+	return bookIDs, nil
 }
 
-func (r *GenreRepository) DeleteGenre(ctx context.Context, id int64) error {
+// ApplyGenreBatch applies the operations in a single transaction as runBatch describes. Genres to
+// update come with all their fields and the version they are expected at, deletions follow the
+// policy.
+func (r *GenreRepository) ApplyGenreBatch(ctx context.Context, operations []*types.GenreOperation, atomic bool, policy types.DeletePolicy) ([]error, error) {
+	return runBatch(ctx, r.db, len(operations), atomic, func(tx *sql.Tx, i int) error {
+		op := operations[i]
+		switch op.Op {
+		case types.BatchCreate:
+			id, err := createGenre(ctx, tx, op.Genre)
+			if err != nil {
+				return err
+			}
+
+			op.Genre.ID = id
+			op.ID, op.Version = id, op.Genre.Version
+		case types.BatchUpdate:
+			err := lockGenres(ctx, tx)
+			if err != nil {
+				return err
+			}
+
+			version, err := updateGenre(ctx, tx, op.ID, op.Genre)
+			if err != nil {
+				return err
+			}
+
+			op.Genre.Version = version
+			op.Version = version
+		case types.BatchDelete:
+			bookIDs, err := deleteGenre(ctx, tx, op.ID, op.Version, policy)
+			if err != nil {
+				return err
+			}
+
+			op.BookIDs = bookIDs
+		}
+
+		return recordGenreRevision(ctx, tx, op.ID, batchRevisionAction(op.Op))
+	})
+}
+
+// MergeGenres moves books and subgenres of the source genre to the target and leaves a redirect
+// from the source ID. A target nested under the source takes the place of the source in the
+// hierarchy. Both genres get a merge revision, the source one as it was before the merge. It
+// returns IDs of the books whose genres changed.
+func (r *GenreRepository) MergeGenres(ctx context.Context, sourceID int64, targetID int64) ([]int64, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer tx.Rollback()
 
-	stmt := `DELETE FROM book_genre WHERE genre_id = $1`
-	res, err := tx.ExecContext(ctx, stmt, id)
+	err = lockGenres(ctx, tx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	rowsAffected, err := res.RowsAffected()
+	stmt := `SELECT count(*) FROM genres WHERE id IN ($1, $2) AND deleted_at IS NULL`
+	var found int
+	err = tx.QueryRowContext(ctx, stmt, sourceID, targetID).Scan(&found)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if rowsAffected == 0 {
-		log.Info("no rows affected on book_genre relation delete")
+	if found != 2 {
+		return nil, ErrNotFound
 	}
 
-	stmt = `DELETE FROM genres WHERE id = $1`
-	res, err = tx.ExecContext(ctx, stmt, id)
+	err = recordGenreRevision(ctx, tx, sourceID, types.RevisionMerge)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	rowsAffected, err = res.RowsAffected()
+	bookIDs, err := queryInt64s(ctx, tx, `SELECT book_id FROM book_genre WHERE genre_id = $1`, sourceID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if rowsAffected == 0 {
-		return ErrNotFound
+	stmt = `DELETE FROM book_genre WHERE genre_id = $1 AND book_id IN (SELECT book_id FROM book_genre WHERE genre_id = $2)`
+	_, err = tx.ExecContext(ctx, stmt, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt = `UPDATE book_genre SET genre_id = $1 WHERE genre_id = $2`
+	_, err = tx.ExecContext(ctx, stmt, targetID, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt = fmt.Sprintf(`
+		UPDATE genres SET parent_id = (SELECT parent_id FROM genres WHERE id = $1), version = version + 1
+		WHERE id = $2 AND id IN (%s)`, fmt.Sprintf(genreSubtreeStmt, "$1"))
+	_, err = tx.ExecContext(ctx, stmt, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt = `UPDATE genres SET parent_id = $1, version = version + 1 WHERE parent_id = $2`
+	_, err = tx.ExecContext(ctx, stmt, targetID, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt = `
+		INSERT INTO genre_translations (genre_id, language, name)
+		SELECT $1, language, name FROM genre_translations WHERE genre_id = $2
+		ON CONFLICT (genre_id, language) DO NOTHING`
+	_, err = tx.ExecContext(ctx, stmt, targetID, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt = `DELETE FROM genre_translations WHERE genre_id = $1`
+	_, err = tx.ExecContext(ctx, stmt, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt = `UPDATE genre_redirects SET target_id = $1 WHERE target_id = $2`
+	_, err = tx.ExecContext(ctx, stmt, targetID, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt = `INSERT INTO genre_redirects(source_id, target_id) VALUES($1, $2)`
+	_, err = tx.ExecContext(ctx, stmt, sourceID, targetID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrEntityExists
+		}
+		return nil, err
+	}
+
+	stmt = `DELETE FROM genres WHERE id = $1`
+	_, err = tx.ExecContext(ctx, stmt, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordGenreRevision(ctx, tx, targetID, types.RevisionMerge)
+	if err != nil {
+		return nil, err
 	}
 
 	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return bookIDs, nil
+}
+
+func (r *GenreRepository) queryGenres(ctx context.Context, stmt string, args ...any) ([]*types.Genre, error) {
+	rows, err := r.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var genres []*types.Genre
+	for rows.Next() {
+		genre, err := scanGenre(rows)
+		if err != nil {
+			return nil, err
+		}
+		genres = append(genres, genre)
+	}
+
+	return genres, nil
+}
+
+// lockGenres serializes changes of the genre hierarchy so that concurrent moves can't close a cycle.
+func lockGenres(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `LOCK TABLE genres IN SHARE ROW EXCLUSIVE MODE`)
 	return err
 }
+
+func scanGenre(row rowScanner) (*types.Genre, error) {
+	var genre types.Genre
+	var parentID sql.NullInt64
+	err := row.Scan(&genre.ID, &genre.Name, &parentID, &genre.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if parentID.Valid {
+		genre.ParentID = &parentID.Int64
+	}
+
+	return &genre, nil
+}
+
+func (r *GenreRepository) GetGenreTranslations(ctx context.Context, id int64) ([]*types.Translation, error) {
+	return getTranslations(ctx, r.db, genreTranslations, id)
+}
+
+func (r *GenreRepository) GetGenreTranslationsByIDs(ctx context.Context, ids []int64, languages []string) (map[int64]map[string]string, error) {
+	return getTranslationsByIDs(ctx, r.db, genreTranslations, ids, languages)
+}
+
+func (r *GenreRepository) SetGenreTranslation(ctx context.Context, id int64, translation *types.Translation) error {
+	return setTranslation(ctx, r.db, genreTranslations, id, translation)
+}
+
+func (r *GenreRepository) DeleteGenreTranslation(ctx context.Context, id int64, language string) error {
+	return deleteTranslation(ctx, r.db, genreTranslations, id, language)
+}
@@ -1,10 +1,30 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/tredoc/go-crud-api/pkg/types"
 	"strconv"
 	"strings"
+	"time"
 )
 
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type execQuerier interface {
+	rowQuerier
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
 func stringToInt64Slice(s string) ([]int64, error) {
 	s = strings.Trim(s, "{}")
 	if s == "" {
@@ -21,3 +41,198 @@ func stringToInt64Slice(s string) ([]int64, error) {
 	}
 	return result, nil
 }
+
+func nullTimeToDate(t sql.NullTime) *types.CustomDate {
+	if !t.Valid {
+		return nil
+	}
+	return &types.CustomDate{Time: t.Time}
+}
+
+func dateToNullString(d *types.CustomDate) sql.NullString {
+	if d == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: d.Format(time.DateOnly), Valid: true}
+}
+
+func stringToNullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func int64PtrToNullInt64(i *int64) sql.NullInt64 {
+	if i == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *i, Valid: true}
+}
+
+// queryColumn returns the values of the single column the statement selects.
+func queryColumn[T any](ctx context.Context, db *sql.DB, stmt string, args ...any) ([]T, error) {
+	rows, err := db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []T
+	for rows.Next() {
+		var value T
+		err := rows.Scan(&value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, rows.Err()
+}
+
+// isUniqueViolation reports whether err is a postgres unique_violation error.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// versionConflict tells why a versioned write of a live row matched nothing: ErrVersionMismatch
+// when the row exists with another version, ErrNotFound when there is no such row.
+func versionConflict(ctx context.Context, q rowQuerier, table string, id int64) error {
+	stmt := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1 AND deleted_at IS NULL)`, table)
+	var exists bool
+	err := q.QueryRowContext(ctx, stmt, id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrVersionMismatch
+	}
+
+	return ErrNotFound
+}
+
+// lockForUpsert locks the row an upsert replaces and tells whether there is one. An ID of a row in
+// the trash or, with a redirects table, of a merged row fails with ErrIDUnavailable. A non-zero
+// version can't match a row that doesn't exist and fails with ErrVersionMismatch.
+func lockForUpsert(ctx context.Context, tx *sql.Tx, table string, redirects string, id int64, version int64) (bool, error) {
+	stmt := fmt.Sprintf(`SELECT deleted_at IS NOT NULL FROM %s WHERE id = $1 FOR UPDATE`, table)
+	var trashed bool
+	err := tx.QueryRowContext(ctx, stmt, id).Scan(&trashed)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	if err == nil {
+		if trashed {
+			return false, ErrIDUnavailable
+		}
+		return true, nil
+	}
+
+	if redirects != "" {
+		stmt = fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE source_id = $1)`, redirects)
+		var merged bool
+		err = tx.QueryRowContext(ctx, stmt, id).Scan(&merged)
+		if err != nil {
+			return false, err
+		}
+
+		if merged {
+			return false, ErrIDUnavailable
+		}
+	}
+
+	if version != 0 {
+		return false, ErrVersionMismatch
+	}
+
+	return false, nil
+}
+
+// syncSequence moves the ID sequence of the table past a row inserted with a chosen ID, so that
+// later inserts don't collide with it.
+func syncSequence(ctx context.Context, tx *sql.Tx, table string, id int64) error {
+	stmt := fmt.Sprintf(`SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), GREATEST($1, nextval(pg_get_serial_sequence('%[1]s', 'id')) - 1))`, table)
+	_, err := tx.ExecContext(ctx, stmt, id)
+	return err
+}
+
+// lockKey takes a transaction-level advisory lock on the key, so that transactions checking for
+// a row with the key before they write one run one after another.
+func lockKey(ctx context.Context, tx *sql.Tx, key string) error {
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, key)
+	return err
+}
+
+// runBatch applies n operations in a single transaction and returns the error of each one. An
+// atomic batch stops at the first failing operation and is rolled back as a whole, otherwise every
+// operation runs in a savepoint so that a failure only undoes that operation.
+func runBatch(ctx context.Context, db *sql.DB, n int, atomic bool, apply func(tx *sql.Tx, i int) error) ([]error, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		if atomic {
+			errs[i] = apply(tx, i)
+			if errs[i] != nil {
+				return errs, nil
+			}
+			continue
+		}
+
+		_, err = tx.ExecContext(ctx, `SAVEPOINT batch_operation`)
+		if err != nil {
+			return nil, err
+		}
+
+		errs[i] = apply(tx, i)
+		stmt := `RELEASE SAVEPOINT batch_operation`
+		if errs[i] != nil {
+			stmt = `ROLLBACK TO SAVEPOINT batch_operation`
+		}
+
+		_, err = tx.ExecContext(ctx, stmt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return errs, tx.Commit()
+}
+
+// batchRevisionAction returns the action a revision records for a batch operation.
+func batchRevisionAction(op types.BatchOp) types.RevisionAction {
+	switch op {
+	case types.BatchCreate:
+		return types.RevisionCreate
+	case types.BatchDelete:
+		return types.RevisionDelete
+	}
+
+	return types.RevisionUpdate
+}
+
+// queryInt64s collects a single bigint column of the query result.
+func queryInt64s(ctx context.Context, tx *sql.Tx, stmt string, args ...any) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []int64
+	for rows.Next() {
+		var value int64
+		err := rows.Scan(&value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, rows.Err()
+}
@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/tredoc/go-crud-api/pkg/types"
+)
+
+const holdColumns = `h.id, h.book_id, h.user_id, h.status, h.copy_id, h.created_at, h.ready_at, h.expires_at,
+	CASE WHEN h.status = 'waiting' THEN (
+		SELECT count(*) FROM holds AS w WHERE w.book_id = h.book_id AND w.status = 'waiting' AND w.id <= h.id
+	) ELSE 0 END`
+
+type HoldRepository struct {
+	db *sql.DB
+}
+
+func NewHoldRepository(db *sql.DB) *HoldRepository {
+	return &HoldRepository{
+		db: db,
+	}
+}
+
+// CreateHold queues the user for the book. The book row is locked so that the queue and the
+// availability check can't change underneath concurrent hold requests.
+func (r *HoldRepository) CreateHold(ctx context.Context, hold *types.Hold) (*types.Hold, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt := `SELECT id FROM books WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	var bookID int64
+	err = tx.QueryRowContext(ctx, stmt, hold.BookID).Scan(&bookID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	stmt = `SELECT id FROM holds WHERE book_id = $1 AND user_id = $2 AND status IN ('waiting', 'ready')`
+	var foundHoldID int64
+	err = tx.QueryRowContext(ctx, stmt, hold.BookID, hold.UserID).Scan(&foundHoldID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	if foundHoldID != 0 {
+		return nil, ErrEntityExists
+	}
+
+	stmt = `
+		SELECT count(*) FROM copies AS c
+		WHERE c.book_id = $1
+		AND NOT EXISTS (SELECT 1 FROM loans AS l WHERE l.copy_id = c.id AND l.returned_at IS NULL)
+		AND NOT EXISTS (SELECT 1 FROM holds AS h WHERE h.copy_id = c.id AND h.status = 'ready')`
+	var availableCopies int
+	err = tx.QueryRowContext(ctx, stmt, hold.BookID).Scan(&availableCopies)
+	if err != nil {
+		return nil, err
+	}
+
+	if availableCopies > 0 {
+		return nil, ErrCopyAvailable
+	}
+
+	stmt = `INSERT INTO holds(book_id, user_id) VALUES($1, $2) RETURNING id`
+	var id int64
+	err = tx.QueryRowContext(ctx, stmt, hold.BookID, hold.UserID).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt = fmt.Sprintf(`SELECT %s FROM holds AS h WHERE h.id = $1`, holdColumns)
+	newHold, err := scanHold(tx.QueryRowContext(ctx, stmt, id))
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return newHold, nil
+}
+
+func (r *HoldRepository) GetHoldByID(ctx context.Context, id int64) (*types.Hold, error) {
+	stmt := fmt.Sprintf(`SELECT %s FROM holds AS h WHERE h.id = $1`, holdColumns)
+
+	hold, err := scanHold(r.db.QueryRowContext(ctx, stmt, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+func (r *HoldRepository) GetHoldsByBookID(ctx context.Context, bookID int64) ([]*types.Hold, error) {
+	stmt := fmt.Sprintf(`
+		SELECT %s FROM holds AS h
+		WHERE h.book_id = $1 AND h.status IN ('waiting', 'ready')
+		ORDER BY h.status = 'waiting', h.id`, holdColumns)
+
+	return r.queryHolds(ctx, stmt, bookID)
+}
+
+func (r *HoldRepository) GetHoldsByUserID(ctx context.Context, userID int64) ([]*types.Hold, error) {
+	stmt := fmt.Sprintf(`
+		SELECT %s FROM holds AS h
+		WHERE h.user_id = $1 AND h.status IN ('waiting', 'ready')
+		ORDER BY h.id`, holdColumns)
+
+	return r.queryHolds(ctx, stmt, userID)
+}
+
+// CancelHold closes an open hold and passes the copy it was holding, if any, to the next in queue.
+func (r *HoldRepository) CancelHold(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The book is locked before the hold, in the order checkouts lock them.
+	stmt := `SELECT b.id FROM books AS b JOIN holds AS h ON h.book_id = b.id WHERE h.id = $1 FOR UPDATE OF b`
+	var bookID int64
+	err = tx.QueryRowContext(ctx, stmt, id).Scan(&bookID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	stmt = `UPDATE holds SET status = 'cancelled' WHERE id = $1 AND status IN ('waiting', 'ready') RETURNING copy_id`
+	var copyID sql.NullInt64
+	err = tx.QueryRowContext(ctx, stmt, id).Scan(&copyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	if copyID.Valid {
+		err = assignCopyToNextHold(ctx, tx, copyID.Int64)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ExpireHolds closes ready holds that weren't picked up in time and advances their queues.
+func (r *HoldRepository) ExpireHolds(ctx context.Context) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// The books are locked before the holds, in the order checkouts lock them.
+	stmt := `
+		SELECT id FROM books
+		WHERE id IN (SELECT book_id FROM holds WHERE status = 'ready' AND expires_at < now())
+		ORDER BY id
+		FOR UPDATE`
+	_, err = tx.ExecContext(ctx, stmt)
+	if err != nil {
+		return 0, err
+	}
+
+	stmt = `
+		UPDATE holds SET status = 'expired'
+		WHERE id IN (SELECT id FROM holds WHERE status = 'ready' AND expires_at < now() FOR UPDATE SKIP LOCKED)
+		RETURNING copy_id`
+	rows, err := tx.QueryContext(ctx, stmt)
+	if err != nil {
+		return 0, err
+	}
+
+	var copyIDs []int64
+	for rows.Next() {
+		var copyID int64
+		err := rows.Scan(&copyID)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		copyIDs = append(copyIDs, copyID)
+	}
+	rows.Close()
+
+	for _, copyID := range copyIDs {
+		err = assignCopyToNextHold(ctx, tx, copyID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(copyIDs)), nil
+}
+
+func (r *HoldRepository) queryHolds(ctx context.Context, stmt string, args ...any) ([]*types.Hold, error) {
+	rows, err := r.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holds []*types.Hold
+	for rows.Next() {
+		hold, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, hold)
+	}
+
+	return holds, nil
+}
+
+// assignCopyToNextHold makes the copy ready for pickup by the oldest waiting hold on its book.
+// It does nothing when nobody is waiting. The book row is locked first, like CreateHold does, so a
+// hold placed while the copy is handed on is either seen here or sees the copy available.
+func assignCopyToNextHold(ctx context.Context, tx *sql.Tx, copyID int64) error {
+	stmt := `SELECT b.id FROM books AS b JOIN copies AS c ON c.book_id = b.id WHERE c.id = $1 FOR UPDATE OF b`
+	var bookID int64
+	err := tx.QueryRowContext(ctx, stmt, copyID).Scan(&bookID)
+	if err != nil {
+		return err
+	}
+
+	stmt = `
+		UPDATE holds SET status = 'ready', copy_id = $1, ready_at = now(), expires_at = now() + make_interval(secs => $2)
+		WHERE id = (
+			SELECT id FROM holds
+			WHERE book_id = $3 AND status = 'waiting'
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)`
+	_, err = tx.ExecContext(ctx, stmt, copyID, types.HoldPickupWindow.Seconds(), bookID)
+	return err
+}
+
+func scanHold(row rowScanner) (*types.Hold, error) {
+	var hold types.Hold
+	var copyID sql.NullInt64
+	var readyAt, expiresAt sql.NullTime
+	err := row.Scan(&hold.ID, &hold.BookID, &hold.UserID, &hold.Status, &copyID, &hold.CreatedAt, &readyAt, &expiresAt, &hold.Position)
+	if err != nil {
+		return nil, err
+	}
+
+	if copyID.Valid {
+		hold.CopyID = &copyID.Int64
+	}
+
+	if readyAt.Valid {
+		hold.ReadyAt = &readyAt.Time
+	}
+
+	if expiresAt.Valid {
+		hold.ExpiresAt = &expiresAt.Time
+	}
+
+	return &hold, nil
+}
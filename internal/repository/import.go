@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"strings"
+	"time"
+)
+
+type ImportRepository struct {
+	db *sql.DB
+}
+
+func NewImportRepository(db *sql.DB) *ImportRepository {
+	return &ImportRepository{
+		db: db,
+	}
+}
+
+func (r *ImportRepository) CreateImportJob(ctx context.Context, job *types.ImportJob) error {
+	stmt := `
+		INSERT INTO import_jobs(format, dry_run, status, total_rows, actor_id)
+		VALUES($1, $2, $3, $4, $5) RETURNING id, created_at`
+	return r.db.QueryRowContext(ctx, stmt, job.Format, job.DryRun, job.Status, job.TotalRows, int64PtrToNullInt64(job.ActorID)).
+		Scan(&job.ID, &job.CreatedAt)
+}
+
+func (r *ImportRepository) GetImportJob(ctx context.Context, id int64) (*types.ImportJob, error) {
+	stmt := `
+		SELECT id, format, dry_run, status, total_rows, processed_rows, imported_rows, failed_rows,
+		failure, errors, actor_id, created_at, finished_at
+		FROM import_jobs WHERE id = $1`
+
+	var job types.ImportJob
+	var errs []byte
+	var actorID sql.NullInt64
+	var finishedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, stmt, id).Scan(&job.ID, &job.Format, &job.DryRun, &job.Status, &job.TotalRows,
+		&job.ProcessedRows, &job.ImportedRows, &job.FailedRows, &job.Failure, &errs, &actorID, &job.CreatedAt, &finishedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	err = json.Unmarshal(errs, &job.Errors)
+	if err != nil {
+		return nil, err
+	}
+
+	if actorID.Valid {
+		job.ActorID = &actorID.Int64
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+
+	return &job, nil
+}
+
+// UpdateImportJob stores the progress of the job, appends the errors of rows that failed since the
+// last update to the stored ones and renews the heartbeat of the job.
+func (r *ImportRepository) UpdateImportJob(ctx context.Context, job *types.ImportJob, newErrors []*types.ImportRowError) error {
+	if newErrors == nil {
+		newErrors = []*types.ImportRowError{}
+	}
+
+	errs, err := json.Marshal(newErrors)
+	if err != nil {
+		return err
+	}
+
+	stmt := `
+		UPDATE import_jobs SET status = $2, processed_rows = $3, imported_rows = $4, failed_rows = $5,
+		failure = $6, errors = errors || $7::jsonb, finished_at = $8, heartbeat_at = now()
+		WHERE id = $1`
+	_, err = r.db.ExecContext(ctx, stmt, job.ID, job.Status, job.ProcessedRows, job.ImportedRows, job.FailedRows,
+		job.Failure, errs, job.FinishedAt)
+	return err
+}
+
+// FailStaleImportJobs fails the running jobs whose heartbeat is older than staleAfter, e.g. because
+// the server that ran them went down, and returns how many there were.
+func (r *ImportRepository) FailStaleImportJobs(ctx context.Context, failure string, staleAfter time.Duration) (int64, error) {
+	stmt := `
+		UPDATE import_jobs SET status = $1, failure = $2, finished_at = now()
+		WHERE status = $3 AND heartbeat_at < now() - make_interval(secs => $4)`
+	res, err := r.db.ExecContext(ctx, stmt, types.ImportFailed, failure, types.ImportRunning, staleAfter.Seconds())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// UpsertBookByISBN creates the book or, when a live book has the same ISBN, hyphens and spaces
+// aside, replaces the oldest such book but keeps its tags, and its publish date when the book has
+// the same year. The ISBN stays locked until the book is written, so that concurrent imports of an
+// ISBN don't both create a book. It reports whether the book was created and sets its ID, version
+// and creation time.
+func (r *ImportRepository) UpsertBookByISBN(ctx context.Context, book *types.Book) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	key := isbnKey(book.ISBN)
+	err = lockKey(ctx, tx, "books:isbn:"+key)
+	if err != nil {
+		return false, err
+	}
+
+	stmt := `SELECT id, publish_date FROM books WHERE isbn_key = $1 AND deleted_at IS NULL ORDER BY id LIMIT 1 FOR UPDATE`
+	var publishDate time.Time
+	err = tx.QueryRowContext(ctx, stmt, key).Scan(&book.ID, &publishDate)
+	created := errors.Is(err, sql.ErrNoRows)
+	if err != nil && !created {
+		return false, err
+	}
+
+	action := types.RevisionCreate
+	if created {
+		book.ID, book.CreatedAt, err = createBook(ctx, tx, book)
+	} else {
+		if publishDate.Year() == book.PublishDate.Year() {
+			book.PublishDate = types.CustomDate{Time: publishDate}
+		}
+
+		action = types.RevisionUpdate
+		book.Version = 0
+		book.Version, book.CreatedAt, err = updateBook(ctx, tx, book.ID, book)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	err = recordBookRevision(ctx, tx, book.ID, action)
+	if err != nil {
+		return false, err
+	}
+
+	return created, tx.Commit()
+}
+
+// isbnKey normalizes the ISBN like the isbn_key column of books: digits and an uppercase X only.
+func isbnKey(isbn string) string {
+	var key strings.Builder
+	for _, c := range isbn {
+		switch {
+		case '0' <= c && c <= '9':
+			key.WriteRune(c)
+		case c == 'x' || c == 'X':
+			key.WriteByte('X')
+		}
+	}
+
+	return key.String()
+}
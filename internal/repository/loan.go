@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"time"
+)
+
+const loanColumns = `l.id, l.copy_id, c.book_id, l.user_id, c.barcode, l.checked_out_at, l.due_at, l.returned_at, l.renewals`
+
+type LoanRepository struct {
+	db *sql.DB
+}
+
+func NewLoanRepository(db *sql.DB) *LoanRepository {
+	return &LoanRepository{
+		db: db,
+	}
+}
+
+// CheckoutCopy lends the copy with the given barcode to the user. The book, the copy and the user
+// rows are locked for the duration of the transaction so concurrent checkouts can't put one copy
+// on two active loans or push the user over the limit, and holds on the book can't be placed or
+// handed on meanwhile. A copy waiting on a ready hold is only lent to the holder. The checkout
+// fulfils the open hold of the user on the book, a copy another ready hold of theirs was keeping
+// goes to the next in queue.
+func (r *LoanRepository) CheckoutCopy(ctx context.Context, barcode string, userID int64, dueAt time.Time, limit int) (*types.Loan, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	loan := types.Loan{UserID: userID, Barcode: barcode, DueAt: dueAt}
+
+	stmt := `
+		SELECT c.id, c.book_id FROM copies AS c JOIN books AS b ON b.id = c.book_id
+		WHERE c.barcode = $1 AND b.deleted_at IS NULL
+		FOR UPDATE OF b, c`
+	err = tx.QueryRowContext(ctx, stmt, barcode).Scan(&loan.CopyID, &loan.BookID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	stmt = `SELECT count(*) FROM loans WHERE copy_id = $1 AND returned_at IS NULL`
+	var activeCopyLoans int
+	err = tx.QueryRowContext(ctx, stmt, loan.CopyID).Scan(&activeCopyLoans)
+	if err != nil {
+		return nil, err
+	}
+
+	if activeCopyLoans > 0 {
+		return nil, ErrCopyUnavailable
+	}
+
+	stmt = `SELECT id FROM users WHERE id = $1 FOR UPDATE`
+	err = tx.QueryRowContext(ctx, stmt, userID).Scan(&loan.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	stmt = `SELECT count(*) FROM loans WHERE user_id = $1 AND returned_at IS NULL`
+	var activeUserLoans int
+	err = tx.QueryRowContext(ctx, stmt, userID).Scan(&activeUserLoans)
+	if err != nil {
+		return nil, err
+	}
+
+	if activeUserLoans >= limit {
+		return nil, ErrLoanLimitReached
+	}
+
+	stmt = `SELECT user_id FROM holds WHERE copy_id = $1 AND status = 'ready' FOR UPDATE`
+	var holdUserID int64
+	err = tx.QueryRowContext(ctx, stmt, loan.CopyID).Scan(&holdUserID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	if holdUserID != 0 && holdUserID != userID {
+		return nil, ErrCopyUnavailable
+	}
+
+	stmt = `INSERT INTO loans(copy_id, user_id, due_at) VALUES($1, $2, $3) RETURNING id, checked_out_at`
+	err = tx.QueryRowContext(ctx, stmt, loan.CopyID, userID, dueAt).Scan(&loan.ID, &loan.CheckedOutAt)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt = `
+		UPDATE holds SET status = 'fulfilled'
+		WHERE book_id = $1 AND user_id = $2 AND status IN ('waiting', 'ready')
+		RETURNING copy_id`
+	var heldCopyID sql.NullInt64
+	err = tx.QueryRowContext(ctx, stmt, loan.BookID, userID).Scan(&heldCopyID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	if heldCopyID.Valid && heldCopyID.Int64 != loan.CopyID {
+		err = assignCopyToNextHold(ctx, tx, heldCopyID.Int64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &loan, nil
+}
+
+func (r *LoanRepository) GetLoanByID(ctx context.Context, id int64) (*types.Loan, error) {
+	stmt := fmt.Sprintf(`SELECT %s FROM loans AS l JOIN copies AS c ON c.id = l.copy_id WHERE l.id = $1`, loanColumns)
+
+	loan, err := scanLoan(r.db.QueryRowContext(ctx, stmt, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return loan, nil
+}
+
+func (r *LoanRepository) GetLoansByUserID(ctx context.Context, userID int64) ([]*types.Loan, error) {
+	stmt := fmt.Sprintf(`
+		SELECT %s FROM loans AS l JOIN copies AS c ON c.id = l.copy_id
+		WHERE l.user_id = $1 AND l.returned_at IS NULL
+		ORDER BY l.due_at`, loanColumns)
+
+	return r.queryLoans(ctx, stmt, userID)
+}
+
+func (r *LoanRepository) GetLoansByStatus(ctx context.Context, status types.LoanStatus) ([]*types.Loan, error) {
+	var condition string
+	switch status {
+	case types.OverdueLoan:
+		condition = `l.returned_at IS NULL AND l.due_at < now()`
+	case types.ReturnedLoan:
+		condition = `l.returned_at IS NOT NULL`
+	default:
+		condition = `l.returned_at IS NULL`
+	}
+
+	stmt := fmt.Sprintf(`
+		SELECT %s FROM loans AS l JOIN copies AS c ON c.id = l.copy_id
+		WHERE %s
+		ORDER BY l.due_at`, loanColumns, condition)
+
+	return r.queryLoans(ctx, stmt)
+}
+
+// ReturnLoan closes the loan and hands the returned copy to the next waiting hold on its book.
+func (r *LoanRepository) ReturnLoan(ctx context.Context, id int64) (time.Time, error) {
+	var returnedAt time.Time
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return returnedAt, err
+	}
+	defer tx.Rollback()
+
+	stmt := `UPDATE loans SET returned_at = now() WHERE id = $1 AND returned_at IS NULL RETURNING copy_id, returned_at`
+	var copyID int64
+	err = tx.QueryRowContext(ctx, stmt, id).Scan(&copyID, &returnedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return returnedAt, ErrLoanClosed
+		}
+		return returnedAt, err
+	}
+
+	err = assignCopyToNextHold(ctx, tx, copyID)
+	if err != nil {
+		return returnedAt, err
+	}
+
+	err = tx.Commit()
+	return returnedAt, err
+}
+
+// RenewLoan extends an open loan unless it reached maxRenewals or somebody is waiting for the book.
+func (r *LoanRepository) RenewLoan(ctx context.Context, id int64, dueAt time.Time, maxRenewals int) error {
+	stmt := `
+		UPDATE loans SET due_at = $1, renewals = renewals + 1
+		WHERE id = $2 AND returned_at IS NULL AND renewals < $3
+		AND NOT EXISTS (
+			SELECT 1 FROM holds AS h JOIN copies AS c ON c.book_id = h.book_id
+			WHERE c.id = loans.copy_id AND h.status = 'waiting'
+		)`
+	res, err := r.db.ExecContext(ctx, stmt, dueAt, id, maxRenewals)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrLoanClosed
+	}
+
+	return nil
+}
+
+func (r *LoanRepository) queryLoans(ctx context.Context, stmt string, args ...any) ([]*types.Loan, error) {
+	rows, err := r.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var loans []*types.Loan
+	for rows.Next() {
+		loan, err := scanLoan(rows)
+		if err != nil {
+			return nil, err
+		}
+		loans = append(loans, loan)
+	}
+
+	return loans, nil
+}
+
+func scanLoan(row rowScanner) (*types.Loan, error) {
+	var loan types.Loan
+	var returnedAt sql.NullTime
+	err := row.Scan(&loan.ID, &loan.CopyID, &loan.BookID, &loan.UserID, &loan.Barcode, &loan.CheckedOutAt, &loan.DueAt, &returnedAt, &loan.Renewals)
+	if err != nil {
+		return nil, err
+	}
+
+	if returnedAt.Valid {
+		loan.ReturnedAt = &returnedAt.Time
+	}
+
+	return &loan, nil
+}
@@ -10,9 +10,18 @@ import (
 type Book interface {
 	CreateBook(ctx context.Context, book *types.Book) (int64, time.Time, error)
 	GetBookByID(context.Context, int64) (*types.Book, error)
-	GetAllBooks(context.Context) ([]*types.Book, error)
-	UpdateBook(context.Context, int64, *types.Book) error
-	DeleteBook(context.Context, int64) error
+	GetAllBooks(context.Context, *types.BookFilter) ([]*types.Book, error)
+	GetBooksByIDs(context.Context, []int64) ([]*types.Book, error)
+	ExportBooks(context.Context, *types.BookFilter, func(*types.ExportedBook) error) error
+	GetBookPage(context.Context, *types.BookFilter, types.BookOrder, int, int) (*types.BookPage, error)
+	UpdateBook(context.Context, int64, *types.Book, types.RevisionAction) error
+	UpsertBook(context.Context, int64, *types.Book) (bool, error)
+	DeleteBook(context.Context, int64, int64) error
+	ApplyBookBatch(context.Context, []*types.BookOperation, bool) ([]error, error)
+	GetBookTranslations(context.Context, int64) ([]*types.Translation, error)
+	GetBookTranslationsByIDs(context.Context, []int64, []string) (map[int64]map[string]string, error)
+	SetBookTranslation(context.Context, int64, *types.Translation) error
+	DeleteBookTranslation(context.Context, int64, string) error
 }
 
 type Genre interface {
@@ -20,8 +29,16 @@ type Genre interface {
 	GetGenreByID(context.Context, int64) (*types.Genre, error)
 	GetGenresByIDs(context.Context, []int64) ([]*types.Genre, error)
 	GetAllGenres(context.Context) ([]*types.Genre, error)
-	UpdateGenre(context.Context, int64, *types.Genre) error
-	DeleteGenre(context.Context, int64) error
+	UpdateGenre(context.Context, int64, *types.Genre, types.RevisionAction) error
+	UpsertGenre(context.Context, int64, *types.Genre) (bool, error)
+	DeleteGenre(context.Context, int64, int64, types.DeletePolicy) ([]int64, error)
+	ApplyGenreBatch(context.Context, []*types.GenreOperation, bool, types.DeletePolicy) ([]error, error)
+	MergeGenres(context.Context, int64, int64) ([]int64, error)
+	GetGenreTree(context.Context) ([]*types.Genre, error)
+	GetGenreTranslations(context.Context, int64) ([]*types.Translation, error)
+	GetGenreTranslationsByIDs(context.Context, []int64, []string) (map[int64]map[string]string, error)
+	SetGenreTranslation(context.Context, int64, *types.Translation) error
+	DeleteGenreTranslation(context.Context, int64, string) error
 }
 
 type Author interface {
@@ -29,9 +46,14 @@ type Author interface {
 	GetAuthorByID(context.Context, int64) (*types.Author, error)
 	GetAuthorsByIDs(context.Context, []int64) ([]*types.Author, error)
 	GetAuthorByName(context.Context, string, string) (*types.Author, error)
+	FindAuthorByName(context.Context, string) (*types.Author, error)
 	GetAllAuthors(context.Context) ([]*types.Author, error)
-	UpdateAuthor(context.Context, int64, *types.Author) error
-	DeleteAuthor(context.Context, int64) error
+	GetAuthorPage(context.Context, int, int) (*types.AuthorPage, error)
+	UpdateAuthor(context.Context, int64, *types.Author, types.RevisionAction) error
+	UpsertAuthor(context.Context, int64, *types.Author) (bool, error)
+	DeleteAuthor(context.Context, int64, int64, types.DeletePolicy) ([]int64, error)
+	ApplyAuthorBatch(context.Context, []*types.AuthorOperation, bool, types.DeletePolicy) ([]error, error)
+	MergeAuthors(context.Context, int64, int64) ([]int64, error)
 }
 
 type User interface {
@@ -40,18 +62,112 @@ type User interface {
 	GetUserByID(context.Context, int64) (*types.User, error)
 }
 
+type Review interface {
+	CreateReview(context.Context, *types.Review) (int64, time.Time, error)
+	GetReviewByID(context.Context, int64) (*types.Review, error)
+	GetReviewsByBookID(context.Context, int64) ([]*types.Review, error)
+	UpdateReview(context.Context, int64, *types.Review) error
+	DeleteReview(context.Context, int64) error
+}
+
+type Shelf interface {
+	GetShelves(context.Context, int64) ([]*types.Shelf, error)
+	CreateShelf(context.Context, int64, *types.Shelf) (int64, time.Time, error)
+	DeleteShelf(context.Context, int64, int64) error
+	GetStatusShelfBooks(context.Context, int64, types.ShelfStatus) ([]*types.ShelvedBook, error)
+	GetCustomShelfBooks(context.Context, int64, int64) ([]*types.ShelvedBook, error)
+	AddBookToShelf(context.Context, int64, int64, int64) error
+	RemoveBookFromShelf(context.Context, int64, int64, int64) error
+	GetReadingEntry(context.Context, int64, int64) (*types.ReadingEntry, error)
+	SaveReadingEntry(context.Context, int64, *types.ReadingEntry) (time.Time, error)
+	DeleteReadingEntry(context.Context, int64, int64, types.ShelfStatus) error
+	GetReadingStats(context.Context, int64, int) (*types.ReadingStats, error)
+}
+
+type Copy interface {
+	CreateCopy(context.Context, *types.Copy) (int64, time.Time, error)
+	GetCopyByID(context.Context, int64) (*types.Copy, error)
+	GetCopiesByBookID(context.Context, int64) ([]*types.Copy, error)
+	UpdateCopy(context.Context, int64, *types.Copy) error
+	DeleteCopy(context.Context, int64) error
+}
+
+type Loan interface {
+	CheckoutCopy(context.Context, string, int64, time.Time, int) (*types.Loan, error)
+	GetLoanByID(context.Context, int64) (*types.Loan, error)
+	GetLoansByUserID(context.Context, int64) ([]*types.Loan, error)
+	GetLoansByStatus(context.Context, types.LoanStatus) ([]*types.Loan, error)
+	ReturnLoan(context.Context, int64) (time.Time, error)
+	RenewLoan(context.Context, int64, time.Time, int) error
+}
+
+type Hold interface {
+	CreateHold(context.Context, *types.Hold) (*types.Hold, error)
+	GetHoldByID(context.Context, int64) (*types.Hold, error)
+	GetHoldsByBookID(context.Context, int64) ([]*types.Hold, error)
+	GetHoldsByUserID(context.Context, int64) ([]*types.Hold, error)
+	CancelHold(context.Context, int64) error
+	ExpireHolds(context.Context) (int64, error)
+}
+
+type Tag interface {
+	AddBookTags(context.Context, int64, int64, []string) error
+	GetBookTags(context.Context, int64) ([]string, error)
+	RemoveBookTags(context.Context, int64, []string, int64) error
+	GetTagCounts(context.Context, int) ([]*types.TagCount, error)
+}
+
+type Trash interface {
+	GetTrash(context.Context) (*types.Trash, error)
+	RestoreBook(context.Context, int64) error
+	RestoreAuthor(context.Context, int64) ([]int64, error)
+	RestoreGenre(context.Context, int64) ([]int64, error)
+	PurgeTrash(context.Context, time.Time) (int64, error)
+}
+
+type Revision interface {
+	GetRevisions(context.Context, types.EntityType, int64) ([]*types.Revision, error)
+	GetRevision(context.Context, types.EntityType, int64, int64) (*types.Revision, error)
+}
+
+type Import interface {
+	CreateImportJob(context.Context, *types.ImportJob) error
+	GetImportJob(context.Context, int64) (*types.ImportJob, error)
+	UpdateImportJob(context.Context, *types.ImportJob, []*types.ImportRowError) error
+	FailStaleImportJobs(context.Context, string, time.Duration) (int64, error)
+	UpsertBookByISBN(context.Context, *types.Book) (bool, error)
+}
+
 type Repository struct {
 	Book
 	Genre
 	Author
 	User
+	Review
+	Shelf
+	Copy
+	Loan
+	Hold
+	Tag
+	Trash
+	Revision
+	Import
 }
 
 func NewRepository(db *sql.DB) *Repository {
 	return &Repository{
-		Book:   NewBookRepository(db),
-		Genre:  NewGenreRepository(db),
-		Author: NewAuthorRepository(db),
-		User:   NewUserRepository(db),
+		Book:     NewBookRepository(db),
+		Genre:    NewGenreRepository(db),
+		Author:   NewAuthorRepository(db),
+		User:     NewUserRepository(db),
+		Review:   NewReviewRepository(db),
+		Shelf:    NewShelfRepository(db),
+		Copy:     NewCopyRepository(db),
+		Loan:     NewLoanRepository(db),
+		Hold:     NewHoldRepository(db),
+		Tag:      NewTagRepository(db),
+		Trash:    NewTrashRepository(db),
+		Revision: NewRevisionRepository(db),
+		Import:   NewImportRepository(db),
 	}
 }
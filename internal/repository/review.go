@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"time"
+)
+
+type ReviewRepository struct {
+	db *sql.DB
+}
+
+func NewReviewRepository(db *sql.DB) *ReviewRepository {
+	return &ReviewRepository{
+		db: db,
+	}
+}
+
+func (r *ReviewRepository) CreateReview(ctx context.Context, review *types.Review) (int64, time.Time, error) {
+	var id int64
+	var createdAt time.Time
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return id, createdAt, err
+	}
+	defer tx.Rollback()
+
+	stmt := `UPDATE books SET rating_count = rating_count + 1, rating_sum = rating_sum + $1 WHERE id = $2 AND deleted_at IS NULL`
+	res, err := tx.ExecContext(ctx, stmt, review.Rating, review.BookID)
+	if err != nil {
+		return id, createdAt, err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return id, createdAt, err
+	}
+
+	if rowsAffected == 0 {
+		return id, createdAt, ErrNotFound
+	}
+
+	stmt = `SELECT id FROM reviews WHERE book_id = $1 AND user_id = $2`
+	var foundReviewID int64
+	err = tx.QueryRowContext(ctx, stmt, review.BookID, review.UserID).Scan(&foundReviewID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return id, createdAt, err
+	}
+
+	if foundReviewID != 0 {
+		return id, createdAt, ErrEntityExists
+	}
+
+	// A review of the same book by the same user created concurrently passes the check above and is
+	// caught by the unique index.
+	stmt = `INSERT INTO reviews(book_id, user_id, rating, text) VALUES($1, $2, $3, $4) RETURNING id, created_at`
+	err = tx.QueryRowContext(ctx, stmt, review.BookID, review.UserID, review.Rating, review.Text).Scan(&id, &createdAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return id, createdAt, ErrEntityExists
+		}
+		return id, createdAt, err
+	}
+
+	err = tx.Commit()
+	return id, createdAt, err
+}
+
+func (r *ReviewRepository) GetReviewByID(ctx context.Context, id int64) (*types.Review, error) {
+	stmt := `SELECT id, book_id, user_id, rating, text, created_at, updated_at FROM reviews WHERE id = $1`
+
+	var review types.Review
+	err := r.db.QueryRowContext(ctx, stmt, id).Scan(&review.ID, &review.BookID, &review.UserID, &review.Rating, &review.Text, &review.CreatedAt, &review.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+func (r *ReviewRepository) GetReviewsByBookID(ctx context.Context, bookID int64) ([]*types.Review, error) {
+	stmt := `
+		SELECT id, book_id, user_id, rating, text, created_at, updated_at FROM reviews
+		WHERE book_id = $1 AND book_id IN (SELECT id FROM books WHERE deleted_at IS NULL)
+		ORDER BY created_at DESC`
+	rows, err := r.db.QueryContext(ctx, stmt, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []*types.Review
+	for rows.Next() {
+		var review types.Review
+		err := rows.Scan(&review.ID, &review.BookID, &review.UserID, &review.Rating, &review.Text, &review.CreatedAt, &review.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, &review)
+	}
+
+	return reviews, nil
+}
+
+func (r *ReviewRepository) UpdateReview(ctx context.Context, id int64, review *types.Review) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt := `SELECT rating FROM reviews WHERE id = $1 FOR UPDATE`
+	var oldRating uint8
+	err = tx.QueryRowContext(ctx, stmt, id).Scan(&oldRating)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	stmt = `UPDATE reviews SET rating = $1, text = $2, updated_at = now() WHERE id = $3`
+	_, err = tx.ExecContext(ctx, stmt, review.Rating, review.Text, id)
+	if err != nil {
+		return err
+	}
+
+	stmt = `UPDATE books SET rating_sum = rating_sum + $1 WHERE id = $2`
+	_, err = tx.ExecContext(ctx, stmt, int(review.Rating)-int(oldRating), review.BookID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *ReviewRepository) DeleteReview(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var bookID int64
+	var rating uint8
+	stmt := `DELETE FROM reviews WHERE id = $1 RETURNING book_id, rating`
+	err = tx.QueryRowContext(ctx, stmt, id).Scan(&bookID, &rating)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	stmt = `UPDATE books SET rating_count = rating_count - 1, rating_sum = rating_sum - $1 WHERE id = $2`
+	_, err = tx.ExecContext(ctx, stmt, rating, bookID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"time"
+)
+
+// revisionTables are the tables of the entities that keep a revision history.
+var revisionTables = map[types.EntityType]string{
+	types.BookEntity:   "books",
+	types.AuthorEntity: "authors",
+	types.GenreEntity:  "genres",
+}
+
+type RevisionRepository struct {
+	db *sql.DB
+}
+
+func NewRevisionRepository(db *sql.DB) *RevisionRepository {
+	return &RevisionRepository{
+		db: db,
+	}
+}
+
+// GetRevisions returns revisions of the entity, the latest first. An entity without revisions
+// that doesn't exist either, not even in the trash, fails with ErrNotFound.
+func (r *RevisionRepository) GetRevisions(ctx context.Context, entityType types.EntityType, entityID int64) ([]*types.Revision, error) {
+	stmt := `
+		SELECT id, action, snapshot, actor_id, created_at FROM revisions
+		WHERE entity_type = $1 AND entity_id = $2
+		ORDER BY id DESC`
+	rows, err := r.db.QueryContext(ctx, stmt, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []*types.Revision{}
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(revisions) == 0 {
+		stmt = fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1)`, revisionTables[entityType])
+		var exists bool
+		err = r.db.QueryRowContext(ctx, stmt, entityID).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+
+		if !exists {
+			return nil, ErrNotFound
+		}
+	}
+
+	return revisions, nil
+}
+
+func (r *RevisionRepository) GetRevision(ctx context.Context, entityType types.EntityType, entityID int64, id int64) (*types.Revision, error) {
+	stmt := `SELECT id, action, snapshot, actor_id, created_at FROM revisions WHERE entity_type = $1 AND entity_id = $2 AND id = $3`
+	revision, err := scanRevision(r.db.QueryRowContext(ctx, stmt, entityType, entityID, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+// recordRevision stores the snapshot of an entity as part of the transaction that changed it, so
+// a change can't be committed without its revision. The actor is the user of the request.
+func recordRevision(ctx context.Context, tx *sql.Tx, entityType types.EntityType, entityID int64, action types.RevisionAction, snapshot any) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	var actorID *int64
+	if user := types.UserFromContext(ctx); user != nil && !user.IsAnonymous() {
+		actorID = &user.ID
+	}
+
+	stmt := `INSERT INTO revisions(entity_type, entity_id, action, snapshot, actor_id) VALUES($1, $2, $3, $4, $5)`
+	_, err = tx.ExecContext(ctx, stmt, entityType, entityID, action, data, int64PtrToNullInt64(actorID))
+	return err
+}
+
+// recordBookRevision records the book as the transaction sees it. Deleted books are read as well,
+// their authors and genres are the ones that aren't in the trash.
+func recordBookRevision(ctx context.Context, tx *sql.Tx, id int64, action types.RevisionAction) error {
+	var snapshot types.BookSnapshot
+	var publishDate time.Time
+	stmt := `SELECT title, publish_date, isbn, pages FROM books WHERE id = $1`
+	err := tx.QueryRowContext(ctx, stmt, id).Scan(&snapshot.Title, &publishDate, &snapshot.ISBN, &snapshot.Pages)
+	if err != nil {
+		return err
+	}
+	snapshot.PublishDate = types.CustomDate{Time: publishDate}
+
+	snapshot.Authors, err = queryInt64s(ctx, tx, `
+		SELECT ba.author_id FROM book_author AS ba JOIN authors AS a ON a.id = ba.author_id
+		WHERE ba.book_id = $1 AND a.deleted_at IS NULL`, id)
+	if err != nil {
+		return err
+	}
+
+	snapshot.Genres, err = queryInt64s(ctx, tx, `
+		SELECT bg.genre_id FROM book_genre AS bg JOIN genres AS g ON g.id = bg.genre_id
+		WHERE bg.book_id = $1 AND g.deleted_at IS NULL`, id)
+	if err != nil {
+		return err
+	}
+
+	return recordRevision(ctx, tx, types.BookEntity, id, action, &snapshot)
+}
+
+// recordAuthorRevision records the author as the transaction sees it, deleted authors included.
+func recordAuthorRevision(ctx context.Context, tx *sql.Tx, id int64, action types.RevisionAction) error {
+	stmt := fmt.Sprintf(`SELECT %s FROM authors AS a WHERE a.id = $1`, authorColumns)
+	author, err := scanAuthor(tx.QueryRowContext(ctx, stmt, id))
+	if err != nil {
+		return err
+	}
+
+	return recordRevision(ctx, tx, types.AuthorEntity, id, action, types.NewAuthorSnapshot(author))
+}
+
+// recordGenreRevision records the genre as the transaction sees it, deleted genres included.
+func recordGenreRevision(ctx context.Context, tx *sql.Tx, id int64, action types.RevisionAction) error {
+	stmt := fmt.Sprintf(`SELECT %s FROM genres WHERE id = $1`, genreColumns)
+	genre, err := scanGenre(tx.QueryRowContext(ctx, stmt, id))
+	if err != nil {
+		return err
+	}
+
+	return recordRevision(ctx, tx, types.GenreEntity, id, action, types.NewGenreSnapshot(genre))
+}
+
+func scanRevision(row rowScanner) (*types.Revision, error) {
+	var revision types.Revision
+	var snapshot []byte
+	var actorID sql.NullInt64
+	err := row.Scan(&revision.ID, &revision.Action, &snapshot, &actorID, &revision.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	revision.Snapshot = snapshot
+	if actorID.Valid {
+		revision.ActorID = &actorID.Int64
+	}
+	return &revision, nil
+}
@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"time"
+)
+
+type ShelfRepository struct {
+	db *sql.DB
+}
+
+func NewShelfRepository(db *sql.DB) *ShelfRepository {
+	return &ShelfRepository{
+		db: db,
+	}
+}
+
+func (r *ShelfRepository) GetShelves(ctx context.Context, userID int64) ([]*types.Shelf, error) {
+	counts := make(map[types.ShelfStatus]int64)
+	stmt := `SELECT status, count(*) FROM reading_entries WHERE user_id = $1 GROUP BY status`
+	rows, err := r.db.QueryContext(ctx, stmt, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status types.ShelfStatus
+		var count int64
+		err := rows.Scan(&status, &count)
+		if err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+
+	var shelves []*types.Shelf
+	for _, status := range types.ShelfStatuses {
+		shelves = append(shelves, &types.Shelf{Name: string(status), Status: status, BooksCount: counts[status]})
+	}
+
+	stmt = `
+		SELECT s.id, s.name, s.created_at, count(sb.book_id)
+		FROM shelves AS s
+		LEFT JOIN shelf_book AS sb ON s.id = sb.shelf_id
+		WHERE s.user_id = $1
+		GROUP BY s.id
+		ORDER BY s.name`
+	rows, err = r.db.QueryContext(ctx, stmt, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shelf types.Shelf
+		var createdAt time.Time
+		err := rows.Scan(&shelf.ID, &shelf.Name, &createdAt, &shelf.BooksCount)
+		if err != nil {
+			return nil, err
+		}
+		shelf.CreatedAt = &createdAt
+		shelves = append(shelves, &shelf)
+	}
+
+	return shelves, nil
+}
+
+func (r *ShelfRepository) CreateShelf(ctx context.Context, userID int64, shelf *types.Shelf) (int64, time.Time, error) {
+	stmt := `SELECT id FROM shelves WHERE user_id = $1 AND name = $2`
+	var foundShelfID int64
+	err := r.db.QueryRowContext(ctx, stmt, userID, shelf.Name).Scan(&foundShelfID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, time.Time{}, err
+	}
+
+	if foundShelfID != 0 {
+		return 0, time.Time{}, ErrEntityExists
+	}
+
+	var id int64
+	var createdAt time.Time
+	stmt = `INSERT INTO shelves(user_id, name) VALUES($1, $2) RETURNING id, created_at`
+	err = r.db.QueryRowContext(ctx, stmt, userID, shelf.Name).Scan(&id, &createdAt)
+	if err != nil {
+		return 0, createdAt, err
+	}
+
+	return id, createdAt, nil
+}
+
+func (r *ShelfRepository) DeleteShelf(ctx context.Context, userID int64, shelfID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt := `DELETE FROM shelf_book WHERE shelf_id = (SELECT id FROM shelves WHERE id = $1 AND user_id = $2)`
+	_, err = tx.ExecContext(ctx, stmt, shelfID, userID)
+	if err != nil {
+		return err
+	}
+
+	stmt = `DELETE FROM shelves WHERE id = $1 AND user_id = $2`
+	res, err := tx.ExecContext(ctx, stmt, shelfID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return tx.Commit()
+}
+
+func (r *ShelfRepository) GetStatusShelfBooks(ctx context.Context, userID int64, status types.ShelfStatus) ([]*types.ShelvedBook, error) {
+	stmt := `
+		SELECT b.id, b.title, b.pages, re.status, re.current_page, re.started_at, re.finished_at, re.updated_at
+		FROM reading_entries AS re
+		JOIN books AS b ON b.id = re.book_id
+		WHERE re.user_id = $1 AND re.status = $2 AND b.deleted_at IS NULL
+		ORDER BY re.updated_at DESC`
+
+	return r.queryShelvedBooks(ctx, stmt, userID, status)
+}
+
+func (r *ShelfRepository) GetCustomShelfBooks(ctx context.Context, userID int64, shelfID int64) ([]*types.ShelvedBook, error) {
+	err := r.checkShelfOwner(ctx, userID, shelfID)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := `
+		SELECT b.id, b.title, b.pages, COALESCE(re.status, ''), COALESCE(re.current_page, 0), re.started_at, re.finished_at, sb.added_at
+		FROM shelf_book AS sb
+		JOIN books AS b ON b.id = sb.book_id
+		LEFT JOIN reading_entries AS re ON re.book_id = sb.book_id AND re.user_id = $1
+		WHERE sb.shelf_id = $2 AND b.deleted_at IS NULL
+		ORDER BY sb.added_at DESC`
+
+	return r.queryShelvedBooks(ctx, stmt, userID, shelfID)
+}
+
+func (r *ShelfRepository) AddBookToShelf(ctx context.Context, userID int64, shelfID int64, bookID int64) error {
+	err := r.checkShelfOwner(ctx, userID, shelfID)
+	if err != nil {
+		return err
+	}
+
+	stmt := `INSERT INTO shelf_book(shelf_id, book_id) VALUES($1, $2) ON CONFLICT DO NOTHING`
+	res, err := r.db.ExecContext(ctx, stmt, shelfID, bookID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrEntityExists
+	}
+
+	return nil
+}
+
+func (r *ShelfRepository) RemoveBookFromShelf(ctx context.Context, userID int64, shelfID int64, bookID int64) error {
+	err := r.checkShelfOwner(ctx, userID, shelfID)
+	if err != nil {
+		return err
+	}
+
+	stmt := `DELETE FROM shelf_book WHERE shelf_id = $1 AND book_id = $2`
+	res, err := r.db.ExecContext(ctx, stmt, shelfID, bookID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *ShelfRepository) GetReadingEntry(ctx context.Context, userID int64, bookID int64) (*types.ReadingEntry, error) {
+	stmt := `SELECT book_id, status, current_page, started_at, finished_at, updated_at FROM reading_entries WHERE user_id = $1 AND book_id = $2`
+
+	var entry types.ReadingEntry
+	var startedAt, finishedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, stmt, userID, bookID).Scan(&entry.BookID, &entry.Status, &entry.CurrentPage, &startedAt, &finishedAt, &entry.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	entry.StartedAt = nullTimeToDate(startedAt)
+	entry.FinishedAt = nullTimeToDate(finishedAt)
+	return &entry, nil
+}
+
+func (r *ShelfRepository) SaveReadingEntry(ctx context.Context, userID int64, entry *types.ReadingEntry) (time.Time, error) {
+	stmt := `
+		INSERT INTO reading_entries(user_id, book_id, status, current_page, started_at, finished_at)
+		VALUES($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, book_id) DO UPDATE
+		SET status = $3, current_page = $4, started_at = $5, finished_at = $6, updated_at = now()
+		RETURNING updated_at`
+
+	var updatedAt time.Time
+	err := r.db.QueryRowContext(ctx, stmt, userID, entry.BookID, entry.Status, entry.CurrentPage, dateToNullString(entry.StartedAt), dateToNullString(entry.FinishedAt)).Scan(&updatedAt)
+	return updatedAt, err
+}
+
+func (r *ShelfRepository) DeleteReadingEntry(ctx context.Context, userID int64, bookID int64, status types.ShelfStatus) error {
+	stmt := `DELETE FROM reading_entries WHERE user_id = $1 AND book_id = $2 AND status = $3`
+	res, err := r.db.ExecContext(ctx, stmt, userID, bookID, status)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *ShelfRepository) GetReadingStats(ctx context.Context, userID int64, year int) (*types.ReadingStats, error) {
+	stats := types.ReadingStats{Year: year}
+
+	stmt := `
+		SELECT EXTRACT(MONTH FROM re.finished_at)::int, count(*), COALESCE(sum(b.pages), 0)
+		FROM reading_entries AS re
+		JOIN books AS b ON b.id = re.book_id
+		WHERE re.user_id = $1 AND re.status = 'read' AND EXTRACT(YEAR FROM re.finished_at) = $2
+		GROUP BY 1`
+	rows, err := r.db.QueryContext(ctx, stmt, userID, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var month int
+		var books, pages int64
+		err := rows.Scan(&month, &books, &pages)
+		if err != nil {
+			return nil, err
+		}
+
+		stats.BooksByMonth[month-1] = books
+		stats.BooksRead += books
+		stats.PagesRead += pages
+	}
+
+	stmt = `SELECT count(*) FROM reading_entries WHERE user_id = $1 AND status = 'reading'`
+	err = r.db.QueryRowContext(ctx, stmt, userID).Scan(&stats.CurrentlyReading)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+func (r *ShelfRepository) checkShelfOwner(ctx context.Context, userID int64, shelfID int64) error {
+	stmt := `SELECT id FROM shelves WHERE id = $1 AND user_id = $2`
+	var id int64
+	err := r.db.QueryRowContext(ctx, stmt, shelfID, userID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *ShelfRepository) queryShelvedBooks(ctx context.Context, stmt string, args ...any) ([]*types.ShelvedBook, error) {
+	rows, err := r.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var books []*types.ShelvedBook
+	for rows.Next() {
+		var book types.ShelvedBook
+		var startedAt, finishedAt sql.NullTime
+		err := rows.Scan(&book.BookID, &book.Title, &book.Pages, &book.Status, &book.CurrentPage, &startedAt, &finishedAt, &book.AddedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		book.StartedAt = nullTimeToDate(startedAt)
+		book.FinishedAt = nullTimeToDate(finishedAt)
+		books = append(books, &book)
+	}
+
+	return books, nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/tredoc/go-crud-api/pkg/types"
+)
+
+type TagRepository struct {
+	db *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) *TagRepository {
+	return &TagRepository{
+		db: db,
+	}
+}
+
+// AddBookTags applies the tags to the book on behalf of the user, creating tags that don't exist yet.
+// Tags already on the book are left untouched.
+func (r *TagRepository) AddBookTags(ctx context.Context, bookID int64, userID int64, names []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt := `SELECT id FROM books WHERE id = $1 AND deleted_at IS NULL`
+	var foundBookID int64
+	err = tx.QueryRowContext(ctx, stmt, bookID).Scan(&foundBookID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	for _, name := range names {
+		stmt = `INSERT INTO tags(name) VALUES($1) ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name RETURNING id`
+		var tagID int64
+		err = tx.QueryRowContext(ctx, stmt, name).Scan(&tagID)
+		if err != nil {
+			return err
+		}
+
+		stmt = `INSERT INTO book_tag(book_id, tag_id, user_id) VALUES($1, $2, $3) ON CONFLICT DO NOTHING`
+		_, err = tx.ExecContext(ctx, stmt, bookID, tagID, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *TagRepository) GetBookTags(ctx context.Context, bookID int64) ([]string, error) {
+	stmt := `SELECT t.name FROM book_tag AS bt JOIN tags AS t ON t.id = bt.tag_id WHERE bt.book_id = $1 ORDER BY t.name`
+	rows, err := r.db.QueryContext(ctx, stmt, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		err := rows.Scan(&tag)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// RemoveBookTags removes the tags from the book. When appliedBy is not zero only tags applied
+// by that user are removed.
+func (r *TagRepository) RemoveBookTags(ctx context.Context, bookID int64, names []string, appliedBy int64) error {
+	stmt := `
+		DELETE FROM book_tag
+		WHERE book_id = $1 AND tag_id IN (SELECT id FROM tags WHERE name = ANY($2::text[]))
+		AND ($3 = 0 OR user_id = $3)`
+	res, err := r.db.ExecContext(ctx, stmt, bookID, pq.Array(names), appliedBy)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *TagRepository) GetTagCounts(ctx context.Context, limit int) ([]*types.TagCount, error) {
+	stmt := `
+		SELECT t.name, count(*) FROM tags AS t
+		JOIN book_tag AS bt ON t.id = bt.tag_id
+		JOIN books AS b ON b.id = bt.book_id
+		WHERE b.deleted_at IS NULL
+		GROUP BY t.name
+		ORDER BY count(*) DESC, t.name
+		LIMIT $1`
+	rows, err := r.db.QueryContext(ctx, stmt, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []*types.TagCount
+	for rows.Next() {
+		var tag types.TagCount
+		err := rows.Scan(&tag.Name, &tag.Count)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, &tag)
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/tredoc/go-crud-api/pkg/types"
+)
+
+// translationTable describes a table holding translations of one column of its parent table.
+type translationTable struct {
+	name   string
+	key    string
+	column string
+	parent string
+}
+
+var (
+	genreTranslations = translationTable{name: "genre_translations", key: "genre_id", column: "name", parent: "genres"}
+	bookTranslations  = translationTable{name: "book_translations", key: "book_id", column: "title", parent: "books"}
+)
+
+func getTranslations(ctx context.Context, db *sql.DB, table translationTable, id int64) ([]*types.Translation, error) {
+	stmt := fmt.Sprintf(`SELECT language, %s FROM %s WHERE %s = $1 ORDER BY language`, table.column, table.name, table.key)
+	rows, err := db.QueryContext(ctx, stmt, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := []*types.Translation{}
+	for rows.Next() {
+		var translation types.Translation
+		err := rows.Scan(&translation.Language, &translation.Text)
+		if err != nil {
+			return nil, err
+		}
+		translations = append(translations, &translation)
+	}
+
+	return translations, rows.Err()
+}
+
+// getTranslationsByIDs returns translations of the given entities limited to the given languages,
+// keyed by entity ID and language tag.
+func getTranslationsByIDs(ctx context.Context, db *sql.DB, table translationTable, ids []int64, languages []string) (map[int64]map[string]string, error) {
+	stmt := fmt.Sprintf(`SELECT %s, language, %s FROM %s WHERE %s = ANY($1) AND language = ANY($2)`, table.key, table.column, table.name, table.key)
+	rows, err := db.QueryContext(ctx, stmt, pq.Array(ids), pq.Array(languages))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := make(map[int64]map[string]string)
+	for rows.Next() {
+		var id int64
+		var tag, text string
+		err := rows.Scan(&id, &tag, &text)
+		if err != nil {
+			return nil, err
+		}
+
+		if translations[id] == nil {
+			translations[id] = make(map[string]string)
+		}
+		translations[id][tag] = text
+	}
+
+	return translations, rows.Err()
+}
+
+// setTranslation creates or replaces the translation, it fails with ErrNotFound when the
+// translated entity doesn't exist.
+func setTranslation(ctx context.Context, db *sql.DB, table translationTable, id int64, translation *types.Translation) error {
+	stmt := fmt.Sprintf(`
+		INSERT INTO %[1]s (%[2]s, language, %[3]s)
+		SELECT $1, $2, $3 WHERE EXISTS (SELECT 1 FROM %[4]s WHERE id = $1 AND deleted_at IS NULL)
+		ON CONFLICT (%[2]s, language) DO UPDATE SET %[3]s = EXCLUDED.%[3]s`, table.name, table.key, table.column, table.parent)
+	res, err := db.ExecContext(ctx, stmt, id, translation.Language, translation.Text)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func deleteTranslation(ctx context.Context, db *sql.DB, table translationTable, id int64, language string) error {
+	stmt := fmt.Sprintf(`DELETE FROM %s WHERE %s = $1 AND language = $2`, table.name, table.key)
+	res, err := db.ExecContext(ctx, stmt, id, language)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,352 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"time"
+)
+
+type TrashRepository struct {
+	db *sql.DB
+}
+
+func NewTrashRepository(db *sql.DB) *TrashRepository {
+	return &TrashRepository{
+		db: db,
+	}
+}
+
+// GetTrash returns deleted books, authors and genres, the most recently deleted first.
+func (r *TrashRepository) GetTrash(ctx context.Context) (*types.Trash, error) {
+	books, err := r.queryTrashItems(ctx, `SELECT id, title, deleted_at FROM books WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+
+	authors, err := r.queryTrashItems(ctx, `
+		SELECT id, concat_ws(' ', first_name, NULLIF(middle_name, ''), last_name), deleted_at
+		FROM authors WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+
+	genres, err := r.queryTrashItems(ctx, `SELECT id, name, deleted_at FROM genres WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Trash{Books: books, Authors: authors, Genres: genres}, nil
+}
+
+func (r *TrashRepository) RestoreBook(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = r.restore(ctx, tx, `UPDATE books SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return err
+	}
+
+	err = recordBookRevision(ctx, tx, id, types.RevisionRestore)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RestoreAuthor brings the author back to the books it was listed on and returns IDs of those
+// books. It fails with ErrEntityExists when an author added meanwhile has the same name, has an
+// alias that is the name of the restored author or is named like one of its aliases, or took one
+// of its ISNI, VIAF or Wikidata identifiers.
+func (r *TrashRepository) RestoreAuthor(ctx context.Context, id int64) ([]int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt := `SELECT first_name_key, last_name_key FROM authors WHERE id = $1 AND deleted_at IS NOT NULL`
+	var firstNameKey, lastNameKey string
+	err = tx.QueryRowContext(ctx, stmt, id).Scan(&firstNameKey, &lastNameKey)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	err = lockAuthorName(ctx, tx, firstNameKey, lastNameKey)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt = `
+		SELECT EXISTS (
+			SELECT 1 FROM authors AS a JOIN authors AS d ON a.id <> d.id
+			WHERE d.id = $1 AND a.deleted_at IS NULL AND (
+				a.first_name_key = d.first_name_key AND a.last_name_key = d.last_name_key
+				OR a.first_name_key || ' ' || a.last_name_key IN (SELECT name_key FROM author_aliases WHERE author_id = d.id)
+				OR EXISTS (
+					SELECT 1 FROM author_aliases AS al
+					WHERE al.author_id = a.id AND al.name_key = d.first_name_key || ' ' || d.last_name_key
+				)
+			)
+		)`
+	var exists bool
+	err = tx.QueryRowContext(ctx, stmt, id).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		return nil, ErrEntityExists
+	}
+
+	err = r.restore(ctx, tx, `UPDATE authors SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordAuthorRevision(ctx, tx, id, types.RevisionRestore)
+	if err != nil {
+		return nil, err
+	}
+
+	bookIDs, err := queryInt64s(ctx, tx, `SELECT book_id FROM book_author WHERE author_id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return bookIDs, nil
+}
+
+// RestoreGenre brings the genre back to its books and above its subgenres and returns IDs of
+// the books. It fails with ErrEntityExists when a genre with the same name, compared the way
+// CreateGenre does, was added meanwhile.
+func (r *TrashRepository) RestoreGenre(ctx context.Context, id int64) ([]int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = lockGenres(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := `SELECT name FROM genres WHERE id = $1 AND deleted_at IS NOT NULL`
+	var name string
+	err = tx.QueryRowContext(ctx, stmt, id).Scan(&name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT name FROM genres WHERE deleted_at IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var liveName string
+		err = rows.Scan(&liveName)
+		if err != nil {
+			return nil, err
+		}
+		if types.FoldName(liveName) == types.FoldName(name) {
+			return nil, ErrEntityExists
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	err = r.restore(ctx, tx, `UPDATE genres SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordGenreRevision(ctx, tx, id, types.RevisionRestore)
+	if err != nil {
+		return nil, err
+	}
+
+	bookIDs, err := queryInt64s(ctx, tx, `SELECT book_id FROM book_genre WHERE genre_id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return bookIDs, nil
+}
+
+// PurgeTrash permanently removes books, authors and genres deleted before the given time and
+// returns how many of them were removed. Each of them gets a purge revision with its last state,
+// so its history stays readable.
+func (r *TrashRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	err = lockGenres(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+
+	purges := []struct {
+		stmt   string
+		record func(context.Context, *sql.Tx, int64, types.RevisionAction) error
+		purge  func(context.Context, *sql.Tx, int64) error
+	}{
+		{`SELECT id FROM books WHERE deleted_at < $1`, recordBookRevision, purgeBook},
+		{`SELECT id FROM authors WHERE deleted_at < $1`, recordAuthorRevision, purgeAuthor},
+		{`SELECT id FROM genres WHERE deleted_at < $1`, recordGenreRevision, purgeGenre},
+	}
+
+	var purged int64
+	for _, p := range purges {
+		ids, err := queryInt64s(ctx, tx, p.stmt, before)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, id := range ids {
+			err = p.record(ctx, tx, id, types.RevisionPurge)
+			if err != nil {
+				return 0, err
+			}
+
+			err = p.purge(ctx, tx, id)
+			if err != nil {
+				return 0, err
+			}
+		}
+		purged += int64(len(ids))
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+func (r *TrashRepository) restore(ctx context.Context, tx *sql.Tx, stmt string, id int64) error {
+	res, err := tx.ExecContext(ctx, stmt, id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrEntityExists
+		}
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *TrashRepository) queryTrashItems(ctx context.Context, stmt string) ([]*types.TrashItem, error) {
+	rows, err := r.db.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*types.TrashItem{}
+	for rows.Next() {
+		var item types.TrashItem
+		err := rows.Scan(&item.ID, &item.Name, &item.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, &item)
+	}
+
+	return items, rows.Err()
+}
+
+// purgeBook removes the book together with its copies, loans, holds, reviews and shelf entries.
+func purgeBook(ctx context.Context, tx *sql.Tx, id int64) error {
+	stmts := []string{
+		`DELETE FROM reviews WHERE book_id = $1`,
+		`DELETE FROM reading_entries WHERE book_id = $1`,
+		`DELETE FROM shelf_book WHERE book_id = $1`,
+		`DELETE FROM holds WHERE book_id = $1`,
+		`DELETE FROM loans WHERE copy_id IN (SELECT id FROM copies WHERE book_id = $1)`,
+		`DELETE FROM copies WHERE book_id = $1`,
+		`DELETE FROM book_translations WHERE book_id = $1`,
+		`DELETE FROM book_tag WHERE book_id = $1`,
+		`DELETE FROM book_genre WHERE book_id = $1`,
+		`DELETE FROM book_author WHERE book_id = $1`,
+		`DELETE FROM books WHERE id = $1`,
+	}
+
+	return execAll(ctx, tx, stmts, id)
+}
+
+// purgeAuthor removes the author, its aliases and the redirects of authors merged into it.
+func purgeAuthor(ctx context.Context, tx *sql.Tx, id int64) error {
+	stmts := []string{
+		`DELETE FROM book_author WHERE author_id = $1`,
+		`DELETE FROM author_aliases WHERE author_id = $1`,
+		`DELETE FROM author_redirects WHERE target_id = $1`,
+		`DELETE FROM authors WHERE id = $1`,
+	}
+
+	return execAll(ctx, tx, stmts, id)
+}
+
+// purgeGenre removes the genre, its subgenres move up to its parent.
+func purgeGenre(ctx context.Context, tx *sql.Tx, id int64) error {
+	stmts := []string{
+		`DELETE FROM book_genre WHERE genre_id = $1`,
+		`DELETE FROM genre_redirects WHERE target_id = $1`,
+		`DELETE FROM genre_translations WHERE genre_id = $1`,
+		`UPDATE genres SET parent_id = (SELECT parent_id FROM genres WHERE id = $1), version = version + 1 WHERE parent_id = $1`,
+		`DELETE FROM genres WHERE id = $1`,
+	}
+
+	return execAll(ctx, tx, stmts, id)
+}
+
+func execAll(ctx context.Context, tx *sql.Tx, stmts []string, args ...any) error {
+	for _, stmt := range stmts {
+		_, err := tx.ExecContext(ctx, stmt, args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -6,22 +6,31 @@ import (
 	"fmt"
 	"github.com/tredoc/go-crud-api/internal/cache"
 	"github.com/tredoc/go-crud-api/internal/repository"
+	"github.com/tredoc/go-crud-api/internal/validator"
 	"github.com/tredoc/go-crud-api/pkg/types"
+	"sort"
 )
 
 type AuthorService struct {
-	repo  repository.Author
-	cache cache.RCache
+	repo      repository.Author
+	bookRepo  repository.Book
+	revisions revisionLog
+	onDelete  types.DeletePolicy
+	cache     cache.RCache
 }
 
-func NewAuthorService(repo repository.Author, cache cache.RCache) *AuthorService {
+func NewAuthorService(repo repository.Author, bookRepo repository.Book, revisionRepo repository.Revision, onDelete types.DeletePolicy, cache cache.RCache) *AuthorService {
 	return &AuthorService{
-		repo:  repo,
-		cache: cache,
+		repo:      repo,
+		bookRepo:  bookRepo,
+		revisions: revisionLog{repo: revisionRepo, entityType: types.AuthorEntity},
+		onDelete:  onDelete,
+		cache:     cache,
 	}
 }
 
 func (s *AuthorService) CreateAuthor(ctx context.Context, author *types.Author) (*types.Author, error) {
+	author.Normalize()
 	id, err := s.repo.CreateAuthor(ctx, author)
 	if err != nil {
 		if errors.Is(err, repository.ErrEntityExists) {
@@ -39,7 +48,7 @@ func (s *AuthorService) CreateAuthor(ctx context.Context, author *types.Author)
 func (s *AuthorService) GetAuthorByID(ctx context.Context, id int64) (*types.Author, error) {
 	key := fmt.Sprintf("author:%d", id)
 	var authorCache types.Author
-	err := getFromCache(s.cache.Get, key, &authorCache)
+	err := getCachedPayload(ctx, s.cache.Get, key, &authorCache)
 	if err == nil {
 		return &authorCache, nil
 	}
@@ -53,7 +62,7 @@ func (s *AuthorService) GetAuthorByID(ctx context.Context, id int64) (*types.Aut
 		return nil, err
 	}
 
-	go setToCache(s.cache.Set, key, author, cache.EXPIRATION)
+	go setToCache(s.cache.Set, key, newCachedPayload(ctx, author), cache.EXPIRATION)
 	return author, nil
 }
 
@@ -83,10 +92,43 @@ func (s *AuthorService) GetAuthorByName(ctx context.Context, firstName string, l
 	return author, nil
 }
 
+func (s *AuthorService) FindAuthorByName(ctx context.Context, name string) (*types.Author, error) {
+	author, err := s.repo.FindAuthorByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return author, nil
+}
+
+// GetAuthorBooks returns the bibliography of the author sorted by publish date, titled in the
+// languages of the request.
+func (s *AuthorService) GetAuthorBooks(ctx context.Context, id int64) ([]*types.Book, error) {
+	_, err := s.GetAuthorByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	books, err := s.bookRepo.GetAllBooks(ctx, &types.BookFilter{AuthorID: id})
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	sort.SliceStable(books, func(i, j int) bool {
+		return books[i].PublishDate.Before(books[j].PublishDate.Time)
+	})
+
+	return localizeBooks(ctx, s.bookRepo, books)
+}
+
 func (s *AuthorService) GetAllAuthors(ctx context.Context) ([]*types.Author, error) {
 	key := "authors"
 	var authorsCache []*types.Author
-	err := getFromCache(s.cache.Get, key, &authorsCache)
+	err := getCachedPayload(ctx, s.cache.Get, key, &authorsCache)
 	if err == nil {
 		return authorsCache, nil
 	}
@@ -100,11 +142,19 @@ func (s *AuthorService) GetAllAuthors(ctx context.Context) ([]*types.Author, err
 		return nil, err
 	}
 
-	go setToCache(s.cache.Set, key, authors, cache.EXPIRATION)
+	go setToCache(s.cache.Set, key, newCachedPayload(ctx, authors), cache.EXPIRATION)
 	return authors, nil
 }
 
-func (s *AuthorService) UpdateAuthor(ctx context.Context, id int64, author *types.UpdateAuthor) (*types.Author, error) {
+// GetAuthorPage returns the page, counted from 1, of the authors by last and first name, size
+// authors to a page.
+func (s *AuthorService) GetAuthorPage(ctx context.Context, page int, size int) (*types.AuthorPage, error) {
+	return s.repo.GetAuthorPage(ctx, size, (page-1)*size)
+}
+
+// UpdateAuthor applies the given fields to the author. A non-zero version has to match the
+// current one, the author can't change between the read and the write either.
+func (s *AuthorService) UpdateAuthor(ctx context.Context, id int64, version int64, author *types.UpdateAuthor) (*types.Author, error) {
 	existingAuthor, err := s.repo.GetAuthorByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
@@ -114,34 +164,360 @@ func (s *AuthorService) UpdateAuthor(ctx context.Context, id int64, author *type
 		return nil, err
 	}
 
-	if author.FirstName != nil {
-		existingAuthor.FirstName = *author.FirstName
+	err = checkVersion(version, existingAuthor.Version)
+	if err != nil {
+		return nil, err
 	}
 
-	if author.MiddleName != nil {
-		existingAuthor.MiddleName = *author.MiddleName
+	applyAuthorChanges(existingAuthor, author)
+	v := validator.New()
+	types.ValidateAuthorDates(v, existingAuthor)
+	if !v.IsValid() {
+		return nil, &ValidationError{Errors: v.Errors}
 	}
 
-	if author.LastName != nil {
-		existingAuthor.LastName = *author.LastName
+	err = s.saveAuthor(ctx, id, existingAuthor, types.RevisionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	return existingAuthor, nil
+}
+
+func applyAuthorChanges(author *types.Author, changes *types.UpdateAuthor) {
+	if changes.FirstName != nil {
+		author.FirstName = *changes.FirstName
+	}
+
+	if changes.MiddleName != nil {
+		author.MiddleName = *changes.MiddleName
+	}
+
+	if changes.LastName != nil {
+		author.LastName = *changes.LastName
+	}
+
+	if changes.BirthDate != nil {
+		author.BirthDate = changes.BirthDate
+	}
+
+	if changes.DeathDate != nil {
+		author.DeathDate = changes.DeathDate
+	}
+
+	if changes.Nationality != nil {
+		author.Nationality = *changes.Nationality
+	}
+
+	if changes.Biography != nil {
+		author.Biography = *changes.Biography
+	}
+
+	if changes.Aliases != nil {
+		author.Aliases = changes.Aliases
+	}
+
+	if changes.ISNI != nil {
+		author.ISNI = *changes.ISNI
+	}
+
+	if changes.VIAF != nil {
+		author.VIAF = *changes.VIAF
+	}
+
+	if changes.Wikidata != nil {
+		author.Wikidata = *changes.Wikidata
+	}
+}
+
+// PatchAuthor applies a merge patch or JSON patch to the author. The ID and the version stay as
+// they are whatever the patch says.
+func (s *AuthorService) PatchAuthor(ctx context.Context, id int64, version int64, patch *types.Patch) (*types.Author, error) {
+	existingAuthor, err := s.repo.GetAuthorByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	err = checkVersion(version, existingAuthor.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	var author types.Author
+	err = patch.Apply(types.NewAuthorSnapshot(existingAuthor), &author)
+	if err != nil {
+		return nil, &ValidationError{Errors: map[string]string{"patch": err.Error()}}
+	}
+
+	author.ID = id
+	author.Version = existingAuthor.Version
+	author.Normalize()
+
+	v := validator.New()
+	types.ValidateAuthor(v, &author)
+	if !v.IsValid() {
+		return nil, &ValidationError{Errors: v.Errors}
+	}
+
+	err = s.saveAuthor(ctx, id, &author, types.RevisionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &author, nil
+}
+
+// ReplaceAuthor overwrites the author or creates the author under the ID when there is none yet,
+// and reports whether it was created. A non-zero version has to match the current one and can't
+// match an author that doesn't exist.
+func (s *AuthorService) ReplaceAuthor(ctx context.Context, id int64, version int64, author *types.Author) (*types.Author, bool, error) {
+	author.ID = id
+	author.Version = version
+	author.Normalize()
+	created, err := s.repo.UpsertAuthor(ctx, id, author)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, false, ErrNotFound
+		}
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			return nil, false, ErrVersionMismatch
+		}
+		if errors.Is(err, repository.ErrIDUnavailable) {
+			return nil, false, ErrIDUnavailable
+		}
+		if errors.Is(err, repository.ErrEntityExists) {
+			return nil, false, ErrEntityExists
+		}
+
+		return nil, false, err
+	}
+
+	go s.cache.Invalidate("authors")
+	go s.cache.Invalidate(fmt.Sprintf("author:%d", id))
+	return author, created, nil
+}
+
+// DeleteAuthor moves the author to the trash. A non-zero version has to match the current one.
+func (s *AuthorService) DeleteAuthor(ctx context.Context, id int64, version int64) error {
+	author, err := s.repo.GetAuthorByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	err = checkVersion(version, author.Version)
+	if err != nil {
+		return err
 	}
 
-	err = s.repo.UpdateAuthor(ctx, id, existingAuthor)
+	bookIDs, err := s.repo.DeleteAuthor(ctx, id, author.Version, s.onDelete)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			return ErrVersionMismatch
+		}
+		if errors.Is(err, repository.ErrEntityInUse) {
+			return ErrEntityInUse
+		}
+
+		return err
+	}
 	go s.cache.Invalidate("authors")
 	go s.cache.Invalidate(fmt.Sprintf("author:%d", id))
-	return existingAuthor, err
+	invalidateBooks(s.cache, bookIDs)
+	return nil
+}
+
+// BatchAuthors applies the operations of the batch as applyBatch describes. Updates and deletions
+// check the version like UpdateAuthor and DeleteAuthor do.
+func (s *AuthorService) BatchAuthors(ctx context.Context, batch *types.AuthorBatch) ([]error, error) {
+	operations := batch.Operations
+	var ids []int64
+	for _, op := range operations {
+		if op != nil && op.Op != types.BatchCreate {
+			ids = append(ids, op.ID)
+		}
+	}
+
+	authors, err := s.repo.GetAuthorsByIDs(ctx, ids)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	stored := make(map[int64]*types.Author, len(authors))
+	for _, author := range authors {
+		stored[author.ID] = author
+	}
+
+	atomic := batch.Mode == types.AtomicBatch
+	errs, err := applyBatch(len(operations), atomic, func(i int) error {
+		return s.prepareAuthorOperation(operations[i], stored)
+	}, func(indexes []int) ([]error, error) {
+		prepared := make([]*types.AuthorOperation, len(indexes))
+		for j, i := range indexes {
+			prepared[j] = operations[i]
+		}
+		return s.repo.ApplyAuthorBatch(ctx, prepared, atomic, s.onDelete)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var bookIDs []int64
+	go s.cache.Invalidate("authors")
+	for i, op := range operations {
+		if errs[i] == nil {
+			go s.cache.Invalidate(fmt.Sprintf("author:%d", op.ID))
+			bookIDs = append(bookIDs, op.BookIDs...)
+		}
+	}
+
+	invalidateBooks(s.cache, bookIDs)
+
+	return errs, nil
+}
+
+// prepareAuthorOperation validates the operation and, for updates and deletions, puts the stored
+// author, looked up in the authors loaded for the batch, with the changes applied in its place.
+func (s *AuthorService) prepareAuthorOperation(op *types.AuthorOperation, stored map[int64]*types.Author) error {
+	v := validator.New()
+	types.ValidateAuthorOperation(v, op)
+	if !v.IsValid() {
+		return &ValidationError{Errors: v.Errors}
+	}
+
+	if op.Op == types.BatchCreate {
+		op.Author.Normalize()
+		return nil
+	}
+
+	found, ok := stored[op.ID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	author := *found
+	err := checkVersion(op.Version, author.Version)
+	if err != nil {
+		return err
+	}
+
+	op.Author = &author
+	op.Version = author.Version
+	if op.Op == types.BatchDelete {
+		return nil
+	}
+
+	applyAuthorChanges(&author, op.Changes)
+	types.ValidateAuthorDates(v, &author)
+	if !v.IsValid() {
+		return &ValidationError{Errors: v.Errors}
+	}
+
+	return nil
+}
+
+func (s *AuthorService) GetAuthorRevisions(ctx context.Context, id int64) ([]*types.Revision, error) {
+	return s.revisions.list(ctx, id)
+}
+
+func (s *AuthorService) DiffAuthorRevisions(ctx context.Context, id int64, fromID int64, toID int64) (*types.RevisionDiff, error) {
+	return s.revisions.diff(ctx, id, fromID, toID)
 }
 
-func (s *AuthorService) DeleteAuthor(ctx context.Context, id int64) error {
-	err := s.repo.DeleteAuthor(ctx, id)
+// RevertAuthor brings the author back to the given revision and records the result as a new
+// revision. A non-zero version has to match the current one, the author can't change between the
+// read and the write either.
+func (s *AuthorService) RevertAuthor(ctx context.Context, id int64, version int64, revisionID int64) (*types.Author, error) {
+	var author types.Author
+	err := s.revisions.snapshot(ctx, id, revisionID, &author)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.GetAuthorByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	err = checkVersion(version, current.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	author.ID = id
+	author.Version = current.Version
+	author.Normalize()
+	err = s.saveAuthor(ctx, id, &author, types.RevisionRevert)
+	if err != nil {
+		return nil, err
+	}
+
+	return &author, nil
+}
+
+func (s *AuthorService) saveAuthor(ctx context.Context, id int64, author *types.Author, action types.RevisionAction) error {
+	err := s.repo.UpdateAuthor(ctx, id, author, action)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return ErrNotFound
 		}
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			return ErrVersionMismatch
+		}
+		if errors.Is(err, repository.ErrEntityExists) {
+			return ErrEntityExists
+		}
 
 		return err
 	}
+
 	go s.cache.Invalidate("authors")
 	go s.cache.Invalidate(fmt.Sprintf("author:%d", id))
 	return nil
 }
+
+// MergeAuthors folds the source author into the target one and returns the merged target.
+func (s *AuthorService) MergeAuthors(ctx context.Context, targetID int64, sourceID int64) (*types.Author, error) {
+	bookIDs, err := s.repo.MergeAuthors(ctx, sourceID, targetID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		if errors.Is(err, repository.ErrEntityExists) {
+			return nil, ErrEntityExists
+		}
+
+		return nil, err
+	}
+
+	go s.cache.Invalidate("authors")
+	go s.cache.Invalidate(fmt.Sprintf("author:%d", sourceID))
+	go s.cache.Invalidate(fmt.Sprintf("author:%d", targetID))
+	invalidateBooks(s.cache, bookIDs)
+
+	author, err := s.repo.GetAuthorByID(ctx, targetID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return author, nil
+}
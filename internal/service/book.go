@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/tredoc/go-crud-api/internal/cache"
 	"github.com/tredoc/go-crud-api/internal/repository"
+	"github.com/tredoc/go-crud-api/internal/validator"
 	"github.com/tredoc/go-crud-api/pkg/types"
 )
 
@@ -13,14 +14,16 @@ type BookService struct {
 	repo       repository.Book
 	authorRepo repository.Author
 	genreRepo  repository.Genre
+	revisions  revisionLog
 	cache      cache.RCache
 }
 
-func NewBookService(bookRepo repository.Book, authorRepo repository.Author, genreRepo repository.Genre, cache cache.RCache) *BookService {
+func NewBookService(bookRepo repository.Book, authorRepo repository.Author, genreRepo repository.Genre, revisionRepo repository.Revision, cache cache.RCache) *BookService {
 	return &BookService{
 		repo:       bookRepo,
 		authorRepo: authorRepo,
 		genreRepo:  genreRepo,
+		revisions:  revisionLog{repo: revisionRepo, entityType: types.BookEntity},
 		cache:      cache,
 	}
 }
@@ -28,15 +31,10 @@ func NewBookService(bookRepo repository.Book, authorRepo repository.Author, genr
 func (s *BookService) CreateBook(ctx context.Context, book *types.Book) (*types.BookWithDetails, error) {
 	id, createdAt, err := s.repo.CreateBook(ctx, book)
 	if err != nil {
-		return nil, err
-	}
-
-	authors, err := s.authorRepo.GetAuthorsByIDs(ctx, book.Authors)
-	if err != nil {
-		return nil, err
+		return nil, referencesError(err)
 	}
 
-	genres, err := s.genreRepo.GetGenresByIDs(ctx, book.Genres)
+	authors, genres, err := s.getReferences(ctx, book)
 	if err != nil {
 		return nil, err
 	}
@@ -55,10 +53,41 @@ func (s *BookService) CreateBook(ctx context.Context, book *types.Book) (*types.
 	return &newBook, nil
 }
 
+// GetBookByID returns the book with its title and genres in the languages of the request.
 func (s *BookService) GetBookByID(ctx context.Context, id int64) (*types.BookWithDetails, error) {
+	book, err := s.getBookByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := types.LanguagesFromContext(ctx)
+	if len(chain) == 0 {
+		return book, nil
+	}
+
+	translations, err := s.repo.GetBookTranslationsByIDs(ctx, []int64{book.ID}, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	genres, err := localizeGenres(ctx, s.genreRepo, book.Genres)
+	if err != nil {
+		return nil, err
+	}
+
+	localized := *book
+	if title, ok := types.Localize(translations[book.ID], chain); ok {
+		localized.Title = title
+	}
+	localized.Genres = genres
+
+	return &localized, nil
+}
+
+func (s *BookService) getBookByID(ctx context.Context, id int64) (*types.BookWithDetails, error) {
 	key := fmt.Sprintf("book:%d", id)
 	var bookCache types.BookWithDetails
-	err := getFromCache(s.cache.Get, key, &bookCache)
+	err := getCachedPayload(ctx, s.cache.Get, key, &bookCache)
 	if err == nil {
 		return &bookCache, nil
 	}
@@ -83,29 +112,135 @@ func (s *BookService) GetBookByID(ctx context.Context, id int64) (*types.BookWit
 	}
 
 	bookWithDetails := types.BookWithDetails{
-		ID:          id,
-		Title:       book.Title,
-		PublishDate: book.PublishDate,
-		CreatedAt:   book.CreatedAt,
-		ISBN:        book.ISBN,
-		Pages:       book.Pages,
-		Authors:     authors,
-		Genres:      genres,
+		ID:            id,
+		Title:         book.Title,
+		PublishDate:   book.PublishDate,
+		CreatedAt:     book.CreatedAt,
+		ISBN:          book.ISBN,
+		Pages:         book.Pages,
+		AverageRating: book.AverageRating,
+		RatingsCount:  book.RatingsCount,
+		Authors:       authors,
+		Genres:        genres,
+		Tags:          book.Tags,
+		Version:       book.Version,
 	}
 
-	go setToCache(s.cache.Set, key, bookWithDetails, cache.EXPIRATION)
+	go setToCache(s.cache.Set, key, newCachedPayload(ctx, bookWithDetails), cache.EXPIRATION)
 	return &bookWithDetails, nil
 }
 
-func (s *BookService) GetAllBooks(ctx context.Context) ([]*types.Book, error) {
+// GetBooksByIDs returns the books with their authors and genres in the order of the IDs, titled in
+// the languages of the request. It fails with ErrNotFound when one of the books doesn't exist.
+func (s *BookService) GetBooksByIDs(ctx context.Context, ids []int64) ([]*types.BookWithDetails, error) {
+	books, err := s.repo.GetBooksByIDs(ctx, ids)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	books, err = localizeBooks(ctx, s.repo, books)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*types.Book, len(books))
+	var authorIDs, genreIDs []int64
+	for _, book := range books {
+		byID[book.ID] = book
+		authorIDs = append(authorIDs, book.Authors...)
+		genreIDs = append(genreIDs, book.Genres...)
+	}
+
+	authors, err := s.authorRepo.GetAuthorsByIDs(ctx, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	genres, err := s.genreRepo.GetGenresByIDs(ctx, genreIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	genres, err = localizeGenres(ctx, s.genreRepo, genres)
+	if err != nil {
+		return nil, err
+	}
+
+	authorsByID := make(map[int64]*types.Author, len(authors))
+	for _, author := range authors {
+		authorsByID[author.ID] = author
+	}
+
+	genresByID := make(map[int64]*types.Genre, len(genres))
+	for _, genre := range genres {
+		genresByID[genre.ID] = genre
+	}
+
+	details := make([]*types.BookWithDetails, len(ids))
+	for i, id := range ids {
+		book, ok := byID[id]
+		if !ok {
+			return nil, ErrNotFound
+		}
+
+		details[i] = &types.BookWithDetails{
+			ID:            id,
+			Title:         book.Title,
+			PublishDate:   book.PublishDate,
+			CreatedAt:     book.CreatedAt,
+			ISBN:          book.ISBN,
+			Pages:         book.Pages,
+			AverageRating: book.AverageRating,
+			RatingsCount:  book.RatingsCount,
+			Authors:       []*types.Author{},
+			Genres:        []*types.Genre{},
+			Tags:          book.Tags,
+			Version:       book.Version,
+		}
+		for _, authorID := range book.Authors {
+			if author, ok := authorsByID[authorID]; ok {
+				details[i].Authors = append(details[i].Authors, author)
+			}
+		}
+		for _, genreID := range book.Genres {
+			if genre, ok := genresByID[genreID]; ok {
+				details[i].Genres = append(details[i].Genres, genre)
+			}
+		}
+	}
+
+	return details, nil
+}
+
+// GetAllBooks returns books matching the filter titled in the languages of the request. Only the
+// unfiltered list is cached.
+func (s *BookService) GetAllBooks(ctx context.Context, filter *types.BookFilter) ([]*types.Book, error) {
+	books, err := s.getAllBooks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return localizeBooks(ctx, s.repo, books)
+}
+
+func (s *BookService) getAllBooks(ctx context.Context, filter *types.BookFilter) ([]*types.Book, error) {
+	if !filter.IsEmpty() {
+		books, err := s.repo.GetAllBooks(ctx, filter)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+
+		return books, nil
+	}
+
 	key := "books"
 	var booksCache []*types.Book
-	err := getFromCache(s.cache.Get, key, &booksCache)
+	err := getCachedPayload(ctx, s.cache.Get, key, &booksCache)
 	if err == nil {
 		return booksCache, nil
 	}
 
-	books, err := s.repo.GetAllBooks(ctx)
+	books, err := s.repo.GetAllBooks(ctx, filter)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return books, nil
@@ -114,11 +249,25 @@ func (s *BookService) GetAllBooks(ctx context.Context) ([]*types.Book, error) {
 		return nil, err
 	}
 
-	go setToCache(s.cache.Set, key, books, cache.EXPIRATION)
+	go setToCache(s.cache.Set, key, newCachedPayload(ctx, books), cache.EXPIRATION)
 	return books, nil
 }
 
-func (s *BookService) UpdateBook(ctx context.Context, id int64, book *types.UpdateBook) (*types.Book, error) {
+// ExportBooks hands every book matching the filter to each as it is read from the database. The
+// export bypasses the cache, it would have to hold the whole catalogue.
+func (s *BookService) ExportBooks(ctx context.Context, filter *types.BookFilter, each func(*types.ExportedBook) error) error {
+	return s.repo.ExportBooks(ctx, filter, each)
+}
+
+// GetBookPage returns the page, counted from 1, of the books matching the filter, size books to a
+// page.
+func (s *BookService) GetBookPage(ctx context.Context, filter *types.BookFilter, order types.BookOrder, page int, size int) (*types.BookPage, error) {
+	return s.repo.GetBookPage(ctx, filter, order, size, (page-1)*size)
+}
+
+// UpdateBook applies the given fields to the book. A non-zero version has to match the current
+// one, the book can't change between the read and the write either.
+func (s *BookService) UpdateBook(ctx context.Context, id int64, version int64, book *types.UpdateBook) (*types.Book, error) {
 	bookUPD, err := s.repo.GetBookByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
@@ -128,42 +277,354 @@ func (s *BookService) UpdateBook(ctx context.Context, id int64, book *types.Upda
 		return nil, err
 	}
 
-	if book.Title != nil {
-		bookUPD.Title = *book.Title
+	err = checkVersion(version, bookUPD.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	applyBookChanges(bookUPD, book)
+	err = s.saveBook(ctx, id, bookUPD, types.RevisionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	return bookUPD, nil
+}
+
+func applyBookChanges(book *types.Book, changes *types.UpdateBook) {
+	if changes.Title != nil {
+		book.Title = *changes.Title
+	}
+
+	if changes.PublishDate != nil {
+		book.PublishDate = *changes.PublishDate
+	}
+
+	if changes.ISBN != nil {
+		book.ISBN = *changes.ISBN
+	}
+
+	if changes.Pages != nil {
+		book.Pages = *changes.Pages
+	}
+
+	if changes.Authors != nil {
+		book.Authors = changes.Authors
+	}
+
+	if changes.Genres != nil {
+		book.Genres = changes.Genres
+	}
+}
+
+// PatchBook applies a merge patch or JSON patch to the editable fields of the book. The patched
+// book is validated as a whole, a patch can remove required fields after all.
+func (s *BookService) PatchBook(ctx context.Context, id int64, version int64, patch *types.Patch) (*types.Book, error) {
+	book, err := s.repo.GetBookByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	err = checkVersion(version, book.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot types.BookSnapshot
+	err = patch.Apply(types.NewBookSnapshot(book), &snapshot)
+	if err != nil {
+		return nil, &ValidationError{Errors: map[string]string{"patch": err.Error()}}
+	}
+
+	book.ID = id
+	snapshot.Apply(book)
+
+	// A patch can remove the publish date, which a stored book always has.
+	v := validator.New()
+	v.Check(!book.PublishDate.IsZero(), "publish_date", validator.CantBeEmpty)
+	types.ValidateBook(v, book)
+	if !v.IsValid() {
+		return nil, &ValidationError{Errors: v.Errors}
+	}
+
+	err = s.saveBook(ctx, id, book, types.RevisionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	return book, nil
+}
+
+// ReplaceBook overwrites the editable fields of the book or creates the book under the ID when
+// there is none yet, and reports whether it was created. A non-zero version has to match the
+// current one and can't match a book that doesn't exist.
+func (s *BookService) ReplaceBook(ctx context.Context, id int64, version int64, book *types.Book) (*types.Book, bool, error) {
+	replaced, err := s.repo.GetBookByID(ctx, id)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			return nil, false, err
+		}
+		replaced = &types.Book{Tags: []string{}}
+	}
+
+	types.NewBookSnapshot(book).Apply(replaced)
+	replaced.ID = id
+	replaced.Version = version
+	created, err := s.repo.UpsertBook(ctx, id, replaced)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, false, ErrNotFound
+		}
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			return nil, false, ErrVersionMismatch
+		}
+		if errors.Is(err, repository.ErrIDUnavailable) {
+			return nil, false, ErrIDUnavailable
+		}
+		if errors.Is(err, repository.ErrEntityExists) {
+			return nil, false, ErrEntityExists
+		}
+
+		return nil, false, referencesError(err)
+	}
+
+	go s.cache.Invalidate("books")
+	go s.cache.Invalidate(fmt.Sprintf("book:%d", id))
+	return replaced, created, nil
+}
+
+// DeleteBook moves the book to the trash. A non-zero version has to match the current one.
+func (s *BookService) DeleteBook(ctx context.Context, id int64, version int64) error {
+	book, err := s.repo.GetBookByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	err = checkVersion(version, book.Version)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.DeleteBook(ctx, id, book.Version)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			return ErrVersionMismatch
+		}
+
+		return err
+	}
+
+	go s.cache.Invalidate("books")
+	go s.cache.Invalidate(fmt.Sprintf("book:%d", id))
+	return nil
+}
+
+// BatchBooks applies the operations of the batch as applyBatch describes. Updates and deletions
+// check the version like UpdateBook and DeleteBook do.
+func (s *BookService) BatchBooks(ctx context.Context, batch *types.BookBatch) ([]error, error) {
+	operations := batch.Operations
+	var ids []int64
+	for _, op := range operations {
+		if op != nil && op.Op != types.BatchCreate {
+			ids = append(ids, op.ID)
+		}
+	}
+
+	books, err := s.repo.GetBooksByIDs(ctx, ids)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	stored := make(map[int64]*types.Book, len(books))
+	for _, book := range books {
+		stored[book.ID] = book
+	}
+
+	atomic := batch.Mode == types.AtomicBatch
+	errs, err := applyBatch(len(operations), atomic, func(i int) error {
+		return s.prepareBookOperation(operations[i], stored)
+	}, func(indexes []int) ([]error, error) {
+		prepared := make([]*types.BookOperation, len(indexes))
+		for j, i := range indexes {
+			prepared[j] = operations[i]
+		}
+		return s.repo.ApplyBookBatch(ctx, prepared, atomic)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []int64
+	for i, op := range operations {
+		if errs[i] == nil {
+			applied = append(applied, op.ID)
+		}
+	}
+
+	invalidateBooks(s.cache, applied)
+
+	return errs, nil
+}
+
+// prepareBookOperation validates the operation and, for updates and deletions, puts the stored book,
+// looked up in the books loaded for the batch, with the changes applied in its place.
+func (s *BookService) prepareBookOperation(op *types.BookOperation, stored map[int64]*types.Book) error {
+	v := validator.New()
+	types.ValidateBookOperation(v, op)
+	if !v.IsValid() {
+		return &ValidationError{Errors: v.Errors}
+	}
+
+	if op.Op == types.BatchCreate {
+		return nil
 	}
 
-	if book.PublishDate != nil {
-		bookUPD.PublishDate = *book.PublishDate
+	found, ok := stored[op.ID]
+	if !ok {
+		return ErrNotFound
 	}
 
-	if book.ISBN != nil {
-		bookUPD.ISBN = *book.ISBN
+	book := *found
+	err := checkVersion(op.Version, book.Version)
+	if err != nil {
+		return err
+	}
+
+	op.Book = &book
+	op.Version = book.Version
+	if op.Op == types.BatchDelete {
+		return nil
 	}
 
-	if book.Pages != nil {
-		bookUPD.Pages = *book.Pages
+	applyBookChanges(&book, op.Changes)
+	return nil
+}
+
+func (s *BookService) GetBookRevisions(ctx context.Context, id int64) ([]*types.Revision, error) {
+	return s.revisions.list(ctx, id)
+}
+
+func (s *BookService) DiffBookRevisions(ctx context.Context, id int64, fromID int64, toID int64) (*types.RevisionDiff, error) {
+	return s.revisions.diff(ctx, id, fromID, toID)
+}
+
+// RevertBook brings the editable fields of the book back to the given revision and records the
+// result as a new revision. A non-zero version has to match the current one, the book can't change
+// between the read and the write either.
+func (s *BookService) RevertBook(ctx context.Context, id int64, version int64, revisionID int64) (*types.Book, error) {
+	var snapshot types.BookSnapshot
+	err := s.revisions.snapshot(ctx, id, revisionID, &snapshot)
+	if err != nil {
+		return nil, err
 	}
 
-	if book.Authors != nil {
-		bookUPD.Authors = book.Authors
+	book, err := s.repo.GetBookByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
 	}
 
-	if book.Genres != nil {
-		bookUPD.Genres = book.Genres
+	err = checkVersion(version, book.Version)
+	if err != nil {
+		return nil, err
 	}
 
-	err = s.repo.UpdateBook(ctx, id, bookUPD)
+	book.ID = id
+	snapshot.Apply(book)
+	err = s.saveBook(ctx, id, book, types.RevisionRevert)
 	if err != nil {
 		return nil, err
 	}
 
+	return book, nil
+}
+
+func (s *BookService) saveBook(ctx context.Context, id int64, book *types.Book, action types.RevisionAction) error {
+	err := s.repo.UpdateBook(ctx, id, book, action)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			return ErrVersionMismatch
+		}
+
+		return referencesError(err)
+	}
+
 	go s.cache.Invalidate("books")
 	go s.cache.Invalidate(fmt.Sprintf("book:%d", id))
-	return bookUPD, nil
+	return nil
 }
 
-func (s *BookService) DeleteBook(ctx context.Context, id int64) error {
+func (s *BookService) GetBookTranslations(ctx context.Context, id int64) ([]*types.Translation, error) {
+	_, err := s.repo.GetBookByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return s.repo.GetBookTranslations(ctx, id)
+}
+
+// SetBookTranslation saves the title of the book in a language. The cached book is dropped so that
+// Last-Modified of its reads moves along.
+func (s *BookService) SetBookTranslation(ctx context.Context, id int64, translation *types.Translation) error {
+	types.NormalizeBookTranslation(translation)
+	err := s.repo.SetBookTranslation(ctx, id, translation)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+
 	go s.cache.Invalidate("books")
 	go s.cache.Invalidate(fmt.Sprintf("book:%d", id))
-	return s.repo.DeleteBook(ctx, id)
+	return nil
+}
+
+func (s *BookService) DeleteBookTranslation(ctx context.Context, id int64, language string) error {
+	err := s.repo.DeleteBookTranslation(ctx, id, language)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	go s.cache.Invalidate("books")
+	go s.cache.Invalidate(fmt.Sprintf("book:%d", id))
+	return nil
+}
+
+// getReferences returns the authors and genres listed on the stored book.
+func (s *BookService) getReferences(ctx context.Context, book *types.Book) ([]*types.Author, []*types.Genre, error) {
+	authors, err := s.authorRepo.GetAuthorsByIDs(ctx, book.Authors)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	genres, err := s.genreRepo.GetGenresByIDs(ctx, book.Genres)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return authors, genres, nil
 }
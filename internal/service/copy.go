@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/tredoc/go-crud-api/internal/repository"
+	"github.com/tredoc/go-crud-api/pkg/types"
+)
+
+type CopyService struct {
+	repo repository.Copy
+}
+
+func NewCopyService(repo repository.Copy) *CopyService {
+	return &CopyService{
+		repo: repo,
+	}
+}
+
+func (s *CopyService) CreateCopy(ctx context.Context, bookCopy *types.Copy) (*types.Copy, error) {
+	id, createdAt, err := s.repo.CreateCopy(ctx, bookCopy)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		if errors.Is(err, repository.ErrEntityExists) {
+			return nil, ErrEntityExists
+		}
+
+		return nil, err
+	}
+
+	bookCopy.ID = id
+	bookCopy.CreatedAt = createdAt
+	bookCopy.Available = true
+	return bookCopy, nil
+}
+
+func (s *CopyService) GetCopiesByBookID(ctx context.Context, bookID int64) ([]*types.Copy, error) {
+	return s.repo.GetCopiesByBookID(ctx, bookID)
+}
+
+func (s *CopyService) UpdateCopy(ctx context.Context, id int64, bookCopy *types.UpdateCopy) (*types.Copy, error) {
+	existingCopy, err := s.repo.GetCopyByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	if bookCopy.Barcode != nil {
+		existingCopy.Barcode = *bookCopy.Barcode
+	}
+
+	if bookCopy.Location != nil {
+		existingCopy.Location = *bookCopy.Location
+	}
+
+	if bookCopy.Condition != nil {
+		existingCopy.Condition = *bookCopy.Condition
+	}
+
+	err = s.repo.UpdateCopy(ctx, id, existingCopy)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		if errors.Is(err, repository.ErrEntityExists) {
+			return nil, ErrEntityExists
+		}
+
+		return nil, err
+	}
+
+	return existingCopy, nil
+}
+
+func (s *CopyService) DeleteCopy(ctx context.Context, id int64) error {
+	err := s.repo.DeleteCopy(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+
+		if errors.Is(err, repository.ErrCopyUnavailable) {
+			return ErrCopyUnavailable
+		}
+
+		return err
+	}
+
+	return nil
+}
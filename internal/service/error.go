@@ -7,4 +7,27 @@ var (
 	ErrEntityExists          = errors.New("entity exists")
 	ErrCantHandleCredentials = errors.New("can't handle password conversion")
 	ErrCredentialsMismatch   = errors.New("credentials mismatch")
+	ErrNotPermitted          = errors.New("not permitted")
+	ErrCopyUnavailable       = errors.New("copy unavailable")
+	ErrLoanLimitReached      = errors.New("loan limit reached")
+	ErrLoanClosed            = errors.New("loan closed")
+	ErrRenewalNotAllowed     = errors.New("renewal not allowed")
+	ErrCopyAvailable         = errors.New("copy available")
+	ErrGenreCycle            = errors.New("genre cycle")
+	ErrEntityInUse           = errors.New("entity in use")
+	ErrVersionMismatch       = errors.New("version mismatch")
+	ErrIDUnavailable         = errors.New("id unavailable")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key reused")
+	ErrRequestInProgress     = errors.New("request in progress")
+	ErrBatchAborted          = errors.New("batch aborted")
 )
+
+// ValidationError reports fields that can only be checked against stored data,
+// e.g. a reading progress page beyond the length of the book.
+type ValidationError struct {
+	Errors map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	return "validation failed"
+}
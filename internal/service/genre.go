@@ -6,34 +6,37 @@ import (
 	"fmt"
 	"github.com/tredoc/go-crud-api/internal/cache"
 	"github.com/tredoc/go-crud-api/internal/repository"
+	"github.com/tredoc/go-crud-api/internal/validator"
 	"github.com/tredoc/go-crud-api/pkg/types"
 	"strings"
 )
 
 type GenreService struct {
-	repo  repository.Genre
-	cache cache.RCache
+	repo      repository.Genre
+	revisions revisionLog
+	onDelete  types.DeletePolicy
+	cache     cache.RCache
 }
 
-func NewGenreService(repo repository.Genre, cache cache.RCache) *GenreService {
+func NewGenreService(repo repository.Genre, revisionRepo repository.Revision, onDelete types.DeletePolicy, cache cache.RCache) *GenreService {
 	return &GenreService{
-		repo:  repo,
-		cache: cache,
+		repo:      repo,
+		revisions: revisionLog{repo: revisionRepo, entityType: types.GenreEntity},
+		onDelete:  onDelete,
+		cache:     cache,
 	}
 }
 
 func (s *GenreService) CreateGenre(ctx context.Context, genre *types.Genre) (*types.Genre, error) {
-	genres, err := s.repo.GetAllGenres(ctx)
-	if err != nil && !errors.Is(err, repository.ErrNotFound) {
-		return nil, err
+	genre.Name = strings.ToLower(types.NormalizeName(genre.Name))
+	existing, err := s.findNamesake(ctx, 0, genre.Name)
+	if err != nil {
+		return existing, err
 	}
 
-	genre.Name = strings.ToLower(genre.Name)
-
-	for _, g := range genres {
-		if genre.Name == g.Name {
-			return g, ErrEntityExists
-		}
+	err = s.resolveParent(ctx, genre)
+	if err != nil {
+		return nil, err
 	}
 
 	id, err := s.repo.CreateGenre(ctx, genre)
@@ -42,14 +45,29 @@ func (s *GenreService) CreateGenre(ctx context.Context, genre *types.Genre) (*ty
 	}
 
 	genre.ID = id
-	go s.cache.Invalidate("genres")
+	s.invalidateGenres()
 	return genre, nil
 }
 
+// GetGenreByID returns the genre named in the languages of the request.
 func (s *GenreService) GetGenreByID(ctx context.Context, id int64) (*types.Genre, error) {
+	genre, err := s.getGenreByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	genres, err := localizeGenres(ctx, s.repo, []*types.Genre{genre})
+	if err != nil {
+		return nil, err
+	}
+
+	return genres[0], nil
+}
+
+func (s *GenreService) getGenreByID(ctx context.Context, id int64) (*types.Genre, error) {
 	key := fmt.Sprintf("genre:%d", id)
 	var genreCache types.Genre
-	err := getFromCache(s.cache.Get, key, &genreCache)
+	err := getCachedPayload(ctx, s.cache.Get, key, &genreCache)
 	if err == nil {
 		return &genreCache, nil
 	}
@@ -63,7 +81,7 @@ func (s *GenreService) GetGenreByID(ctx context.Context, id int64) (*types.Genre
 		return nil, err
 	}
 
-	go setToCache(s.cache.Set, key, genre, cache.EXPIRATION)
+	go setToCache(s.cache.Set, key, newCachedPayload(ctx, genre), cache.EXPIRATION)
 	return genre, nil
 }
 
@@ -80,10 +98,20 @@ func (s *GenreService) GetGenresByIDs(ctx context.Context, ids []int64) ([]*type
 	return genres, nil
 }
 
+// GetAllGenres returns genres named in the languages of the request.
 func (s *GenreService) GetAllGenres(ctx context.Context) ([]*types.Genre, error) {
+	genres, err := s.getAllGenres(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return localizeGenres(ctx, s.repo, genres)
+}
+
+func (s *GenreService) getAllGenres(ctx context.Context) ([]*types.Genre, error) {
 	key := "genres"
 	var genresCache []*types.Genre
-	err := getFromCache(s.cache.Get, key, &genresCache)
+	err := getCachedPayload(ctx, s.cache.Get, key, &genresCache)
 	if err == nil {
 		return genresCache, nil
 	}
@@ -97,13 +125,44 @@ func (s *GenreService) GetAllGenres(ctx context.Context) ([]*types.Genre, error)
 		return nil, err
 	}
 
-	go setToCache(s.cache.Set, key, genres, cache.EXPIRATION)
+	go setToCache(s.cache.Set, key, newCachedPayload(ctx, genres), cache.EXPIRATION)
 	return genres, nil
 }
 
-func (s *GenreService) UpdateGenre(ctx context.Context, id int64, genre *types.Genre) error {
-	genre.Name = strings.ToLower(genre.Name)
-	err := s.repo.UpdateGenre(ctx, id, genre)
+// GetGenreTree returns the genre hierarchy starting from the top level genres, named in the
+// languages of the request.
+func (s *GenreService) GetGenreTree(ctx context.Context) ([]*types.GenreNode, error) {
+	key := "genres:tree"
+	var genres []*types.Genre
+	err := getCachedPayload(ctx, s.cache.Get, key, &genres)
+	if err != nil {
+		genres, err = s.repo.GetGenreTree(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		go setToCache(s.cache.Set, key, newCachedPayload(ctx, genres), cache.EXPIRATION)
+	}
+
+	genres, err = localizeGenres(ctx, s.repo, genres)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.BuildGenreTree(genres), nil
+}
+
+// UpdateGenre renames the genre and moves it under parent_id. The genre keeps its parent when
+// parent_id is omitted and becomes a top level genre when it is 0. A non-zero version has to match
+// the current one, the genre can't change between the read and the write either.
+func (s *GenreService) UpdateGenre(ctx context.Context, id int64, version int64, genre *types.Genre) error {
+	genre.Name = strings.ToLower(types.NormalizeName(genre.Name))
+	_, err := s.findNamesake(ctx, id, genre.Name)
+	if err != nil {
+		return err
+	}
+
+	existingGenre, err := s.repo.GetGenreByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return ErrNotFound
@@ -111,21 +170,419 @@ func (s *GenreService) UpdateGenre(ctx context.Context, id int64, genre *types.G
 		return err
 	}
 
-	go s.cache.Invalidate("genres")
+	err = checkVersion(version, existingGenre.Version)
+	if err != nil {
+		return err
+	}
+
+	genre.Version = existingGenre.Version
+	if genre.ParentID == nil {
+		genre.ParentID = existingGenre.ParentID
+	} else {
+		err := s.resolveParent(ctx, genre)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.saveGenre(ctx, id, genre, types.RevisionUpdate)
+}
+
+// ReplaceGenre overwrites the genre or creates the genre under the ID when there is none yet, and
+// reports whether it was created. Unlike UpdateGenre a missing parent makes a top level genre. A
+// non-zero version has to match the current one and can't match a genre that doesn't exist.
+func (s *GenreService) ReplaceGenre(ctx context.Context, id int64, version int64, genre *types.Genre) (bool, error) {
+	genre.Name = strings.ToLower(types.NormalizeName(genre.Name))
+	_, err := s.findNamesake(ctx, id, genre.Name)
+	if err != nil {
+		return false, err
+	}
+
+	err = s.resolveParent(ctx, genre)
+	if err != nil {
+		return false, err
+	}
+
+	genre.ID = id
+	genre.Version = version
+	created, err := s.repo.UpsertGenre(ctx, id, genre)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return false, ErrNotFound
+		}
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			return false, ErrVersionMismatch
+		}
+		if errors.Is(err, repository.ErrIDUnavailable) {
+			return false, ErrIDUnavailable
+		}
+		if errors.Is(err, repository.ErrGenreCycle) {
+			return false, ErrGenreCycle
+		}
+
+		return false, err
+	}
+
+	s.invalidateGenres()
 	go s.cache.Invalidate(fmt.Sprintf("genre:%d", id))
+	return created, nil
+}
+
+// DeleteGenre moves the genre to the trash. A non-zero version has to match the current one.
+func (s *GenreService) DeleteGenre(ctx context.Context, id int64, version int64) error {
+	genre, err := s.repo.GetGenreByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	err = checkVersion(version, genre.Version)
+	if err != nil {
+		return err
+	}
+
+	bookIDs, err := s.repo.DeleteGenre(ctx, id, genre.Version, s.onDelete)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			return ErrVersionMismatch
+		}
+		if errors.Is(err, repository.ErrEntityInUse) {
+			return ErrEntityInUse
+		}
+
+		return err
+	}
+
+	s.invalidateGenres()
+	go s.cache.Invalidate(fmt.Sprintf("genre:%d", id))
+	invalidateBooks(s.cache, bookIDs)
 	return nil
 }
 
-func (s *GenreService) DeleteGenre(ctx context.Context, id int64) error {
-	err := s.repo.DeleteGenre(ctx, id)
+// BatchGenres applies the operations of the batch as applyBatch describes. Updates and deletions
+// check the version like UpdateGenre and DeleteGenre do.
+func (s *GenreService) BatchGenres(ctx context.Context, batch *types.GenreBatch) ([]error, error) {
+	operations := batch.Operations
+	genres, err := s.repo.GetAllGenres(ctx)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	state := &genreBatchState{stored: make(map[int64]*types.Genre, len(genres)), names: make(map[string]int64, len(genres))}
+	for _, g := range genres {
+		state.stored[g.ID] = g
+		state.names[types.FoldName(g.Name)] = g.ID
+	}
+
+	atomic := batch.Mode == types.AtomicBatch
+	errs, err := applyBatch(len(operations), atomic, func(i int) error {
+		return s.prepareGenreOperation(ctx, state, operations[i])
+	}, func(indexes []int) ([]error, error) {
+		prepared := make([]*types.GenreOperation, len(indexes))
+		for j, i := range indexes {
+			prepared[j] = operations[i]
+		}
+		return s.repo.ApplyGenreBatch(ctx, prepared, atomic, s.onDelete)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var bookIDs []int64
+	s.invalidateGenres()
+	for i, op := range operations {
+		if errs[i] == nil {
+			go s.cache.Invalidate(fmt.Sprintf("genre:%d", op.ID))
+			bookIDs = append(bookIDs, op.BookIDs...)
+		}
+	}
+
+	invalidateBooks(s.cache, bookIDs)
+
+	return errs, nil
+}
+
+// genreBatchState holds the genres the operations of a batch are checked against, loaded once for
+// the batch, and the folded names taken so far by stored genres and by the operations before.
+type genreBatchState struct {
+	stored map[int64]*types.Genre
+	names  map[string]int64
+}
+
+// prepareGenreOperation validates the operation like CreateGenre, UpdateGenre and DeleteGenre do.
+// Deletions get the stored genre in place of the one given. The name of a created or updated genre
+// is taken for the rest of the batch once the operation is prepared.
+func (s *GenreService) prepareGenreOperation(ctx context.Context, state *genreBatchState, op *types.GenreOperation) error {
+	v := validator.New()
+	types.ValidateGenreOperation(v, op)
+	if !v.IsValid() {
+		return &ValidationError{Errors: v.Errors}
+	}
+
+	if op.Op == types.BatchCreate {
+		op.Genre.Name = strings.ToLower(types.NormalizeName(op.Genre.Name))
+		folded := types.FoldName(op.Genre.Name)
+		if _, taken := state.names[folded]; taken {
+			return ErrEntityExists
+		}
+
+		err := s.resolveBatchParent(ctx, state, op.Genre)
+		if err != nil {
+			return err
+		}
+
+		state.names[folded] = 0
+		return nil
+	}
+
+	genre, ok := state.stored[op.ID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	err := checkVersion(op.Version, genre.Version)
+	if err != nil {
+		return err
+	}
+
+	op.Version = genre.Version
+	if op.Op == types.BatchDelete {
+		deleted := *genre
+		op.Genre = &deleted
+		return nil
+	}
+
+	op.Genre.ID = op.ID
+	op.Genre.Version = genre.Version
+	op.Genre.Name = strings.ToLower(types.NormalizeName(op.Genre.Name))
+	folded := types.FoldName(op.Genre.Name)
+	if id, taken := state.names[folded]; taken && id != op.ID {
+		return ErrEntityExists
+	}
+
+	if op.Genre.ParentID == nil {
+		op.Genre.ParentID = genre.ParentID
+	} else {
+		err = s.resolveBatchParent(ctx, state, op.Genre)
+		if err != nil {
+			return err
+		}
+	}
+
+	state.names[folded] = op.ID
+	return nil
+}
+
+// resolveBatchParent resolves the parent like resolveParent does, looking it up in the genres
+// loaded for the batch first.
+func (s *GenreService) resolveBatchParent(ctx context.Context, state *genreBatchState, genre *types.Genre) error {
+	if genre.ParentID != nil {
+		if parent, ok := state.stored[*genre.ParentID]; ok {
+			genre.ParentID = &parent.ID
+			return nil
+		}
+	}
+
+	return s.resolveParent(ctx, genre)
+}
+
+// MergeGenres folds the source genre into the target one and returns the merged target.
+func (s *GenreService) MergeGenres(ctx context.Context, targetID int64, sourceID int64) (*types.Genre, error) {
+	bookIDs, err := s.repo.MergeGenres(ctx, sourceID, targetID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		if errors.Is(err, repository.ErrEntityExists) {
+			return nil, ErrEntityExists
+		}
+
+		return nil, err
+	}
+
+	s.invalidateGenres()
+	go s.cache.Invalidate(fmt.Sprintf("genre:%d", sourceID))
+	go s.cache.Invalidate(fmt.Sprintf("genre:%d", targetID))
+	invalidateBooks(s.cache, bookIDs)
+
+	genre, err := s.repo.GetGenreByID(ctx, targetID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return genre, nil
+}
+
+func (s *GenreService) GetGenreTranslations(ctx context.Context, id int64) ([]*types.Translation, error) {
+	genre, err := s.repo.GetGenreByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return s.repo.GetGenreTranslations(ctx, genre.ID)
+}
+
+// SetGenreTranslation saves the name of the genre in a language. The cached genres are dropped so
+// that Last-Modified of their reads moves along.
+func (s *GenreService) SetGenreTranslation(ctx context.Context, id int64, translation *types.Translation) error {
+	types.NormalizeGenreTranslation(translation)
+	err := s.repo.SetGenreTranslation(ctx, id, translation)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return ErrNotFound
 		}
+		return err
+	}
+
+	s.invalidateGenres()
+	go s.cache.Invalidate(fmt.Sprintf("genre:%d", id))
+	return nil
+}
 
+func (s *GenreService) DeleteGenreTranslation(ctx context.Context, id int64, language string) error {
+	err := s.repo.DeleteGenreTranslation(ctx, id, language)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
 		return err
 	}
 
+	s.invalidateGenres()
 	go s.cache.Invalidate(fmt.Sprintf("genre:%d", id))
 	return nil
 }
+
+func (s *GenreService) GetGenreRevisions(ctx context.Context, id int64) ([]*types.Revision, error) {
+	return s.revisions.list(ctx, id)
+}
+
+func (s *GenreService) DiffGenreRevisions(ctx context.Context, id int64, fromID int64, toID int64) (*types.RevisionDiff, error) {
+	return s.revisions.diff(ctx, id, fromID, toID)
+}
+
+// RevertGenre brings the name and the parent of the genre back to the given revision and records
+// the result as a new revision. A parent that was deleted since fails validation. A non-zero
+// version has to match the current one, the genre can't change between the read and the write
+// either.
+func (s *GenreService) RevertGenre(ctx context.Context, id int64, version int64, revisionID int64) (*types.Genre, error) {
+	var genre types.Genre
+	err := s.revisions.snapshot(ctx, id, revisionID, &genre)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.GetGenreByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	err = checkVersion(version, current.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.findNamesake(ctx, id, genre.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	genre.ID = id
+	genre.Version = current.Version
+	if genre.ParentID == nil {
+		genre.ParentID = new(int64)
+	}
+
+	err = s.resolveParent(ctx, &genre)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.saveGenre(ctx, id, &genre, types.RevisionRevert)
+	if err != nil {
+		return nil, err
+	}
+
+	return &genre, nil
+}
+
+func (s *GenreService) saveGenre(ctx context.Context, id int64, genre *types.Genre, action types.RevisionAction) error {
+	err := s.repo.UpdateGenre(ctx, id, genre, action)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			return ErrVersionMismatch
+		}
+		if errors.Is(err, repository.ErrGenreCycle) {
+			return ErrGenreCycle
+		}
+		return err
+	}
+
+	s.invalidateGenres()
+	go s.cache.Invalidate(fmt.Sprintf("genre:%d", id))
+	genre.ID = id
+	return nil
+}
+
+// findNamesake fails with ErrEntityExists and returns the genre when another genre than the one
+// with the ID has the name, compared ignoring case and accents.
+func (s *GenreService) findNamesake(ctx context.Context, id int64, name string) (*types.Genre, error) {
+	genres, err := s.repo.GetAllGenres(ctx)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	for _, g := range genres {
+		if g.ID != id && types.FoldName(name) == types.FoldName(g.Name) {
+			return g, ErrEntityExists
+		}
+	}
+
+	return nil, nil
+}
+
+// resolveParent checks that the parent genre exists and replaces a merged parent ID with its
+// target. A zero parent ID stands for a top level genre.
+func (s *GenreService) resolveParent(ctx context.Context, genre *types.Genre) error {
+	if genre.ParentID == nil || *genre.ParentID == 0 {
+		genre.ParentID = nil
+		return nil
+	}
+
+	parent, err := s.repo.GetGenreByID(ctx, *genre.ParentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return &ValidationError{Errors: map[string]string{"parent_id": "genre doesn't exist"}}
+		}
+		return err
+	}
+
+	genre.ParentID = &parent.ID
+	return nil
+}
+
+func (s *GenreService) invalidateGenres() {
+	go s.cache.Invalidate("genres")
+	go s.cache.Invalidate("genres:tree")
+}
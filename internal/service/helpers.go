@@ -1,11 +1,15 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/tredoc/go-crud-api/internal/cache"
+	"github.com/tredoc/go-crud-api/internal/repository"
 	"github.com/tredoc/go-crud-api/pkg/log"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -27,9 +31,9 @@ func getFromCache(fn func(key string) (string, error), key string, entity any) e
 	if err == nil {
 		decodeErr := json.NewDecoder(strings.NewReader(authorCached)).Decode(entity)
 		if decodeErr != nil {
-			log.Error(fmt.Sprintf("cache error on decoding %s from cache string: %s", key, err.Error()))
+			log.Error(fmt.Sprintf("cache error on decoding %s from cache string: %s", key, decodeErr.Error()))
 		}
-		return nil
+		return decodeErr
 	}
 
 	if err != nil && !errors.Is(err, cache.ErrNotFound) {
@@ -37,3 +41,157 @@ func getFromCache(fn func(key string) (string, error), key string, entity any) e
 	}
 	return err
 }
+
+// cachedPayload keeps a payload in the cache together with the time it was built at. Books, authors
+// and genres drop their caches on every change, so the time serves as Last-Modified of the reads
+// built from the payload.
+type cachedPayload struct {
+	Payload  json.RawMessage `json:"payload"`
+	CachedAt time.Time       `json:"cached_at"`
+}
+
+// newCachedPayload wraps a payload built right now and reports now as its Last-Modified.
+func newCachedPayload(ctx context.Context, entity any) *cachedPayload {
+	payload, err := json.Marshal(entity)
+	if err != nil {
+		log.Error("cache error on converting payload to string: " + err.Error())
+	}
+
+	cachedAt := time.Now().UTC().Truncate(time.Second)
+	types.SetLastModified(ctx, cachedAt)
+	return &cachedPayload{Payload: payload, CachedAt: cachedAt}
+}
+
+// getCachedPayload decodes a payload stored with newCachedPayload into entity and reports the time
+// it was cached at as its Last-Modified.
+func getCachedPayload(ctx context.Context, fn func(key string) (string, error), key string, entity any) error {
+	var cached cachedPayload
+	err := getFromCache(fn, key, &cached)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(cached.Payload, entity)
+	if err != nil {
+		return err
+	}
+
+	types.SetLastModified(ctx, cached.CachedAt)
+	return nil
+}
+
+// invalidateBooks drops the book list and every given book from the cache.
+func invalidateBooks(c cache.RCache, bookIDs []int64) {
+	go c.Invalidate("books")
+	for _, id := range bookIDs {
+		go c.Invalidate(fmt.Sprintf("book:%d", id))
+	}
+}
+
+// referencesError turns a ReferenceError of the repository into a ValidationError that lists the
+// authors and genres that don't exist, other errors are returned as they are.
+func referencesError(err error) error {
+	var refErr *repository.ReferenceError
+	if !errors.As(err, &refErr) {
+		return err
+	}
+
+	errs := make(map[string]string)
+	if len(refErr.Authors) > 0 {
+		errs["authors"] = "don't exist: " + joinIDs(refErr.Authors)
+	}
+
+	if len(refErr.Genres) > 0 {
+		errs["genres"] = "don't exist: " + joinIDs(refErr.Genres)
+	}
+
+	return &ValidationError{Errors: errs}
+}
+
+// joinIDs lists the IDs separated by commas.
+func joinIDs(ids []int64) string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = strconv.FormatInt(id, 10)
+	}
+
+	return strings.Join(values, ", ")
+}
+
+// checkVersion fails with ErrVersionMismatch when the client expects another version of an entity
+// than the current one. A zero expected version matches any.
+func checkVersion(expected int64, current int64) error {
+	if expected != 0 && expected != current {
+		return ErrVersionMismatch
+	}
+
+	return nil
+}
+
+// applyBatch prepares every operation of a batch, hands the prepared ones to apply and returns the
+// error of each operation. An atomic batch with a failing operation writes nothing, or has its
+// writes rolled back, and reports the other operations as ErrBatchAborted.
+func applyBatch(n int, atomic bool, prepare func(i int) error, apply func(indexes []int) ([]error, error)) ([]error, error) {
+	errs := make([]error, n)
+	var indexes []int
+	for i := 0; i < n; i++ {
+		errs[i] = prepare(i)
+		if errs[i] == nil {
+			indexes = append(indexes, i)
+		} else if atomic {
+			return abortBatch(errs), nil
+		}
+	}
+
+	if len(indexes) == 0 {
+		return errs, nil
+	}
+
+	applied, err := apply(indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := false
+	for j, i := range indexes {
+		if applied[j] != nil {
+			errs[i] = batchError(applied[j])
+			failed = true
+		}
+	}
+
+	if atomic && failed {
+		return abortBatch(errs), nil
+	}
+
+	return errs, nil
+}
+
+// abortBatch reports every operation that hasn't failed itself as ErrBatchAborted.
+func abortBatch(errs []error) []error {
+	for i := range errs {
+		if errs[i] == nil {
+			errs[i] = ErrBatchAborted
+		}
+	}
+
+	return errs
+}
+
+// batchError turns a repository error of a batch operation into the one the service reports.
+func batchError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return ErrNotFound
+	case errors.Is(err, repository.ErrVersionMismatch):
+		return ErrVersionMismatch
+	case errors.Is(err, repository.ErrEntityExists):
+		return ErrEntityExists
+	case errors.Is(err, repository.ErrEntityInUse):
+		return ErrEntityInUse
+	case errors.Is(err, repository.ErrGenreCycle):
+		return ErrGenreCycle
+	}
+
+	return referencesError(err)
+}
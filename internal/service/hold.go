@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/tredoc/go-crud-api/internal/repository"
+	"github.com/tredoc/go-crud-api/pkg/types"
+)
+
+type HoldService struct {
+	repo repository.Hold
+}
+
+func NewHoldService(repo repository.Hold) *HoldService {
+	return &HoldService{
+		repo: repo,
+	}
+}
+
+func (s *HoldService) PlaceHold(ctx context.Context, bookID int64, userID int64) (*types.Hold, error) {
+	hold, err := s.repo.CreateHold(ctx, &types.Hold{BookID: bookID, UserID: userID})
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return nil, ErrNotFound
+		case errors.Is(err, repository.ErrEntityExists):
+			return nil, ErrEntityExists
+		case errors.Is(err, repository.ErrCopyAvailable):
+			return nil, ErrCopyAvailable
+		default:
+			return nil, err
+		}
+	}
+
+	return hold, nil
+}
+
+func (s *HoldService) GetBookHolds(ctx context.Context, bookID int64) ([]*types.Hold, error) {
+	return s.repo.GetHoldsByBookID(ctx, bookID)
+}
+
+func (s *HoldService) GetUserHolds(ctx context.Context, userID int64) ([]*types.Hold, error) {
+	return s.repo.GetHoldsByUserID(ctx, userID)
+}
+
+func (s *HoldService) CancelHold(ctx context.Context, id int64, user *types.User) error {
+	hold, err := s.repo.GetHoldByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	if hold.UserID != user.ID && !user.IsAdmin() {
+		return ErrNotPermitted
+	}
+
+	err = s.repo.CancelHold(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *HoldService) ExpireHolds(ctx context.Context) (int64, error) {
+	return s.repo.ExpireHolds(ctx)
+}
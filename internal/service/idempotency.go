@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/tredoc/go-crud-api/internal/cache"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"time"
+)
+
+const (
+	IdempotencyExpiration = 24 * time.Hour
+
+	// IdempotencyClaimRenewal is how often the claim of a request still being handled is extended.
+	IdempotencyClaimRenewal = idempotencyClaimExpiration / 3
+
+	// idempotencyClaimExpiration frees the key of a request that never finished, e.g. because the
+	// server went down while handling it.
+	idempotencyClaimExpiration = time.Minute
+)
+
+type IdempotencyService struct {
+	cache cache.RCache
+}
+
+func NewIdempotencyService(cache cache.RCache) *IdempotencyService {
+	return &IdempotencyService{
+		cache: cache,
+	}
+}
+
+// Begin claims the key for the request with the given fingerprint. It returns the response stored
+// for an earlier request with the same key and fingerprint, which is to be replayed, or nil when
+// the request goes ahead and its response is due in Finish. A key used for another request fails
+// with ErrIdempotencyKeyReused, one of a request still being handled with ErrRequestInProgress.
+func (s *IdempotencyService) Begin(_ context.Context, key string, fingerprint string) (*types.IdempotentResponse, error) {
+	claim, err := json.Marshal(&types.IdempotentResponse{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, err
+	}
+
+	claimed, err := s.cache.SetNX(idempotencyKey(key), claim, idempotencyClaimExpiration)
+	if err != nil {
+		return nil, err
+	}
+
+	if claimed {
+		return nil, nil
+	}
+
+	value, err := s.cache.Get(idempotencyKey(key))
+	if err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			return nil, ErrRequestInProgress
+		}
+		return nil, err
+	}
+
+	var stored types.IdempotentResponse
+	err = json.Unmarshal([]byte(value), &stored)
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.Fingerprint != fingerprint {
+		return nil, ErrIdempotencyKeyReused
+	}
+
+	if stored.Status == 0 {
+		return nil, ErrRequestInProgress
+	}
+
+	return &stored, nil
+}
+
+// Finish stores the response of the request that claimed the key.
+func (s *IdempotencyService) Finish(_ context.Context, key string, response *types.IdempotentResponse) error {
+	js, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	return s.cache.Set(idempotencyKey(key), js, IdempotencyExpiration)
+}
+
+// Extend keeps the claim of the key for another idempotencyClaimExpiration, a request that is
+// handled for longer than that renews its claim every IdempotencyClaimRenewal.
+func (s *IdempotencyService) Extend(_ context.Context, key string) error {
+	_, err := s.cache.Expire(idempotencyKey(key), idempotencyClaimExpiration)
+	return err
+}
+
+// Abandon gives up the claim of the key, a retry of the request is handled anew.
+func (s *IdempotencyService) Abandon(_ context.Context, key string) {
+	s.cache.Invalidate(idempotencyKey(key))
+}
+
+func idempotencyKey(key string) string {
+	return "idempotency:" + key
+}
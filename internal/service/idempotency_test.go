@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/tredoc/go-crud-api/internal/cache"
+	mockcache "github.com/tredoc/go-crud-api/mocks/cache"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"net/http"
+	"testing"
+)
+
+type idempotencyServiceSuite struct {
+	suite.Suite
+	cache   *mockcache.RCache
+	service *IdempotencyService
+}
+
+func (s *idempotencyServiceSuite) SetupTest() {
+	s.cache = new(mockcache.RCache)
+	s.service = NewIdempotencyService(s.cache)
+}
+
+func (s *idempotencyServiceSuite) TearDownTest() {
+	s.cache.AssertExpectations(s.T())
+}
+
+func (s *idempotencyServiceSuite) stored(response *types.IdempotentResponse) string {
+	js, err := json.Marshal(response)
+	s.NoError(err, "can`t marshal stored response")
+	return string(js)
+}
+
+func (s *idempotencyServiceSuite) TestBegin_Claimed() {
+	claim := s.stored(&types.IdempotentResponse{Fingerprint: "create"})
+	s.cache.On("SetNX", "idempotency:user:1:claimed", []byte(claim), idempotencyClaimExpiration).Return(true, nil).Once()
+
+	stored, err := s.service.Begin(context.Background(), "user:1:claimed", "create")
+
+	s.NoError(err)
+	s.Nil(stored)
+}
+
+func (s *idempotencyServiceSuite) TestBegin_ClaimConflict() {
+	s.cache.On("SetNX", "idempotency:user:1:running", mock.AnythingOfType("[]uint8"), idempotencyClaimExpiration).Return(false, nil).Once()
+	s.cache.On("Get", "idempotency:user:1:running").Return(s.stored(&types.IdempotentResponse{Fingerprint: "create"}), nil).Once()
+
+	stored, err := s.service.Begin(context.Background(), "user:1:running", "create")
+
+	s.ErrorIs(err, ErrRequestInProgress)
+	s.Nil(stored)
+}
+
+func (s *idempotencyServiceSuite) TestBegin_ClaimExpired() {
+	s.cache.On("SetNX", "idempotency:user:1:expired", mock.AnythingOfType("[]uint8"), idempotencyClaimExpiration).Return(false, nil).Once()
+	s.cache.On("Get", "idempotency:user:1:expired").Return("", cache.ErrNotFound).Once()
+
+	_, err := s.service.Begin(context.Background(), "user:1:expired", "create")
+
+	s.ErrorIs(err, ErrRequestInProgress)
+}
+
+func (s *idempotencyServiceSuite) TestBegin_Replay() {
+	response := &types.IdempotentResponse{
+		Fingerprint: "create",
+		Status:      http.StatusCreated,
+		Header:      http.Header{"Content-Type": {"application/json"}},
+		Body:        []byte(`{"genre":{"id":5,"name":"cyberpunk"}}`),
+	}
+	s.cache.On("SetNX", "idempotency:user:1:done", mock.AnythingOfType("[]uint8"), idempotencyClaimExpiration).Return(false, nil).Once()
+	s.cache.On("Get", "idempotency:user:1:done").Return(s.stored(response), nil).Once()
+
+	stored, err := s.service.Begin(context.Background(), "user:1:done", "create")
+
+	s.NoError(err)
+	s.Equal(response, stored)
+}
+
+func (s *idempotencyServiceSuite) TestBegin_FingerprintMismatch() {
+	response := &types.IdempotentResponse{Fingerprint: "create", Status: http.StatusCreated, Body: []byte(`{}`)}
+	s.cache.On("SetNX", "idempotency:user:1:reused", mock.AnythingOfType("[]uint8"), idempotencyClaimExpiration).Return(false, nil).Once()
+	s.cache.On("Get", "idempotency:user:1:reused").Return(s.stored(response), nil).Once()
+
+	stored, err := s.service.Begin(context.Background(), "user:1:reused", "update")
+
+	s.ErrorIs(err, ErrIdempotencyKeyReused)
+	s.Nil(stored)
+}
+
+func (s *idempotencyServiceSuite) TestAbandon_AfterError() {
+	claim := s.stored(&types.IdempotentResponse{Fingerprint: "create"})
+	s.cache.On("SetNX", "idempotency:user:1:failed", []byte(claim), idempotencyClaimExpiration).Return(true, nil).Twice()
+	s.cache.On("Invalidate", "idempotency:user:1:failed").Return().Once()
+
+	_, err := s.service.Begin(context.Background(), "user:1:failed", "create")
+	s.NoError(err)
+
+	s.service.Abandon(context.Background(), "user:1:failed")
+
+	stored, err := s.service.Begin(context.Background(), "user:1:failed", "create")
+	s.NoError(err, "the retry claims the key anew")
+	s.Nil(stored)
+}
+
+func (s *idempotencyServiceSuite) TestFinish() {
+	response := &types.IdempotentResponse{Fingerprint: "create", Status: http.StatusCreated, Body: []byte(`{}`)}
+	s.cache.On("Set", "idempotency:user:1:finished", []byte(s.stored(response)), IdempotencyExpiration).Return(nil).Once()
+
+	err := s.service.Finish(context.Background(), "user:1:finished", response)
+
+	s.NoError(err)
+}
+
+func (s *idempotencyServiceSuite) TestExtend() {
+	s.cache.On("Expire", "idempotency:user:1:slow", idempotencyClaimExpiration).Return(true, nil).Once()
+
+	err := s.service.Extend(context.Background(), "user:1:slow")
+
+	s.NoError(err)
+}
+
+func TestIdempotencyService(t *testing.T) {
+	suite.Run(t, new(idempotencyServiceSuite))
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/tredoc/go-crud-api/internal/cache"
+	"github.com/tredoc/go-crud-api/internal/repository"
+	"github.com/tredoc/go-crud-api/internal/validator"
+	"github.com/tredoc/go-crud-api/pkg/log"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"sync"
+	"time"
+)
+
+const (
+	// importProgressInterval is the number of rows processed between saves of the job progress.
+	importProgressInterval = 25
+	// importHeartbeatInterval is the longest a running import goes without saving its progress.
+	importHeartbeatInterval = 30 * time.Second
+
+	// ImportStaleAfter is how long a running import can go without saving its progress before it's
+	// failed as interrupted, e.g. because the server that ran it went down.
+	ImportStaleAfter = 5 * time.Minute
+)
+
+type ImportService struct {
+	repo    repository.Import
+	cache   cache.RCache
+	books   Book
+	authors Author
+	genres  Genre
+	ctx     context.Context
+	workers *sync.WaitGroup
+}
+
+// NewImportService returns the service. Imports stop when ctx is cancelled and are counted in
+// workers, so a shutdown can wait for them.
+func NewImportService(ctx context.Context, workers *sync.WaitGroup, repo repository.Import, cache cache.RCache, books Book, authors Author, genres Genre) *ImportService {
+	return &ImportService{
+		repo:    repo,
+		cache:   cache,
+		books:   books,
+		authors: authors,
+		genres:  genres,
+		ctx:     ctx,
+		workers: workers,
+	}
+}
+
+// StartImport creates the job and imports the rows in the background. The import keeps the user
+// and the other values of the request, not its cancellation, and stops at a shutdown instead.
+func (s *ImportService) StartImport(ctx context.Context, job *types.ImportJob, rows []*types.ImportRow) error {
+	job.Status = types.ImportRunning
+	job.TotalRows = len(rows)
+	job.Errors = []*types.ImportRowError{}
+	if user := types.UserFromContext(ctx); user != nil && !user.IsAnonymous() {
+		job.ActorID = &user.ID
+	}
+
+	err := s.repo.CreateImportJob(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	running := *job
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		s.runImport(context.WithoutCancel(ctx), &running, rows)
+	}()
+	return nil
+}
+
+func (s *ImportService) GetImportJob(ctx context.Context, id int64) (*types.ImportJob, error) {
+	job, err := s.repo.GetImportJob(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// FailStaleImports fails the running jobs that stopped saving their progress for ImportStaleAfter,
+// their rows were only kept in memory of a server that is gone. Jobs of other servers that are
+// still running keep going.
+func (s *ImportService) FailStaleImports(ctx context.Context) (int64, error) {
+	return s.repo.FailStaleImportJobs(ctx, "interrupted, upload the file again", ImportStaleAfter)
+}
+
+// runImport imports the rows and saves the progress of the job along the way. Errors of failed rows
+// are kept until a save stores them.
+func (s *ImportService) runImport(ctx context.Context, job *types.ImportJob, rows []*types.ImportRow) {
+	var unsaved []*types.ImportRowError
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Error(fmt.Sprintf("import job %d failed: %v", job.ID, recovered))
+			job.Status = types.ImportFailed
+			job.Failure = "the server encountered a problem and could not finish the import"
+			s.finishImport(ctx, job, unsaved)
+		}
+	}()
+
+	names := importNames{
+		authors:   s.authors,
+		genres:    s.genres,
+		dryRun:    job.DryRun,
+		authorIDs: make(map[string]int64),
+		genreIDs:  make(map[string]int64),
+	}
+
+	job.Status = types.ImportCompleted
+	savedAt := time.Now()
+	for _, row := range rows {
+		if s.ctx.Err() != nil {
+			job.Status = types.ImportFailed
+			job.Failure = "interrupted by a shutdown, upload the file again"
+			break
+		}
+
+		errs := s.importRow(ctx, &names, row, job.DryRun)
+		job.ProcessedRows++
+		if len(errs) > 0 {
+			job.FailedRows++
+			unsaved = append(unsaved, &types.ImportRowError{Row: row.Line, Errors: errs})
+		} else {
+			job.ImportedRows++
+		}
+
+		due := job.ProcessedRows%importProgressInterval == 0 || time.Since(savedAt) >= importHeartbeatInterval
+		if due && job.ProcessedRows < job.TotalRows {
+			if s.saveImport(ctx, job, unsaved) {
+				unsaved = nil
+			}
+			savedAt = time.Now()
+		}
+	}
+
+	s.finishImport(ctx, job, unsaved)
+}
+
+func (s *ImportService) finishImport(ctx context.Context, job *types.ImportJob, unsaved []*types.ImportRowError) {
+	finishedAt := time.Now().UTC()
+	job.FinishedAt = &finishedAt
+	s.saveImport(ctx, job, unsaved)
+}
+
+// saveImport stores the progress of the job and the errors not saved yet, and reports whether it
+// did. The import goes on when that fails, the next save catches up.
+func (s *ImportService) saveImport(ctx context.Context, job *types.ImportJob, unsaved []*types.ImportRowError) bool {
+	err := s.repo.UpdateImportJob(ctx, job, unsaved)
+	if err != nil {
+		log.Error(fmt.Sprintf("can't save import job %d: %s", job.ID, err.Error()))
+		return false
+	}
+
+	return true
+}
+
+// importRow creates the book of the row. It returns the errors by field of a row that wasn't
+// imported.
+func (s *ImportService) importRow(ctx context.Context, names *importNames, row *types.ImportRow, dryRun bool) map[string]string {
+	book, errs := prepareImportRow(ctx, names, row)
+	if errs != nil || dryRun {
+		return errs
+	}
+
+	_, err := s.books.CreateBook(ctx, book)
+	if err != nil {
+		return importRowErrors(err)
+	}
+
+	return nil
+}
+
+// UpsertBooks imports the rows one after the other. A row creates its book or, when a book with its
+// ISBN exists, replaces that one but keeps its tags. A replaced book keeps its publish date when the
+// row has the same year, MARC only dates books by their year.
+func (s *ImportService) UpsertBooks(ctx context.Context, rows []*types.ImportRow) []*types.ImportOutcome {
+	names := importNames{
+		authors:   s.authors,
+		genres:    s.genres,
+		authorIDs: make(map[string]int64),
+		genreIDs:  make(map[string]int64),
+	}
+
+	outcomes := make([]*types.ImportOutcome, len(rows))
+	for i, row := range rows {
+		outcomes[i] = s.upsertRow(ctx, &names, row)
+	}
+
+	return outcomes
+}
+
+func (s *ImportService) upsertRow(ctx context.Context, names *importNames, row *types.ImportRow) *types.ImportOutcome {
+	book, errs := prepareImportRow(ctx, names, row)
+	if errs != nil {
+		return &types.ImportOutcome{Errors: errs}
+	}
+
+	created, err := s.repo.UpsertBookByISBN(ctx, book)
+	if err != nil {
+		return &types.ImportOutcome{Errors: importRowErrors(referencesError(err))}
+	}
+
+	invalidateBooks(s.cache, []int64{book.ID})
+	return &types.ImportOutcome{ID: book.ID, Version: book.Version, Created: created}
+}
+
+// prepareImportRow validates the row with types.ValidateBook and resolves its authors and genres.
+// It returns the book or the errors by field of a row that can't be imported.
+func prepareImportRow(ctx context.Context, names *importNames, row *types.ImportRow) (*types.Book, map[string]string) {
+	if len(row.Errors) > 0 {
+		return nil, row.Errors
+	}
+
+	book := row.Book
+	book.Authors = make([]int64, len(row.Authors))
+	book.Genres = make([]int64, len(row.Genres))
+
+	v := validator.New()
+	types.ValidateBook(v, &book)
+	if !v.IsValid() {
+		return nil, v.Errors
+	}
+
+	for i, name := range row.Authors {
+		id, err := names.author(ctx, name)
+		if err != nil {
+			return nil, importRowErrors(err)
+		}
+		book.Authors[i] = id
+	}
+
+	for i, name := range row.Genres {
+		id, err := names.genre(ctx, name)
+		if err != nil {
+			return nil, importRowErrors(err)
+		}
+		book.Genres[i] = id
+	}
+
+	return &book, nil
+}
+
+func importRowErrors(err error) map[string]string {
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return validationErr.Errors
+	case errors.Is(err, ErrEntityExists):
+		return map[string]string{"row": "clashes with an existing book, author or genre"}
+	}
+
+	log.Error("can't import row: " + err.Error())
+	return map[string]string{"row": "the server encountered a problem and could not import the row"}
+}
+
+// importNames resolves the author and genre names of an import to IDs and creates the ones that
+// don't exist yet. A dry run only checks the names and resolves them to 0.
+type importNames struct {
+	authors   Author
+	genres    Genre
+	dryRun    bool
+	authorIDs map[string]int64
+	genreIDs  map[string]int64
+}
+
+func (n *importNames) author(ctx context.Context, name string) (int64, error) {
+	key := types.FoldName(name)
+	if id, ok := n.authorIDs[key]; ok {
+		return id, nil
+	}
+
+	author := types.ParseAuthorName(name)
+	author.Normalize()
+	v := validator.New()
+	types.ValidateAuthor(v, author)
+	if !v.IsValid() {
+		return 0, &ValidationError{Errors: map[string]string{"authors": fmt.Sprintf("%q isn't a valid author name", name)}}
+	}
+
+	if n.dryRun {
+		n.authorIDs[key] = 0
+		return 0, nil
+	}
+
+	found, err := n.authors.GetAuthorByName(ctx, author.FirstName, author.LastName)
+	if errors.Is(err, ErrNotFound) {
+		found, err = n.authors.CreateAuthor(ctx, author)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	n.authorIDs[key] = found.ID
+	return found.ID, nil
+}
+
+func (n *importNames) genre(ctx context.Context, name string) (int64, error) {
+	key := types.FoldName(name)
+	if id, ok := n.genreIDs[key]; ok {
+		return id, nil
+	}
+
+	genre := types.Genre{Name: name}
+	v := validator.New()
+	types.ValidateGenre(v, &genre)
+	if !v.IsValid() {
+		return 0, &ValidationError{Errors: map[string]string{"genres": fmt.Sprintf("%q isn't a valid genre name", name)}}
+	}
+
+	if n.dryRun {
+		n.genreIDs[key] = 0
+		return 0, nil
+	}
+
+	// CreateGenre hands the existing genre back along with ErrEntityExists.
+	created, err := n.genres.CreateGenre(ctx, &genre)
+	if err != nil && !errors.Is(err, ErrEntityExists) {
+		return 0, err
+	}
+
+	n.genreIDs[key] = created.ID
+	return created.ID, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/tredoc/go-crud-api/internal/repository"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"time"
+)
+
+type LoanService struct {
+	repo     repository.Loan
+	userRepo repository.User
+}
+
+func NewLoanService(repo repository.Loan, userRepo repository.User) *LoanService {
+	return &LoanService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+// Checkout lends a copy to the current user, or to checkout.UserID when an admin is at the desk.
+func (s *LoanService) Checkout(ctx context.Context, user *types.User, checkout *types.Checkout) (*types.Loan, error) {
+	borrower := user
+	if checkout.UserID != 0 && checkout.UserID != user.ID {
+		if !user.IsAdmin() {
+			return nil, ErrNotPermitted
+		}
+
+		var err error
+		borrower, err = s.userRepo.GetUserByID(ctx, checkout.UserID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, ErrNotFound
+			}
+
+			return nil, err
+		}
+	}
+
+	dueAt := time.Now().Add(types.LoanPeriod)
+	loan, err := s.repo.CheckoutCopy(ctx, checkout.Barcode, borrower.ID, dueAt, types.LoanLimits[borrower.Role])
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return nil, ErrNotFound
+		case errors.Is(err, repository.ErrCopyUnavailable):
+			return nil, ErrCopyUnavailable
+		case errors.Is(err, repository.ErrLoanLimitReached):
+			return nil, ErrLoanLimitReached
+		default:
+			return nil, err
+		}
+	}
+
+	return loan, nil
+}
+
+func (s *LoanService) ReturnLoan(ctx context.Context, id int64, user *types.User) (*types.Loan, error) {
+	loan, err := s.getOwnLoan(ctx, id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	returnedAt, err := s.repo.ReturnLoan(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrLoanClosed) {
+			return nil, ErrLoanClosed
+		}
+
+		return nil, err
+	}
+
+	loan.ReturnedAt = &returnedAt
+	return loan, nil
+}
+
+func (s *LoanService) RenewLoan(ctx context.Context, id int64, user *types.User) (*types.Loan, error) {
+	loan, err := s.getOwnLoan(ctx, id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if loan.ReturnedAt != nil {
+		return nil, ErrLoanClosed
+	}
+
+	if loan.IsOverdue(time.Now()) || loan.Renewals >= types.MaxRenewals {
+		return nil, ErrRenewalNotAllowed
+	}
+
+	dueAt := loan.DueAt.Add(types.LoanPeriod)
+	err = s.repo.RenewLoan(ctx, id, dueAt, types.MaxRenewals)
+	if err != nil {
+		if errors.Is(err, repository.ErrLoanClosed) {
+			return nil, ErrRenewalNotAllowed
+		}
+
+		return nil, err
+	}
+
+	loan.DueAt = dueAt
+	loan.Renewals++
+	return loan, nil
+}
+
+func (s *LoanService) GetUserLoans(ctx context.Context, userID int64) ([]*types.Loan, error) {
+	return s.repo.GetLoansByUserID(ctx, userID)
+}
+
+func (s *LoanService) GetLoans(ctx context.Context, status types.LoanStatus) ([]*types.Loan, error) {
+	return s.repo.GetLoansByStatus(ctx, status)
+}
+
+func (s *LoanService) getOwnLoan(ctx context.Context, id int64, user *types.User) (*types.Loan, error) {
+	loan, err := s.repo.GetLoanByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	if loan.UserID != user.ID && !user.IsAdmin() {
+		return nil, ErrNotPermitted
+	}
+
+	return loan, nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/tredoc/go-crud-api/internal/cache"
+	"github.com/tredoc/go-crud-api/internal/repository"
+	"github.com/tredoc/go-crud-api/pkg/types"
+)
+
+type ReviewService struct {
+	repo  repository.Review
+	cache cache.RCache
+}
+
+func NewReviewService(repo repository.Review, cache cache.RCache) *ReviewService {
+	return &ReviewService{
+		repo:  repo,
+		cache: cache,
+	}
+}
+
+func (s *ReviewService) CreateReview(ctx context.Context, review *types.Review) (*types.Review, error) {
+	id, createdAt, err := s.repo.CreateReview(ctx, review)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		if errors.Is(err, repository.ErrEntityExists) {
+			return nil, ErrEntityExists
+		}
+
+		return nil, err
+	}
+
+	review.ID = id
+	review.CreatedAt = createdAt
+	review.UpdatedAt = createdAt
+	s.invalidateBook(review.BookID)
+	return review, nil
+}
+
+func (s *ReviewService) GetReviewsByBookID(ctx context.Context, bookID int64) ([]*types.Review, error) {
+	reviews, err := s.repo.GetReviewsByBookID(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return reviews, nil
+		}
+
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+func (s *ReviewService) UpdateReview(ctx context.Context, id int64, user *types.User, review *types.UpdateReview) (*types.Review, error) {
+	existingReview, err := s.repo.GetReviewByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	if existingReview.UserID != user.ID && !user.IsAdmin() {
+		return nil, ErrNotPermitted
+	}
+
+	if review.Rating != nil {
+		existingReview.Rating = *review.Rating
+	}
+
+	if review.Text != nil {
+		existingReview.Text = *review.Text
+	}
+
+	err = s.repo.UpdateReview(ctx, id, existingReview)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	s.invalidateBook(existingReview.BookID)
+	return existingReview, nil
+}
+
+func (s *ReviewService) DeleteReview(ctx context.Context, id int64, user *types.User) error {
+	review, err := s.repo.GetReviewByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	if review.UserID != user.ID && !user.IsAdmin() {
+		return ErrNotPermitted
+	}
+
+	err = s.repo.DeleteReview(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	s.invalidateBook(review.BookID)
+	return nil
+}
+
+// invalidateBook drops cached book entries whose rating aggregates have changed.
+func (s *ReviewService) invalidateBook(bookID int64) {
+	go s.cache.Invalidate("books")
+	go s.cache.Invalidate(fmt.Sprintf("book:%d", bookID))
+}
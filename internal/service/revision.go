@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/tredoc/go-crud-api/internal/repository"
+	"github.com/tredoc/go-crud-api/pkg/types"
+)
+
+// revisionLog reads the revision history of one kind of catalogue entity, the repositories record
+// it along with each change.
+type revisionLog struct {
+	repo       repository.Revision
+	entityType types.EntityType
+}
+
+func (l revisionLog) list(ctx context.Context, entityID int64) ([]*types.Revision, error) {
+	revisions, err := l.repo.GetRevisions(ctx, l.entityType, entityID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (l revisionLog) get(ctx context.Context, entityID int64, id int64) (*types.Revision, error) {
+	revision, err := l.repo.GetRevision(ctx, l.entityType, entityID, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+func (l revisionLog) diff(ctx context.Context, entityID int64, fromID int64, toID int64) (*types.RevisionDiff, error) {
+	from, err := l.get(ctx, entityID, fromID)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := l.get(ctx, entityID, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.DiffRevisions(from, to)
+}
+
+// snapshot decodes the revision into dst.
+func (l revisionLog) snapshot(ctx context.Context, entityID int64, id int64, dst any) error {
+	revision, err := l.get(ctx, entityID, id)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(revision.Snapshot, dst)
+}
@@ -5,22 +5,46 @@ import (
 	"github.com/tredoc/go-crud-api/internal/cache"
 	"github.com/tredoc/go-crud-api/internal/repository"
 	"github.com/tredoc/go-crud-api/pkg/types"
+	"sync"
+	"time"
 )
 
 type Book interface {
 	CreateBook(context.Context, *types.Book) (*types.BookWithDetails, error)
 	GetBookByID(context.Context, int64) (*types.BookWithDetails, error)
-	GetAllBooks(context.Context) ([]*types.Book, error)
-	UpdateBook(context.Context, int64, *types.UpdateBook) (*types.Book, error)
-	DeleteBook(context.Context, int64) error
+	GetBooksByIDs(context.Context, []int64) ([]*types.BookWithDetails, error)
+	GetAllBooks(context.Context, *types.BookFilter) ([]*types.Book, error)
+	ExportBooks(context.Context, *types.BookFilter, func(*types.ExportedBook) error) error
+	GetBookPage(context.Context, *types.BookFilter, types.BookOrder, int, int) (*types.BookPage, error)
+	UpdateBook(context.Context, int64, int64, *types.UpdateBook) (*types.Book, error)
+	PatchBook(context.Context, int64, int64, *types.Patch) (*types.Book, error)
+	ReplaceBook(context.Context, int64, int64, *types.Book) (*types.Book, bool, error)
+	DeleteBook(context.Context, int64, int64) error
+	BatchBooks(context.Context, *types.BookBatch) ([]error, error)
+	GetBookRevisions(context.Context, int64) ([]*types.Revision, error)
+	DiffBookRevisions(context.Context, int64, int64, int64) (*types.RevisionDiff, error)
+	RevertBook(context.Context, int64, int64, int64) (*types.Book, error)
+	GetBookTranslations(context.Context, int64) ([]*types.Translation, error)
+	SetBookTranslation(context.Context, int64, *types.Translation) error
+	DeleteBookTranslation(context.Context, int64, string) error
 }
 
 type Genre interface {
 	CreateGenre(context.Context, *types.Genre) (*types.Genre, error)
 	GetGenreByID(context.Context, int64) (*types.Genre, error)
 	GetAllGenres(context.Context) ([]*types.Genre, error)
-	UpdateGenre(context.Context, int64, *types.Genre) error
-	DeleteGenre(context.Context, int64) error
+	UpdateGenre(context.Context, int64, int64, *types.Genre) error
+	ReplaceGenre(context.Context, int64, int64, *types.Genre) (bool, error)
+	DeleteGenre(context.Context, int64, int64) error
+	BatchGenres(context.Context, *types.GenreBatch) ([]error, error)
+	GetGenreRevisions(context.Context, int64) ([]*types.Revision, error)
+	DiffGenreRevisions(context.Context, int64, int64, int64) (*types.RevisionDiff, error)
+	RevertGenre(context.Context, int64, int64, int64) (*types.Genre, error)
+	MergeGenres(context.Context, int64, int64) (*types.Genre, error)
+	GetGenreTree(context.Context) ([]*types.GenreNode, error)
+	GetGenreTranslations(context.Context, int64) ([]*types.Translation, error)
+	SetGenreTranslation(context.Context, int64, *types.Translation) error
+	DeleteGenreTranslation(context.Context, int64, string) error
 }
 
 type Author interface {
@@ -28,9 +52,19 @@ type Author interface {
 	GetAuthorByID(context.Context, int64) (*types.Author, error)
 	GetAuthorsByIDs(context.Context, []int64) ([]*types.Author, error)
 	GetAuthorByName(context.Context, string, string) (*types.Author, error)
+	FindAuthorByName(context.Context, string) (*types.Author, error)
+	GetAuthorBooks(context.Context, int64) ([]*types.Book, error)
 	GetAllAuthors(context.Context) ([]*types.Author, error)
-	UpdateAuthor(context.Context, int64, *types.UpdateAuthor) (*types.Author, error)
-	DeleteAuthor(context.Context, int64) error
+	GetAuthorPage(context.Context, int, int) (*types.AuthorPage, error)
+	UpdateAuthor(context.Context, int64, int64, *types.UpdateAuthor) (*types.Author, error)
+	PatchAuthor(context.Context, int64, int64, *types.Patch) (*types.Author, error)
+	ReplaceAuthor(context.Context, int64, int64, *types.Author) (*types.Author, bool, error)
+	DeleteAuthor(context.Context, int64, int64) error
+	BatchAuthors(context.Context, *types.AuthorBatch) ([]error, error)
+	GetAuthorRevisions(context.Context, int64) ([]*types.Revision, error)
+	DiffAuthorRevisions(context.Context, int64, int64, int64) (*types.RevisionDiff, error)
+	RevertAuthor(context.Context, int64, int64, int64) (*types.Author, error)
+	MergeAuthors(context.Context, int64, int64) (*types.Author, error)
 }
 
 type User interface {
@@ -39,18 +73,118 @@ type User interface {
 	GetUserByID(context.Context, int64) (*types.User, error)
 }
 
+type Review interface {
+	CreateReview(context.Context, *types.Review) (*types.Review, error)
+	GetReviewsByBookID(context.Context, int64) ([]*types.Review, error)
+	UpdateReview(context.Context, int64, *types.User, *types.UpdateReview) (*types.Review, error)
+	DeleteReview(context.Context, int64, *types.User) error
+}
+
+type Shelf interface {
+	GetShelves(context.Context, int64) ([]*types.Shelf, error)
+	CreateShelf(context.Context, int64, *types.Shelf) (*types.Shelf, error)
+	DeleteShelf(context.Context, int64, int64) error
+	GetShelfBooks(context.Context, int64, types.ShelfRef) ([]*types.ShelvedBook, error)
+	AddBookToShelf(context.Context, int64, types.ShelfRef, int64) error
+	RemoveBookFromShelf(context.Context, int64, types.ShelfRef, int64) error
+	UpdateReading(context.Context, int64, int64, *types.UpdateReading) (*types.ReadingEntry, error)
+	GetReadingStats(context.Context, int64, int) (*types.ReadingStats, error)
+}
+
+type Copy interface {
+	CreateCopy(context.Context, *types.Copy) (*types.Copy, error)
+	GetCopiesByBookID(context.Context, int64) ([]*types.Copy, error)
+	UpdateCopy(context.Context, int64, *types.UpdateCopy) (*types.Copy, error)
+	DeleteCopy(context.Context, int64) error
+}
+
+type Loan interface {
+	Checkout(context.Context, *types.User, *types.Checkout) (*types.Loan, error)
+	ReturnLoan(context.Context, int64, *types.User) (*types.Loan, error)
+	RenewLoan(context.Context, int64, *types.User) (*types.Loan, error)
+	GetUserLoans(context.Context, int64) ([]*types.Loan, error)
+	GetLoans(context.Context, types.LoanStatus) ([]*types.Loan, error)
+}
+
+type Hold interface {
+	PlaceHold(context.Context, int64, int64) (*types.Hold, error)
+	GetBookHolds(context.Context, int64) ([]*types.Hold, error)
+	GetUserHolds(context.Context, int64) ([]*types.Hold, error)
+	CancelHold(context.Context, int64, *types.User) error
+	ExpireHolds(context.Context) (int64, error)
+}
+
+type Tag interface {
+	AddBookTags(context.Context, int64, int64, []string) ([]string, error)
+	RemoveBookTags(context.Context, int64, *types.User, []string) error
+	GetTagCloud(context.Context, int) ([]*types.TagCount, error)
+}
+
+type Trash interface {
+	GetTrash(context.Context) (*types.Trash, error)
+	RestoreBook(context.Context, int64) error
+	RestoreAuthor(context.Context, int64) error
+	RestoreGenre(context.Context, int64) error
+	PurgeTrash(context.Context, time.Time) (int64, error)
+}
+
+type Idempotency interface {
+	Begin(context.Context, string, string) (*types.IdempotentResponse, error)
+	Finish(context.Context, string, *types.IdempotentResponse) error
+	Extend(context.Context, string) error
+	Abandon(context.Context, string)
+}
+
+type Import interface {
+	StartImport(context.Context, *types.ImportJob, []*types.ImportRow) error
+	GetImportJob(context.Context, int64) (*types.ImportJob, error)
+	FailStaleImports(context.Context) (int64, error)
+	UpsertBooks(context.Context, []*types.ImportRow) []*types.ImportOutcome
+}
+
+// Policies configure how deleting authors and genres that are still listed on books is handled.
+type Policies struct {
+	AuthorOnDelete types.DeletePolicy
+	GenreOnDelete  types.DeletePolicy
+}
+
 type Service struct {
 	Book
 	Author
 	Genre
 	User
+	Review
+	Shelf
+	Copy
+	Loan
+	Hold
+	Tag
+	Trash
+	Idempotency
+	Import
 }
 
-func NewService(repos *repository.Repository, cache *cache.Cache) *Service {
+// NewService wires the services. Work that outlives its request stops when ctx is cancelled and is
+// counted in workers.
+func NewService(ctx context.Context, workers *sync.WaitGroup, repos *repository.Repository, cache *cache.Cache, policies Policies) *Service {
+	book := NewBookService(repos.Book, repos.Author, repos.Genre, repos.Revision, cache.Redis)
+	genre := NewGenreService(repos.Genre, repos.Revision, policies.GenreOnDelete, cache.Redis)
+	author := NewAuthorService(repos.Author, repos.Book, repos.Revision, policies.AuthorOnDelete, cache.Redis)
+
 	return &Service{
-		Book:   NewBookService(repos.Book, repos.Author, repos.Genre, cache.Redis),
-		Genre:  NewGenreService(repos.Genre, cache.Redis),
-		Author: NewAuthorService(repos.Author, cache.Redis),
+		Book:   book,
+		Genre:  genre,
+		Author: author,
 		User:   NewUserService(repos.User),
+		Review: NewReviewService(repos.Review, cache.Redis),
+		Shelf:  NewShelfService(repos.Shelf, repos.Book),
+		Copy:   NewCopyService(repos.Copy),
+		Loan:   NewLoanService(repos.Loan, repos.User),
+		Hold:   NewHoldService(repos.Hold),
+		Tag:    NewTagService(repos.Tag, cache.Redis),
+		Trash:  NewTrashService(repos.Trash, cache.Redis),
+
+		Idempotency: NewIdempotencyService(cache.Redis),
+		Import:      NewImportService(ctx, workers, repos.Import, cache.Redis, book, author, genre),
 	}
 }
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/tredoc/go-crud-api/internal/repository"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"time"
+)
+
+type ShelfService struct {
+	repo     repository.Shelf
+	bookRepo repository.Book
+}
+
+func NewShelfService(repo repository.Shelf, bookRepo repository.Book) *ShelfService {
+	return &ShelfService{
+		repo:     repo,
+		bookRepo: bookRepo,
+	}
+}
+
+func (s *ShelfService) GetShelves(ctx context.Context, userID int64) ([]*types.Shelf, error) {
+	return s.repo.GetShelves(ctx, userID)
+}
+
+func (s *ShelfService) CreateShelf(ctx context.Context, userID int64, shelf *types.Shelf) (*types.Shelf, error) {
+	id, createdAt, err := s.repo.CreateShelf(ctx, userID, shelf)
+	if err != nil {
+		if errors.Is(err, repository.ErrEntityExists) {
+			return nil, ErrEntityExists
+		}
+
+		return nil, err
+	}
+
+	shelf.ID = id
+	shelf.CreatedAt = &createdAt
+	return shelf, nil
+}
+
+func (s *ShelfService) DeleteShelf(ctx context.Context, userID int64, shelfID int64) error {
+	err := s.repo.DeleteShelf(ctx, userID, shelfID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *ShelfService) GetShelfBooks(ctx context.Context, userID int64, shelf types.ShelfRef) ([]*types.ShelvedBook, error) {
+	var books []*types.ShelvedBook
+	var err error
+	if shelf.IsBuiltIn() {
+		books, err = s.repo.GetStatusShelfBooks(ctx, userID, shelf.Status)
+	} else {
+		books, err = s.repo.GetCustomShelfBooks(ctx, userID, shelf.ID)
+	}
+
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return books, nil
+}
+
+func (s *ShelfService) AddBookToShelf(ctx context.Context, userID int64, shelf types.ShelfRef, bookID int64) error {
+	book, err := s.bookRepo.GetBookByID(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	if !shelf.IsBuiltIn() {
+		err = s.repo.AddBookToShelf(ctx, userID, shelf.ID, bookID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrNotFound
+			}
+
+			if errors.Is(err, repository.ErrEntityExists) {
+				return ErrEntityExists
+			}
+
+			return err
+		}
+
+		return nil
+	}
+
+	entry, err := s.getReadingEntry(ctx, userID, bookID)
+	if err != nil {
+		return err
+	}
+
+	moveToStatus(entry, shelf.Status, book.Pages)
+	_, err = s.repo.SaveReadingEntry(ctx, userID, entry)
+	return err
+}
+
+func (s *ShelfService) RemoveBookFromShelf(ctx context.Context, userID int64, shelf types.ShelfRef, bookID int64) error {
+	var err error
+	if shelf.IsBuiltIn() {
+		err = s.repo.DeleteReadingEntry(ctx, userID, bookID, shelf.Status)
+	} else {
+		err = s.repo.RemoveBookFromShelf(ctx, userID, shelf.ID, bookID)
+	}
+
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *ShelfService) UpdateReading(ctx context.Context, userID int64, bookID int64, reading *types.UpdateReading) (*types.ReadingEntry, error) {
+	book, err := s.bookRepo.GetBookByID(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	entry, err := s.getReadingEntry(ctx, userID, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if reading.CurrentPage != nil {
+		if *reading.CurrentPage > book.Pages {
+			return nil, &ValidationError{Errors: map[string]string{"current_page": "can't be bigger than book pages"}}
+		}
+
+		// A page that contradicts the status asked for would otherwise silently move the book to
+		// another shelf.
+		if reading.Status != nil && *reading.Status == types.ReadShelf && *reading.CurrentPage < book.Pages {
+			return nil, &ValidationError{Errors: map[string]string{"current_page": "must be the last page of a read book"}}
+		}
+		if reading.Status != nil && *reading.Status == types.WantToReadShelf && *reading.CurrentPage > 0 {
+			return nil, &ValidationError{Errors: map[string]string{"current_page": "must be 0 for a book you want to read"}}
+		}
+		if reading.Status != nil && *reading.Status == types.ReadingShelf && *reading.CurrentPage == book.Pages {
+			return nil, &ValidationError{Errors: map[string]string{"current_page": "can't be the last page of a book you are reading"}}
+		}
+	}
+
+	if reading.Status != nil {
+		moveToStatus(entry, *reading.Status, book.Pages)
+	}
+
+	if reading.CurrentPage != nil {
+		entry.CurrentPage = *reading.CurrentPage
+		switch {
+		case entry.CurrentPage == book.Pages:
+			moveToStatus(entry, types.ReadShelf, book.Pages)
+		case entry.CurrentPage > 0 && entry.Status != types.ReadingShelf:
+			moveToStatus(entry, types.ReadingShelf, book.Pages)
+			entry.CurrentPage = *reading.CurrentPage
+		}
+	}
+
+	if reading.StartedAt != nil {
+		entry.StartedAt = reading.StartedAt
+	}
+
+	if reading.FinishedAt != nil {
+		entry.FinishedAt = reading.FinishedAt
+	}
+
+	if entry.StartedAt != nil && entry.FinishedAt != nil && entry.FinishedAt.Before(entry.StartedAt.Time) {
+		return nil, &ValidationError{Errors: map[string]string{"finished_at": "can't be before started_at"}}
+	}
+
+	updatedAt, err := s.repo.SaveReadingEntry(ctx, userID, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.UpdatedAt = updatedAt
+	return entry, nil
+}
+
+func (s *ShelfService) GetReadingStats(ctx context.Context, userID int64, year int) (*types.ReadingStats, error) {
+	return s.repo.GetReadingStats(ctx, userID, year)
+}
+
+func (s *ShelfService) getReadingEntry(ctx context.Context, userID int64, bookID int64) (*types.ReadingEntry, error) {
+	entry, err := s.repo.GetReadingEntry(ctx, userID, bookID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return &types.ReadingEntry{BookID: bookID, Status: types.WantToReadShelf}, nil
+		}
+
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// moveToStatus puts the entry on a status shelf and keeps its dates and progress consistent with it.
+func moveToStatus(entry *types.ReadingEntry, status types.ShelfStatus, pages uint16) {
+	today := &types.CustomDate{Time: time.Now().UTC().Truncate(24 * time.Hour)}
+
+	entry.Status = status
+	switch status {
+	case types.WantToReadShelf:
+		entry.CurrentPage = 0
+		entry.StartedAt = nil
+		entry.FinishedAt = nil
+	case types.ReadingShelf:
+		if entry.StartedAt == nil {
+			entry.StartedAt = today
+		}
+		entry.FinishedAt = nil
+	case types.ReadShelf:
+		if entry.FinishedAt == nil {
+			entry.FinishedAt = today
+		}
+		if entry.StartedAt == nil {
+			entry.StartedAt = entry.FinishedAt
+		}
+		entry.CurrentPage = pages
+	}
+}
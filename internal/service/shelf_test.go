@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/suite"
+	mockrepository "github.com/tredoc/go-crud-api/mocks/repository"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"testing"
+)
+
+type shelfServiceSuite struct {
+	suite.Suite
+	repo     *mockrepository.Shelf
+	bookRepo *mockrepository.Book
+	service  *ShelfService
+}
+
+func (s *shelfServiceSuite) SetupTest() {
+	s.repo = new(mockrepository.Shelf)
+	s.bookRepo = new(mockrepository.Book)
+	s.service = NewShelfService(s.repo, s.bookRepo)
+}
+
+func (s *shelfServiceSuite) TearDownTest() {
+	s.repo.AssertExpectations(s.T())
+	s.bookRepo.AssertExpectations(s.T())
+}
+
+func (s *shelfServiceSuite) TestUpdateReading_PageContradictsStatus() {
+	tests := []struct {
+		name   string
+		status types.ShelfStatus
+		page   uint16
+	}{
+		{name: "read before the last page", status: types.ReadShelf, page: 100},
+		{name: "want to read with progress", status: types.WantToReadShelf, page: 1},
+		{name: "reading at the last page", status: types.ReadingShelf, page: 387},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			ctx := context.Background()
+			s.bookRepo.On("GetBookByID", ctx, int64(3)).Return(&types.Book{ID: 3, Pages: 387}, nil).Once()
+			s.repo.On("GetReadingEntry", ctx, int64(1), int64(3)).Return(&types.ReadingEntry{BookID: 3, Status: types.ReadingShelf, CurrentPage: 50}, nil).Once()
+
+			status, page := test.status, test.page
+			entry, err := s.service.UpdateReading(ctx, 1, 3, &types.UpdateReading{Status: &status, CurrentPage: &page})
+
+			var validationErr *ValidationError
+			s.ErrorAs(err, &validationErr)
+			s.Contains(validationErr.Errors, "current_page")
+			s.Nil(entry)
+		})
+	}
+}
+
+func TestShelfService(t *testing.T) {
+	suite.Run(t, new(shelfServiceSuite))
+}
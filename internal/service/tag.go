@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/tredoc/go-crud-api/internal/cache"
+	"github.com/tredoc/go-crud-api/internal/repository"
+	"github.com/tredoc/go-crud-api/pkg/types"
+)
+
+type TagService struct {
+	repo  repository.Tag
+	cache cache.RCache
+}
+
+func NewTagService(repo repository.Tag, cache cache.RCache) *TagService {
+	return &TagService{
+		repo:  repo,
+		cache: cache,
+	}
+}
+
+// AddBookTags applies the tags to the book and returns all tags the book has afterwards.
+func (s *TagService) AddBookTags(ctx context.Context, bookID int64, userID int64, tags []string) ([]string, error) {
+	err := s.repo.AddBookTags(ctx, bookID, userID, tags)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	s.invalidateBook(bookID)
+	return s.repo.GetBookTags(ctx, bookID)
+}
+
+// RemoveBookTags removes the tags from the book, users may only remove tags they applied themselves.
+func (s *TagService) RemoveBookTags(ctx context.Context, bookID int64, user *types.User, tags []string) error {
+	appliedBy := user.ID
+	if user.IsAdmin() {
+		appliedBy = 0
+	}
+
+	err := s.repo.RemoveBookTags(ctx, bookID, tags, appliedBy)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	s.invalidateBook(bookID)
+	return nil
+}
+
+func (s *TagService) GetTagCloud(ctx context.Context, limit int) ([]*types.TagCount, error) {
+	return s.repo.GetTagCounts(ctx, limit)
+}
+
+// invalidateBook drops cached book entries whose tags have changed.
+func (s *TagService) invalidateBook(bookID int64) {
+	go s.cache.Invalidate("books")
+	go s.cache.Invalidate(fmt.Sprintf("book:%d", bookID))
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"github.com/tredoc/go-crud-api/internal/repository"
+	"github.com/tredoc/go-crud-api/pkg/types"
+)
+
+// localizeGenres returns copies of the genres named in the languages of the request. Genres
+// without a translation in any of them keep their original name.
+func localizeGenres(ctx context.Context, repo repository.Genre, genres []*types.Genre) ([]*types.Genre, error) {
+	chain := types.LanguagesFromContext(ctx)
+	if len(chain) == 0 || len(genres) == 0 {
+		return genres, nil
+	}
+
+	ids := make([]int64, len(genres))
+	for i, genre := range genres {
+		ids[i] = genre.ID
+	}
+
+	translations, err := repo.GetGenreTranslationsByIDs(ctx, ids, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	localized := make([]*types.Genre, len(genres))
+	for i, genre := range genres {
+		genreCopy := *genre
+		if name, ok := types.Localize(translations[genre.ID], chain); ok {
+			genreCopy.Name = name
+		}
+		localized[i] = &genreCopy
+	}
+
+	return localized, nil
+}
+
+// localizeBooks returns copies of the books titled in the languages of the request.
+func localizeBooks(ctx context.Context, repo repository.Book, books []*types.Book) ([]*types.Book, error) {
+	chain := types.LanguagesFromContext(ctx)
+	if len(chain) == 0 || len(books) == 0 {
+		return books, nil
+	}
+
+	ids := make([]int64, len(books))
+	for i, book := range books {
+		ids[i] = book.ID
+	}
+
+	translations, err := repo.GetBookTranslationsByIDs(ctx, ids, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	localized := make([]*types.Book, len(books))
+	for i, book := range books {
+		bookCopy := *book
+		if title, ok := types.Localize(translations[book.ID], chain); ok {
+			bookCopy.Title = title
+		}
+		localized[i] = &bookCopy
+	}
+
+	return localized, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/tredoc/go-crud-api/internal/cache"
+	"github.com/tredoc/go-crud-api/internal/repository"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"time"
+)
+
+type TrashService struct {
+	repo  repository.Trash
+	cache cache.RCache
+}
+
+func NewTrashService(repo repository.Trash, cache cache.RCache) *TrashService {
+	return &TrashService{
+		repo:  repo,
+		cache: cache,
+	}
+}
+
+func (s *TrashService) GetTrash(ctx context.Context) (*types.Trash, error) {
+	return s.repo.GetTrash(ctx)
+}
+
+func (s *TrashService) RestoreBook(ctx context.Context, id int64) error {
+	err := s.repo.RestoreBook(ctx, id)
+	if err != nil {
+		return mapRestoreError(err)
+	}
+
+	invalidateBooks(s.cache, []int64{id})
+	return nil
+}
+
+func (s *TrashService) RestoreAuthor(ctx context.Context, id int64) error {
+	bookIDs, err := s.repo.RestoreAuthor(ctx, id)
+	if err != nil {
+		return mapRestoreError(err)
+	}
+
+	go s.cache.Invalidate("authors")
+	go s.cache.Invalidate(fmt.Sprintf("author:%d", id))
+	invalidateBooks(s.cache, bookIDs)
+	return nil
+}
+
+func (s *TrashService) RestoreGenre(ctx context.Context, id int64) error {
+	bookIDs, err := s.repo.RestoreGenre(ctx, id)
+	if err != nil {
+		return mapRestoreError(err)
+	}
+
+	go s.cache.Invalidate("genres")
+	go s.cache.Invalidate("genres:tree")
+	go s.cache.Invalidate(fmt.Sprintf("genre:%d", id))
+	invalidateBooks(s.cache, bookIDs)
+	return nil
+}
+
+// PurgeTrash permanently removes everything deleted before the given time and returns how many
+// items were removed.
+func (s *TrashService) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	purged, err := s.repo.PurgeTrash(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	if purged > 0 {
+		go s.cache.Invalidate("genres")
+		go s.cache.Invalidate("genres:tree")
+	}
+	return purged, nil
+}
+
+func mapRestoreError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return ErrNotFound
+	case errors.Is(err, repository.ErrEntityExists):
+		return ErrEntityExists
+	default:
+		return err
+	}
+}
@@ -3,12 +3,19 @@ package validator
 import "regexp"
 
 var (
-	CantBeEmpty        = "can't be empty"
-	OnlyLatinLetters   = "must contain only latin letters"
-	OnlyInThePast      = "publish date can't be in future"
-	CantBeLessThanOne  = "can't be less than 1"
-	CantBeBiggerThan5k = "must be less than 5000"
-	CantBeShorterThan6 = "can't be shorter than 6"
+	CantBeEmpty         = "can't be empty"
+	OnlyLatinLetters    = "must contain only latin letters"
+	OnlyInThePast       = "publish date can't be in future"
+	CantBeLessThanOne   = "can't be less than 1"
+	CantBeBiggerThan5k  = "must be less than 5000"
+	CantBeShorterThan6  = "can't be shorter than 6"
+	CantBeLongerThan5k  = "must be shorter than 5000 characters"
+	MustBeFrom1To5      = "must be from 1 to 5"
+	CantBeLongerThan100 = "must be shorter than 100 characters"
+	CantBeInFuture      = "can't be in future"
+	CantBeLongerThan50  = "must be shorter than 50 characters"
+
+	OnlyLettersAndPunctuation = "must contain only letters, spaces, apostrophes, hyphens and periods"
 )
 
 type Validator struct {
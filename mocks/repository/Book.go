@@ -0,0 +1,395 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mockrepository
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	types "github.com/tredoc/go-crud-api/pkg/types"
+)
+
+// Book is an autogenerated mock type for the Book type
+type Book struct {
+	mock.Mock
+}
+
+// ApplyBookBatch provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Book) ApplyBookBatch(_a0 context.Context, _a1 []*types.BookOperation, _a2 bool) ([]error, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ApplyBookBatch")
+	}
+
+	var r0 []error
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*types.BookOperation, bool) ([]error, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []*types.BookOperation, bool) []error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]error)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []*types.BookOperation, bool) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateBook provides a mock function with given fields: ctx, book
+func (_m *Book) CreateBook(ctx context.Context, book *types.Book) (int64, time.Time, error) {
+	ret := _m.Called(ctx, book)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateBook")
+	}
+
+	var r0 int64
+	var r1 time.Time
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.Book) (int64, time.Time, error)); ok {
+		return rf(ctx, book)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *types.Book) int64); ok {
+		r0 = rf(ctx, book)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *types.Book) time.Time); ok {
+		r1 = rf(ctx, book)
+	} else {
+		r1 = ret.Get(1).(time.Time)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *types.Book) error); ok {
+		r2 = rf(ctx, book)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// DeleteBook provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Book) DeleteBook(_a0 context.Context, _a1 int64, _a2 int64) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteBook")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteBookTranslation provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Book) DeleteBookTranslation(_a0 context.Context, _a1 int64, _a2 string) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteBookTranslation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExportBooks provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Book) ExportBooks(_a0 context.Context, _a1 *types.BookFilter, _a2 func(*types.ExportedBook) error) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportBooks")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.BookFilter, func(*types.ExportedBook) error) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAllBooks provides a mock function with given fields: _a0, _a1
+func (_m *Book) GetAllBooks(_a0 context.Context, _a1 *types.BookFilter) ([]*types.Book, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllBooks")
+	}
+
+	var r0 []*types.Book
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.BookFilter) ([]*types.Book, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *types.BookFilter) []*types.Book); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Book)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *types.BookFilter) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBookByID provides a mock function with given fields: _a0, _a1
+func (_m *Book) GetBookByID(_a0 context.Context, _a1 int64) (*types.Book, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBookByID")
+	}
+
+	var r0 *types.Book
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*types.Book, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *types.Book); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Book)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBookPage provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
+func (_m *Book) GetBookPage(_a0 context.Context, _a1 *types.BookFilter, _a2 types.BookOrder, _a3 int, _a4 int) (*types.BookPage, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3, _a4)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBookPage")
+	}
+
+	var r0 *types.BookPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.BookFilter, types.BookOrder, int, int) (*types.BookPage, error)); ok {
+		return rf(_a0, _a1, _a2, _a3, _a4)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *types.BookFilter, types.BookOrder, int, int) *types.BookPage); ok {
+		r0 = rf(_a0, _a1, _a2, _a3, _a4)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.BookPage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *types.BookFilter, types.BookOrder, int, int) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3, _a4)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBookTranslations provides a mock function with given fields: _a0, _a1
+func (_m *Book) GetBookTranslations(_a0 context.Context, _a1 int64) ([]*types.Translation, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBookTranslations")
+	}
+
+	var r0 []*types.Translation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*types.Translation, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*types.Translation); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Translation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBookTranslationsByIDs provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Book) GetBookTranslationsByIDs(_a0 context.Context, _a1 []int64, _a2 []string) (map[int64]map[string]string, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBookTranslationsByIDs")
+	}
+
+	var r0 map[int64]map[string]string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int64, []string) (map[int64]map[string]string, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int64, []string) map[int64]map[string]string); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[int64]map[string]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int64, []string) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBooksByIDs provides a mock function with given fields: _a0, _a1
+func (_m *Book) GetBooksByIDs(_a0 context.Context, _a1 []int64) ([]*types.Book, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBooksByIDs")
+	}
+
+	var r0 []*types.Book
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) ([]*types.Book, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) []*types.Book); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Book)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetBookTranslation provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Book) SetBookTranslation(_a0 context.Context, _a1 int64, _a2 *types.Translation) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetBookTranslation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.Translation) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateBook provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Book) UpdateBook(_a0 context.Context, _a1 int64, _a2 *types.Book, _a3 types.RevisionAction) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateBook")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.Book, types.RevisionAction) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpsertBook provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Book) UpsertBook(_a0 context.Context, _a1 int64, _a2 *types.Book) (bool, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertBook")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.Book) (bool, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.Book) bool); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, *types.Book) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewBook creates a new instance of Book. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewBook(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Book {
+	mock := &Book{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
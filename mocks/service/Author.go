@@ -15,6 +15,36 @@ type Author struct {
 	mock.Mock
 }
 
+// BatchAuthors provides a mock function with given fields: _a0, _a1
+func (_m *Author) BatchAuthors(_a0 context.Context, _a1 *types.AuthorBatch) ([]error, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BatchAuthors")
+	}
+
+	var r0 []error
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.AuthorBatch) ([]error, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *types.AuthorBatch) []error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]error)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *types.AuthorBatch) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateAuthor provides a mock function with given fields: _a0, _a1
 func (_m *Author) CreateAuthor(_a0 context.Context, _a1 *types.Author) (*types.Author, error) {
 	ret := _m.Called(_a0, _a1)
@@ -45,17 +75,17 @@ func (_m *Author) CreateAuthor(_a0 context.Context, _a1 *types.Author) (*types.A
 	return r0, r1
 }
 
-// DeleteAuthor provides a mock function with given fields: _a0, _a1
-func (_m *Author) DeleteAuthor(_a0 context.Context, _a1 int64) error {
-	ret := _m.Called(_a0, _a1)
+// DeleteAuthor provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Author) DeleteAuthor(_a0 context.Context, _a1 int64, _a2 int64) error {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteAuthor")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
-		r0 = rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -63,6 +93,66 @@ func (_m *Author) DeleteAuthor(_a0 context.Context, _a1 int64) error {
 	return r0
 }
 
+// DiffAuthorRevisions provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Author) DiffAuthorRevisions(_a0 context.Context, _a1 int64, _a2 int64, _a3 int64) (*types.RevisionDiff, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DiffAuthorRevisions")
+	}
+
+	var r0 *types.RevisionDiff
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) (*types.RevisionDiff, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) *types.RevisionDiff); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.RevisionDiff)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, int64) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindAuthorByName provides a mock function with given fields: _a0, _a1
+func (_m *Author) FindAuthorByName(_a0 context.Context, _a1 string) (*types.Author, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAuthorByName")
+	}
+
+	var r0 *types.Author
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*types.Author, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *types.Author); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Author)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAllAuthors provides a mock function with given fields: _a0
 func (_m *Author) GetAllAuthors(_a0 context.Context) ([]*types.Author, error) {
 	ret := _m.Called(_a0)
@@ -93,6 +183,36 @@ func (_m *Author) GetAllAuthors(_a0 context.Context) ([]*types.Author, error) {
 	return r0, r1
 }
 
+// GetAuthorBooks provides a mock function with given fields: _a0, _a1
+func (_m *Author) GetAuthorBooks(_a0 context.Context, _a1 int64) ([]*types.Book, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAuthorBooks")
+	}
+
+	var r0 []*types.Book
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*types.Book, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*types.Book); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Book)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAuthorByID provides a mock function with given fields: _a0, _a1
 func (_m *Author) GetAuthorByID(_a0 context.Context, _a1 int64) (*types.Author, error) {
 	ret := _m.Called(_a0, _a1)
@@ -153,6 +273,66 @@ func (_m *Author) GetAuthorByName(_a0 context.Context, _a1 string, _a2 string) (
 	return r0, r1
 }
 
+// GetAuthorPage provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Author) GetAuthorPage(_a0 context.Context, _a1 int, _a2 int) (*types.AuthorPage, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAuthorPage")
+	}
+
+	var r0 *types.AuthorPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (*types.AuthorPage, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) *types.AuthorPage); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.AuthorPage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAuthorRevisions provides a mock function with given fields: _a0, _a1
+func (_m *Author) GetAuthorRevisions(_a0 context.Context, _a1 int64) ([]*types.Revision, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAuthorRevisions")
+	}
+
+	var r0 []*types.Revision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*types.Revision, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*types.Revision); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Revision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAuthorsByIDs provides a mock function with given fields: _a0, _a1
 func (_m *Author) GetAuthorsByIDs(_a0 context.Context, _a1 []int64) ([]*types.Author, error) {
 	ret := _m.Called(_a0, _a1)
@@ -183,20 +363,20 @@ func (_m *Author) GetAuthorsByIDs(_a0 context.Context, _a1 []int64) ([]*types.Au
 	return r0, r1
 }
 
-// UpdateAuthor provides a mock function with given fields: _a0, _a1, _a2
-func (_m *Author) UpdateAuthor(_a0 context.Context, _a1 int64, _a2 *types.UpdateAuthor) (*types.Author, error) {
+// MergeAuthors provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Author) MergeAuthors(_a0 context.Context, _a1 int64, _a2 int64) (*types.Author, error) {
 	ret := _m.Called(_a0, _a1, _a2)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateAuthor")
+		panic("no return value specified for MergeAuthors")
 	}
 
 	var r0 *types.Author
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.UpdateAuthor) (*types.Author, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (*types.Author, error)); ok {
 		return rf(_a0, _a1, _a2)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.UpdateAuthor) *types.Author); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) *types.Author); ok {
 		r0 = rf(_a0, _a1, _a2)
 	} else {
 		if ret.Get(0) != nil {
@@ -204,7 +384,7 @@ func (_m *Author) UpdateAuthor(_a0 context.Context, _a1 int64, _a2 *types.Update
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int64, *types.UpdateAuthor) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
 		r1 = rf(_a0, _a1, _a2)
 	} else {
 		r1 = ret.Error(1)
@@ -213,6 +393,133 @@ func (_m *Author) UpdateAuthor(_a0 context.Context, _a1 int64, _a2 *types.Update
 	return r0, r1
 }
 
+// PatchAuthor provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Author) PatchAuthor(_a0 context.Context, _a1 int64, _a2 int64, _a3 *types.Patch) (*types.Author, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PatchAuthor")
+	}
+
+	var r0 *types.Author
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, *types.Patch) (*types.Author, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, *types.Patch) *types.Author); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Author)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, *types.Patch) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReplaceAuthor provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Author) ReplaceAuthor(_a0 context.Context, _a1 int64, _a2 int64, _a3 *types.Author) (*types.Author, bool, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceAuthor")
+	}
+
+	var r0 *types.Author
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, *types.Author) (*types.Author, bool, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, *types.Author) *types.Author); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Author)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, *types.Author) bool); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int64, int64, *types.Author) error); ok {
+		r2 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// RevertAuthor provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Author) RevertAuthor(_a0 context.Context, _a1 int64, _a2 int64, _a3 int64) (*types.Author, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevertAuthor")
+	}
+
+	var r0 *types.Author
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) (*types.Author, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) *types.Author); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Author)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, int64) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateAuthor provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Author) UpdateAuthor(_a0 context.Context, _a1 int64, _a2 int64, _a3 *types.UpdateAuthor) (*types.Author, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateAuthor")
+	}
+
+	var r0 *types.Author
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, *types.UpdateAuthor) (*types.Author, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, *types.UpdateAuthor) *types.Author); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Author)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, *types.UpdateAuthor) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewAuthor creates a new instance of Author. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewAuthor(t interface {
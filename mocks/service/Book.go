@@ -15,6 +15,36 @@ type Book struct {
 	mock.Mock
 }
 
+// BatchBooks provides a mock function with given fields: _a0, _a1
+func (_m *Book) BatchBooks(_a0 context.Context, _a1 *types.BookBatch) ([]error, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BatchBooks")
+	}
+
+	var r0 []error
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.BookBatch) ([]error, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *types.BookBatch) []error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]error)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *types.BookBatch) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateBook provides a mock function with given fields: _a0, _a1
 func (_m *Book) CreateBook(_a0 context.Context, _a1 *types.Book) (*types.BookWithDetails, error) {
 	ret := _m.Called(_a0, _a1)
@@ -45,17 +75,83 @@ func (_m *Book) CreateBook(_a0 context.Context, _a1 *types.Book) (*types.BookWit
 	return r0, r1
 }
 
-// DeleteBook provides a mock function with given fields: _a0, _a1
-func (_m *Book) DeleteBook(_a0 context.Context, _a1 int64) error {
-	ret := _m.Called(_a0, _a1)
+// DeleteBook provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Book) DeleteBook(_a0 context.Context, _a1 int64, _a2 int64) error {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteBook")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
-		r0 = rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteBookTranslation provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Book) DeleteBookTranslation(_a0 context.Context, _a1 int64, _a2 string) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteBookTranslation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DiffBookRevisions provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Book) DiffBookRevisions(_a0 context.Context, _a1 int64, _a2 int64, _a3 int64) (*types.RevisionDiff, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DiffBookRevisions")
+	}
+
+	var r0 *types.RevisionDiff
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) (*types.RevisionDiff, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) *types.RevisionDiff); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.RevisionDiff)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, int64) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExportBooks provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Book) ExportBooks(_a0 context.Context, _a1 *types.BookFilter, _a2 func(*types.ExportedBook) error) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportBooks")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.BookFilter, func(*types.ExportedBook) error) error); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -63,9 +159,9 @@ func (_m *Book) DeleteBook(_a0 context.Context, _a1 int64) error {
 	return r0
 }
 
-// GetAllBooks provides a mock function with given fields: _a0
-func (_m *Book) GetAllBooks(_a0 context.Context) ([]*types.Book, error) {
-	ret := _m.Called(_a0)
+// GetAllBooks provides a mock function with given fields: _a0, _a1
+func (_m *Book) GetAllBooks(_a0 context.Context, _a1 *types.BookFilter) ([]*types.Book, error) {
+	ret := _m.Called(_a0, _a1)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetAllBooks")
@@ -73,19 +169,19 @@ func (_m *Book) GetAllBooks(_a0 context.Context) ([]*types.Book, error) {
 
 	var r0 []*types.Book
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) ([]*types.Book, error)); ok {
-		return rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, *types.BookFilter) ([]*types.Book, error)); ok {
+		return rf(_a0, _a1)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) []*types.Book); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, *types.BookFilter) []*types.Book); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*types.Book)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, *types.BookFilter) error); ok {
+		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -123,29 +219,264 @@ func (_m *Book) GetBookByID(_a0 context.Context, _a1 int64) (*types.BookWithDeta
 	return r0, r1
 }
 
-// UpdateBook provides a mock function with given fields: _a0, _a1, _a2
-func (_m *Book) UpdateBook(_a0 context.Context, _a1 int64, _a2 *types.UpdateBook) (*types.Book, error) {
+// GetBookPage provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
+func (_m *Book) GetBookPage(_a0 context.Context, _a1 *types.BookFilter, _a2 types.BookOrder, _a3 int, _a4 int) (*types.BookPage, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3, _a4)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBookPage")
+	}
+
+	var r0 *types.BookPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.BookFilter, types.BookOrder, int, int) (*types.BookPage, error)); ok {
+		return rf(_a0, _a1, _a2, _a3, _a4)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *types.BookFilter, types.BookOrder, int, int) *types.BookPage); ok {
+		r0 = rf(_a0, _a1, _a2, _a3, _a4)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.BookPage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *types.BookFilter, types.BookOrder, int, int) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3, _a4)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBookRevisions provides a mock function with given fields: _a0, _a1
+func (_m *Book) GetBookRevisions(_a0 context.Context, _a1 int64) ([]*types.Revision, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBookRevisions")
+	}
+
+	var r0 []*types.Revision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*types.Revision, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*types.Revision); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Revision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBookTranslations provides a mock function with given fields: _a0, _a1
+func (_m *Book) GetBookTranslations(_a0 context.Context, _a1 int64) ([]*types.Translation, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBookTranslations")
+	}
+
+	var r0 []*types.Translation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*types.Translation, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*types.Translation); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Translation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBooksByIDs provides a mock function with given fields: _a0, _a1
+func (_m *Book) GetBooksByIDs(_a0 context.Context, _a1 []int64) ([]*types.BookWithDetails, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBooksByIDs")
+	}
+
+	var r0 []*types.BookWithDetails
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) ([]*types.BookWithDetails, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) []*types.BookWithDetails); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.BookWithDetails)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PatchBook provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Book) PatchBook(_a0 context.Context, _a1 int64, _a2 int64, _a3 *types.Patch) (*types.Book, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PatchBook")
+	}
+
+	var r0 *types.Book
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, *types.Patch) (*types.Book, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, *types.Patch) *types.Book); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Book)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, *types.Patch) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReplaceBook provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Book) ReplaceBook(_a0 context.Context, _a1 int64, _a2 int64, _a3 *types.Book) (*types.Book, bool, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceBook")
+	}
+
+	var r0 *types.Book
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, *types.Book) (*types.Book, bool, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, *types.Book) *types.Book); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Book)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, *types.Book) bool); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int64, int64, *types.Book) error); ok {
+		r2 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// RevertBook provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Book) RevertBook(_a0 context.Context, _a1 int64, _a2 int64, _a3 int64) (*types.Book, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevertBook")
+	}
+
+	var r0 *types.Book
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) (*types.Book, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) *types.Book); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Book)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, int64) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetBookTranslation provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Book) SetBookTranslation(_a0 context.Context, _a1 int64, _a2 *types.Translation) error {
 	ret := _m.Called(_a0, _a1, _a2)
 
+	if len(ret) == 0 {
+		panic("no return value specified for SetBookTranslation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.Translation) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateBook provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Book) UpdateBook(_a0 context.Context, _a1 int64, _a2 int64, _a3 *types.UpdateBook) (*types.Book, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateBook")
 	}
 
 	var r0 *types.Book
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.UpdateBook) (*types.Book, error)); ok {
-		return rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, *types.UpdateBook) (*types.Book, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.UpdateBook) *types.Book); ok {
-		r0 = rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, *types.UpdateBook) *types.Book); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*types.Book)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int64, *types.UpdateBook) error); ok {
-		r1 = rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, *types.UpdateBook) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
 	} else {
 		r1 = ret.Error(1)
 	}
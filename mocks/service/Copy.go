@@ -0,0 +1,138 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mockservice
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	types "github.com/tredoc/go-crud-api/pkg/types"
+)
+
+// Copy is an autogenerated mock type for the Copy type
+type Copy struct {
+	mock.Mock
+}
+
+// CreateCopy provides a mock function with given fields: _a0, _a1
+func (_m *Copy) CreateCopy(_a0 context.Context, _a1 *types.Copy) (*types.Copy, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateCopy")
+	}
+
+	var r0 *types.Copy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.Copy) (*types.Copy, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *types.Copy) *types.Copy); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Copy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *types.Copy) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteCopy provides a mock function with given fields: _a0, _a1
+func (_m *Copy) DeleteCopy(_a0 context.Context, _a1 int64) error {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCopy")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetCopiesByBookID provides a mock function with given fields: _a0, _a1
+func (_m *Copy) GetCopiesByBookID(_a0 context.Context, _a1 int64) ([]*types.Copy, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCopiesByBookID")
+	}
+
+	var r0 []*types.Copy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*types.Copy, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*types.Copy); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Copy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateCopy provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Copy) UpdateCopy(_a0 context.Context, _a1 int64, _a2 *types.UpdateCopy) (*types.Copy, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCopy")
+	}
+
+	var r0 *types.Copy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.UpdateCopy) (*types.Copy, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.UpdateCopy) *types.Copy); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Copy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, *types.UpdateCopy) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCopy creates a new instance of Copy. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCopy(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Copy {
+	mock := &Copy{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
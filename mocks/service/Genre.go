@@ -15,6 +15,36 @@ type Genre struct {
 	mock.Mock
 }
 
+// BatchGenres provides a mock function with given fields: _a0, _a1
+func (_m *Genre) BatchGenres(_a0 context.Context, _a1 *types.GenreBatch) ([]error, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BatchGenres")
+	}
+
+	var r0 []error
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.GenreBatch) ([]error, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *types.GenreBatch) []error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]error)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *types.GenreBatch) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateGenre provides a mock function with given fields: _a0, _a1
 func (_m *Genre) CreateGenre(_a0 context.Context, _a1 *types.Genre) (*types.Genre, error) {
 	ret := _m.Called(_a0, _a1)
@@ -45,17 +75,17 @@ func (_m *Genre) CreateGenre(_a0 context.Context, _a1 *types.Genre) (*types.Genr
 	return r0, r1
 }
 
-// DeleteGenre provides a mock function with given fields: _a0, _a1
-func (_m *Genre) DeleteGenre(_a0 context.Context, _a1 int64) error {
-	ret := _m.Called(_a0, _a1)
+// DeleteGenre provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Genre) DeleteGenre(_a0 context.Context, _a1 int64, _a2 int64) error {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteGenre")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
-		r0 = rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -63,6 +93,54 @@ func (_m *Genre) DeleteGenre(_a0 context.Context, _a1 int64) error {
 	return r0
 }
 
+// DeleteGenreTranslation provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Genre) DeleteGenreTranslation(_a0 context.Context, _a1 int64, _a2 string) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteGenreTranslation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DiffGenreRevisions provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Genre) DiffGenreRevisions(_a0 context.Context, _a1 int64, _a2 int64, _a3 int64) (*types.RevisionDiff, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DiffGenreRevisions")
+	}
+
+	var r0 *types.RevisionDiff
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) (*types.RevisionDiff, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) *types.RevisionDiff); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.RevisionDiff)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, int64) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAllGenres provides a mock function with given fields: _a0
 func (_m *Genre) GetAllGenres(_a0 context.Context) ([]*types.Genre, error) {
 	ret := _m.Called(_a0)
@@ -123,16 +201,194 @@ func (_m *Genre) GetGenreByID(_a0 context.Context, _a1 int64) (*types.Genre, err
 	return r0, r1
 }
 
-// UpdateGenre provides a mock function with given fields: _a0, _a1, _a2
-func (_m *Genre) UpdateGenre(_a0 context.Context, _a1 int64, _a2 *types.Genre) error {
+// GetGenreRevisions provides a mock function with given fields: _a0, _a1
+func (_m *Genre) GetGenreRevisions(_a0 context.Context, _a1 int64) ([]*types.Revision, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGenreRevisions")
+	}
+
+	var r0 []*types.Revision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*types.Revision, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*types.Revision); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Revision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetGenreTranslations provides a mock function with given fields: _a0, _a1
+func (_m *Genre) GetGenreTranslations(_a0 context.Context, _a1 int64) ([]*types.Translation, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGenreTranslations")
+	}
+
+	var r0 []*types.Translation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*types.Translation, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*types.Translation); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Translation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetGenreTree provides a mock function with given fields: _a0
+func (_m *Genre) GetGenreTree(_a0 context.Context) ([]*types.GenreNode, error) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGenreTree")
+	}
+
+	var r0 []*types.GenreNode
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*types.GenreNode, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*types.GenreNode); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.GenreNode)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MergeGenres provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Genre) MergeGenres(_a0 context.Context, _a1 int64, _a2 int64) (*types.Genre, error) {
 	ret := _m.Called(_a0, _a1, _a2)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateGenre")
+		panic("no return value specified for MergeGenres")
+	}
+
+	var r0 *types.Genre
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (*types.Genre, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) *types.Genre); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Genre)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReplaceGenre provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Genre) ReplaceGenre(_a0 context.Context, _a1 int64, _a2 int64, _a3 *types.Genre) (bool, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceGenre")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, *types.Genre) (bool, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, *types.Genre) bool); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, *types.Genre) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevertGenre provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Genre) RevertGenre(_a0 context.Context, _a1 int64, _a2 int64, _a3 int64) (*types.Genre, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevertGenre")
+	}
+
+	var r0 *types.Genre
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) (*types.Genre, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) *types.Genre); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Genre)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, int64) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetGenreTranslation provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Genre) SetGenreTranslation(_a0 context.Context, _a1 int64, _a2 *types.Translation) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetGenreTranslation")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.Genre) error); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.Translation) error); ok {
 		r0 = rf(_a0, _a1, _a2)
 	} else {
 		r0 = ret.Error(0)
@@ -141,6 +397,24 @@ func (_m *Genre) UpdateGenre(_a0 context.Context, _a1 int64, _a2 *types.Genre) e
 	return r0
 }
 
+// UpdateGenre provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Genre) UpdateGenre(_a0 context.Context, _a1 int64, _a2 int64, _a3 *types.Genre) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateGenre")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, *types.Genre) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewGenre creates a new instance of Genre. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewGenre(t interface {
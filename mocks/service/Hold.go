@@ -0,0 +1,166 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mockservice
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	types "github.com/tredoc/go-crud-api/pkg/types"
+)
+
+// Hold is an autogenerated mock type for the Hold type
+type Hold struct {
+	mock.Mock
+}
+
+// CancelHold provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Hold) CancelHold(_a0 context.Context, _a1 int64, _a2 *types.User) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelHold")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.User) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExpireHolds provides a mock function with given fields: _a0
+func (_m *Hold) ExpireHolds(_a0 context.Context) (int64, error) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExpireHolds")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBookHolds provides a mock function with given fields: _a0, _a1
+func (_m *Hold) GetBookHolds(_a0 context.Context, _a1 int64) ([]*types.Hold, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBookHolds")
+	}
+
+	var r0 []*types.Hold
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*types.Hold, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*types.Hold); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Hold)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserHolds provides a mock function with given fields: _a0, _a1
+func (_m *Hold) GetUserHolds(_a0 context.Context, _a1 int64) ([]*types.Hold, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserHolds")
+	}
+
+	var r0 []*types.Hold
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*types.Hold, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*types.Hold); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Hold)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PlaceHold provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Hold) PlaceHold(_a0 context.Context, _a1 int64, _a2 int64) (*types.Hold, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PlaceHold")
+	}
+
+	var r0 *types.Hold
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (*types.Hold, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) *types.Hold); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Hold)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewHold creates a new instance of Hold. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewHold(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Hold {
+	mock := &Hold{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,101 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mockservice
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	types "github.com/tredoc/go-crud-api/pkg/types"
+)
+
+// Idempotency is an autogenerated mock type for the Idempotency type
+type Idempotency struct {
+	mock.Mock
+}
+
+// Abandon provides a mock function with given fields: _a0, _a1
+func (_m *Idempotency) Abandon(_a0 context.Context, _a1 string) {
+	_m.Called(_a0, _a1)
+}
+
+// Begin provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Idempotency) Begin(_a0 context.Context, _a1 string, _a2 string) (*types.IdempotentResponse, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Begin")
+	}
+
+	var r0 *types.IdempotentResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*types.IdempotentResponse, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *types.IdempotentResponse); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.IdempotentResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Extend provides a mock function with given fields: _a0, _a1
+func (_m *Idempotency) Extend(_a0 context.Context, _a1 string) error {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Extend")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Finish provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Idempotency) Finish(_a0 context.Context, _a1 string, _a2 *types.IdempotentResponse) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Finish")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *types.IdempotentResponse) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewIdempotency creates a new instance of Idempotency. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIdempotency(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Idempotency {
+	mock := &Idempotency{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,126 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mockservice
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	types "github.com/tredoc/go-crud-api/pkg/types"
+)
+
+// Import is an autogenerated mock type for the Import type
+type Import struct {
+	mock.Mock
+}
+
+// FailStaleImports provides a mock function with given fields: _a0
+func (_m *Import) FailStaleImports(_a0 context.Context) (int64, error) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FailStaleImports")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetImportJob provides a mock function with given fields: _a0, _a1
+func (_m *Import) GetImportJob(_a0 context.Context, _a1 int64) (*types.ImportJob, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetImportJob")
+	}
+
+	var r0 *types.ImportJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*types.ImportJob, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *types.ImportJob); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.ImportJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StartImport provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Import) StartImport(_a0 context.Context, _a1 *types.ImportJob, _a2 []*types.ImportRow) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartImport")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.ImportJob, []*types.ImportRow) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpsertBooks provides a mock function with given fields: _a0, _a1
+func (_m *Import) UpsertBooks(_a0 context.Context, _a1 []*types.ImportRow) []*types.ImportOutcome {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertBooks")
+	}
+
+	var r0 []*types.ImportOutcome
+	if rf, ok := ret.Get(0).(func(context.Context, []*types.ImportRow) []*types.ImportOutcome); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.ImportOutcome)
+		}
+	}
+
+	return r0
+}
+
+// NewImport creates a new instance of Import. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewImport(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Import {
+	mock := &Import{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,180 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mockservice
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	types "github.com/tredoc/go-crud-api/pkg/types"
+)
+
+// Loan is an autogenerated mock type for the Loan type
+type Loan struct {
+	mock.Mock
+}
+
+// Checkout provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Loan) Checkout(_a0 context.Context, _a1 *types.User, _a2 *types.Checkout) (*types.Loan, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Checkout")
+	}
+
+	var r0 *types.Loan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.User, *types.Checkout) (*types.Loan, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *types.User, *types.Checkout) *types.Loan); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Loan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *types.User, *types.Checkout) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLoans provides a mock function with given fields: _a0, _a1
+func (_m *Loan) GetLoans(_a0 context.Context, _a1 types.LoanStatus) ([]*types.Loan, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLoans")
+	}
+
+	var r0 []*types.Loan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, types.LoanStatus) ([]*types.Loan, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, types.LoanStatus) []*types.Loan); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Loan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, types.LoanStatus) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserLoans provides a mock function with given fields: _a0, _a1
+func (_m *Loan) GetUserLoans(_a0 context.Context, _a1 int64) ([]*types.Loan, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserLoans")
+	}
+
+	var r0 []*types.Loan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*types.Loan, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*types.Loan); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Loan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RenewLoan provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Loan) RenewLoan(_a0 context.Context, _a1 int64, _a2 *types.User) (*types.Loan, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenewLoan")
+	}
+
+	var r0 *types.Loan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.User) (*types.Loan, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.User) *types.Loan); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Loan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, *types.User) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReturnLoan provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Loan) ReturnLoan(_a0 context.Context, _a1 int64, _a2 *types.User) (*types.Loan, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReturnLoan")
+	}
+
+	var r0 *types.Loan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.User) (*types.Loan, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.User) *types.Loan); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Loan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, *types.User) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewLoan creates a new instance of Loan. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLoan(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Loan {
+	mock := &Loan{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
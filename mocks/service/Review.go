@@ -0,0 +1,138 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mockservice
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	types "github.com/tredoc/go-crud-api/pkg/types"
+)
+
+// Review is an autogenerated mock type for the Review type
+type Review struct {
+	mock.Mock
+}
+
+// CreateReview provides a mock function with given fields: _a0, _a1
+func (_m *Review) CreateReview(_a0 context.Context, _a1 *types.Review) (*types.Review, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateReview")
+	}
+
+	var r0 *types.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.Review) (*types.Review, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *types.Review) *types.Review); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *types.Review) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteReview provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Review) DeleteReview(_a0 context.Context, _a1 int64, _a2 *types.User) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteReview")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.User) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetReviewsByBookID provides a mock function with given fields: _a0, _a1
+func (_m *Review) GetReviewsByBookID(_a0 context.Context, _a1 int64) ([]*types.Review, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReviewsByBookID")
+	}
+
+	var r0 []*types.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*types.Review, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*types.Review); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateReview provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Review) UpdateReview(_a0 context.Context, _a1 int64, _a2 *types.User, _a3 *types.UpdateReview) (*types.Review, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateReview")
+	}
+
+	var r0 *types.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.User, *types.UpdateReview) (*types.Review, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.User, *types.UpdateReview) *types.Review); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, *types.User, *types.UpdateReview) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewReview creates a new instance of Review. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReview(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Review {
+	mock := &Review{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
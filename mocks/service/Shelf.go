@@ -0,0 +1,234 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mockservice
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	types "github.com/tredoc/go-crud-api/pkg/types"
+)
+
+// Shelf is an autogenerated mock type for the Shelf type
+type Shelf struct {
+	mock.Mock
+}
+
+// AddBookToShelf provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Shelf) AddBookToShelf(_a0 context.Context, _a1 int64, _a2 types.ShelfRef, _a3 int64) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddBookToShelf")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, types.ShelfRef, int64) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateShelf provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Shelf) CreateShelf(_a0 context.Context, _a1 int64, _a2 *types.Shelf) (*types.Shelf, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateShelf")
+	}
+
+	var r0 *types.Shelf
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.Shelf) (*types.Shelf, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *types.Shelf) *types.Shelf); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Shelf)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, *types.Shelf) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteShelf provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Shelf) DeleteShelf(_a0 context.Context, _a1 int64, _a2 int64) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteShelf")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetReadingStats provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Shelf) GetReadingStats(_a0 context.Context, _a1 int64, _a2 int) (*types.ReadingStats, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReadingStats")
+	}
+
+	var r0 *types.ReadingStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) (*types.ReadingStats, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) *types.ReadingStats); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.ReadingStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetShelfBooks provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Shelf) GetShelfBooks(_a0 context.Context, _a1 int64, _a2 types.ShelfRef) ([]*types.ShelvedBook, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetShelfBooks")
+	}
+
+	var r0 []*types.ShelvedBook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, types.ShelfRef) ([]*types.ShelvedBook, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, types.ShelfRef) []*types.ShelvedBook); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.ShelvedBook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, types.ShelfRef) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetShelves provides a mock function with given fields: _a0, _a1
+func (_m *Shelf) GetShelves(_a0 context.Context, _a1 int64) ([]*types.Shelf, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetShelves")
+	}
+
+	var r0 []*types.Shelf
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*types.Shelf, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*types.Shelf); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Shelf)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveBookFromShelf provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Shelf) RemoveBookFromShelf(_a0 context.Context, _a1 int64, _a2 types.ShelfRef, _a3 int64) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveBookFromShelf")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, types.ShelfRef, int64) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateReading provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Shelf) UpdateReading(_a0 context.Context, _a1 int64, _a2 int64, _a3 *types.UpdateReading) (*types.ReadingEntry, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateReading")
+	}
+
+	var r0 *types.ReadingEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, *types.UpdateReading) (*types.ReadingEntry, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, *types.UpdateReading) *types.ReadingEntry); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.ReadingEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, *types.UpdateReading) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewShelf creates a new instance of Shelf. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewShelf(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Shelf {
+	mock := &Shelf{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,229 @@
+package citation
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MaxBooks is the most books one citation export can hold.
+const MaxBooks = 100
+
+type Format string
+
+const (
+	BibTeX  Format = "bibtex"
+	RIS     Format = "ris"
+	CSLJSON Format = "csl-json"
+)
+
+// ContentType returns the media type of the format and whether the format is known.
+func ContentType(format Format) (string, bool) {
+	switch format {
+	case BibTeX:
+		return "application/x-bibtex; charset=utf-8", true
+	case RIS:
+		return "application/x-research-info-systems; charset=utf-8", true
+	case CSLJSON:
+		return "application/vnd.citationstyles.csl+json", true
+	}
+
+	return "", false
+}
+
+// Write writes the citations of the books in the format, in the order of the books.
+func Write(w io.Writer, format Format, books []*types.BookWithDetails) error {
+	switch format {
+	case BibTeX:
+		return writeBibTeX(w, books)
+	case RIS:
+		return writeRIS(w, books)
+	case CSLJSON:
+		return writeCSLJSON(w, books)
+	}
+
+	return fmt.Errorf("citation: unknown format %q", format)
+}
+
+// Key returns the citation key of the book: the last name of the first author, the year of
+// publication and the first word of the title that isn't an article, folded to lowercase ASCII and
+// followed by a hyphen and the ID of the book, e.g. "leguin1974dispossessed-12". The ID keeps keys
+// of books that share the rest apart, and the key of a book the same whichever books it's cited
+// with.
+func Key(book *types.BookWithDetails) string {
+	return baseKey(book) + "-" + strconv.FormatInt(book.ID, 10)
+}
+
+var articles = map[string]bool{"a": true, "an": true, "the": true, "der": true, "die": true, "das": true,
+	"le": true, "la": true, "les": true, "el": true, "los": true, "las": true, "il": true, "lo": true}
+
+func baseKey(book *types.BookWithDetails) string {
+	var key strings.Builder
+	if len(book.Authors) > 0 {
+		key.WriteString(keyPart(book.Authors[0].LastName))
+	}
+	if !book.PublishDate.IsZero() {
+		key.WriteString(strconv.Itoa(book.PublishDate.Year()))
+	}
+
+	for _, word := range strings.FieldsFunc(types.FoldName(book.Title), isNotKeyRune) {
+		if !articles[word] {
+			key.WriteString(keyPart(word))
+			break
+		}
+	}
+
+	if key.Len() == 0 {
+		return "book"
+	}
+
+	return key.String()
+}
+
+// keyPart folds the text to the lowercase ASCII letters and digits keys are made of.
+func keyPart(text string) string {
+	return strings.Map(func(r rune) rune {
+		if isNotKeyRune(r) {
+			return -1
+		}
+		return r
+	}, types.FoldName(text))
+}
+
+func isNotKeyRune(r rune) bool {
+	return r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+var bibTeXEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`, `{`, `\{`, `}`, `\}`, `&`, `\&`, `%`, `\%`, `$`, `\$`, `#`, `\#`, `_`, `\_`,
+	`~`, `\textasciitilde{}`, `^`, `\textasciicircum{}`,
+)
+
+func writeBibTeX(w io.Writer, books []*types.BookWithDetails) error {
+	var out strings.Builder
+	for i, book := range books {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+
+		authors := make([]string, len(book.Authors))
+		for j, author := range book.Authors {
+			// Braces keep an "and" in a name from splitting it into two authors.
+			authors[j] = "{" + bibTeXEscaper.Replace(author.LastName) + "}"
+			if given := author.GivenNames(); given != "" {
+				authors[j] += ", " + bibTeXEscaper.Replace(given)
+			}
+		}
+
+		fmt.Fprintf(&out, "@book{%s,\n", Key(book))
+		writeBibTeXField(&out, "author", strings.Join(authors, " and "))
+		writeBibTeXField(&out, "title", bibTeXEscaper.Replace(book.Title))
+		if !book.PublishDate.IsZero() {
+			writeBibTeXField(&out, "year", strconv.Itoa(book.PublishDate.Year()))
+			writeBibTeXField(&out, "date", book.PublishDate.Format(time.DateOnly))
+		}
+		writeBibTeXField(&out, "isbn", bibTeXEscaper.Replace(book.ISBN))
+		if book.Pages > 0 {
+			writeBibTeXField(&out, "pagetotal", strconv.Itoa(int(book.Pages)))
+		}
+		out.WriteString("}\n")
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+func writeBibTeXField(out *strings.Builder, name string, value string) {
+	if value != "" {
+		fmt.Fprintf(out, "  %s = {%s},\n", name, value)
+	}
+}
+
+func writeRIS(w io.Writer, books []*types.BookWithDetails) error {
+	var out strings.Builder
+	tag := func(name string, value string) {
+		if value != "" {
+			fmt.Fprintf(&out, "%s  - %s\r\n", name, strings.Join(strings.Fields(value), " "))
+		}
+	}
+
+	for _, book := range books {
+		tag("TY", "BOOK")
+		tag("ID", Key(book))
+		for _, author := range book.Authors {
+			tag("AU", author.InvertedName())
+		}
+		tag("TI", book.Title)
+		if !book.PublishDate.IsZero() {
+			tag("PY", strconv.Itoa(book.PublishDate.Year()))
+			tag("DA", book.PublishDate.Format("2006/01/02"))
+		}
+		tag("SN", book.ISBN)
+		if book.Pages > 0 {
+			// SP holds the number of pages of a BOOK.
+			tag("SP", strconv.Itoa(int(book.Pages)))
+		}
+		for _, genre := range book.Genres {
+			tag("KW", genre.Name)
+		}
+		out.WriteString("ER  - \r\n")
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+type cslItem struct {
+	ID            string     `json:"id"`
+	Type          string     `json:"type"`
+	Title         string     `json:"title"`
+	Author        []*cslName `json:"author,omitempty"`
+	Issued        *cslDate   `json:"issued,omitempty"`
+	ISBN          string     `json:"ISBN,omitempty"`
+	NumberOfPages int        `json:"number-of-pages,omitempty"`
+	Keyword       string     `json:"keyword,omitempty"`
+}
+
+type cslName struct {
+	Family string `json:"family"`
+	Given  string `json:"given,omitempty"`
+}
+
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+func writeCSLJSON(w io.Writer, books []*types.BookWithDetails) error {
+	items := make([]*cslItem, len(books))
+	for i, book := range books {
+		item := cslItem{
+			ID:            Key(book),
+			Type:          "book",
+			Title:         book.Title,
+			ISBN:          book.ISBN,
+			NumberOfPages: int(book.Pages),
+		}
+		for _, author := range book.Authors {
+			item.Author = append(item.Author, &cslName{Family: author.LastName, Given: author.GivenNames()})
+		}
+		if !book.PublishDate.IsZero() {
+			date := book.PublishDate
+			item.Issued = &cslDate{DateParts: [][]int{{date.Year(), int(date.Month()), date.Day()}}}
+		}
+		genres := make([]string, len(book.Genres))
+		for j, genre := range book.Genres {
+			genres[j] = genre.Name
+		}
+		item.Keyword = strings.Join(genres, ", ")
+		items[i] = &item
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(items)
+}
@@ -0,0 +1,150 @@
+package citation
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(t *testing.T, value string) types.CustomDate {
+	parsed, err := time.Parse(time.DateOnly, value)
+	require.NoError(t, err)
+	return types.CustomDate{Time: parsed}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name string
+		book *types.BookWithDetails
+		key  string
+	}{
+		{
+			name: "leading article",
+			book: &types.BookWithDetails{ID: 12, Title: "The Dispossessed", PublishDate: date(t, "1974-05-01"),
+				Authors: []*types.Author{{FirstName: "Ursula", LastName: "Le Guin"}}},
+			key: "leguin1974dispossessed-12",
+		},
+		{
+			name: "accents and punctuation",
+			book: &types.BookWithDetails{ID: 3, Title: "Cien años de soledad", PublishDate: date(t, "1967-05-30"),
+				Authors: []*types.Author{{FirstName: "Gabriel", LastName: "García Márquez"}}},
+			key: "garciamarquez1967cien-3",
+		},
+		{
+			name: "first author only",
+			book: &types.BookWithDetails{ID: 5, Title: "Go", PublishDate: date(t, "2015-01-01"),
+				Authors: []*types.Author{{LastName: "Donovan"}, {LastName: "Kernighan"}}},
+			key: "donovan2015go-5",
+		},
+		{
+			name: "title word ending like a suffix",
+			book: &types.BookWithDetails{ID: 2, Title: "Gob", PublishDate: date(t, "2006-01-01"),
+				Authors: []*types.Author{{LastName: "Pike"}}},
+			key: "pike2006gob-2",
+		},
+		{
+			name: "nothing to build the key of",
+			book: &types.BookWithDetails{ID: 7, Title: "¿?"},
+			key:  "book-7",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.key, Key(test.book))
+		})
+	}
+}
+
+func TestWrite_KeysDontDependOnOtherBooks(t *testing.T) {
+	first := &types.BookWithDetails{ID: 93, Title: "Go", PublishDate: date(t, "2015-01-01"), Authors: []*types.Author{{LastName: "Donovan"}}}
+	second := &types.BookWithDetails{ID: 94, Title: "Go", PublishDate: date(t, "2015-06-01"), Authors: []*types.Author{{LastName: "Donovan"}}}
+
+	var alone, together bytes.Buffer
+	require.NoError(t, Write(&alone, BibTeX, []*types.BookWithDetails{second}))
+	require.NoError(t, Write(&together, BibTeX, []*types.BookWithDetails{first, second}))
+
+	assert.True(t, strings.HasPrefix(alone.String(), "@book{donovan2015go-94,\n"))
+	assert.Contains(t, together.String(), "@book{donovan2015go-93,\n")
+	assert.Contains(t, together.String(), "@book{donovan2015go-94,\n")
+}
+
+func TestWrite(t *testing.T) {
+	book := &types.BookWithDetails{
+		ID:          92,
+		Title:       "The Dispossessed: {an} ambiguous\nutopia & 100% more",
+		PublishDate: date(t, "1974-05-01"),
+		ISBN:        "978-0-06-051275-4",
+		Pages:       387,
+		Authors:     []*types.Author{{FirstName: "Ursula", MiddleName: "K.", LastName: "Le Guin"}},
+		Genres:      []*types.Genre{{Name: "science fiction"}, {Name: "utopia"}},
+	}
+
+	t.Run("bibtex", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, Write(&out, BibTeX, []*types.BookWithDetails{book}))
+		assert.Equal(t, "@book{leguin1974dispossessed-92,\n"+
+			"  author = {{Le Guin}, Ursula K.},\n"+
+			"  title = {The Dispossessed: \\{an\\} ambiguous\nutopia \\& 100\\% more},\n"+
+			"  year = {1974},\n"+
+			"  date = {1974-05-01},\n"+
+			"  isbn = {978-0-06-051275-4},\n"+
+			"  pagetotal = {387},\n"+
+			"}\n", out.String())
+	})
+
+	t.Run("ris", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, Write(&out, RIS, []*types.BookWithDetails{book}))
+		assert.Equal(t, "TY  - BOOK\r\nID  - leguin1974dispossessed-92\r\nAU  - Le Guin, Ursula K.\r\n"+
+			"TI  - The Dispossessed: {an} ambiguous utopia & 100% more\r\nPY  - 1974\r\nDA  - 1974/05/01\r\n"+
+			"SN  - 978-0-06-051275-4\r\nSP  - 387\r\nKW  - science fiction\r\nKW  - utopia\r\nER  - \r\n", out.String())
+	})
+
+	t.Run("csl-json", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, Write(&out, CSLJSON, []*types.BookWithDetails{book}))
+
+		var items []*cslItem
+		require.NoError(t, json.Unmarshal(out.Bytes(), &items))
+		require.Len(t, items, 1)
+		assert.Equal(t, "leguin1974dispossessed-92", items[0].ID)
+		assert.Equal(t, "book", items[0].Type)
+		assert.Equal(t, []*cslName{{Family: "Le Guin", Given: "Ursula K."}}, items[0].Author)
+		assert.Equal(t, [][]int{{1974, 5, 1}}, items[0].Issued.DateParts)
+		assert.Equal(t, 387, items[0].NumberOfPages)
+		assert.Equal(t, "science fiction, utopia", items[0].Keyword)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.Error(t, Write(&out, Format("endnote"), []*types.BookWithDetails{book}))
+		assert.Zero(t, out.Len())
+	})
+}
+
+func TestContentType(t *testing.T) {
+	tests := []struct {
+		format      Format
+		contentType string
+		ok          bool
+	}{
+		{format: BibTeX, contentType: "application/x-bibtex; charset=utf-8", ok: true},
+		{format: RIS, contentType: "application/x-research-info-systems; charset=utf-8", ok: true},
+		{format: CSLJSON, contentType: "application/vnd.citationstyles.csl+json", ok: true},
+		{format: Format("BibTeX")},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.format), func(t *testing.T) {
+			contentType, ok := ContentType(test.format)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.contentType, contentType)
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package marc
+
+import (
+	"fmt"
+	"github.com/tredoc/go-crud-api/pkg/types"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	yearRX  = regexp.MustCompile(`\d{4}`)
+	pagesRX = regexp.MustCompile(`(\d+)\s*(?:p\b|p\.|pages)`)
+)
+
+// FromBook returns the record of the book. The first author is the main entry in 100, the others
+// are added entries in 700, genres are genre terms in 655. The catalogue keeps no publisher, 264
+// only holds the year of publication.
+func FromBook(book *types.BookWithDetails) *Record {
+	record := Record{Leader: defaultLeader}
+	record.addControlField("001", strconv.FormatInt(book.ID, 10))
+	record.addControlField("008", fixedLengthData(book))
+
+	if book.ISBN != "" {
+		record.addDataField("020", ' ', ' ', &Subfield{Code: 'a', Value: book.ISBN})
+	}
+
+	if len(book.Authors) > 0 {
+		record.addDataField("100", '1', ' ', &Subfield{Code: 'a', Value: book.Authors[0].InvertedName()})
+	}
+
+	var titleAddedEntry byte = '0'
+	if len(book.Authors) > 0 {
+		titleAddedEntry = '1'
+	}
+	record.addDataField("245", titleAddedEntry, '0', &Subfield{Code: 'a', Value: book.Title})
+
+	record.addDataField("264", ' ', '1', &Subfield{Code: 'c', Value: strconv.Itoa(book.PublishDate.Year())})
+	record.addDataField("300", ' ', ' ', &Subfield{Code: 'a', Value: fmt.Sprintf("%d pages", book.Pages)})
+
+	for _, genre := range book.Genres {
+		record.addDataField("655", ' ', '4', &Subfield{Code: 'a', Value: genre.Name})
+	}
+
+	for i := 1; i < len(book.Authors); i++ {
+		record.addDataField("700", '1', ' ', &Subfield{Code: 'a', Value: book.Authors[i].InvertedName()})
+	}
+
+	return &record
+}
+
+// ToImportRow reads the book of a record the way an import row holds it. MARC only dates a book by
+// its year, the publish date is the first of January of it. Line is the position of the record in
+// the upload.
+func ToImportRow(record *Record, line int) *types.ImportRow {
+	row := types.ImportRow{Line: line, Errors: make(map[string]string)}
+
+	for _, field := range record.Fields("245") {
+		row.Book.Title = trimPunctuation(field.Subfield('a'))
+		if subtitle := trimPunctuation(field.Subfield('b')); subtitle != "" {
+			row.Book.Title += ": " + subtitle
+		}
+		break
+	}
+
+	for _, field := range record.Fields("020") {
+		if isbn, _, _ := strings.Cut(field.Subfield('a'), " "); isbn != "" {
+			row.Book.ISBN = isbn
+			break
+		}
+	}
+
+	year := publicationYear(record)
+	if year == 0 {
+		row.Errors["publish_date"] = "no year of publication in 008, 260 or 264"
+	} else {
+		row.Book.PublishDate = types.CustomDate{Time: time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	}
+
+	for _, field := range record.Fields("300") {
+		match := pagesRX.FindStringSubmatch(field.Subfield('a'))
+		if match == nil {
+			continue
+		}
+		pages, err := strconv.ParseUint(match[1], 10, 16)
+		if err != nil {
+			row.Errors["pages"] = fmt.Sprintf("%s pages is more than a book can have", match[1])
+			break
+		}
+		row.Book.Pages = uint16(pages)
+		break
+	}
+
+	for _, field := range record.Fields("100", "700") {
+		// A 700 with a title is an added entry for another work, not an author of this one.
+		if field.Ind1 == '3' || field.Subfield('t') != "" {
+			continue
+		}
+		if name := trimPunctuation(field.Subfield('a')); name != "" {
+			row.Authors = append(row.Authors, name)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, field := range record.Fields("655", "650") {
+		name := trimPunctuation(field.Subfield('a'))
+		if name == "" || seen[types.FoldName(name)] {
+			continue
+		}
+		seen[types.FoldName(name)] = true
+		row.Genres = append(row.Genres, name)
+	}
+
+	if len(row.Errors) == 0 {
+		row.Errors = nil
+	}
+	return &row
+}
+
+// fixedLengthData returns the 008 of the book: the date it was entered, a single known date of
+// publication and fill characters for what the catalogue doesn't know.
+func fixedLengthData(book *types.BookWithDetails) string {
+	entered := strings.Repeat(" ", 6)
+	if !book.CreatedAt.IsZero() {
+		entered = book.CreatedAt.UTC().Format("060102")
+	}
+
+	return fmt.Sprintf("%ss%04d    xx %sund d", entered, book.PublishDate.Year(), strings.Repeat("|", 17))
+}
+
+// publicationYear returns the year of 264 or 260 $c, or of 008 when neither has one.
+func publicationYear(record *Record) int {
+	for _, field := range record.Fields("264", "260") {
+		if field.Tag == "264" && field.Ind2 != '1' {
+			continue
+		}
+		if year := yearRX.FindString(field.Subfield('c')); year != "" {
+			y, _ := strconv.Atoi(year)
+			return y
+		}
+	}
+
+	if fixed := record.ControlField("008"); len(fixed) >= 11 {
+		if y, err := strconv.Atoi(fixed[7:11]); err == nil {
+			return y
+		}
+	}
+
+	return 0
+}
+
+// trimPunctuation removes the punctuation cataloguers end fields with. The period after an initial
+// is kept.
+func trimPunctuation(s string) string {
+	s = strings.TrimRight(strings.TrimSpace(s), " ,;:/=")
+	if !strings.HasSuffix(s, ".") {
+		return s
+	}
+
+	words := strings.Fields(s)
+	if last := words[len(words)-1]; len([]rune(last)) == 2 {
+		return s
+	}
+
+	return strings.TrimSuffix(s, ".")
+}
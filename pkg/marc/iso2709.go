@@ -0,0 +1,198 @@
+package marc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+const (
+	subfieldDelimiter = 0x1f
+	fieldTerminator   = 0x1e
+	recordTerminator  = 0x1d
+
+	leaderLength         = 24
+	directoryEntryLength = 12
+	maxFieldLength       = 9999
+	maxRecordLength      = 99999
+
+	// defaultLeader is the leader of a record of a book coded in UTF-8, the lengths are set when
+	// the record is written.
+	defaultLeader = "00000nam a2200000   4500"
+)
+
+// ErrMalformed is returned for a record whose leader or directory doesn't describe its data.
+var ErrMalformed = errors.New("marc: malformed record")
+
+// Marshal encodes the record in ISO 2709. The record length and the base address of the leader are
+// computed, its other positions are kept.
+func Marshal(record *Record) ([]byte, error) {
+	var directory, data bytes.Buffer
+	addField := func(tag string, field []byte) error {
+		if len(tag) != 3 {
+			return fmt.Errorf("marc: tag %q isn't 3 characters long", tag)
+		}
+		if len(field) > maxFieldLength {
+			return fmt.Errorf("marc: field %s is longer than %d bytes", tag, maxFieldLength)
+		}
+		fmt.Fprintf(&directory, "%s%04d%05d", tag, len(field), data.Len())
+		data.Write(field)
+		return nil
+	}
+
+	for _, field := range record.ControlFields {
+		err := addField(field.Tag, append([]byte(field.Value), fieldTerminator))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, field := range record.DataFields {
+		buf := []byte{indicator(field.Ind1), indicator(field.Ind2)}
+		for _, subfield := range field.Subfields {
+			buf = append(buf, subfieldDelimiter, subfield.Code)
+			buf = append(buf, subfield.Value...)
+		}
+		err := addField(field.Tag, append(buf, fieldTerminator))
+		if err != nil {
+			return nil, err
+		}
+	}
+	directory.WriteByte(fieldTerminator)
+	data.WriteByte(recordTerminator)
+
+	baseAddress := leaderLength + directory.Len()
+	length := baseAddress + data.Len()
+	if length > maxRecordLength {
+		return nil, fmt.Errorf("marc: record is longer than %d bytes", maxRecordLength)
+	}
+
+	leader := record.Leader
+	if len(leader) != leaderLength {
+		leader = defaultLeader
+	}
+
+	out := make([]byte, 0, length)
+	out = fmt.Appendf(out, "%05d%s22%05d%s4500", length, leader[5:10], baseAddress, leader[17:20])
+	out = append(out, directory.Bytes()...)
+	return append(out, data.Bytes()...), nil
+}
+
+// Reader reads the records of an ISO 2709 file one at a time.
+type Reader struct {
+	r *bufio.Reader
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Read returns the next record or io.EOF after the last one. Line breaks between records are
+// skipped.
+func (r *Reader) Read() (*Record, error) {
+	data, err := r.r.ReadBytes(recordTerminator)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	data = bytes.TrimLeft(data, "\r\n\t ")
+	if len(data) == 0 {
+		return nil, io.EOF
+	}
+	if data[len(data)-1] != recordTerminator {
+		return nil, fmt.Errorf("%w: record isn't terminated", ErrMalformed)
+	}
+
+	return decode(data)
+}
+
+// decode reads a record ending in its terminator. The lengths and positions of the leader and the
+// directory are checked against the data before it's sliced, a record that doesn't add up fails
+// with ErrMalformed.
+func decode(data []byte) (*Record, error) {
+	if len(data) < leaderLength+1 {
+		return nil, fmt.Errorf("%w: record is shorter than its leader", ErrMalformed)
+	}
+	if !utf8.Valid(data) {
+		return nil, errors.New("marc: record isn't UTF-8, MARC-8 records aren't supported")
+	}
+
+	record := Record{Leader: string(data[:leaderLength])}
+	baseAddress, ok := parseDigits(data[12:17])
+	if !ok || baseAddress <= leaderLength || baseAddress > len(data)-1 {
+		return nil, fmt.Errorf("%w: leader has no valid base address", ErrMalformed)
+	}
+
+	directory := data[leaderLength : baseAddress-1]
+	if data[baseAddress-1] != fieldTerminator || len(directory)%directoryEntryLength != 0 {
+		return nil, fmt.Errorf("%w: directory isn't made of whole entries", ErrMalformed)
+	}
+
+	// The data ends before the record terminator.
+	dataEnd := len(data) - 1
+	for entry := directory; len(entry) > 0; entry = entry[directoryEntryLength:] {
+		tag := string(entry[:3])
+		length, ok := parseDigits(entry[3:7])
+		if !ok || length < 1 {
+			return nil, fmt.Errorf("%w: field %s has no valid length", ErrMalformed, tag)
+		}
+		start, ok := parseDigits(entry[7:12])
+		if !ok {
+			return nil, fmt.Errorf("%w: field %s has no valid start", ErrMalformed, tag)
+		}
+
+		end := baseAddress + start + length
+		if end > dataEnd {
+			return nil, fmt.Errorf("%w: field %s is out of the record", ErrMalformed, tag)
+		}
+		if data[end-1] != fieldTerminator {
+			return nil, fmt.Errorf("%w: field %s isn't terminated", ErrMalformed, tag)
+		}
+		field := data[baseAddress+start : end-1]
+
+		if isControlTag(tag) {
+			record.addControlField(tag, string(field))
+			continue
+		}
+
+		if len(field) < 2 {
+			return nil, fmt.Errorf("marc: field %s has no indicators", tag)
+		}
+		dataField := DataField{Tag: tag, Ind1: field[0], Ind2: field[1]}
+		chunks := bytes.Split(field[2:], []byte{subfieldDelimiter})
+		for _, chunk := range chunks[1:] {
+			if len(chunk) == 0 {
+				continue
+			}
+			dataField.Subfields = append(dataField.Subfields, &Subfield{Code: chunk[0], Value: string(chunk[1:])})
+		}
+		record.DataFields = append(record.DataFields, &dataField)
+	}
+
+	return &record, nil
+}
+
+// parseDigits reads a number made of ASCII digits only, unlike strconv.Atoi it takes no sign.
+func parseDigits(digits []byte) (int, bool) {
+	n := 0
+	for _, digit := range digits {
+		if digit < '0' || digit > '9' {
+			return 0, false
+		}
+		n = n*10 + int(digit-'0')
+	}
+
+	return n, len(digits) > 0
+}
+
+// indicator returns the indicator with a blank for an unset one.
+func indicator(ind byte) byte {
+	if ind == 0 {
+		return ' '
+	}
+
+	return ind
+}
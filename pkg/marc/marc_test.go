@@ -0,0 +1,184 @@
+package marc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+)
+
+// sampleRecord is a record of a book with an ISBN, a main entry, added entries and genre terms.
+func sampleRecord() *Record {
+	record := Record{Leader: defaultLeader}
+	record.addControlField("001", "42")
+	record.addControlField("008", "240501s2015    xx ||||||||||||||||| und d")
+	record.addDataField("020", ' ', ' ', &Subfield{Code: 'a', Value: "9780134190440 (paperback)"})
+	record.addDataField("100", '1', ' ', &Subfield{Code: 'a', Value: "Donovan, Alan A. A.,"})
+	record.addDataField("245", '1', '4', &Subfield{Code: 'a', Value: "The Go programming language /"})
+	record.addDataField("264", ' ', '1', &Subfield{Code: 'c', Value: "[2015]"})
+	record.addDataField("300", ' ', ' ', &Subfield{Code: 'a', Value: "xvii, 380 pages ;"})
+	record.addDataField("650", ' ', '0', &Subfield{Code: 'a', Value: "Go (Computer program language)"})
+	record.addDataField("700", '1', ' ', &Subfield{Code: 'a', Value: "Kernighan, Brian W.,"})
+	record.addDataField("700", '1', '2', &Subfield{Code: 'a', Value: "Pike, Rob."}, &Subfield{Code: 't', Value: "The practice of programming."})
+	return &record
+}
+
+func marshalSample(t *testing.T) []byte {
+	data, err := Marshal(sampleRecord())
+	require.NoError(t, err)
+	return data
+}
+
+// patch returns a copy of the data with the bytes at the offset replaced.
+func patch(data []byte, offset int, replacement string) []byte {
+	patched := bytes.Clone(data)
+	copy(patched[offset:], replacement)
+	return patched
+}
+
+func TestDecode(t *testing.T) {
+	valid := marshalSample(t)
+	// The first directory entry starts right after the leader: a tag, 4 digits of length and
+	// 5 digits of start.
+	lengthAt, startAt := leaderLength+3, leaderLength+7
+	baseAddress := leaderLength + directoryEntryLength*len(sampleRecord().ControlFields) + directoryEntryLength*len(sampleRecord().DataFields) + 1
+	firstTerminator := baseAddress + bytes.IndexByte(valid[baseAddress:], fieldTerminator)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "shorter than the leader", data: append(bytes.Clone(valid[:20]), recordTerminator)},
+		{name: "base address isn't a number", data: patch(valid, 12, "00a00")},
+		{name: "base address has a sign", data: patch(valid, 12, "-0001")},
+		{name: "base address is past the record", data: patch(valid, 12, "99999")},
+		{name: "base address is inside the leader", data: patch(valid, 12, "00010")},
+		{name: "field length has a sign", data: patch(valid, lengthAt, "+003")},
+		{name: "field length isn't a number", data: patch(valid, lengthAt, "00x3")},
+		{name: "field length is zero", data: patch(valid, lengthAt, "0000")},
+		{name: "field start has a sign", data: patch(valid, startAt, "-0001")},
+		{name: "field start isn't a number", data: patch(valid, startAt, "0 001")},
+		{name: "field is out of the record", data: patch(valid, startAt, "99990")},
+		{name: "field isn't terminated", data: patch(valid, firstTerminator, "x")},
+		{name: "truncated", data: append(bytes.Clone(valid[:len(valid)/2]), recordTerminator)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			record, err := decode(test.data)
+			assert.ErrorIs(t, err, ErrMalformed)
+			assert.Nil(t, record)
+		})
+	}
+}
+
+func TestReader(t *testing.T) {
+	valid := marshalSample(t)
+
+	t.Run("valid records", func(t *testing.T) {
+		reader := NewReader(bytes.NewReader(append(append(bytes.Clone(valid), '\n'), valid...)))
+		for i := 0; i < 2; i++ {
+			record, err := reader.Read()
+			require.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf("%05d", len(valid)), record.Leader[:5], "record length")
+			assert.Equal(t, sampleRecord().ControlFields, record.ControlFields)
+			assert.Equal(t, sampleRecord().DataFields, record.DataFields)
+		}
+
+		_, err := reader.Read()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("truncated input", func(t *testing.T) {
+		_, err := NewReader(bytes.NewReader(valid[:len(valid)-10])).Read()
+		assert.ErrorIs(t, err, ErrMalformed)
+	})
+
+	t.Run("not UTF-8", func(t *testing.T) {
+		data := patch(valid, bytes.Index(valid, []byte("Donovan")), "\xff")
+		_, err := NewReader(bytes.NewReader(data)).Read()
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrMalformed)
+	})
+}
+
+func TestMarshalXML_RoundTrip(t *testing.T) {
+	data, err := MarshalXML(sampleRecord())
+	require.NoError(t, err)
+
+	reader := NewXMLReader(bytes.NewReader(data))
+	record, err := reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, sampleRecord(), record)
+
+	_, err = reader.Read()
+	assert.True(t, errors.Is(err, io.EOF))
+}
+
+func TestXMLReader_Namespace(t *testing.T) {
+	_, err := NewXMLReader(bytes.NewReader([]byte(`<record><leader>` + defaultLeader + `</leader></record>`))).Read()
+	assert.Error(t, err)
+}
+
+func TestToImportRow(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  func() *Record
+		isbn    string
+		authors []string
+		year    int
+		pages   uint16
+		errors  []string
+	}{
+		{
+			name:    "isbn and authors",
+			record:  sampleRecord,
+			isbn:    "9780134190440",
+			authors: []string{"Donovan, Alan A. A.", "Kernighan, Brian W."},
+			year:    2015,
+			pages:   380,
+		},
+		{
+			name: "year from 008 without 264",
+			record: func() *Record {
+				record := sampleRecord()
+				record.DataFields = record.Fields("020", "100", "245")
+				return record
+			},
+			isbn:    "9780134190440",
+			authors: []string{"Donovan, Alan A. A."},
+			year:    2015,
+		},
+		{
+			name: "no year of publication",
+			record: func() *Record {
+				record := Record{Leader: defaultLeader}
+				record.addDataField("245", '0', '0', &Subfield{Code: 'a', Value: "Untitled"})
+				return &record
+			},
+			errors: []string{"publish_date"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			row := ToImportRow(test.record(), 3)
+			assert.Equal(t, 3, row.Line)
+			assert.Equal(t, test.isbn, row.Book.ISBN)
+			assert.Equal(t, test.authors, row.Authors)
+			assert.Equal(t, test.pages, row.Book.Pages)
+			if test.year != 0 {
+				assert.Equal(t, test.year, row.Book.PublishDate.Year())
+			}
+			for _, field := range test.errors {
+				assert.Contains(t, row.Errors, field)
+			}
+			if len(test.errors) == 0 {
+				assert.Nil(t, row.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package marc
+
+import "strings"
+
+const (
+	// Type is the media type of ISO 2709 records.
+	Type = "application/marc"
+	// XMLType is the media type of MARCXML.
+	XMLType = "application/marcxml+xml"
+	// Namespace is the namespace of MARCXML elements.
+	Namespace = "http://www.loc.gov/MARC21/slim"
+)
+
+// Record is a MARC 21 bibliographic record. Control fields, tags 001 to 009, hold their data as
+// is, data fields hold two indicators and a list of subfields.
+type Record struct {
+	Leader        string
+	ControlFields []*ControlField
+	DataFields    []*DataField
+}
+
+type ControlField struct {
+	Tag   string
+	Value string
+}
+
+type DataField struct {
+	Tag       string
+	Ind1      byte
+	Ind2      byte
+	Subfields []*Subfield
+}
+
+type Subfield struct {
+	Code  byte
+	Value string
+}
+
+// ControlField returns the data of the first control field with the tag, or "" without one.
+func (r *Record) ControlField(tag string) string {
+	for _, field := range r.ControlFields {
+		if field.Tag == tag {
+			return field.Value
+		}
+	}
+
+	return ""
+}
+
+// Fields returns the data fields with one of the tags in the order of the record.
+func (r *Record) Fields(tags ...string) []*DataField {
+	var fields []*DataField
+	for _, field := range r.DataFields {
+		for _, tag := range tags {
+			if field.Tag == tag {
+				fields = append(fields, field)
+				break
+			}
+		}
+	}
+
+	return fields
+}
+
+// Subfield returns the trimmed value of the first subfield with the code, or "" without one.
+func (f *DataField) Subfield(code byte) string {
+	for _, subfield := range f.Subfields {
+		if subfield.Code == code {
+			return strings.TrimSpace(subfield.Value)
+		}
+	}
+
+	return ""
+}
+
+func (r *Record) addControlField(tag string, value string) {
+	r.ControlFields = append(r.ControlFields, &ControlField{Tag: tag, Value: value})
+}
+
+func (r *Record) addDataField(tag string, ind1 byte, ind2 byte, subfields ...*Subfield) {
+	r.DataFields = append(r.DataFields, &DataField{Tag: tag, Ind1: ind1, Ind2: ind2, Subfields: subfields})
+}
+
+func isControlTag(tag string) bool {
+	return strings.HasPrefix(tag, "00")
+}
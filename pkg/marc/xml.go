@@ -0,0 +1,116 @@
+package marc
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+)
+
+type xmlRecord struct {
+	XMLName       xml.Name           `xml:"http://www.loc.gov/MARC21/slim record"`
+	Leader        string             `xml:"leader"`
+	ControlFields []*xmlControlField `xml:"controlfield"`
+	DataFields    []*xmlDataField    `xml:"datafield"`
+}
+
+type xmlControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlDataField struct {
+	Tag       string         `xml:"tag,attr"`
+	Ind1      string         `xml:"ind1,attr"`
+	Ind2      string         `xml:"ind2,attr"`
+	Subfields []*xmlSubfield `xml:"subfield"`
+}
+
+type xmlSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// MarshalXML encodes the record as a MARCXML record element.
+func MarshalXML(record *Record) ([]byte, error) {
+	out := xmlRecord{Leader: record.Leader}
+	if len(out.Leader) != leaderLength {
+		out.Leader = defaultLeader
+	}
+
+	for _, field := range record.ControlFields {
+		out.ControlFields = append(out.ControlFields, &xmlControlField{Tag: field.Tag, Value: field.Value})
+	}
+
+	for _, field := range record.DataFields {
+		dataField := xmlDataField{
+			Tag:  field.Tag,
+			Ind1: string(indicator(field.Ind1)),
+			Ind2: string(indicator(field.Ind2)),
+		}
+		for _, subfield := range field.Subfields {
+			dataField.Subfields = append(dataField.Subfields, &xmlSubfield{Code: string(subfield.Code), Value: subfield.Value})
+		}
+		out.DataFields = append(out.DataFields, &dataField)
+	}
+
+	return xml.Marshal(out)
+}
+
+// XMLReader reads the records of a MARCXML document one at a time, the document being a collection
+// or a single record.
+type XMLReader struct {
+	decoder *xml.Decoder
+}
+
+func NewXMLReader(r io.Reader) *XMLReader {
+	return &XMLReader{decoder: xml.NewDecoder(r)}
+}
+
+// Read returns the next record or io.EOF after the last one.
+func (r *XMLReader) Read() (*Record, error) {
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+		if start.Name.Space != Namespace {
+			return nil, errors.New("marc: record isn't in the MARCXML namespace")
+		}
+
+		var in xmlRecord
+		err = r.decoder.DecodeElement(&in, &start)
+		if err != nil {
+			return nil, err
+		}
+
+		record := Record{Leader: in.Leader}
+		for _, field := range in.ControlFields {
+			record.addControlField(field.Tag, field.Value)
+		}
+		for _, field := range in.DataFields {
+			dataField := DataField{Tag: field.Tag, Ind1: xmlIndicator(field.Ind1), Ind2: xmlIndicator(field.Ind2)}
+			for _, subfield := range field.Subfields {
+				if subfield.Code == "" {
+					continue
+				}
+				dataField.Subfields = append(dataField.Subfields, &Subfield{Code: subfield.Code[0], Value: subfield.Value})
+			}
+			record.DataFields = append(record.DataFields, &dataField)
+		}
+
+		return &record, nil
+	}
+}
+
+func xmlIndicator(ind string) byte {
+	if ind == "" {
+		return ' '
+	}
+
+	return ind[0]
+}
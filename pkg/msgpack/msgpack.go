@@ -0,0 +1,101 @@
+package msgpack
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Type is the media type of MessagePack.
+const Type = "application/msgpack"
+
+// Encoder appends MessagePack values to a buffer, each in its shortest form. Arrays and maps are
+// written as their length followed by their elements, keys and values alternating in maps.
+type Encoder struct {
+	buf []byte
+}
+
+// Bytes returns the values encoded so far.
+func (e *Encoder) Bytes() []byte {
+	return e.buf
+}
+
+func (e *Encoder) EncodeNil() {
+	e.buf = append(e.buf, 0xc0)
+}
+
+func (e *Encoder) EncodeBool(v bool) {
+	if v {
+		e.buf = append(e.buf, 0xc3)
+	} else {
+		e.buf = append(e.buf, 0xc2)
+	}
+}
+
+func (e *Encoder) EncodeInt(v int64) {
+	switch {
+	case v >= 0 && v <= math.MaxInt8:
+		e.buf = append(e.buf, byte(v))
+	case v >= -32 && v < 0:
+		e.buf = append(e.buf, byte(v))
+	case v >= 0 && v <= math.MaxUint8:
+		e.buf = append(e.buf, 0xcc, byte(v))
+	case v >= 0 && v <= math.MaxUint16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, 0xcd), uint16(v))
+	case v >= 0 && v <= math.MaxUint32:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, 0xce), uint32(v))
+	case v >= 0:
+		e.buf = binary.BigEndian.AppendUint64(append(e.buf, 0xcf), uint64(v))
+	case v >= math.MinInt8:
+		e.buf = append(e.buf, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, 0xd1), uint16(v))
+	case v >= math.MinInt32:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, 0xd2), uint32(v))
+	default:
+		e.buf = binary.BigEndian.AppendUint64(append(e.buf, 0xd3), uint64(v))
+	}
+}
+
+func (e *Encoder) EncodeUint(v uint64) {
+	if v <= math.MaxInt64 {
+		e.EncodeInt(int64(v))
+		return
+	}
+
+	e.buf = binary.BigEndian.AppendUint64(append(e.buf, 0xcf), v)
+}
+
+func (e *Encoder) EncodeFloat(v float64) {
+	e.buf = binary.BigEndian.AppendUint64(append(e.buf, 0xcb), math.Float64bits(v))
+}
+
+func (e *Encoder) EncodeString(v string) {
+	e.encodeLength(len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+	e.buf = append(e.buf, v...)
+}
+
+// EncodeArrayLen starts an array of n elements.
+func (e *Encoder) EncodeArrayLen(n int) {
+	e.encodeLength(n, 0x90, 16, 0, 0xdc, 0xdd)
+}
+
+// EncodeMapLen starts a map of n pairs.
+func (e *Encoder) EncodeMapLen(n int) {
+	e.encodeLength(n, 0x80, 16, 0, 0xde, 0xdf)
+}
+
+// encodeLength writes the header of a string, an array or a map. Lengths below fixedLimit are
+// packed into the fixed prefix, longer ones follow a prefix of 8, 16 or 32 bits. Arrays and maps
+// have no 8 bit form, their prefix8 is zero.
+func (e *Encoder) encodeLength(n int, fixed byte, fixedLimit int, prefix8 byte, prefix16 byte, prefix32 byte) {
+	switch {
+	case n < fixedLimit:
+		e.buf = append(e.buf, fixed|byte(n))
+	case prefix8 != 0 && n <= math.MaxUint8:
+		e.buf = append(e.buf, prefix8, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, prefix16), uint16(n))
+	default:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, prefix32), uint32(n))
+	}
+}
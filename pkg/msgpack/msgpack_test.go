@@ -0,0 +1,142 @@
+package msgpack
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"strings"
+	"testing"
+)
+
+// encoded returns the bytes the encode function writes.
+func encoded(encode func(*Encoder)) []byte {
+	var encoder Encoder
+	encode(&encoder)
+	return encoder.Bytes()
+}
+
+func TestEncodeInt(t *testing.T) {
+	tests := []struct {
+		value int64
+		want  []byte
+	}{
+		{value: 0, want: []byte{0x00}},
+		{value: 127, want: []byte{0x7f}},
+		{value: 128, want: []byte{0xcc, 0x80}},
+		{value: 255, want: []byte{0xcc, 0xff}},
+		{value: 256, want: []byte{0xcd, 0x01, 0x00}},
+		{value: math.MaxUint16, want: []byte{0xcd, 0xff, 0xff}},
+		{value: math.MaxUint16 + 1, want: []byte{0xce, 0x00, 0x01, 0x00, 0x00}},
+		{value: math.MaxUint32, want: []byte{0xce, 0xff, 0xff, 0xff, 0xff}},
+		{value: math.MaxUint32 + 1, want: []byte{0xcf, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00}},
+		{value: math.MaxInt64, want: []byte{0xcf, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{value: -1, want: []byte{0xff}},
+		{value: -32, want: []byte{0xe0}},
+		{value: -33, want: []byte{0xd0, 0xdf}},
+		{value: math.MinInt8, want: []byte{0xd0, 0x80}},
+		{value: math.MinInt8 - 1, want: []byte{0xd1, 0xff, 0x7f}},
+		{value: math.MinInt16, want: []byte{0xd1, 0x80, 0x00}},
+		{value: math.MinInt16 - 1, want: []byte{0xd2, 0xff, 0xff, 0x7f, 0xff}},
+		{value: math.MinInt32, want: []byte{0xd2, 0x80, 0x00, 0x00, 0x00}},
+		{value: math.MinInt32 - 1, want: []byte{0xd3, 0xff, 0xff, 0xff, 0xff, 0x7f, 0xff, 0xff, 0xff}},
+		{value: math.MinInt64, want: []byte{0xd3, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+	}
+
+	for _, test := range tests {
+		var encoder Encoder
+		encoder.EncodeInt(test.value)
+		assert.Equal(t, test.want, encoder.Bytes(), "int %d", test.value)
+	}
+}
+
+func TestEncodeUint(t *testing.T) {
+	var encoder Encoder
+	encoder.EncodeUint(200)
+	encoder.EncodeUint(math.MaxUint64)
+	assert.Equal(t, []byte{0xcc, 0xc8, 0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, encoder.Bytes())
+}
+
+func TestEncodeString(t *testing.T) {
+	tests := []struct {
+		name   string
+		length int
+		header []byte
+	}{
+		{name: "empty fixstr", length: 0, header: []byte{0xa0}},
+		{name: "longest fixstr", length: 31, header: []byte{0xbf}},
+		{name: "shortest str8", length: 32, header: []byte{0xd9, 0x20}},
+		{name: "longest str8", length: math.MaxUint8, header: []byte{0xd9, 0xff}},
+		{name: "shortest str16", length: math.MaxUint8 + 1, header: []byte{0xda, 0x01, 0x00}},
+		{name: "longest str16", length: math.MaxUint16, header: []byte{0xda, 0xff, 0xff}},
+		{name: "str32", length: math.MaxUint16 + 1, header: []byte{0xdb, 0x00, 0x01, 0x00, 0x00}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value := strings.Repeat("a", test.length)
+			data := encoded(func(e *Encoder) { e.EncodeString(value) })
+			assert.Equal(t, test.header, data[:len(test.header)])
+			assert.Equal(t, value, string(data[len(test.header):]))
+		})
+	}
+
+	t.Run("length in bytes", func(t *testing.T) {
+		assert.Equal(t, []byte{0xa2, 0xc3, 0xa9}, encoded(func(e *Encoder) { e.EncodeString("é") }))
+	})
+}
+
+func TestEncodeArrayLen(t *testing.T) {
+	tests := []struct {
+		length int
+		want   []byte
+	}{
+		{length: 0, want: []byte{0x90}},
+		{length: 15, want: []byte{0x9f}},
+		{length: 16, want: []byte{0xdc, 0x00, 0x10}},
+		{length: math.MaxUint8 + 1, want: []byte{0xdc, 0x01, 0x00}},
+		{length: math.MaxUint16, want: []byte{0xdc, 0xff, 0xff}},
+		{length: math.MaxUint16 + 1, want: []byte{0xdd, 0x00, 0x01, 0x00, 0x00}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, encoded(func(e *Encoder) { e.EncodeArrayLen(test.length) }), "array of %d", test.length)
+	}
+}
+
+func TestEncodeMapLen(t *testing.T) {
+	tests := []struct {
+		length int
+		want   []byte
+	}{
+		{length: 0, want: []byte{0x80}},
+		{length: 15, want: []byte{0x8f}},
+		{length: 16, want: []byte{0xde, 0x00, 0x10}},
+		{length: math.MaxUint16, want: []byte{0xde, 0xff, 0xff}},
+		{length: math.MaxUint16 + 1, want: []byte{0xdf, 0x00, 0x01, 0x00, 0x00}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, encoded(func(e *Encoder) { e.EncodeMapLen(test.length) }), "map of %d", test.length)
+	}
+}
+
+func TestEncode_Values(t *testing.T) {
+	var encoder Encoder
+	encoder.EncodeMapLen(2)
+	encoder.EncodeString("id")
+	encoder.EncodeInt(5)
+	encoder.EncodeString("tags")
+	encoder.EncodeArrayLen(3)
+	encoder.EncodeNil()
+	encoder.EncodeBool(true)
+	encoder.EncodeFloat(1.5)
+	encoder.EncodeBool(false)
+
+	want := bytes.Join([][]byte{
+		{0x82, 0xa2, 'i', 'd', 0x05},
+		{0xa4, 't', 'a', 'g', 's', 0x93, 0xc0, 0xc3},
+		{0xcb, 0x3f, 0xf8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		{0xc2},
+	}, nil)
+	assert.Equal(t, want, encoder.Bytes())
+}
@@ -3,45 +3,213 @@ package types
 import (
 	"github.com/tredoc/go-crud-api/internal/validator"
 	"regexp"
+	"strings"
+	"time"
+)
+
+const MaxAuthorAliases = 20
+
+var (
+	nationalityRX = regexp.MustCompile(`^[A-Z]{2}$`)
+	isniRX        = regexp.MustCompile(`^[0-9]{15}[0-9X]$`)
+	viafRX        = regexp.MustCompile(`^[0-9]{1,22}$`)
+	wikidataRX    = regexp.MustCompile(`^Q[1-9][0-9]*$`)
 )
 
 type Author struct {
-	ID         int64  `json:"id,omitempty"`
-	FirstName  string `json:"first_name"`
-	MiddleName string `json:"middle_name"`
-	LastName   string `json:"last_name"`
+	ID          int64       `json:"id,omitempty"`
+	FirstName   string      `json:"first_name"`
+	MiddleName  string      `json:"middle_name,omitempty"`
+	LastName    string      `json:"last_name"`
+	BirthDate   *CustomDate `json:"birth_date,omitempty"`
+	DeathDate   *CustomDate `json:"death_date,omitempty"`
+	Nationality string      `json:"nationality,omitempty"`
+	Biography   string      `json:"biography,omitempty"`
+	Aliases     []string    `json:"aliases,omitempty"`
+	ISNI        string      `json:"isni,omitempty"`
+	VIAF        string      `json:"viaf,omitempty"`
+	Wikidata    string      `json:"wikidata,omitempty"`
+	Version     int64       `json:"version,omitempty"`
+}
+
+// AuthorPage is a page of the authors, Total counts all of them.
+type AuthorPage struct {
+	Authors []*Author
+	Total   int
 }
 
 type UpdateAuthor struct {
-	FirstName  *string `json:"first_name"`
-	MiddleName *string `json:"middle_name"`
-	LastName   *string `json:"last_name"`
+	FirstName   *string     `json:"first_name"`
+	MiddleName  *string     `json:"middle_name"`
+	LastName    *string     `json:"last_name"`
+	BirthDate   *CustomDate `json:"birth_date"`
+	DeathDate   *CustomDate `json:"death_date"`
+	Nationality *string     `json:"nationality"`
+	Biography   *string     `json:"biography"`
+	Aliases     []string    `json:"aliases"`
+	ISNI        *string     `json:"isni"`
+	VIAF        *string     `json:"viaf"`
+	Wikidata    *string     `json:"wikidata"`
 }
 
 func ValidateAuthor(v *validator.Validator, author *Author) {
 	v.Check(author.FirstName != "", "first_name", validator.CantBeEmpty)
-	v.Check(v.Matches(author.FirstName, regexp.MustCompile(`^[a-zA-Z]+$`)), "first_name", validator.OnlyLatinLetters)
+	ValidateName(v, "first_name", author.FirstName)
 
 	if author.MiddleName != "" {
-		v.Check(v.Matches(author.MiddleName, regexp.MustCompile(`^[a-zA-Z]+$`)), "middle_name", validator.OnlyLatinLetters)
+		ValidateName(v, "middle_name", author.MiddleName)
 	}
 
 	v.Check(author.LastName != "", "last_name", validator.CantBeEmpty)
-	v.Check(v.Matches(author.LastName, regexp.MustCompile(`^[a-zA-Z]+$`)), "last_name", validator.OnlyLatinLetters)
+	ValidateName(v, "last_name", author.LastName)
+
+	validateAuthorProfile(v, author)
 }
 
 func ValidateUpdateAuthor(v *validator.Validator, author *UpdateAuthor) {
 	if author.FirstName != nil {
 		v.Check(*author.FirstName != "", "first_name", validator.CantBeEmpty)
-		v.Check(v.Matches(*author.FirstName, regexp.MustCompile(`^[a-zA-Z]+$`)), "first_name", validator.OnlyLatinLetters)
+		ValidateName(v, "first_name", *author.FirstName)
 	}
 
 	if author.MiddleName != nil && *author.MiddleName != "" {
-		v.Check(v.Matches(*author.MiddleName, regexp.MustCompile(`^[a-zA-Z]+$`)), "middle_name", validator.OnlyLatinLetters)
+		ValidateName(v, "middle_name", *author.MiddleName)
 	}
 
 	if author.LastName != nil {
 		v.Check(*author.LastName != "", "last_name", validator.CantBeEmpty)
-		v.Check(v.Matches(*author.LastName, regexp.MustCompile(`^[a-zA-Z]+$`)), "last_name", validator.OnlyLatinLetters)
+		ValidateName(v, "last_name", *author.LastName)
+	}
+
+	profile := Author{BirthDate: author.BirthDate, DeathDate: author.DeathDate, Aliases: author.Aliases}
+	if author.Nationality != nil {
+		profile.Nationality = *author.Nationality
+	}
+
+	if author.Biography != nil {
+		profile.Biography = *author.Biography
+	}
+
+	if author.ISNI != nil {
+		profile.ISNI = *author.ISNI
+	}
+
+	if author.VIAF != nil {
+		profile.VIAF = *author.VIAF
+	}
+
+	if author.Wikidata != nil {
+		profile.Wikidata = *author.Wikidata
+	}
+
+	validateAuthorProfile(v, &profile)
+}
+
+// ValidateAuthorDates checks birth and death dates against each other, it is called again on
+// update once the stored dates are merged with the changed ones.
+func ValidateAuthorDates(v *validator.Validator, author *Author) {
+	if author.BirthDate != nil {
+		v.Check(author.BirthDate.Before(time.Now()), "birth_date", validator.CantBeInFuture)
+	}
+
+	if author.DeathDate != nil {
+		v.Check(author.DeathDate.Before(time.Now()), "death_date", validator.CantBeInFuture)
 	}
+
+	if author.BirthDate != nil && author.DeathDate != nil {
+		v.Check(!author.DeathDate.Before(author.BirthDate.Time), "death_date", "can't be before birth_date")
+	}
+}
+
+func validateAuthorProfile(v *validator.Validator, author *Author) {
+	ValidateAuthorDates(v, author)
+
+	if author.Nationality != "" {
+		v.Check(v.Matches(author.Nationality, nationalityRX), "nationality", "must be an ISO 3166-1 alpha-2 country code")
+	}
+
+	v.Check(len(author.Biography) <= 5000, "biography", validator.CantBeLongerThan5k)
+
+	v.Check(len(author.Aliases) <= MaxAuthorAliases, "aliases", "can't contain more than 20 aliases")
+	for _, alias := range author.Aliases {
+		v.Check(alias != "", "aliases", validator.CantBeEmpty)
+		ValidateName(v, "aliases", alias)
+	}
+
+	if author.ISNI != "" {
+		isni := normalizeISNI(author.ISNI)
+		v.Check(v.Matches(isni, isniRX) && isISNIChecksumValid(isni), "isni", "must be a valid 16 character ISNI")
+	}
+
+	if author.VIAF != "" {
+		v.Check(v.Matches(author.VIAF, viafRX), "viaf", "must be a numeric VIAF ID")
+	}
+
+	if author.Wikidata != "" {
+		v.Check(v.Matches(author.Wikidata, wikidataRX), "wikidata", "must be a Wikidata item ID like Q42")
+	}
+}
+
+// Normalize converts all names of the author to NFC, drops duplicate aliases and brings
+// the ISNI to its compact form.
+func (a *Author) Normalize() {
+	a.FirstName = NormalizeName(a.FirstName)
+	a.MiddleName = NormalizeName(a.MiddleName)
+	a.LastName = NormalizeName(a.LastName)
+	a.ISNI = normalizeISNI(a.ISNI)
+
+	if a.Aliases == nil {
+		return
+	}
+
+	seen := make(map[string]bool, len(a.Aliases))
+	aliases := make([]string, 0, len(a.Aliases))
+	for _, alias := range a.Aliases {
+		alias = NormalizeName(alias)
+		if seen[FoldName(alias)] {
+			continue
+		}
+		seen[FoldName(alias)] = true
+		aliases = append(aliases, alias)
+	}
+	a.Aliases = aliases
+}
+
+// FullName joins the non-empty parts of the name with spaces.
+func (a *Author) FullName() string {
+	return strings.Join(strings.Fields(a.FirstName+" "+a.MiddleName+" "+a.LastName), " ")
+}
+
+// GivenNames joins the first and middle names with a space.
+func (a *Author) GivenNames() string {
+	return strings.TrimSpace(a.FirstName + " " + a.MiddleName)
+}
+
+// InvertedName returns the name as "Last, First Middle", the form catalogues and bibliographies
+// sort and split names by.
+func (a *Author) InvertedName() string {
+	if given := a.GivenNames(); given != "" {
+		return a.LastName + ", " + given
+	}
+
+	return a.LastName
+}
+
+func normalizeISNI(isni string) string {
+	return strings.ToUpper(strings.ReplaceAll(isni, " ", ""))
+}
+
+// isISNIChecksumValid verifies the ISO 7064 MOD 11-2 check character of the ISNI.
+func isISNIChecksumValid(isni string) bool {
+	total := 0
+	for _, r := range isni[:15] {
+		total = (total + int(r-'0')) * 2
+	}
+
+	check := (12 - total%11) % 11
+	if check == 10 {
+		return isni[15] == 'X'
+	}
+
+	return int(isni[15]-'0') == check
 }
@@ -0,0 +1,161 @@
+package types
+
+import (
+	"fmt"
+	"github.com/tredoc/go-crud-api/internal/validator"
+)
+
+const MaxBatchOperations = 500
+
+// BatchMode decides what a failing operation does to the rest of a batch.
+type BatchMode string
+
+const (
+	// AtomicBatch applies all operations or, when one of them fails, none.
+	AtomicBatch BatchMode = "atomic"
+	// BestEffortBatch applies every operation that succeeds on its own.
+	BestEffortBatch BatchMode = "best_effort"
+)
+
+type BatchOp string
+
+const (
+	BatchCreate BatchOp = "create"
+	BatchUpdate BatchOp = "update"
+	BatchDelete BatchOp = "delete"
+)
+
+// BookOperation creates a book from Book, applies Changes to the book with ID or deletes it. A
+// non-zero Version has to match the one of the book. Once applied, ID and Version are the ones of
+// the stored book.
+type BookOperation struct {
+	Op      BatchOp     `json:"op"`
+	ID      int64       `json:"id,omitempty"`
+	Version int64       `json:"version,omitempty"`
+	Book    *Book       `json:"book,omitempty"`
+	Changes *UpdateBook `json:"changes,omitempty"`
+}
+
+type BookBatch struct {
+	Mode       BatchMode        `json:"mode"`
+	Operations []*BookOperation `json:"operations"`
+}
+
+// AuthorOperation creates an author from Author, applies Changes to the author with ID or deletes
+// it, like BookOperation does for books. BookIDs lists the books a deletion took the author from.
+type AuthorOperation struct {
+	Op      BatchOp       `json:"op"`
+	ID      int64         `json:"id,omitempty"`
+	Version int64         `json:"version,omitempty"`
+	Author  *Author       `json:"author,omitempty"`
+	Changes *UpdateAuthor `json:"changes,omitempty"`
+	BookIDs []int64       `json:"-"`
+}
+
+type AuthorBatch struct {
+	Mode       BatchMode          `json:"mode"`
+	Operations []*AuthorOperation `json:"operations"`
+}
+
+// GenreOperation creates a genre, updates the genre with ID or deletes it. Genre holds the new
+// genre or its changes, an update without parent_id keeps the parent.
+type GenreOperation struct {
+	Op      BatchOp `json:"op"`
+	ID      int64   `json:"id,omitempty"`
+	Version int64   `json:"version,omitempty"`
+	Genre   *Genre  `json:"genre,omitempty"`
+	BookIDs []int64 `json:"-"`
+}
+
+type GenreBatch struct {
+	Mode       BatchMode         `json:"mode"`
+	Operations []*GenreOperation `json:"operations"`
+}
+
+// BatchResult reports the outcome of one operation of a batch with the status a request of its own
+// would get. Error holds a message or, for invalid operations, the errors by field.
+type BatchResult struct {
+	Index   int   `json:"index"`
+	Status  int   `json:"status"`
+	ID      int64 `json:"id,omitempty"`
+	Version int64 `json:"version,omitempty"`
+	Error   any   `json:"error,omitempty"`
+}
+
+func ValidateBatch(v *validator.Validator, mode BatchMode, operations int) {
+	v.Check(mode == AtomicBatch || mode == BestEffortBatch, "mode", "must be one of atomic, best_effort")
+	v.Check(operations > 0, "operations", validator.CantBeEmpty)
+	v.Check(operations <= MaxBatchOperations, "operations", fmt.Sprintf("can't contain more than %d operations", MaxBatchOperations))
+}
+
+func ValidateBookOperation(v *validator.Validator, op *BookOperation) {
+	if op == nil {
+		v.AddError("operation", validator.CantBeEmpty)
+		return
+	}
+
+	switch op.Op {
+	case BatchCreate:
+		v.Check(op.Book != nil, "book", validator.CantBeEmpty)
+		if op.Book != nil {
+			ValidateBook(v, op.Book)
+		}
+	case BatchUpdate:
+		v.Check(op.ID > 0, "id", validator.CantBeLessThanOne)
+		v.Check(op.Changes != nil, "changes", validator.CantBeEmpty)
+		if op.Changes != nil {
+			ValidateUpdateBook(v, op.Changes)
+		}
+	case BatchDelete:
+		v.Check(op.ID > 0, "id", validator.CantBeLessThanOne)
+	default:
+		v.AddError("op", "must be one of create, update, delete")
+	}
+}
+
+func ValidateAuthorOperation(v *validator.Validator, op *AuthorOperation) {
+	if op == nil {
+		v.AddError("operation", validator.CantBeEmpty)
+		return
+	}
+
+	switch op.Op {
+	case BatchCreate:
+		v.Check(op.Author != nil, "author", validator.CantBeEmpty)
+		if op.Author != nil {
+			ValidateAuthor(v, op.Author)
+		}
+	case BatchUpdate:
+		v.Check(op.ID > 0, "id", validator.CantBeLessThanOne)
+		v.Check(op.Changes != nil, "changes", validator.CantBeEmpty)
+		if op.Changes != nil {
+			ValidateUpdateAuthor(v, op.Changes)
+		}
+	case BatchDelete:
+		v.Check(op.ID > 0, "id", validator.CantBeLessThanOne)
+	default:
+		v.AddError("op", "must be one of create, update, delete")
+	}
+}
+
+func ValidateGenreOperation(v *validator.Validator, op *GenreOperation) {
+	if op == nil {
+		v.AddError("operation", validator.CantBeEmpty)
+		return
+	}
+
+	switch op.Op {
+	case BatchCreate, BatchUpdate:
+		if op.Op == BatchUpdate {
+			v.Check(op.ID > 0, "id", validator.CantBeLessThanOne)
+		}
+		v.Check(op.Genre != nil, "genre", validator.CantBeEmpty)
+		if op.Genre != nil {
+			ValidateGenre(v, op.Genre)
+		}
+	case BatchDelete:
+		v.Check(op.ID > 0, "id", validator.CantBeLessThanOne)
+	default:
+		v.AddError("op", "must be one of create, update, delete")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/tredoc/go-crud-api/internal/validator"
 	"strconv"
 	"time"
+	"unicode/utf8"
 )
 
 const layout = time.DateOnly
@@ -14,6 +15,10 @@ type CustomDate struct {
 }
 
 func (cd *CustomDate) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+
 	s, err := strconv.Unquote(string(b))
 	if err != nil {
 		return err
@@ -33,14 +38,18 @@ func (cd *CustomDate) MarshalJSON() ([]byte, error) {
 }
 
 type Book struct {
-	ID          int64      `json:"id,omitempty"`
-	Title       string     `json:"title"`
-	PublishDate CustomDate `json:"publish_date"`
-	CreatedAt   time.Time  `json:"created_at,omitempty"`
-	ISBN        string     `json:"isbn"`
-	Pages       uint16     `json:"pages"`
-	Authors     []int64    `json:"authors"`
-	Genres      []int64    `json:"genres"`
+	ID            int64      `json:"id,omitempty"`
+	Title         string     `json:"title"`
+	PublishDate   CustomDate `json:"publish_date"`
+	CreatedAt     time.Time  `json:"created_at,omitempty"`
+	ISBN          string     `json:"isbn"`
+	Pages         uint16     `json:"pages"`
+	AverageRating float64    `json:"average_rating"`
+	RatingsCount  int64      `json:"ratings_count"`
+	Authors       []int64    `json:"authors"`
+	Genres        []int64    `json:"genres"`
+	Tags          []string   `json:"tags"`
+	Version       int64      `json:"version,omitempty"`
 }
 
 func ValidateBook(v *validator.Validator, book *Book) {
@@ -54,14 +63,18 @@ func ValidateBook(v *validator.Validator, book *Book) {
 }
 
 type BookWithDetails struct {
-	ID          int64      `json:"id,omitempty"`
-	Title       string     `json:"title"`
-	PublishDate CustomDate `json:"publish_date"`
-	CreatedAt   time.Time  `json:"created_at"`
-	ISBN        string     `json:"isbn"`
-	Pages       uint16     `json:"pages"`
-	Authors     []*Author  `json:"authors"`
-	Genres      []*Genre   `json:"genres"`
+	ID            int64      `json:"id,omitempty"`
+	Title         string     `json:"title"`
+	PublishDate   CustomDate `json:"publish_date"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ISBN          string     `json:"isbn"`
+	Pages         uint16     `json:"pages"`
+	AverageRating float64    `json:"average_rating"`
+	RatingsCount  int64      `json:"ratings_count"`
+	Authors       []*Author  `json:"authors"`
+	Genres        []*Genre   `json:"genres"`
+	Tags          []string   `json:"tags"`
+	Version       int64      `json:"version,omitempty"`
 }
 
 type UpdateBook struct {
@@ -99,3 +112,49 @@ func ValidateUpdateBook(v *validator.Validator, book *UpdateBook) {
 		v.Check(len(book.Genres) > 0, "genres", validator.CantBeEmpty)
 	}
 }
+
+type TagMatch string
+
+const (
+	MatchAnyTag  TagMatch = "any"
+	MatchAllTags TagMatch = "all"
+)
+
+// BookFilter narrows down the list of books, the zero value matches every book. Query matches the
+// books with the text in their title or in the name of one of their authors.
+type BookFilter struct {
+	Tags             []string
+	TagMatch         TagMatch
+	AuthorID         int64
+	GenreID          int64
+	IncludeSubgenres bool
+	Query            string
+}
+
+func (f *BookFilter) IsEmpty() bool {
+	return len(f.Tags) == 0 && f.AuthorID == 0 && f.GenreID == 0 && f.Query == ""
+}
+
+func ValidateBookFilter(v *validator.Validator, filter *BookFilter) {
+	v.Check(filter.TagMatch == MatchAnyTag || filter.TagMatch == MatchAllTags, "match", "must be one of any, all")
+	v.Check(len(filter.Tags) <= MaxTagsPerRequest, "tags", "can't contain more than 20 tags")
+	for _, tag := range filter.Tags {
+		ValidateTag(v, "tags", tag)
+	}
+	v.Check(filter.GenreID >= 0, "genre", "can't be negative")
+	v.Check(utf8.RuneCountInString(filter.Query) <= 100, "q", validator.CantBeLongerThan100)
+}
+
+// BookOrder sorts a page of books.
+type BookOrder string
+
+const (
+	OrderByTitle  BookOrder = "title"
+	OrderByNewest BookOrder = "newest"
+)
+
+// BookPage is a page of the books matching a filter, Total counts all of them.
+type BookPage struct {
+	Books []*ExportedBook
+	Total int
+}
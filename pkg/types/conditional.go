@@ -0,0 +1,17 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+const LastModifiedContextKey = contextKey("last_modified")
+
+// SetLastModified reports when the data a read returns last changed to the handler serving the
+// read. Of several reports the latest wins, reads nobody asked about are ignored.
+func SetLastModified(ctx context.Context, modifiedAt time.Time) {
+	lastModified, ok := ctx.Value(LastModifiedContextKey).(*time.Time)
+	if ok && modifiedAt.After(*lastModified) {
+		*lastModified = modifiedAt
+	}
+}
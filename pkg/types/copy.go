@@ -0,0 +1,62 @@
+package types
+
+import (
+	"github.com/tredoc/go-crud-api/internal/validator"
+	"time"
+)
+
+type Condition string
+
+const (
+	NewCondition     Condition = "new"
+	GoodCondition    Condition = "good"
+	FairCondition    Condition = "fair"
+	PoorCondition    Condition = "poor"
+	DamagedCondition Condition = "damaged"
+)
+
+func (c Condition) IsValid() bool {
+	switch c {
+	case NewCondition, GoodCondition, FairCondition, PoorCondition, DamagedCondition:
+		return true
+	}
+	return false
+}
+
+type Copy struct {
+	ID        int64     `json:"id,omitempty"`
+	BookID    int64     `json:"book_id"`
+	Barcode   string    `json:"barcode"`
+	Location  string    `json:"location"`
+	Condition Condition `json:"condition"`
+	Available bool      `json:"available"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+type UpdateCopy struct {
+	Barcode   *string    `json:"barcode"`
+	Location  *string    `json:"location"`
+	Condition *Condition `json:"condition"`
+}
+
+func ValidateCopy(v *validator.Validator, bookCopy *Copy) {
+	v.Check(bookCopy.Barcode != "", "barcode", validator.CantBeEmpty)
+	v.Check(len(bookCopy.Barcode) <= 100, "barcode", validator.CantBeLongerThan100)
+	v.Check(len(bookCopy.Location) <= 100, "location", validator.CantBeLongerThan100)
+	v.Check(bookCopy.Condition.IsValid(), "condition", "must be one of new, good, fair, poor, damaged")
+}
+
+func ValidateUpdateCopy(v *validator.Validator, bookCopy *UpdateCopy) {
+	if bookCopy.Barcode != nil {
+		v.Check(*bookCopy.Barcode != "", "barcode", validator.CantBeEmpty)
+		v.Check(len(*bookCopy.Barcode) <= 100, "barcode", validator.CantBeLongerThan100)
+	}
+
+	if bookCopy.Location != nil {
+		v.Check(len(*bookCopy.Location) <= 100, "location", validator.CantBeLongerThan100)
+	}
+
+	if bookCopy.Condition != nil {
+		v.Check(bookCopy.Condition.IsValid(), "condition", "must be one of new, good, fair, poor, damaged")
+	}
+}
@@ -0,0 +1,30 @@
+package types
+
+import "time"
+
+type ExportFormat string
+
+const (
+	CSVExport     ExportFormat = "csv"
+	NDJSONExport  ExportFormat = "ndjson"
+	JSONExport    ExportFormat = "json"
+	MARCExport    ExportFormat = "marc"
+	MARCXMLExport ExportFormat = "marcxml"
+)
+
+// ExportedBook is a book of the catalogue export with its authors and genres by name. Credits holds
+// the authors with their names split for the formats that need them, like MARC.
+type ExportedBook struct {
+	ID            int64      `json:"id"`
+	Title         string     `json:"title"`
+	PublishDate   CustomDate `json:"publish_date"`
+	ISBN          string     `json:"isbn"`
+	Pages         uint16     `json:"pages"`
+	AverageRating float64    `json:"average_rating"`
+	RatingsCount  int64      `json:"ratings_count"`
+	Authors       []string   `json:"authors"`
+	Genres        []string   `json:"genres"`
+	Tags          []string   `json:"tags"`
+	CreatedAt     time.Time  `json:"-"`
+	Credits       []*Author  `json:"-"`
+}
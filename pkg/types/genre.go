@@ -2,15 +2,59 @@ package types
 
 import (
 	"github.com/tredoc/go-crud-api/internal/validator"
-	"regexp"
+	"sort"
 )
 
 type Genre struct {
-	ID   int64  `json:"id,omitempty"`
-	Name string `json:"name"`
+	ID       int64  `json:"id,omitempty"`
+	Name     string `json:"name"`
+	ParentID *int64 `json:"parent_id,omitempty"`
+	Version  int64  `json:"version,omitempty"`
 }
 
 func ValidateGenre(v *validator.Validator, genre *Genre) {
 	v.Check(len(genre.Name) > 0, "name", validator.CantBeEmpty)
-	v.Check(v.Matches(genre.Name, regexp.MustCompile(`^[a-zA-Z]+$`)), "name", "should contain only latin letters")
+	ValidateName(v, "name", genre.Name)
+	v.Check(genre.ParentID == nil || *genre.ParentID >= 0, "parent_id", "can't be negative")
+}
+
+// GenreNode is a genre with its subgenres nested under it.
+type GenreNode struct {
+	ID       int64        `json:"id"`
+	Name     string       `json:"name"`
+	Children []*GenreNode `json:"children"`
+}
+
+// BuildGenreTree nests genres under their parents. Genres whose parent isn't in the list become
+// roots, siblings are sorted by name.
+func BuildGenreTree(genres []*Genre) []*GenreNode {
+	nodes := make(map[int64]*GenreNode, len(genres))
+	for _, genre := range genres {
+		nodes[genre.ID] = &GenreNode{ID: genre.ID, Name: genre.Name, Children: []*GenreNode{}}
+	}
+
+	roots := []*GenreNode{}
+	for _, genre := range genres {
+		node := nodes[genre.ID]
+		if genre.ParentID != nil {
+			if parent, ok := nodes[*genre.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	sortGenreNodes(roots)
+	return roots
+}
+
+func sortGenreNodes(nodes []*GenreNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	for _, node := range nodes {
+		sortGenreNodes(node.Children)
+	}
 }
@@ -0,0 +1,27 @@
+package types
+
+import "time"
+
+const HoldPickupWindow time.Duration = time.Hour * 24 * 3
+
+type HoldStatus string
+
+const (
+	WaitingHold   HoldStatus = "waiting"
+	ReadyHold     HoldStatus = "ready"
+	FulfilledHold HoldStatus = "fulfilled"
+	CancelledHold HoldStatus = "cancelled"
+	ExpiredHold   HoldStatus = "expired"
+)
+
+type Hold struct {
+	ID        int64      `json:"id,omitempty"`
+	BookID    int64      `json:"book_id"`
+	UserID    int64      `json:"user_id"`
+	Status    HoldStatus `json:"status"`
+	Position  int64      `json:"position"`
+	CopyID    *int64     `json:"copy_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadyAt   *time.Time `json:"ready_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
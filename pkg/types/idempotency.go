@@ -0,0 +1,12 @@
+package types
+
+import "net/http"
+
+// IdempotentResponse is the response stored for an Idempotency-Key together with the fingerprint
+// of the request that produced it. Status stays zero while that request is being handled.
+type IdempotentResponse struct {
+	Fingerprint string      `json:"fingerprint"`
+	Status      int         `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
@@ -0,0 +1,272 @@
+package types
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	CSVType    = "text/csv"
+	NDJSONType = "application/x-ndjson"
+
+	MaxImportRows = 10000
+)
+
+type ImportFormat string
+
+const (
+	CSVImport    ImportFormat = "csv"
+	NDJSONImport ImportFormat = "ndjson"
+)
+
+type ImportStatus string
+
+const (
+	ImportRunning   ImportStatus = "running"
+	ImportCompleted ImportStatus = "completed"
+	ImportFailed    ImportStatus = "failed"
+)
+
+// ImportJob tracks an upload of books processed in the background. A dry run checks every row and
+// counts the ones it would import as imported without storing anything.
+type ImportJob struct {
+	ID            int64             `json:"id"`
+	Format        ImportFormat      `json:"format"`
+	DryRun        bool              `json:"dry_run"`
+	Status        ImportStatus      `json:"status"`
+	TotalRows     int               `json:"total_rows"`
+	ProcessedRows int               `json:"processed_rows"`
+	ImportedRows  int               `json:"imported_rows"`
+	FailedRows    int               `json:"failed_rows"`
+	Failure       string            `json:"failure,omitempty"`
+	Errors        []*ImportRowError `json:"-"`
+	ActorID       *int64            `json:"actor_id,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	FinishedAt    *time.Time        `json:"finished_at,omitempty"`
+}
+
+// ImportRowError holds the errors by field of a row that wasn't imported. Row is the line of the
+// upload the row starts at.
+type ImportRowError struct {
+	Row    int               `json:"row"`
+	Errors map[string]string `json:"errors"`
+}
+
+// ImportRow is a book of an upload with its authors and genres given by name. Errors holds the
+// fields that couldn't be read.
+type ImportRow struct {
+	Line    int
+	Book    Book
+	Authors []string
+	Genres  []string
+	Errors  map[string]string
+}
+
+// ImportOutcome is what became of an upserted row: the book it created or replaced, or the errors by
+// field that kept it from being imported.
+type ImportOutcome struct {
+	ID      int64
+	Version int64
+	Created bool
+	Errors  map[string]string
+}
+
+type importRecord struct {
+	Title       string     `json:"title"`
+	PublishDate CustomDate `json:"publish_date"`
+	ISBN        string     `json:"isbn"`
+	Pages       uint16     `json:"pages"`
+	Authors     []string   `json:"authors"`
+	Genres      []string   `json:"genres"`
+}
+
+var importColumns = []string{"title", "publish_date", "isbn", "pages", "authors", "genres"}
+
+// ParseImport reads the rows of an upload. CSV needs a header naming the title, publish_date, isbn,
+// pages, authors and genres columns, authors and genres are separated by semicolons. NDJSON holds a
+// book object per line with authors and genres as arrays of names. A row that can't be read fails
+// on its own, an upload that can't be read at all returns an error.
+func ParseImport(r io.Reader, format ImportFormat) ([]*ImportRow, error) {
+	var rows []*ImportRow
+	var err error
+	switch format {
+	case CSVImport:
+		rows, err = parseCSVImport(r)
+	case NDJSONImport:
+		rows, err = parseNDJSONImport(r)
+	default:
+		return nil, fmt.Errorf("unknown import format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("upload holds no rows")
+	}
+
+	return rows, nil
+}
+
+func parseCSVImport(r io.Reader) ([]*ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("upload holds no rows")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't read csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range importColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("csv header misses the %s column", name)
+		}
+	}
+
+	var rows []*ImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			return nil, fmt.Errorf("can't read csv: %w", err)
+		}
+
+		if len(rows) == MaxImportRows {
+			return nil, fmt.Errorf("upload can't hold more than %d rows", MaxImportRows)
+		}
+
+		if parseErr != nil {
+			rows = append(rows, &ImportRow{Line: parseErr.StartLine, Errors: map[string]string{"row": "can't read csv: " + parseErr.Err.Error()}})
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := ImportRow{
+			Line:    line,
+			Book:    Book{Title: field("title"), ISBN: field("isbn")},
+			Authors: splitNames(field("authors")),
+			Genres:  splitNames(field("genres")),
+			Errors:  make(map[string]string),
+		}
+
+		if date := field("publish_date"); date != "" {
+			publishDate, err := time.Parse(layout, date)
+			if err != nil {
+				row.Errors["publish_date"] = "must be a date like " + layout
+			}
+			row.Book.PublishDate = CustomDate{Time: publishDate}
+		}
+
+		if pages := field("pages"); pages != "" {
+			count, err := strconv.ParseUint(pages, 10, 16)
+			if err != nil {
+				row.Errors["pages"] = "must be a whole number"
+			}
+			row.Book.Pages = uint16(count)
+		}
+
+		rows = append(rows, &row)
+	}
+
+	return rows, nil
+}
+
+func parseNDJSONImport(r io.Reader) ([]*ImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []*ImportRow
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		if len(rows) == MaxImportRows {
+			return nil, fmt.Errorf("upload can't hold more than %d rows", MaxImportRows)
+		}
+
+		row := ImportRow{Line: line, Errors: make(map[string]string)}
+		var record importRecord
+		err := json.Unmarshal([]byte(text), &record)
+		if err != nil {
+			row.Errors["row"] = "can't decode json"
+		}
+
+		row.Book = Book{Title: record.Title, PublishDate: record.PublishDate, ISBN: record.ISBN, Pages: record.Pages}
+		row.Authors = record.Authors
+		row.Genres = record.Genres
+		rows = append(rows, &row)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("can't read ndjson: %w", err)
+	}
+
+	return rows, nil
+}
+
+func splitNames(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ";") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// ParseAuthorName splits a full name into first, middle and last name. Both "First Middle Last"
+// and "Last, First Middle" are understood.
+func ParseAuthorName(name string) *Author {
+	var author Author
+	if last, rest, ok := strings.Cut(name, ","); ok {
+		author.LastName = strings.TrimSpace(last)
+		first, middle, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		author.FirstName = first
+		author.MiddleName = strings.TrimSpace(middle)
+		return &author
+	}
+
+	parts := strings.Fields(name)
+	switch len(parts) {
+	case 0:
+	case 1:
+		author.LastName = parts[0]
+	default:
+		author.FirstName = parts[0]
+		author.MiddleName = strings.Join(parts[1:len(parts)-1], " ")
+		author.LastName = parts[len(parts)-1]
+	}
+
+	return &author
+}
@@ -0,0 +1,59 @@
+package types
+
+import (
+	"github.com/tredoc/go-crud-api/internal/validator"
+	"time"
+)
+
+const (
+	LoanPeriod  time.Duration = time.Hour * 24 * 14
+	MaxRenewals               = 2
+)
+
+// LoanLimits is the number of copies a user of the role may hold at once.
+var LoanLimits = map[Role]int{
+	AdminRole: 20,
+	UserRole:  5,
+}
+
+type LoanStatus string
+
+const (
+	ActiveLoan   LoanStatus = "active"
+	OverdueLoan  LoanStatus = "overdue"
+	ReturnedLoan LoanStatus = "returned"
+)
+
+func (s LoanStatus) IsValid() bool {
+	switch s {
+	case ActiveLoan, OverdueLoan, ReturnedLoan:
+		return true
+	}
+	return false
+}
+
+type Loan struct {
+	ID           int64      `json:"id,omitempty"`
+	CopyID       int64      `json:"copy_id"`
+	BookID       int64      `json:"book_id"`
+	UserID       int64      `json:"user_id"`
+	Barcode      string     `json:"barcode"`
+	CheckedOutAt time.Time  `json:"checked_out_at"`
+	DueAt        time.Time  `json:"due_at"`
+	ReturnedAt   *time.Time `json:"returned_at"`
+	Renewals     int        `json:"renewals"`
+}
+
+func (l *Loan) IsOverdue(now time.Time) bool {
+	return l.ReturnedAt == nil && l.DueAt.Before(now)
+}
+
+type Checkout struct {
+	Barcode string `json:"barcode"`
+	UserID  int64  `json:"user_id,omitempty"`
+}
+
+func ValidateCheckout(v *validator.Validator, checkout *Checkout) {
+	v.Check(checkout.Barcode != "", "barcode", validator.CantBeEmpty)
+	v.Check(checkout.UserID >= 0, "user_id", validator.CantBeLessThanOne)
+}
@@ -0,0 +1,13 @@
+package types
+
+import "github.com/tredoc/go-crud-api/internal/validator"
+
+// Merge names the duplicate that is folded into the entity addressed by the request path.
+type Merge struct {
+	SourceID int64 `json:"source_id"`
+}
+
+func ValidateMerge(v *validator.Validator, merge *Merge, targetID int64) {
+	v.Check(merge.SourceID > 0, "source_id", validator.CantBeLessThanOne)
+	v.Check(merge.SourceID != targetID, "source_id", "can't be merged into itself")
+}
@@ -0,0 +1,39 @@
+package types
+
+import (
+	"github.com/tredoc/go-crud-api/internal/validator"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// nameRX accepts letters of any script with combining marks, and apostrophes, hyphens,
+// spaces and periods between them, e.g. "García Márquez", "Jean-Paul", "O'Brien" or "J.R.R.".
+var nameRX = regexp.MustCompile(`^\p{L}[\p{L}\p{M}'’. -]*$`)
+
+// NormalizeName trims the name, collapses runs of whitespace and converts it to NFC so that
+// visually identical names are stored identically.
+func NormalizeName(name string) string {
+	return norm.NFC.String(strings.Join(strings.Fields(name), " "))
+}
+
+// FoldName returns the accent and case insensitive form of the name used to detect duplicates,
+// "Gabriel García Márquez" and "gabriel garcia marquez" fold to the same key.
+func FoldName(name string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, NormalizeName(name))
+	if err != nil {
+		folded = NormalizeName(name)
+	}
+
+	return strings.ToLower(strings.ReplaceAll(folded, "’", "'"))
+}
+
+func ValidateName(v *validator.Validator, key string, name string) {
+	v.Check(utf8.RuneCountInString(name) <= 100, key, validator.CantBeLongerThan100)
+	v.Check(v.Matches(name, nameRX), key, validator.OnlyLettersAndPunctuation)
+}
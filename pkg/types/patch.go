@@ -0,0 +1,425 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+// PatchOperation is a single operation of a JSON patch (RFC 6902).
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Patch is either a JSON merge patch (RFC 7396) or a list of JSON patch operations.
+type Patch struct {
+	Merge      json.RawMessage
+	Operations []PatchOperation
+}
+
+// DecodeMergePatch reads a JSON merge patch document.
+func DecodeMergePatch(r io.Reader) (*Patch, error) {
+	var merge json.RawMessage
+	err := json.NewDecoder(r).Decode(&merge)
+	if err != nil {
+		return nil, errors.New("can't decode merge patch")
+	}
+
+	return &Patch{Merge: merge}, nil
+}
+
+// DecodeJSONPatch reads a JSON patch document and checks that its operations are well formed.
+func DecodeJSONPatch(r io.Reader) (*Patch, error) {
+	var operations []PatchOperation
+	err := json.NewDecoder(r).Decode(&operations)
+	if err != nil {
+		return nil, errors.New("can't decode json patch")
+	}
+
+	for i, operation := range operations {
+		err := operation.check()
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+
+	return &Patch{Operations: operations}, nil
+}
+
+func (o *PatchOperation) check() error {
+	switch o.Op {
+	case "add", "replace", "test":
+		if o.Value == nil {
+			return fmt.Errorf("%s needs a value", o.Op)
+		}
+	case "move", "copy":
+		_, err := parsePointer(o.From)
+		if err != nil {
+			return err
+		}
+	case "remove":
+	default:
+		return fmt.Errorf("unknown op %q", o.Op)
+	}
+
+	_, err := parsePointer(o.Path)
+	return err
+}
+
+// Apply patches the JSON form of target and decodes the outcome into result. Fields unknown to
+// result fail the patch, so do operations on paths that don't exist and failed tests.
+func (p *Patch) Apply(target any, result any) error {
+	js, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+
+	var doc any
+	err = json.Unmarshal(js, &doc)
+	if err != nil {
+		return err
+	}
+
+	// Members left out of the target for being empty are patched as null members, so that
+	// operations can replace them.
+	if object, ok := doc.(map[string]any); ok {
+		for _, name := range jsonFieldNames(reflect.TypeOf(result)) {
+			if _, ok := object[name]; !ok {
+				object[name] = nil
+			}
+		}
+	}
+
+	if p.Merge != nil {
+		var merge any
+		err = json.Unmarshal(p.Merge, &merge)
+		if err != nil {
+			return err
+		}
+		doc = mergePatch(doc, merge)
+	}
+
+	for i, operation := range p.Operations {
+		doc, err = operation.apply(doc)
+		if err != nil {
+			return fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+
+	js, err = json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(js))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(result)
+}
+
+// jsonFieldNames returns the names of the members of the JSON form of a struct or a pointer to one.
+func jsonFieldNames(t reflect.Type) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// mergePatch merges patch into target as RFC 7396 describes: nulls remove members, objects are
+// merged recursively and any other value replaces the target.
+func mergePatch(target any, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any)
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+func (o *PatchOperation) apply(doc any) (any, error) {
+	path, _ := parsePointer(o.Path)
+	from, _ := parsePointer(o.From)
+
+	var value any
+	if o.Value != nil {
+		err := json.Unmarshal(o.Value, &value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch o.Op {
+	case "add":
+		return addValue(doc, path, value)
+	case "remove":
+		return removeValue(doc, path)
+	case "replace":
+		_, err := getValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		return replaceValue(doc, path, value)
+	case "move":
+		if o.Path != o.From && strings.HasPrefix(o.Path, o.From+"/") {
+			return nil, fmt.Errorf("can't move %s into itself", o.From)
+		}
+
+		moved, err := getValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		doc, err = removeValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, moved)
+	case "copy":
+		copied, err := getValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		js, err := json.Marshal(copied)
+		if err != nil {
+			return nil, err
+		}
+
+		var clone any
+		err = json.Unmarshal(js, &clone)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, clone)
+	case "test":
+		current, err := getValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !reflect.DeepEqual(current, value) {
+			return nil, fmt.Errorf("test of %s failed", o.Path)
+		}
+		return doc, nil
+	}
+
+	return nil, fmt.Errorf("unknown op %q", o.Op)
+}
+
+// parsePointer splits a JSON pointer (RFC 6901) into its unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("path %q has to start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// arrayIndex resolves a reference token against an array of the given length. The index right
+// past the end, also written as "-", is only valid for adding.
+func arrayIndex(token string, length int, adding bool) (int, error) {
+	if adding && token == "-" {
+		return length, nil
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || strconv.Itoa(index) != token {
+		return 0, fmt.Errorf("%q isn't an array index", token)
+	}
+
+	if index > length || (!adding && index == length) {
+		return 0, fmt.Errorf("index %d is out of range", index)
+	}
+
+	return index, nil
+}
+
+func getValue(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q doesn't exist", token)
+			}
+			doc = value
+		case []any:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[index]
+		default:
+			return nil, fmt.Errorf("member %q doesn't exist", token)
+		}
+	}
+
+	return doc, nil
+}
+
+// updateValue hands the container holding the last token of the path to change and puts the
+// container it returns in place of the old one.
+func updateValue(doc any, path []string, change func(container any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return change(doc, path[0])
+	}
+
+	switch node := doc.(type) {
+	case map[string]any:
+		child, ok := node[path[0]]
+		if !ok {
+			return nil, fmt.Errorf("member %q doesn't exist", path[0])
+		}
+
+		updated, err := updateValue(child, path[1:], change)
+		if err != nil {
+			return nil, err
+		}
+		node[path[0]] = updated
+		return node, nil
+	case []any:
+		index, err := arrayIndex(path[0], len(node), false)
+		if err != nil {
+			return nil, err
+		}
+
+		updated, err := updateValue(node[index], path[1:], change)
+		if err != nil {
+			return nil, err
+		}
+		node[index] = updated
+		return node, nil
+	}
+
+	return nil, fmt.Errorf("member %q doesn't exist", path[0])
+}
+
+func addValue(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return updateValue(doc, path, func(container any, token string) (any, error) {
+		switch node := container.(type) {
+		case map[string]any:
+			node[token] = value
+			return node, nil
+		case []any:
+			index, err := arrayIndex(token, len(node), true)
+			if err != nil {
+				return nil, err
+			}
+
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		}
+
+		return nil, fmt.Errorf("can't add %q to a value that isn't an object or an array", token)
+	})
+}
+
+func removeValue(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, errors.New("can't remove the whole document")
+	}
+
+	return updateValue(doc, path, func(container any, token string) (any, error) {
+		switch node := container.(type) {
+		case map[string]any:
+			if _, ok := node[token]; !ok {
+				return nil, fmt.Errorf("member %q doesn't exist", token)
+			}
+
+			delete(node, token)
+			return node, nil
+		case []any:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+
+			return append(node[:index], node[index+1:]...), nil
+		}
+
+		return nil, fmt.Errorf("member %q doesn't exist", token)
+	})
+}
+
+func replaceValue(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return updateValue(doc, path, func(container any, token string) (any, error) {
+		switch node := container.(type) {
+		case map[string]any:
+			node[token] = value
+			return node, nil
+		case []any:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+
+			node[index] = value
+			return node, nil
+		}
+
+		return nil, fmt.Errorf("member %q doesn't exist", token)
+	})
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"github.com/tredoc/go-crud-api/internal/validator"
+	"time"
+)
+
+type Review struct {
+	ID        int64     `json:"id,omitempty"`
+	BookID    int64     `json:"book_id"`
+	UserID    int64     `json:"user_id"`
+	Rating    uint8     `json:"rating"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+type UpdateReview struct {
+	Rating *uint8  `json:"rating"`
+	Text   *string `json:"text"`
+}
+
+func ValidateReview(v *validator.Validator, review *Review) {
+	v.Check(review.Rating >= 1 && review.Rating <= 5, "rating", validator.MustBeFrom1To5)
+	v.Check(review.Text != "", "text", validator.CantBeEmpty)
+	v.Check(len(review.Text) <= 5000, "text", validator.CantBeLongerThan5k)
+}
+
+func ValidateUpdateReview(v *validator.Validator, review *UpdateReview) {
+	if review.Rating != nil {
+		v.Check(*review.Rating >= 1 && *review.Rating <= 5, "rating", validator.MustBeFrom1To5)
+	}
+
+	if review.Text != nil {
+		v.Check(*review.Text != "", "text", validator.CantBeEmpty)
+		v.Check(len(*review.Text) <= 5000, "text", validator.CantBeLongerThan5k)
+	}
+}
@@ -0,0 +1,131 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+)
+
+type EntityType string
+
+const (
+	BookEntity   EntityType = "book"
+	AuthorEntity EntityType = "author"
+	GenreEntity  EntityType = "genre"
+)
+
+type RevisionAction string
+
+const (
+	RevisionCreate  RevisionAction = "create"
+	RevisionUpdate  RevisionAction = "update"
+	RevisionDelete  RevisionAction = "delete"
+	RevisionRevert  RevisionAction = "revert"
+	RevisionMerge   RevisionAction = "merge"
+	RevisionRestore RevisionAction = "restore"
+	RevisionPurge   RevisionAction = "purge"
+)
+
+// Revision is a snapshot of a book, author or genre taken after a change, ActorID is the user
+// who made the change.
+type Revision struct {
+	ID        int64           `json:"id"`
+	Action    RevisionAction  `json:"action"`
+	Snapshot  json.RawMessage `json:"snapshot" swaggertype:"object"`
+	ActorID   *int64          `json:"actor_id"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// BookSnapshot holds the fields of a book that admins edit, ratings and tags are left out.
+type BookSnapshot struct {
+	Title       string     `json:"title"`
+	PublishDate CustomDate `json:"publish_date"`
+	ISBN        string     `json:"isbn"`
+	Pages       uint16     `json:"pages"`
+	Authors     []int64    `json:"authors"`
+	Genres      []int64    `json:"genres"`
+}
+
+func NewBookSnapshot(book *Book) *BookSnapshot {
+	return &BookSnapshot{
+		Title:       book.Title,
+		PublishDate: book.PublishDate,
+		ISBN:        book.ISBN,
+		Pages:       book.Pages,
+		Authors:     book.Authors,
+		Genres:      book.Genres,
+	}
+}
+
+// Apply overwrites the editable fields of the book with the snapshot.
+func (s *BookSnapshot) Apply(book *Book) {
+	book.Title = s.Title
+	book.PublishDate = s.PublishDate
+	book.ISBN = s.ISBN
+	book.Pages = s.Pages
+	book.Authors = s.Authors
+	book.Genres = s.Genres
+}
+
+// NewAuthorSnapshot returns a copy of the author without the version, which changes with every
+// revision anyway.
+func NewAuthorSnapshot(author *Author) *Author {
+	snapshot := *author
+	snapshot.Version = 0
+	return &snapshot
+}
+
+// NewGenreSnapshot returns a copy of the genre without the version.
+func NewGenreSnapshot(genre *Genre) *Genre {
+	snapshot := *genre
+	snapshot.Version = 0
+	return &snapshot
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+type RevisionDiff struct {
+	From    int64          `json:"from"`
+	To      int64          `json:"to"`
+	Changes []*FieldChange `json:"changes"`
+}
+
+// DiffRevisions lists the top level snapshot fields that differ between the revisions, sorted by
+// field name. A field missing from one of the snapshots is reported as null.
+func DiffRevisions(from *Revision, to *Revision) (*RevisionDiff, error) {
+	var fromFields, toFields map[string]any
+	err := json.Unmarshal(from.Snapshot, &fromFields)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(to.Snapshot, &toFields)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, 0, len(fromFields)+len(toFields))
+	for field := range fromFields {
+		fields = append(fields, field)
+	}
+	for field := range toFields {
+		if _, ok := fromFields[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	diff := RevisionDiff{From: from.ID, To: to.ID, Changes: []*FieldChange{}}
+	for _, field := range fields {
+		if !reflect.DeepEqual(fromFields[field], toFields[field]) {
+			diff.Changes = append(diff.Changes, &FieldChange{Field: field, From: fromFields[field], To: toFields[field]})
+		}
+	}
+
+	return &diff, nil
+}
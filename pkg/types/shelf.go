@@ -0,0 +1,110 @@
+package types
+
+import (
+	"github.com/tredoc/go-crud-api/internal/validator"
+	"time"
+)
+
+type ShelfStatus string
+
+const (
+	WantToReadShelf ShelfStatus = "want-to-read"
+	ReadingShelf    ShelfStatus = "reading"
+	ReadShelf       ShelfStatus = "read"
+)
+
+var ShelfStatuses = []ShelfStatus{WantToReadShelf, ReadingShelf, ReadShelf}
+
+func (s ShelfStatus) IsValid() bool {
+	for _, status := range ShelfStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ShelfRef points either to one of the built-in status shelves or to a custom shelf by ID.
+type ShelfRef struct {
+	Status ShelfStatus
+	ID     int64
+}
+
+func (r ShelfRef) IsBuiltIn() bool {
+	return r.Status != ""
+}
+
+type Shelf struct {
+	ID         int64       `json:"id,omitempty"`
+	Name       string      `json:"name"`
+	Status     ShelfStatus `json:"status,omitempty"`
+	BooksCount int64       `json:"books_count"`
+	CreatedAt  *time.Time  `json:"created_at,omitempty"`
+}
+
+func ValidateShelf(v *validator.Validator, shelf *Shelf) {
+	v.Check(shelf.Name != "", "name", validator.CantBeEmpty)
+	v.Check(len(shelf.Name) <= 100, "name", validator.CantBeLongerThan100)
+	v.Check(!ShelfStatus(shelf.Name).IsValid(), "name", "is reserved for a built-in shelf")
+}
+
+type ShelfBook struct {
+	BookID int64 `json:"book_id"`
+}
+
+func ValidateShelfBook(v *validator.Validator, book *ShelfBook) {
+	v.Check(book.BookID > 0, "book_id", validator.CantBeLessThanOne)
+}
+
+type ShelvedBook struct {
+	BookID      int64       `json:"book_id"`
+	Title       string      `json:"title"`
+	Pages       uint16      `json:"pages"`
+	Status      ShelfStatus `json:"status,omitempty"`
+	CurrentPage uint16      `json:"current_page"`
+	StartedAt   *CustomDate `json:"started_at"`
+	FinishedAt  *CustomDate `json:"finished_at"`
+	AddedAt     time.Time   `json:"added_at"`
+}
+
+type ReadingEntry struct {
+	BookID      int64       `json:"book_id"`
+	Status      ShelfStatus `json:"status"`
+	CurrentPage uint16      `json:"current_page"`
+	StartedAt   *CustomDate `json:"started_at"`
+	FinishedAt  *CustomDate `json:"finished_at"`
+	UpdatedAt   time.Time   `json:"updated_at,omitempty"`
+}
+
+type UpdateReading struct {
+	Status      *ShelfStatus `json:"status"`
+	CurrentPage *uint16      `json:"current_page"`
+	StartedAt   *CustomDate  `json:"started_at"`
+	FinishedAt  *CustomDate  `json:"finished_at"`
+}
+
+func ValidateUpdateReading(v *validator.Validator, reading *UpdateReading) {
+	if reading.Status != nil {
+		v.Check(reading.Status.IsValid(), "status", "must be one of want-to-read, reading, read")
+	}
+
+	if reading.StartedAt != nil {
+		v.Check(reading.StartedAt.Before(time.Now()), "started_at", validator.CantBeInFuture)
+	}
+
+	if reading.FinishedAt != nil {
+		v.Check(reading.FinishedAt.Before(time.Now()), "finished_at", validator.CantBeInFuture)
+	}
+
+	if reading.StartedAt != nil && reading.FinishedAt != nil {
+		v.Check(!reading.FinishedAt.Before(reading.StartedAt.Time), "finished_at", "can't be before started_at")
+	}
+}
+
+type ReadingStats struct {
+	Year             int       `json:"year"`
+	BooksRead        int64     `json:"books_read"`
+	PagesRead        int64     `json:"pages_read"`
+	BooksByMonth     [12]int64 `json:"books_by_month"`
+	CurrentlyReading int64     `json:"currently_reading"`
+}
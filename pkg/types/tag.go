@@ -0,0 +1,56 @@
+package types
+
+import (
+	"github.com/tredoc/go-crud-api/internal/validator"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const MaxTagsPerRequest = 20
+
+var tagRX = regexp.MustCompile(`^[\p{L}\p{M}\p{N}]+(?:[ -][\p{L}\p{M}\p{N}]+)*$`)
+
+// NormalizeTag lowercases the tag and collapses runs of whitespace so that
+// "Space  Opera" and "space opera" end up as the same tag.
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// NormalizeTags normalizes every tag and drops duplicates keeping the original order.
+func NormalizeTags(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	tags := make([]string, 0, len(names))
+	for _, name := range names {
+		tag := NormalizeTag(name)
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+func ValidateTag(v *validator.Validator, key string, tag string) {
+	v.Check(tag != "", key, validator.CantBeEmpty)
+	v.Check(utf8.RuneCountInString(tag) <= 50, key, validator.CantBeLongerThan50)
+	v.Check(v.Matches(tag, tagRX), key, "should contain only letters, digits, single spaces and hyphens")
+}
+
+type BookTags struct {
+	Tags []string `json:"tags"`
+}
+
+func ValidateBookTags(v *validator.Validator, bookTags *BookTags) {
+	v.Check(len(bookTags.Tags) > 0, "tags", validator.CantBeEmpty)
+	v.Check(len(bookTags.Tags) <= MaxTagsPerRequest, "tags", "can't contain more than 20 tags")
+	for _, tag := range bookTags.Tags {
+		ValidateTag(v, "tags", tag)
+	}
+}
+
+type TagCount struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
@@ -0,0 +1,100 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"github.com/tredoc/go-crud-api/internal/validator"
+	"golang.org/x/text/language"
+	"strings"
+	"unicode/utf8"
+)
+
+const LanguagesContextKey = contextKey("languages")
+
+// MaxLanguageChain bounds the number of language tags looked up for a single request.
+const MaxLanguageChain = 20
+
+var ErrInvalidLanguageTag = errors.New("invalid language tag")
+
+// anyLanguage is what the "*" range of Accept-Language parses to.
+var anyLanguage = language.MustParse("mul")
+
+// Translation is a genre name or a book title in the language given by a BCP 47 tag.
+type Translation struct {
+	Language string `json:"language"`
+	Text     string `json:"text"`
+}
+
+func ValidateGenreTranslation(v *validator.Validator, translation *Translation) {
+	v.Check(translation.Text != "", "text", validator.CantBeEmpty)
+	ValidateName(v, "text", translation.Text)
+}
+
+func ValidateBookTranslation(v *validator.Validator, translation *Translation) {
+	v.Check(translation.Text != "", "text", validator.CantBeEmpty)
+	v.Check(utf8.RuneCountInString(translation.Text) <= 255, "text", "can't be longer than 255 characters")
+}
+
+// ParseLanguageTag returns the canonical form of a BCP 47 tag, e.g. "pt-br" becomes "pt-BR".
+func ParseLanguageTag(tag string) (string, error) {
+	parsed, err := language.Parse(tag)
+	if err != nil || parsed == language.Und {
+		return "", ErrInvalidLanguageTag
+	}
+
+	return parsed.String(), nil
+}
+
+// LanguageChain turns an Accept-Language header into the tags to look translations up by, most
+// preferred first. Every tag is followed by its less specific parents, so "de-CH, fr;q=0.8"
+// gives de-CH, de, fr.
+func LanguageChain(acceptLanguage string) []string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var chain []string
+	for _, tag := range tags {
+		if tag == anyLanguage {
+			continue
+		}
+
+		for ; tag != language.Und && len(chain) < MaxLanguageChain; tag = tag.Parent() {
+			name := tag.String()
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			chain = append(chain, name)
+		}
+	}
+
+	return chain
+}
+
+// Localize returns the translation in the first language of the chain that has one.
+func Localize(translations map[string]string, chain []string) (string, bool) {
+	for _, tag := range chain {
+		if text, ok := translations[tag]; ok {
+			return text, true
+		}
+	}
+
+	return "", false
+}
+
+func NormalizeGenreTranslation(translation *Translation) {
+	translation.Text = strings.ToLower(NormalizeName(translation.Text))
+}
+
+func NormalizeBookTranslation(translation *Translation) {
+	translation.Text = strings.Join(strings.Fields(translation.Text), " ")
+}
+
+// LanguagesFromContext returns the language chain of the request, nil when the client didn't send one.
+func LanguagesFromContext(ctx context.Context) []string {
+	chain, _ := ctx.Value(LanguagesContextKey).([]string)
+	return chain
+}
@@ -0,0 +1,32 @@
+package types
+
+import "time"
+
+// TrashItem is a deleted book, author or genre that can still be restored.
+type TrashItem struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type Trash struct {
+	Books   []*TrashItem `json:"books"`
+	Authors []*TrashItem `json:"authors"`
+	Genres  []*TrashItem `json:"genres"`
+}
+
+// DeletePolicy decides what deleting an author or genre that is still listed on books does.
+type DeletePolicy string
+
+const (
+	RestrictOnDelete DeletePolicy = "restrict"
+	CascadeOnDelete  DeletePolicy = "cascade"
+)
+
+func (p DeletePolicy) IsValid() bool {
+	switch p {
+	case RestrictOnDelete, CascadeOnDelete:
+		return true
+	}
+	return false
+}
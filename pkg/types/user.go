@@ -1,6 +1,7 @@
 package types
 
 import (
+	"context"
 	"errors"
 	"github.com/tredoc/go-crud-api/internal/validator"
 	"golang.org/x/crypto/bcrypt"
@@ -30,10 +31,20 @@ type User struct {
 
 var AnonymousUser = &User{}
 
+// UserFromContext returns the user who sent the request, nil outside of a request.
+func UserFromContext(ctx context.Context) *User {
+	user, _ := ctx.Value(UserContextKey).(*User)
+	return user
+}
+
 func (u *User) IsAnonymous() bool {
 	return u == AnonymousUser
 }
 
+func (u *User) IsAdmin() bool {
+	return u.Role == AdminRole
+}
+
 type Password struct {
 	Plaintext *string
 	Hash      []byte